@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"blog-cdc-search/domain"
+)
+
+// PostHistoryService serves the version history recorded by the CDC history
+// sink and restores old versions
+type PostHistoryService struct {
+	repo  domain.PostVersionRepository
+	posts *PostService
+}
+
+// NewPostHistoryService creates a history service; restores go through posts
+func NewPostHistoryService(repo domain.PostVersionRepository, posts *PostService) *PostHistoryService {
+	return &PostHistoryService{repo: repo, posts: posts}
+}
+
+// ListVersions returns every version of a post, newest first
+func (s *PostHistoryService) ListVersions(ctx context.Context, postID int) ([]*domain.PostVersion, error) {
+	if postID <= 0 {
+		return nil, errors.New("invalid post ID")
+	}
+	return s.repo.ListVersions(ctx, postID)
+}
+
+// GetVersion returns a version and its field-level diff against the version before it
+func (s *PostHistoryService) GetVersion(ctx context.Context, postID, version int) (*domain.PostVersionDiff, error) {
+	if postID <= 0 {
+		return nil, errors.New("invalid post ID")
+	}
+
+	current, err := s.repo.GetVersion(ctx, postID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *domain.PostVersion
+	if version > 1 {
+		previous, err = s.repo.GetVersion(ctx, postID, version-1)
+		if err != nil && !errors.Is(err, domain.ErrPostVersionNotFound) {
+			return nil, err
+		}
+	}
+
+	return &domain.PostVersionDiff{
+		Version:  current,
+		Previous: previous,
+		Changes:  domain.DiffPostVersions(previous, current),
+	}, nil
+}
+
+// RestoreVersion writes a version's content and metadata back to the post.
+// The update reaches the history through CDC like any other edit, as a new
+// version. A version without a language keeps the post's current one.
+func (s *PostHistoryService) RestoreVersion(ctx context.Context, postID, version int) (*domain.Post, error) {
+	restored, err := s.repo.GetVersion(ctx, postID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.posts.UpdatePost(ctx, postID, restored.Title, restored.Image, restored.Excerpt, restored.Body, restored.Author, restored.Category, restored.Language, restored.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore version %d: %w", version, err)
+	}
+	return post, nil
+}
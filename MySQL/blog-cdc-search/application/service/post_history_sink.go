@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// PostHistorySink records every post change as a new version in the post
+// history. Run it synchronously so no version is lost when the service stops.
+type PostHistorySink struct {
+	repo domain.PostVersionRepository
+	now  func() time.Time
+}
+
+// NewPostHistorySink creates a sink writing to repo
+func NewPostHistorySink(repo domain.PostVersionRepository) *PostHistorySink {
+	return &PostHistorySink{repo: repo, now: time.Now}
+}
+
+// Name returns the sink name used in stats and checkpoints
+func (s *PostHistorySink) Name() string {
+	return "history"
+}
+
+// Apply appends the post's new version. The first update of a post with no
+// history also records the state before it, taken from the event's old image.
+// A change that leaves the versioned fields as they were is not recorded, so
+// redelivered events and repeated snapshots do not add versions.
+func (s *PostHistorySink) Apply(ctx context.Context, change *domain.Change) error {
+	event := change.Event
+	if event == nil {
+		return nil
+	}
+
+	var operation string
+	switch event.Type {
+	case domain.EventTypeInsert:
+		operation = domain.PostVersionCreated
+	case domain.EventTypeUpdate:
+		operation = domain.PostVersionUpdated
+	case domain.EventTypeDelete:
+		operation = domain.PostVersionDeleted
+	case domain.EventTypeBootstrapInsert:
+		operation = domain.PostVersionSnapshot
+	default:
+		return nil
+	}
+
+	postID, ok := event.GetID()
+	if !ok {
+		return fmt.Errorf("failed to extract post ID from %s event", event.Type)
+	}
+
+	latest, err := s.repo.LatestVersion(ctx, postID)
+	if err != nil {
+		return fmt.Errorf("failed to load latest post version: %w", err)
+	}
+
+	changedAt := event.Time()
+	if changedAt.IsZero() {
+		changedAt = s.now()
+	}
+
+	if latest == nil && operation == domain.PostVersionUpdated && event.Old != nil {
+		// Maxwell's old image only holds the changed columns, Debezium's
+		// before image the whole row; overlaying it on the new row covers both
+		previous := make(map[string]interface{}, len(event.Data))
+		for key, value := range event.Data {
+			previous[key] = value
+		}
+		for key, value := range event.Old {
+			previous[key] = value
+		}
+
+		baseline := newPostVersion(postID, previous, nil)
+		baseline.Version = 1
+		baseline.Operation = domain.PostVersionSnapshot
+		baseline.ChangedAt = changedAt
+		baseline.ChangedFields = changedFieldNames(nil, baseline)
+		if err := s.repo.CreateVersion(ctx, baseline); err != nil {
+			return fmt.Errorf("failed to record post version: %w", err)
+		}
+		latest = baseline
+	}
+
+	// A delete's before image may only carry the key, so a deleted post keeps
+	// its last known content
+	data := event.Data
+	if operation == domain.PostVersionDeleted && latest != nil {
+		data = nil
+	}
+	version := newPostVersion(postID, data, latest)
+	version.Operation = operation
+	version.ChangedAt = changedAt
+	version.ChangedFields = changedFieldNames(latest, version)
+
+	if latest != nil {
+		if operation != domain.PostVersionDeleted && len(version.ChangedFields) == 0 {
+			return nil
+		}
+		if operation == domain.PostVersionDeleted && latest.Operation == domain.PostVersionDeleted {
+			return nil
+		}
+		version.Version = latest.Version + 1
+	} else {
+		version.Version = 1
+	}
+
+	if err := s.repo.CreateVersion(ctx, version); err != nil {
+		return fmt.Errorf("failed to record post version: %w", err)
+	}
+	return nil
+}
+
+// debeziumUnavailableValue stands in for unchanged TOASTed columns that
+// PostgreSQL does not send with an update
+const debeziumUnavailableValue = "__debezium_unavailable_value"
+
+// newPostVersion reads the versioned fields from a row image, falling back
+// to base for fields the image does not contain
+func newPostVersion(postID int, data map[string]interface{}, base *domain.PostVersion) *domain.PostVersion {
+	version := &domain.PostVersion{PostID: postID, Tags: []string{}}
+	if base != nil {
+		version.Title, version.Image, version.Excerpt, version.Body = base.Title, base.Image, base.Excerpt, base.Body
+		version.Author, version.Category, version.Language, version.Tags = base.Author, base.Category, base.Language, base.Tags
+	}
+
+	// Tags are stored comma-separated, like the posts column
+	tags := domain.JoinTags(version.Tags)
+
+	fields := map[string]*string{
+		"title":    &version.Title,
+		"image":    &version.Image,
+		"excerpt":  &version.Excerpt,
+		"body":     &version.Body,
+		"author":   &version.Author,
+		"category": &version.Category,
+		"language": &version.Language,
+		"tags":     &tags,
+	}
+	for name, target := range fields {
+		value, ok := data[name]
+		if !ok {
+			continue
+		}
+		if text, ok := value.(string); ok {
+			if text != debeziumUnavailableValue {
+				*target = text
+			}
+		} else if value == nil {
+			*target = ""
+		}
+	}
+	version.Tags = domain.SplitTags(tags)
+	return version
+}
+
+func changedFieldNames(previous, current *domain.PostVersion) []string {
+	names := []string{}
+	for _, change := range domain.DiffPostVersions(previous, current) {
+		names = append(names, change.Field)
+	}
+	return names
+}
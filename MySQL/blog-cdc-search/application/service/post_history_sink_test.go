@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"blog-cdc-search/domain"
+)
+
+// memoryPostVersionRepository keeps post versions in a slice
+type memoryPostVersionRepository struct {
+	versions []*domain.PostVersion
+}
+
+func (m *memoryPostVersionRepository) CreateVersion(ctx context.Context, version *domain.PostVersion) error {
+	version.ID = int64(len(m.versions) + 1)
+	m.versions = append(m.versions, version)
+	return nil
+}
+
+func (m *memoryPostVersionRepository) LatestVersion(ctx context.Context, postID int) (*domain.PostVersion, error) {
+	var latest *domain.PostVersion
+	for _, version := range m.versions {
+		if version.PostID == postID {
+			latest = version
+		}
+	}
+	return latest, nil
+}
+
+func (m *memoryPostVersionRepository) GetVersion(ctx context.Context, postID, version int) (*domain.PostVersion, error) {
+	for _, v := range m.versions {
+		if v.PostID == postID && v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, domain.ErrPostVersionNotFound
+}
+
+func (m *memoryPostVersionRepository) ListVersions(ctx context.Context, postID int) ([]*domain.PostVersion, error) {
+	var versions []*domain.PostVersion
+	for i := len(m.versions) - 1; i >= 0; i-- {
+		if m.versions[i].PostID == postID {
+			versions = append(versions, m.versions[i])
+		}
+	}
+	return versions, nil
+}
+
+func postRow(id int, title, body string) map[string]interface{} {
+	return map[string]interface{}{
+		"id": float64(id), "title": title, "image": "", "excerpt": "", "body": body,
+		"author": "", "category": "", "language": "en", "tags": "",
+	}
+}
+
+func applyHistory(t *testing.T, sink *PostHistorySink, event *domain.CDCEvent) {
+	t.Helper()
+	if err := sink.Apply(context.Background(), &domain.Change{Event: event}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+}
+
+func TestPostHistorySink_RecordsVersions(t *testing.T) {
+	repo := &memoryPostVersionRepository{}
+	sink := NewPostHistorySink(repo)
+
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeInsert, Data: postRow(1, "Draft", "Body")})
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeUpdate, Data: postRow(1, "Final", "Body"), Old: map[string]interface{}{"title": "Draft"}})
+	// Redelivered update: nothing changed
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeUpdate, Data: postRow(1, "Final", "Body")})
+	// The before image of a delete may only carry the key
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeDelete, Data: map[string]interface{}{"id": float64(1)}})
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeDelete, Data: map[string]interface{}{"id": float64(1)}})
+
+	if len(repo.versions) != 3 {
+		t.Fatalf("Expected 3 versions, got %d", len(repo.versions))
+	}
+
+	wantOperations := []string{domain.PostVersionCreated, domain.PostVersionUpdated, domain.PostVersionDeleted}
+	for i, version := range repo.versions {
+		if version.Version != i+1 || version.Operation != wantOperations[i] {
+			t.Errorf("Version %d: expected %s, got %d %s", i+1, wantOperations[i], version.Version, version.Operation)
+		}
+	}
+	if got := repo.versions[1]; !reflect.DeepEqual(got.ChangedFields, []string{"title"}) {
+		t.Errorf("Expected the update to change the title, got %+v", got)
+	}
+	if got := repo.versions[2]; got.Title != "Final" || got.Body != "Body" {
+		t.Errorf("Expected the deleted version to keep the last content, got %+v", got)
+	}
+}
+
+func TestPostHistorySink_RecordsMetadataEdits(t *testing.T) {
+	repo := &memoryPostVersionRepository{}
+	sink := NewPostHistorySink(repo)
+
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeInsert, Data: postRow(1, "Title", "Body")})
+	updated := postRow(1, "Title", "Body")
+	updated["category"], updated["tags"] = "News", "go,cdc"
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeUpdate, Data: updated})
+
+	if len(repo.versions) != 2 {
+		t.Fatalf("Expected a metadata-only edit to add a version, got %d versions", len(repo.versions))
+	}
+	got := repo.versions[1]
+	if !reflect.DeepEqual(got.ChangedFields, []string{"category", "tags"}) || got.Category != "News" || !reflect.DeepEqual(got.Tags, []string{"go", "cdc"}) || got.Language != "en" {
+		t.Errorf("Expected the category and tags to change, got %+v", got)
+	}
+}
+
+func TestPostHistorySink_BaselineFromOldImage(t *testing.T) {
+	repo := &memoryPostVersionRepository{}
+	sink := NewPostHistorySink(repo)
+
+	// The post was created before the history existed
+	applyHistory(t, sink, &domain.CDCEvent{
+		Table: "posts",
+		Type:  domain.EventTypeUpdate,
+		Data:  postRow(7, "New title", "Body"),
+		Old:   map[string]interface{}{"title": "Old title"},
+	})
+
+	if len(repo.versions) != 2 {
+		t.Fatalf("Expected a baseline and the update, got %d versions", len(repo.versions))
+	}
+	baseline, update := repo.versions[0], repo.versions[1]
+	if baseline.Operation != domain.PostVersionSnapshot || baseline.Title != "Old title" || baseline.Body != "Body" {
+		t.Errorf("Expected a snapshot of the post before the update, got %+v", baseline)
+	}
+	if update.Version != 2 || update.Title != "New title" || !reflect.DeepEqual(update.ChangedFields, []string{"title"}) {
+		t.Errorf("Expected version 2 to change the title, got %+v", update)
+	}
+}
+
+func TestPostHistorySink_IgnoresOtherEvents(t *testing.T) {
+	repo := &memoryPostVersionRepository{}
+	sink := NewPostHistorySink(repo)
+
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeBootstrapStart})
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeBootstrapInsert, Data: postRow(1, "Title", "Body")})
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeBootstrapInsert, Data: postRow(1, "Title", "Body")})
+
+	if len(repo.versions) != 1 || repo.versions[0].Operation != domain.PostVersionSnapshot {
+		t.Errorf("Expected one snapshot version from repeated bootstraps, got %+v", repo.versions)
+	}
+}
@@ -3,13 +3,16 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"blog-cdc-search/domain"
 )
 
 // PostService handles business logic for posts
 type PostService struct {
-	repo domain.PostRepository
+	repo     domain.PostRepository
+	cache    domain.CacheRepository
+	cacheTTL time.Duration
 }
 
 // NewPostService creates a new PostService instance
@@ -19,12 +22,26 @@ func NewPostService(repo domain.PostRepository) *PostService {
 	}
 }
 
-// CreatePost creates a new post
-func (s *PostService) CreatePost(ctx context.Context, title, image, excerpt, body string) (*domain.Post, error) {
+// SetCache puts a read-through cache in front of GetPost. Entries are
+// invalidated by the CDC cache sink; ttl bounds how stale they can get if an
+// invalidation is lost.
+func (s *PostService) SetCache(cache domain.CacheRepository, ttl time.Duration) {
+	s.cache = cache
+	s.cacheTTL = ttl
+}
+
+// CreatePost creates a new post. Without a language it is detected from the
+// text.
+func (s *PostService) CreatePost(ctx context.Context, title, image, excerpt, body, author, category, language string, tags []string) (*domain.Post, error) {
 	post, err := domain.NewPost(title, image, excerpt, body)
 	if err != nil {
 		return nil, err
 	}
+	post.SetAuthor(author)
+	post.SetTaxonomy(category, tags)
+	if err := post.SetLanguage(language); err != nil {
+		return nil, err
+	}
 
 	if err := s.repo.Create(ctx, post); err != nil {
 		return nil, err
@@ -39,7 +56,21 @@ func (s *PostService) GetPost(ctx context.Context, id int) (*domain.Post, error)
 		return nil, errors.New("invalid post ID")
 	}
 
-	return s.repo.GetByID(ctx, id)
+	if s.cache == nil {
+		return s.repo.GetByID(ctx, id)
+	}
+
+	var cached domain.Post
+	if cacheGet(ctx, s.cache, PostCacheKey(id), &cached) {
+		return &cached, nil
+	}
+
+	post, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cacheSet(ctx, s.cache, PostCacheKey(id), post, s.cacheTTL)
+	return post, nil
 }
 
 // GetAllPosts retrieves all posts
@@ -47,8 +78,9 @@ func (s *PostService) GetAllPosts(ctx context.Context) ([]*domain.Post, error) {
 	return s.repo.GetAll(ctx)
 }
 
-// UpdatePost updates an existing post
-func (s *PostService) UpdatePost(ctx context.Context, id int, title, image, excerpt, body string) (*domain.Post, error) {
+// UpdatePost updates an existing post. Without a language the post keeps its
+// current one.
+func (s *PostService) UpdatePost(ctx context.Context, id int, title, image, excerpt, body, author, category, language string, tags []string) (*domain.Post, error) {
 	if id <= 0 {
 		return nil, errors.New("invalid post ID")
 	}
@@ -61,6 +93,11 @@ func (s *PostService) UpdatePost(ctx context.Context, id int, title, image, exce
 	if err := post.Update(title, image, excerpt, body); err != nil {
 		return nil, err
 	}
+	post.SetAuthor(author)
+	post.SetTaxonomy(category, tags)
+	if err := post.SetLanguage(language); err != nil {
+		return nil, err
+	}
 
 	if err := s.repo.Update(ctx, post); err != nil {
 		return nil, err
@@ -101,7 +101,7 @@ func TestPostService_CreatePost(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			post, err := service.CreatePost(ctx, tt.title, tt.image, tt.excerpt, tt.body)
+			post, err := service.CreatePost(ctx, tt.title, tt.image, tt.excerpt, tt.body, "", "", "", nil)
 
 			if tt.wantErr {
 				if err == nil {
@@ -136,7 +136,7 @@ func TestPostService_GetPost(t *testing.T) {
 	ctx := context.Background()
 
 	// Create a test post first
-	post, err := service.CreatePost(ctx, "Test Title", "test.jpg", "Test excerpt", "Test body")
+	post, err := service.CreatePost(ctx, "Test Title", "test.jpg", "Test excerpt", "Test body", "", "", "", nil)
 	if err != nil {
 		t.Fatalf("Failed to create test post: %v", err)
 	}
@@ -214,12 +214,12 @@ func TestPostService_GetAllPosts(t *testing.T) {
 	}
 
 	// Create some test posts
-	_, err = service.CreatePost(ctx, "Post 1", "img1.jpg", "Excerpt 1", "Body 1")
+	_, err = service.CreatePost(ctx, "Post 1", "img1.jpg", "Excerpt 1", "Body 1", "", "", "", nil)
 	if err != nil {
 		t.Fatalf("Failed to create test post 1: %v", err)
 	}
 
-	_, err = service.CreatePost(ctx, "Post 2", "img2.jpg", "Excerpt 2", "Body 2")
+	_, err = service.CreatePost(ctx, "Post 2", "img2.jpg", "Excerpt 2", "Body 2", "", "", "", nil)
 	if err != nil {
 		t.Fatalf("Failed to create test post 2: %v", err)
 	}
@@ -240,7 +240,7 @@ func TestPostService_UpdatePost(t *testing.T) {
 	ctx := context.Background()
 
 	// Create a test post first
-	post, err := service.CreatePost(ctx, "Original Title", "original.jpg", "Original excerpt", "Original body")
+	post, err := service.CreatePost(ctx, "Original Title", "original.jpg", "Original excerpt", "Original body", "", "", "", nil)
 	if err != nil {
 		t.Fatalf("Failed to create test post: %v", err)
 	}
@@ -294,7 +294,7 @@ func TestPostService_UpdatePost(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := service.UpdatePost(ctx, tt.id, tt.title, tt.image, tt.excerpt, tt.body)
+			result, err := service.UpdatePost(ctx, tt.id, tt.title, tt.image, tt.excerpt, tt.body, "", "", "", nil)
 
 			if tt.wantErr {
 				if err == nil {
@@ -319,13 +319,71 @@ func TestPostService_UpdatePost(t *testing.T) {
 	}
 }
 
+func TestPostService_Taxonomy(t *testing.T) {
+	repo := NewMockPostRepository()
+	service := NewPostService(repo)
+	ctx := context.Background()
+
+	post, err := service.CreatePost(ctx, "Title", "", "", "Body", "", " News ", "", []string{"Go", "go", "CDC"})
+	if err != nil {
+		t.Fatalf("CreatePost() unexpected error: %v", err)
+	}
+	if post.Category != "News" || len(post.Tags) != 2 || post.Tags[0] != "go" || post.Tags[1] != "cdc" {
+		t.Errorf("CreatePost() taxonomy = %q %v", post.Category, post.Tags)
+	}
+
+	updated, err := service.UpdatePost(ctx, post.ID, "Title", "", "", "Body", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("UpdatePost() unexpected error: %v", err)
+	}
+	if updated.Category != "" || len(updated.Tags) != 0 {
+		t.Errorf("UpdatePost() should clear taxonomy, got %q %v", updated.Category, updated.Tags)
+	}
+}
+
+func TestPostService_Language(t *testing.T) {
+	repo := NewMockPostRepository()
+	service := NewPostService(repo)
+	ctx := context.Background()
+
+	// Without a language it is detected from the text
+	post, err := service.CreatePost(ctx, "برنامه‌نویسی همروند", "", "", "گوروتین‌ها و کانال‌ها در زبان گو", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("CreatePost() unexpected error: %v", err)
+	}
+	if post.Language != "fa" {
+		t.Errorf("CreatePost() language = %q, want fa", post.Language)
+	}
+
+	post, err = service.CreatePost(ctx, "Title", "", "", "Body", "", "", " de-AT ", nil)
+	if err != nil {
+		t.Fatalf("CreatePost() unexpected error: %v", err)
+	}
+	if post.Language != "de" {
+		t.Errorf("CreatePost() language = %q, want de", post.Language)
+	}
+
+	// Updates without a language keep the current one
+	updated, err := service.UpdatePost(ctx, post.ID, "Title", "", "", "Body", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("UpdatePost() unexpected error: %v", err)
+	}
+	if updated.Language != "de" {
+		t.Errorf("UpdatePost() language = %q, want de", updated.Language)
+	}
+
+	if _, err := service.CreatePost(ctx, "Title", "", "", "Body", "", "", "not a language", nil); !errors.Is(err, domain.ErrInvalidLanguage) {
+		t.Errorf("CreatePost() expected ErrInvalidLanguage, got %v", err)
+	}
+}
+
 func TestPostService_DeletePost(t *testing.T) {
 	repo := NewMockPostRepository()
 	service := NewPostService(repo)
 	ctx := context.Background()
 
 	// Create a test post first
-	post, err := service.CreatePost(ctx, "Test Title", "test.jpg", "Test excerpt", "Test body")
+	post, err := service.CreatePost(ctx, "Test Title", "test.jpg", "Test excerpt", "Test body", "", "", "", nil)
 	if err != nil {
 		t.Fatalf("Failed to create test post: %v", err)
 	}
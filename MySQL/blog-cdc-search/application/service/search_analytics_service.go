@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// DefaultSearchAnalyticsDays is the period a report covers when none is given
+const DefaultSearchAnalyticsDays = 30
+
+// SearchAnalyticsLimit is the number of queries in each list of a report
+const SearchAnalyticsLimit = 50
+
+// searchAnalyticsTimeout bounds recording, so a slow database does not hold
+// up the search it records
+const searchAnalyticsTimeout = time.Second
+
+// maxSearchQueryLength is the longest query stored, in characters
+const maxSearchQueryLength = 255
+
+// SearchAnalyticsService records what readers search for and which results
+// they click, and reports on it per query
+type SearchAnalyticsService struct {
+	repo domain.SearchAnalyticsRepository
+	salt []byte
+	now  func() time.Time
+}
+
+// NewSearchAnalyticsService creates a new search analytics service. The salt
+// keeps session IDs from being traced back to readers; without one a random
+// salt is used, so sessions are not comparable across restarts or instances.
+func NewSearchAnalyticsService(repo domain.SearchAnalyticsRepository, salt string) *SearchAnalyticsService {
+	service := &SearchAnalyticsService{
+		repo: repo,
+		salt: []byte(salt),
+		now:  time.Now,
+	}
+	if salt == "" {
+		service.salt = make([]byte, 32)
+		rand.Read(service.salt)
+	}
+	return service
+}
+
+// SessionID anonymizes a reader as a hash of the salt, the day, their
+// address and their user agent. It changes daily, so readers cannot be
+// followed from one day to the next.
+func (s *SearchAnalyticsService) SessionID(clientAddr, userAgent string) string {
+	hash := sha256.New()
+	hash.Write(s.salt)
+	fmt.Fprintf(hash, "\x00%s\x00%s\x00%s", s.now().UTC().Format("2006-01-02"), clientAddr, userAgent)
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// RecordSearch stores a search and returns its ID, which result clicks refer
+// to. Empty queries are not recorded and get ID 0.
+func (s *SearchAnalyticsService) RecordSearch(ctx context.Context, query string, results, page int, latency time.Duration, session string) (int64, error) {
+	query = domain.NormalizeSearchQuery(query)
+	if query == "" {
+		return 0, nil
+	}
+	if runes := []rune(query); len(runes) > maxSearchQueryLength {
+		query = string(runes[:maxSearchQueryLength])
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, searchAnalyticsTimeout)
+	defer cancel()
+
+	search := &domain.SearchEvent{
+		Query:     query,
+		Results:   results,
+		LatencyMS: int(latency.Milliseconds()),
+		Page:      page,
+		Session:   session,
+		CreatedAt: s.now(),
+	}
+	if err := s.repo.RecordSearch(ctx, search); err != nil {
+		return 0, err
+	}
+	return search.ID, nil
+}
+
+// RecordClick stores a click on the result at position of a search
+func (s *SearchAnalyticsService) RecordClick(ctx context.Context, searchID int64, postID, position int) error {
+	if searchID <= 0 || postID <= 0 || position <= 0 {
+		return fmt.Errorf("%w: search, post and position are required", domain.ErrInvalidSearchClick)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, searchAnalyticsTimeout)
+	defer cancel()
+
+	return s.repo.RecordClick(ctx, &domain.SearchClick{
+		SearchID:  searchID,
+		PostID:    postID,
+		Position:  position,
+		CreatedAt: s.now(),
+	})
+}
+
+// Report returns the top and zero-result queries of the last days, or of
+// the last DefaultSearchAnalyticsDays
+func (s *SearchAnalyticsService) Report(ctx context.Context, days int) (*domain.SearchAnalyticsReport, error) {
+	if days <= 0 {
+		days = DefaultSearchAnalyticsDays
+	}
+	since := s.now().AddDate(0, 0, -days)
+
+	summary, err := s.repo.Summary(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Searches > 0 {
+		summary.ZeroResultRate = float64(summary.ZeroResults) / float64(summary.Searches)
+		summary.CTR = float64(summary.Clicked) / float64(summary.Searches)
+	}
+
+	top, err := s.repo.TopQueries(ctx, since, SearchAnalyticsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	zeroResults, err := s.repo.ZeroResultQueries(ctx, since, SearchAnalyticsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SearchAnalyticsReport{
+		Since:             since,
+		Summary:           *summary,
+		TopQueries:        top,
+		ZeroResultQueries: zeroResults,
+	}, nil
+}
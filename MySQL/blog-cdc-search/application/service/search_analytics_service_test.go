@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// MockSearchAnalyticsRepository keeps searches and clicks in memory
+type MockSearchAnalyticsRepository struct {
+	searches []*domain.SearchEvent
+	clicks   []*domain.SearchClick
+	summary  domain.SearchAnalyticsSummary
+	since    time.Time
+	limit    int
+}
+
+func (m *MockSearchAnalyticsRepository) RecordSearch(ctx context.Context, search *domain.SearchEvent) error {
+	search.ID = int64(len(m.searches) + 1)
+	m.searches = append(m.searches, search)
+	return nil
+}
+
+func (m *MockSearchAnalyticsRepository) RecordClick(ctx context.Context, click *domain.SearchClick) error {
+	click.ID = int64(len(m.clicks) + 1)
+	m.clicks = append(m.clicks, click)
+	return nil
+}
+
+func (m *MockSearchAnalyticsRepository) Summary(ctx context.Context, since time.Time) (*domain.SearchAnalyticsSummary, error) {
+	m.since = since
+	summary := m.summary
+	return &summary, nil
+}
+
+func (m *MockSearchAnalyticsRepository) TopQueries(ctx context.Context, since time.Time, limit int) ([]*domain.SearchQueryStats, error) {
+	m.limit = limit
+	return []*domain.SearchQueryStats{{Query: "go", Searches: 4, Clicked: 1, CTR: 0.25}}, nil
+}
+
+func (m *MockSearchAnalyticsRepository) ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]*domain.SearchQueryStats, error) {
+	return []*domain.SearchQueryStats{{Query: "rust", Searches: 2}}, nil
+}
+
+func TestSearchAnalyticsService_RecordSearch(t *testing.T) {
+	repo := &MockSearchAnalyticsRepository{}
+	service := NewSearchAnalyticsService(repo, "salt")
+	ctx := context.Background()
+
+	id, err := service.RecordSearch(ctx, "  Go   Concurrency ", 3, 0, 42*time.Millisecond, "session")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if id != 1 || len(repo.searches) != 1 {
+		t.Fatalf("Expected search 1 to be recorded, got %d and %d searches", id, len(repo.searches))
+	}
+	search := repo.searches[0]
+	if search.Query != "go concurrency" || search.Results != 3 || search.Page != 1 || search.LatencyMS != 42 || search.Session != "session" {
+		t.Errorf("Unexpected search: %+v", search)
+	}
+
+	if _, err := service.RecordSearch(ctx, strings.Repeat("é", 300), 0, 1, 0, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := len([]rune(repo.searches[1].Query)); got != maxSearchQueryLength {
+		t.Errorf("Expected the query to be truncated to %d characters, got %d", maxSearchQueryLength, got)
+	}
+
+	// Empty queries list posts rather than search them
+	id, err = service.RecordSearch(ctx, "   ", 10, 1, 0, "")
+	if err != nil || id != 0 || len(repo.searches) != 2 {
+		t.Errorf("Expected empty queries to be skipped, got %d, %v", id, err)
+	}
+}
+
+func TestSearchAnalyticsService_RecordClick(t *testing.T) {
+	repo := &MockSearchAnalyticsRepository{}
+	service := NewSearchAnalyticsService(repo, "salt")
+	ctx := context.Background()
+
+	if err := service.RecordClick(ctx, 7, 3, 2); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(repo.clicks) != 1 || repo.clicks[0].SearchID != 7 || repo.clicks[0].PostID != 3 || repo.clicks[0].Position != 2 {
+		t.Errorf("Unexpected clicks: %+v", repo.clicks)
+	}
+
+	for _, click := range [][3]int{{0, 3, 2}, {7, 0, 2}, {7, 3, 0}} {
+		err := service.RecordClick(ctx, int64(click[0]), click[1], click[2])
+		if !errors.Is(err, domain.ErrInvalidSearchClick) {
+			t.Errorf("Expected ErrInvalidSearchClick for %v, got %v", click, err)
+		}
+	}
+}
+
+func TestSearchAnalyticsService_SessionID(t *testing.T) {
+	service := NewSearchAnalyticsService(&MockSearchAnalyticsRepository{}, "salt")
+	day := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return day }
+
+	session := service.SessionID("203.0.113.7", "Firefox")
+	if len(session) != 32 || strings.Contains(session, "203.0.113.7") {
+		t.Fatalf("Expected an anonymized 32 character ID, got %q", session)
+	}
+	if service.SessionID("203.0.113.7", "Firefox") != session {
+		t.Error("Expected the same reader to get the same ID on the same day")
+	}
+	if service.SessionID("203.0.113.8", "Firefox") == session {
+		t.Error("Expected other readers to get other IDs")
+	}
+
+	service.now = func() time.Time { return day.AddDate(0, 0, 1) }
+	if service.SessionID("203.0.113.7", "Firefox") == session {
+		t.Error("Expected the ID to change the next day")
+	}
+}
+
+func TestSearchAnalyticsService_Report(t *testing.T) {
+	repo := &MockSearchAnalyticsRepository{
+		summary: domain.SearchAnalyticsSummary{Searches: 8, ZeroResults: 2, Clicked: 4},
+	}
+	service := NewSearchAnalyticsService(repo, "salt")
+	now := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	report, err := service.Report(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := now.AddDate(0, 0, -DefaultSearchAnalyticsDays); !report.Since.Equal(want) || !repo.since.Equal(want) {
+		t.Errorf("Expected the default period since %v, got %v", want, report.Since)
+	}
+	if repo.limit != SearchAnalyticsLimit {
+		t.Errorf("Expected limit %d, got %d", SearchAnalyticsLimit, repo.limit)
+	}
+	if report.Summary.ZeroResultRate != 0.25 || report.Summary.CTR != 0.5 {
+		t.Errorf("Unexpected rates: %+v", report.Summary)
+	}
+	if len(report.TopQueries) != 1 || len(report.ZeroResultQueries) != 1 {
+		t.Errorf("Unexpected queries: %+v", report)
+	}
+
+	report, _ = service.Report(context.Background(), 7)
+	if want := now.AddDate(0, 0, -7); !report.Since.Equal(want) {
+		t.Errorf("Expected the last 7 days, got %v", report.Since)
+	}
+}
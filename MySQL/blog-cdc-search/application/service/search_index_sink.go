@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"blog-cdc-search/domain"
+)
+
+// SearchIndexSink writes changes to a search index collection
+type SearchIndexSink struct {
+	searchIndex    domain.SearchIndexRepository
+	collectionName string
+	embedder       domain.Embedder
+}
+
+// NewSearchIndexSink creates a sink that keeps collectionName in sync
+func NewSearchIndexSink(searchIndex domain.SearchIndexRepository, collectionName string) *SearchIndexSink {
+	return &SearchIndexSink{
+		searchIndex:    searchIndex,
+		collectionName: collectionName,
+	}
+}
+
+// SetEmbedder makes the sink embed every upserted post for semantic search
+func (s *SearchIndexSink) SetEmbedder(embedder domain.Embedder) {
+	s.embedder = embedder
+}
+
+// Name returns the sink name used in stats and checkpoints
+func (s *SearchIndexSink) Name() string {
+	return "search"
+}
+
+// Apply upserts or deletes the changed document. An embedding failure is
+// retried like an indexing error and leaves the other sinks alone.
+func (s *SearchIndexSink) Apply(ctx context.Context, change *domain.Change) error {
+	switch change.Op {
+	case domain.ChangeOpUpsert:
+		doc := change.Document
+		if s.embedder != nil {
+			embedding, err := s.embedder.Embed(ctx, doc.EmbeddingText())
+			if err != nil {
+				log.Printf("Failed to embed post: %v", err)
+				return fmt.Errorf("failed to embed post: %w", err)
+			}
+			// The change is shared with the other sinks, so the vector
+			// goes on a copy
+			embedded := *doc
+			embedded.Embedding = embedding
+			doc = &embedded
+		}
+		if err := s.searchIndex.UpsertDocument(ctx, s.collectionName, doc); err != nil {
+			log.Printf("Failed to upsert document to search index: %v", err)
+			return err
+		}
+		log.Printf("Successfully indexed post ID: %s", change.ID)
+	case domain.ChangeOpDelete:
+		if err := s.searchIndex.DeleteDocument(ctx, s.collectionName, change.ID); err != nil {
+			log.Printf("Failed to delete document from search index: %v", err)
+			return err
+		}
+		log.Printf("Successfully removed post ID: %s from index", change.ID)
+	default:
+		return fmt.Errorf("unknown change operation: %s", change.Op)
+	}
+	return nil
+}
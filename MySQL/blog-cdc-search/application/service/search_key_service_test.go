@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// MockSearchKeyRepository keeps keys in memory and scopes them as
+// "parent|filter|expiry"
+type MockSearchKeyRepository struct {
+	keys   []*domain.SearchKey
+	params map[string]interface{}
+	err    error
+}
+
+func (m *MockSearchKeyRepository) CreateSearchKey(ctx context.Context, description string, collections []string, expiresAt time.Time) (*domain.SearchKey, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	id := int64(len(m.keys) + 1)
+	key := &domain.SearchKey{ID: id, Value: fmt.Sprintf("parent-%d", id), Description: description, Collections: collections, ExpiresAt: expiresAt}
+	m.keys = append(m.keys, key)
+	return key, nil
+}
+
+func (m *MockSearchKeyRepository) ListSearchKeys(ctx context.Context) ([]*domain.SearchKey, error) {
+	return m.keys, nil
+}
+
+func (m *MockSearchKeyRepository) DeleteSearchKey(ctx context.Context, id int64) error {
+	for i, key := range m.keys {
+		if key.ID == id {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *MockSearchKeyRepository) ScopeSearchKey(parent *domain.SearchKey, params map[string]interface{}) (string, error) {
+	m.params = params
+	return fmt.Sprintf("%s|%v|%v", parent.Value, params["filter_by"], params["expires_at"]), nil
+}
+
+func TestSearchKeyService_Key(t *testing.T) {
+	repo := &MockSearchKeyRepository{}
+	service := NewSearchKeyService(repo, SearchKeyConfig{Filter: "language:=`en`", Nodes: []string{"https://search.example.com"}})
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	key, err := service.Key(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expiresAt := now.Add(DefaultSearchKeyTTL)
+	if want := fmt.Sprintf("parent-1|language:=`en`|%d", expiresAt.Unix()); key.Key != want {
+		t.Errorf("Expected key %q, got %q", want, key.Key)
+	}
+	if !key.ExpiresAt.Equal(expiresAt) || key.Collection != "posts" || len(key.Nodes) != 1 {
+		t.Errorf("Unexpected key: %+v", key)
+	}
+	if repo.params["exclude_fields"] != "embedding" {
+		t.Errorf("Expected embeddings to be excluded, got %v", repo.params)
+	}
+
+	// The parent is search-only, limited to posts and outlives the scoped
+	// keys derived until the next rotation
+	parent := repo.keys[0]
+	if len(parent.Collections) != 1 || parent.Collections[0] != "posts" {
+		t.Errorf("Expected a parent key for posts, got %v", parent.Collections)
+	}
+	if want := now.Add(DefaultSearchKeyRotation + DefaultSearchKeyTTL); !parent.ExpiresAt.Equal(want) {
+		t.Errorf("Expected the parent to expire at %v, got %v", want, parent.ExpiresAt)
+	}
+
+	// The parent is reused until rotation is overdue
+	now = now.Add(DefaultSearchKeyRotation)
+	service.Key(context.Background())
+	if len(repo.keys) != 1 {
+		t.Fatalf("Expected the parent to be reused, got %d keys", len(repo.keys))
+	}
+	now = now.Add(time.Second)
+	key, _ = service.Key(context.Background())
+	if len(repo.keys) != 2 || key.Key[:8] != "parent-2" {
+		t.Errorf("Expected an overdue rotation to create a new parent, got %d keys and %q", len(repo.keys), key.Key)
+	}
+}
+
+func TestSearchKeyService_Rotate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	repo := &MockSearchKeyRepository{keys: []*domain.SearchKey{
+		{ID: 10, Description: searchKeyDescription, ExpiresAt: now.Add(-time.Minute)},
+		{ID: 11, Description: searchKeyDescription, ExpiresAt: now.Add(time.Hour)},
+		{ID: 12, Description: "admin", ExpiresAt: now.Add(-time.Minute)},
+	}}
+	service := NewSearchKeyService(repo, SearchKeyConfig{TTL: 2 * time.Hour, Rotation: time.Hour})
+	service.now = func() time.Time { return now }
+
+	if service.config.TTL != time.Hour {
+		t.Errorf("Expected the TTL to be capped at the rotation interval, got %v", service.config.TTL)
+	}
+	if err := service.Rotate(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Only the blog's expired keys are deleted; the previous parent is
+	// still valid and other keys are left alone
+	var ids []int64
+	for _, key := range repo.keys {
+		ids = append(ids, key.ID)
+	}
+	if fmt.Sprint(ids) != "[11 12 4]" {
+		t.Errorf("Expected keys [11 12 4], got %v", ids)
+	}
+}
+
+func TestSearchKeyService_IndexDown(t *testing.T) {
+	repo := &MockSearchKeyRepository{err: domain.ErrServiceUnavailable}
+	service := NewSearchKeyService(repo, SearchKeyConfig{})
+
+	if _, err := service.Key(context.Background()); !errors.Is(err, domain.ErrServiceUnavailable) {
+		t.Errorf("Expected ErrServiceUnavailable, got %v", err)
+	}
+
+	repo.err = nil
+	if _, err := service.Key(context.Background()); err != nil {
+		t.Errorf("Expected a key once the index is back, got %v", err)
+	}
+}
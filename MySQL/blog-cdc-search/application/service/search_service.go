@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"log"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"blog-cdc-search/domain"
 )
@@ -13,6 +16,14 @@ import (
 // SearchService handles search operations
 type SearchService struct {
 	searchRepo domain.SearchIndexRepository
+	cache      domain.CacheRepository
+	cacheTTL   time.Duration
+	// suggestCache holds recent suggestions, keyed by normalized query
+	suggestCache domain.CacheRepository
+	suggestTTL   time.Duration
+	// embedder turns queries into vectors for hybrid search
+	embedder    domain.Embedder
+	hybridAlpha float64
 }
 
 // SearchResult represents a search result with relevance score
@@ -22,23 +33,97 @@ type SearchResult struct {
 	Highlights map[string][]string `json:"highlights,omitempty"`
 }
 
-// SearchParams represents search parameters
+// SearchParams represents search parameters. SortBy is one of the names in
+// SearchSorts and defaults to relevance.
 type SearchParams struct {
-	Query    string `json:"query"`
-	Page     int    `json:"page"`
-	PerPage  int    `json:"per_page"`
-	SortBy   string `json:"sort_by"`
-	FilterBy string `json:"filter_by"`
+	Query   string        `json:"query"`
+	Page    int           `json:"page"`
+	PerPage int           `json:"per_page"`
+	SortBy  string        `json:"sort_by"`
+	Filters SearchFilters `json:"filters"`
 }
 
-// SearchResponse represents the complete search response
+// SearchFilters narrow a search to posts matching all of the set fields.
+// CreatedAfter is inclusive and CreatedBefore exclusive. Year and Month
+// (YYYY-MM) match the creation time in UTC, as the facets count it.
+type SearchFilters struct {
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+	HasImage      *bool      `json:"has_image,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+	Category      string     `json:"category,omitempty"`
+	Author        string     `json:"author,omitempty"`
+	Year          int        `json:"year,omitempty"`
+	Month         string     `json:"month,omitempty"`
+	Language      string     `json:"language,omitempty"`
+}
+
+// DefaultSearchSort is used when a search names no sort
+const DefaultSearchSort = "relevance"
+
+// SearchFacets are the fields whose value counts every search returns, in
+// the order they are reported
+var SearchFacets = []string{"tags", "category", "author", "language", "year", "month", "has_image"}
+
+// SearchSorts maps the sort names accepted by the API to index sort clauses
+var SearchSorts = map[string]string{
+	"relevance": "_text_match:desc,created_at:desc",
+	"newest":    "created_at:desc",
+	"oldest":    "created_at:asc",
+	"updated":   "updated_at:desc",
+}
+
+// SearchResponse represents the complete search response. Total counts every
+// match across pages; OutOf is the size of the index when the backend reports it.
 type SearchResponse struct {
-	Results    []*SearchResult `json:"results"`
-	Total      int             `json:"total"`
-	Page       int             `json:"page"`
-	PerPage    int             `json:"per_page"`
-	TotalPages int             `json:"total_pages"`
-	Query      string          `json:"query"`
+	Results      []*SearchResult      `json:"results"`
+	Total        int                  `json:"total"`
+	Page         int                  `json:"page"`
+	PerPage      int                  `json:"per_page"`
+	TotalPages   int                  `json:"total_pages"`
+	Query        string               `json:"query"`
+	SortBy       string               `json:"sort_by"`
+	Filters      *SearchFilters       `json:"filters,omitempty"`
+	Language     string               `json:"language,omitempty"`
+	OutOf        int                  `json:"out_of"`
+	SearchTimeMS int                  `json:"search_time_ms"`
+	Facets       []domain.FacetCounts `json:"facets,omitempty"`
+	Links        SearchLinks          `json:"links"`
+	// SearchID identifies the search in analytics, for attributing clicks
+	SearchID int64 `json:"search_id,omitempty"`
+}
+
+// SearchLinks are the URLs of the current, next and previous result pages.
+// Next and Prev are empty on the last and first page.
+type SearchLinks struct {
+	Self string `json:"self,omitempty"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// SuggestLimit is the number of posts a suggestion lookup returns
+const SuggestLimit = 5
+
+// SuggestResponse holds the completions for a partly typed query.
+// Completions are the distinct titles of the matching posts, best first.
+type SuggestResponse struct {
+	Query       string           `json:"query"`
+	Completions []string         `json:"completions"`
+	Posts       []*SuggestedPost `json:"posts"`
+}
+
+// SuggestedPost is a matching post with just enough to link to it. Highlight
+// is the title with the matched words marked, when the index reports it.
+type SuggestedPost struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	Image     string `json:"image,omitempty"`
+	Highlight string `json:"highlight,omitempty"`
+}
+
+// SuggestCacheKey caches the suggestions for a normalized query
+func SuggestCacheKey(query string) string {
+	return "suggest:" + query
 }
 
 // NewSearchService creates a new search service instance
@@ -48,8 +133,264 @@ func NewSearchService(searchRepo domain.SearchIndexRepository) *SearchService {
 	}
 }
 
-// SearchPosts performs a search for posts based on the given parameters
+// SetCache puts a read-through cache in front of GetAllPostsFromIndex
+func (s *SearchService) SetCache(cache domain.CacheRepository, ttl time.Duration) {
+	s.cache = cache
+	s.cacheTTL = ttl
+}
+
+// SetSuggestCache caches suggestions for ttl. Entries are not invalidated on
+// changes, so the ttl should be short.
+func (s *SearchService) SetSuggestCache(cache domain.CacheRepository, ttl time.Duration) {
+	s.suggestCache = cache
+	s.suggestTTL = ttl
+}
+
+// DefaultHybridAlpha is the weight of vector matches in hybrid search when
+// none is configured, the Typesense default
+const DefaultHybridAlpha = 0.3
+
+// SetEmbedder turns on hybrid search: queries are also embedded and matched
+// against post embeddings. Alpha, between 0 and 1, is the weight of the
+// vector ranking against the keyword ranking; outside that range it falls
+// back to DefaultHybridAlpha. The embedder must be the one posts were
+// indexed with.
+func (s *SearchService) SetEmbedder(embedder domain.Embedder, alpha float64) {
+	if alpha < 0 || alpha > 1 {
+		alpha = DefaultHybridAlpha
+	}
+	s.embedder = embedder
+	s.hybridAlpha = alpha
+}
+
+// SuggestPosts completes a partly typed query from post titles. The last word
+// is matched as a prefix and one typo is tolerated. Titles in the language
+// the query is detected in are matched with that language's tokenizer.
+func (s *SearchService) SuggestPosts(ctx context.Context, query string) (*SuggestResponse, error) {
+	// Queries differing only in case or spacing share a cache entry
+	query = domain.NormalizeSearchQuery(query)
+	if query == "" {
+		return &SuggestResponse{Completions: []string{}, Posts: []*SuggestedPost{}}, nil
+	}
+
+	if s.suggestCache != nil {
+		var cached SuggestResponse
+		if cacheGet(ctx, s.suggestCache, SuggestCacheKey(query), &cached) {
+			return &cached, nil
+		}
+	}
+
+	results, err := s.searchRepo.SearchDocuments(ctx, "posts", query, map[string]interface{}{
+		"query_by":  localizedQueryBy(domain.DetectLanguage(query), "title"),
+		"sort_by":   SearchSorts[DefaultSearchSort],
+		"prefix":    "true",
+		"num_typos": "1",
+		"page":      1,
+		"per_page":  SuggestLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("suggest failed: %w", err)
+	}
+
+	response := &SuggestResponse{Query: query, Completions: []string{}, Posts: []*SuggestedPost{}}
+	for _, hit := range results.Hits {
+		post, err := s.extractPostFromSearchResult(hit.Document)
+		if err != nil {
+			continue // Skip invalid results
+		}
+
+		suggested := &SuggestedPost{ID: post.ID, Title: post.Title, Image: post.Image}
+		if highlights := baseHighlights(hit.Highlights)["title"]; len(highlights) > 0 {
+			suggested.Highlight = highlights[0]
+		}
+		response.Posts = append(response.Posts, suggested)
+		if !slices.Contains(response.Completions, post.Title) {
+			response.Completions = append(response.Completions, post.Title)
+		}
+	}
+
+	if s.suggestCache != nil {
+		cacheSet(ctx, s.suggestCache, SuggestCacheKey(query), response, s.suggestTTL)
+	}
+
+	return response, nil
+}
+
+// RelatedPostsLimit is the number of related posts shown with a post
+const RelatedPostsLimit = 4
+
+// relatedTermsLimit caps the words of a more-like-this query. Typesense drops
+// the last words first when too few posts match them all, so the title words
+// come first.
+const relatedTermsLimit = 12
+
+// relatedStopWords are left out of more-like-this queries, as nearly every
+// post contains them
+var relatedStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "from": true, "how": true, "in": true, "into": true, "is": true, "it": true,
+	"its": true, "of": true, "on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "what": true, "when": true, "why": true, "with": true, "you": true, "your": true,
+}
+
+// RelatedPosts finds the posts most like post, leaving post itself out. With
+// an embedder these are the posts with the nearest embeddings, otherwise the
+// posts sharing the most words of its title and excerpt.
+func (s *SearchService) RelatedPosts(ctx context.Context, post *domain.Post) ([]*domain.Post, error) {
+	var key string
+	if s.cache != nil {
+		key = RelatedPostsCacheKey(s.relatedPostsVersion(ctx), post.ID)
+		var cached []*domain.Post
+		if cacheGet(ctx, s.cache, key, &cached) {
+			return cached, nil
+		}
+	}
+
+	var results *domain.SearchResults
+	if s.embedder != nil {
+		var err error
+		results, err = s.similarPosts(ctx, post)
+		if err != nil {
+			log.Printf("Failed to find similar posts to post %d, matching words instead: %v", post.ID, err)
+		}
+	}
+	if results == nil {
+		var err error
+		results, err = s.morePostsLike(ctx, post)
+		if err != nil {
+			return nil, fmt.Errorf("related posts failed: %w", err)
+		}
+	}
+
+	related := []*domain.Post{}
+	for _, hit := range results.Hits {
+		candidate, err := s.extractPostFromSearchResult(hit.Document)
+		if err != nil || candidate.ID == post.ID {
+			continue
+		}
+		related = append(related, candidate)
+		if len(related) == RelatedPostsLimit {
+			break
+		}
+	}
+
+	if s.cache != nil {
+		cacheSet(ctx, s.cache, key, related, s.cacheTTL)
+	}
+
+	return related, nil
+}
+
+// relatedPostsVersion returns the version the related posts are cached
+// under, starting a new one when the cache sink has deleted it
+func (s *SearchService) relatedPostsVersion(ctx context.Context) string {
+	var version string
+	if cacheGet(ctx, s.cache, RelatedPostsVersionKey, &version) {
+		return version
+	}
+
+	version = strconv.FormatInt(time.Now().UnixNano(), 36)
+	cacheSet(ctx, s.cache, RelatedPostsVersionKey, version, 0)
+	return version
+}
+
+// similarPosts searches for the posts nearest to the embedding of post. It
+// returns nil results when post has nothing to embed. One extra post is
+// requested, as post itself is usually the nearest.
+func (s *SearchService) similarPosts(ctx context.Context, post *domain.Post) (*domain.SearchResults, error) {
+	vector, err := s.embedder.Embed(ctx, domain.NewSearchDocument(post).EmbeddingText())
+	if err != nil || vector == nil {
+		return nil, err
+	}
+
+	return s.searchRepo.SearchDocuments(ctx, "posts", "*", map[string]interface{}{
+		"vector":   vector,
+		"page":     1,
+		"per_page": RelatedPostsLimit + 1,
+	})
+}
+
+// morePostsLike searches for the posts matching the most words of the title
+// and excerpt of post
+func (s *SearchService) morePostsLike(ctx context.Context, post *domain.Post) (*domain.SearchResults, error) {
+	terms := relatedTerms(post)
+	if len(terms) == 0 {
+		return &domain.SearchResults{}, nil
+	}
+
+	return s.searchRepo.SearchDocuments(ctx, "posts", strings.Join(terms, " "), map[string]interface{}{
+		"query_by": localizedQueryBy(post.Language, "title", "excerpt"),
+		"sort_by":  SearchSorts[DefaultSearchSort],
+		"page":     1,
+		"per_page": RelatedPostsLimit + 1,
+	})
+}
+
+// relatedTerms returns the distinct lowercase words of the title and excerpt
+// of post, without stop words
+func relatedTerms(post *domain.Post) []string {
+	isSeparator := func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}
+
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(post.Title+" "+post.Excerpt), isSeparator) {
+		if relatedStopWords[word] || slices.Contains(terms, word) {
+			continue
+		}
+		terms = append(terms, word)
+		if len(terms) == relatedTermsLimit {
+			break
+		}
+	}
+	return terms
+}
+
+// localizedQueryBy lists the fields of a language, if it has its own, before
+// the default fields, so queries in languages the default tokenizer splits
+// poorly match the posts written in them
+func localizedQueryBy(language string, fields ...string) string {
+	queryBy := slices.Clone(fields)
+	for i := len(fields) - 1; i >= 0; i-- {
+		if name := domain.LocalizedField(fields[i], language); name != "" {
+			queryBy = slices.Insert(queryBy, 0, name)
+		}
+	}
+	return strings.Join(queryBy, ",")
+}
+
+// baseHighlights reports the highlights of localized fields, such as
+// title_fa, under the default field names when those have none
+func baseHighlights(highlights map[string][]string) map[string][]string {
+	for field, snippets := range highlights {
+		base, language, ok := strings.Cut(field, "_")
+		if !ok || domain.LocalizedField(base, language) != field {
+			continue
+		}
+		if _, exists := highlights[base]; !exists {
+			highlights[base] = snippets
+		}
+		delete(highlights, field)
+	}
+	return highlights
+}
+
+// SearchPosts performs a search for posts based on the given parameters. The
+// query is searched in the fields of the language filtered on or, without
+// that filter, of the language the query is detected in.
 func (s *SearchService) SearchPosts(ctx context.Context, params SearchParams) (*SearchResponse, error) {
+	if params.SortBy == "" {
+		params.SortBy = DefaultSearchSort
+	}
+	sortBy, ok := SearchSorts[params.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown sort %q", domain.ErrInvalidSearch, params.SortBy)
+	}
+	filters, err := params.Filters.normalize()
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate search parameters
 	if strings.TrimSpace(params.Query) == "" {
 		return &SearchResponse{
@@ -59,6 +400,8 @@ func (s *SearchService) SearchPosts(ctx context.Context, params SearchParams) (*
 			PerPage:    params.PerPage,
 			TotalPages: 0,
 			Query:      params.Query,
+			SortBy:     params.SortBy,
+			Filters:    filters.orNil(),
 		}, nil
 	}
 
@@ -73,85 +416,190 @@ func (s *SearchService) SearchPosts(ctx context.Context, params SearchParams) (*
 		params.PerPage = 100
 	}
 
+	language := filters.Language
+	if language == "" {
+		language = domain.DetectLanguage(params.Query)
+	}
+
 	// Prepare search parameters for Typesense
 	searchParams := map[string]interface{}{
 		"page":     params.Page,
 		"per_page": params.PerPage,
-		"sort_by":  "_text_match:desc,created_at:desc",
-		"query_by": "title,excerpt,body",
+		"sort_by":  sortBy,
+		"query_by": localizedQueryBy(language, "title", "excerpt", "body"),
+		"facet_by": strings.Join(SearchFacets, ","),
+	}
+
+	if searchFilters := filters.searchFilters(); len(searchFilters) > 0 {
+		searchParams["filters"] = searchFilters
 	}
 
-	if params.FilterBy != "" {
-		searchParams["filter_by"] = params.FilterBy
+	// With an embedder, keyword and vector matches are ranked together
+	if s.embedder != nil {
+		vector, err := s.embedder.Embed(ctx, params.Query)
+		if err != nil {
+			log.Printf("Failed to embed query, searching by keywords only: %v", err)
+		} else if vector != nil {
+			searchParams["vector"] = vector
+			searchParams["alpha"] = s.hybridAlpha
+		}
 	}
 
 	// Perform search
-	results, err := s.searchRepo.SearchDocuments("posts", params.Query, searchParams)
+	results, err := s.searchRepo.SearchDocuments(ctx, "posts", params.Query, searchParams)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
-	// Convert results to SearchResult format
-	searchResults := make([]*SearchResult, 0, len(results))
-	for _, result := range results {
-		// Convert the interface{} result to a map
-		resultMap, ok := result.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
+	// Convert hits to SearchResult format
+	searchResults := make([]*SearchResult, 0, len(results.Hits))
+	for _, hit := range results.Hits {
 		// Extract post data
-		post, err := s.extractPostFromSearchResult(resultMap)
+		post, err := s.extractPostFromSearchResult(hit.Document)
 		if err != nil {
 			continue // Skip invalid results
 		}
 
-		// Extract score
-		score := 0.0
-		if scoreVal, ok := resultMap["_text_match"].(float64); ok {
-			score = scoreVal
-		}
-
-		// Extract highlights if available
-		highlights := make(map[string][]string)
-		if highlightsVal, ok := resultMap["highlights"].(map[string]interface{}); ok {
-			for field, highlightList := range highlightsVal {
-				if highlightArray, ok := highlightList.([]interface{}); ok {
-					highlights[field] = make([]string, 0, len(highlightArray))
-					for _, highlight := range highlightArray {
-						if highlightStr, ok := highlight.(string); ok {
-							highlights[field] = append(highlights[field], highlightStr)
-						}
-					}
-				}
-			}
-		}
-
 		searchResults = append(searchResults, &SearchResult{
 			Post:       post,
-			Score:      score,
-			Highlights: highlights,
+			Score:      hit.Score,
+			Highlights: baseHighlights(hit.Highlights),
 		})
 	}
 
-	// Calculate pagination info
-	total := len(searchResults)
-	totalPages := (total + params.PerPage - 1) / params.PerPage
+	// Calculate pagination info from the total reported by the index
+	totalPages := (results.Found + params.PerPage - 1) / params.PerPage
 
 	return &SearchResponse{
-		Results:    searchResults,
-		Total:      total,
-		Page:       params.Page,
-		PerPage:    params.PerPage,
-		TotalPages: totalPages,
-		Query:      params.Query,
+		Results:      searchResults,
+		Total:        results.Found,
+		Page:         params.Page,
+		PerPage:      params.PerPage,
+		TotalPages:   totalPages,
+		Query:        params.Query,
+		SortBy:       params.SortBy,
+		Filters:      filters.orNil(),
+		Language:     language,
+		OutOf:        results.OutOf,
+		SearchTimeMS: results.SearchTimeMS,
+		Facets:       nonEmptyFacets(results.Facets),
 	}, nil
 }
 
+// normalize validates the filters and brings tags and the category into the
+// form they are indexed in
+func (f SearchFilters) normalize() (SearchFilters, error) {
+	f.Category = strings.TrimSpace(f.Category)
+	f.Author = strings.TrimSpace(f.Author)
+	f.Tags = domain.NormalizeTags(f.Tags)
+	if f.Language != "" {
+		language, err := domain.NormalizeLanguage(f.Language)
+		if err != nil {
+			return f, fmt.Errorf("%w: %w", domain.ErrInvalidSearch, err)
+		}
+		f.Language = language
+	}
+	if len(f.Tags) == 0 {
+		f.Tags = nil
+	}
+
+	if f.Year < 0 || f.Year > 9999 {
+		return f, fmt.Errorf("%w: invalid year %d", domain.ErrInvalidSearch, f.Year)
+	}
+	if f.Month != "" {
+		if _, err := time.Parse("2006-01", f.Month); err != nil {
+			return f, fmt.Errorf("%w: month must be YYYY-MM", domain.ErrInvalidSearch)
+		}
+	}
+
+	// Typesense quotes filter values with backticks, which it cannot escape
+	for _, value := range append([]string{f.Category, f.Author}, f.Tags...) {
+		if strings.Contains(value, "`") {
+			return f, fmt.Errorf("%w: filter values cannot contain backticks", domain.ErrInvalidSearch)
+		}
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		return f, fmt.Errorf("%w: created_after must be before created_before", domain.ErrInvalidSearch)
+	}
+	return f, nil
+}
+
+// searchFilters turns normalized filters into the index conditions that
+// each search backend compiles
+func (f SearchFilters) searchFilters() []domain.SearchFilter {
+	var filters []domain.SearchFilter
+	add := func(field string, op domain.FilterOp, value interface{}) {
+		filters = append(filters, domain.SearchFilter{Field: field, Op: op, Value: value})
+	}
+	if f.CreatedAfter != nil {
+		add("created_at", domain.FilterAtLeast, f.CreatedAfter.Unix())
+	}
+	if f.CreatedBefore != nil {
+		add("created_at", domain.FilterBelow, f.CreatedBefore.Unix())
+	}
+	if f.HasImage != nil {
+		add("has_image", domain.FilterEqual, *f.HasImage)
+	}
+	// Every tag must be present
+	for _, tag := range f.Tags {
+		add("tags", domain.FilterEqual, tag)
+	}
+	if f.Category != "" {
+		add("category", domain.FilterEqual, f.Category)
+	}
+	if f.Author != "" {
+		add("author", domain.FilterEqual, f.Author)
+	}
+	if f.Language != "" {
+		add("language", domain.FilterEqual, f.Language)
+	}
+	if f.Year != 0 {
+		add("year", domain.FilterEqual, fmt.Sprintf("%04d", f.Year))
+	}
+	if f.Month != "" {
+		add("month", domain.FilterEqual, f.Month)
+	}
+	return filters
+}
+
+// orNil returns nil when no filter is set, so responses omit them
+func (f SearchFilters) orNil() *SearchFilters {
+	if f.CreatedAfter == nil && f.CreatedBefore == nil && f.HasImage == nil && len(f.Tags) == 0 &&
+		f.Category == "" && f.Author == "" && f.Language == "" && f.Year == 0 && f.Month == "" {
+		return nil
+	}
+	return &f
+}
+
+// nonEmptyFacets drops the counts of empty values, such as posts without an
+// author, and facets left without counts
+func nonEmptyFacets(facets []domain.FacetCounts) []domain.FacetCounts {
+	var result []domain.FacetCounts
+	for _, facet := range facets {
+		counts := []domain.FacetValueCount{}
+		for _, count := range facet.Counts {
+			if count.Value != "" {
+				counts = append(counts, count)
+			}
+		}
+		if len(counts) > 0 {
+			result = append(result, domain.FacetCounts{Field: facet.Field, Counts: counts})
+		}
+	}
+	return result
+}
+
 // GetAllPostsFromIndex retrieves all posts from the search index
 func (s *SearchService) GetAllPostsFromIndex(ctx context.Context) ([]*domain.Post, error) {
+	if s.cache != nil {
+		var cached []*domain.Post
+		if cacheGet(ctx, s.cache, AllPostsCacheKey, &cached) {
+			return cached, nil
+		}
+	}
+
 	// Get all documents from the posts collection
-	results, err := s.searchRepo.GetAllDocuments("posts")
+	results, err := s.searchRepo.GetAllDocuments(ctx, "posts")
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve posts from index: %w", err)
 	}
@@ -174,6 +622,10 @@ func (s *SearchService) GetAllPostsFromIndex(ctx context.Context) ([]*domain.Pos
 		posts = append(posts, post)
 	}
 
+	if s.cache != nil {
+		cacheSet(ctx, s.cache, AllPostsCacheKey, posts, s.cacheTTL)
+	}
+
 	return posts, nil
 }
 
@@ -208,6 +660,18 @@ func (s *SearchService) extractPostFromSearchResult(resultMap map[string]interfa
 	image, _ := resultMap["image"].(string)
 	excerpt, _ := resultMap["excerpt"].(string)
 	body, _ := resultMap["body"].(string)
+	author, _ := resultMap["author"].(string)
+	category, _ := resultMap["category"].(string)
+	language, _ := resultMap["language"].(string)
+
+	var tags []string
+	if values, ok := resultMap["tags"].([]interface{}); ok {
+		for _, value := range values {
+			if tag, ok := value.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+	}
 
 	// Extract and convert timestamps from Unix timestamps to time.Time
 	var createdAt, updatedAt time.Time
@@ -241,6 +705,10 @@ func (s *SearchService) extractPostFromSearchResult(resultMap map[string]interfa
 		Image:     image,
 		Excerpt:   excerpt,
 		Body:      body,
+		Author:    author,
+		Category:  category,
+		Language:  language,
+		Tags:      domain.NormalizeTags(tags),
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
 	}
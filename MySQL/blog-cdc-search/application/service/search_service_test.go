@@ -2,19 +2,30 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"blog-cdc-search/domain"
 )
 
 // MockSearchIndexRepositoryForSearch is a mock implementation for testing search functionality
 type MockSearchIndexRepositoryForSearch struct {
-	searchResults []interface{}
+	searchResults *domain.SearchResults
+	searchQuery   string
+	searchParams  map[string]interface{}
 	searchError   error
+	getAllResults []interface{}
+
+	syncedSynonyms  []*domain.Synonym
+	syncedCurations []*domain.Curation
+	syncError       error
 }
 
-func (m *MockSearchIndexRepositoryForSearch) Connect() error {
+func (m *MockSearchIndexRepositoryForSearch) Connect(ctx context.Context) error {
 	return nil
 }
 
@@ -22,27 +33,78 @@ func (m *MockSearchIndexRepositoryForSearch) Close() error {
 	return nil
 }
 
-func (m *MockSearchIndexRepositoryForSearch) CreateCollection(schema map[string]interface{}) error {
+func (m *MockSearchIndexRepositoryForSearch) CreateCollection(ctx context.Context, schema map[string]interface{}) error {
 	return nil
 }
 
-func (m *MockSearchIndexRepositoryForSearch) UpsertDocument(collectionName string, document interface{}) error {
+func (m *MockSearchIndexRepositoryForSearch) UpsertDocument(ctx context.Context, collectionName string, document interface{}) error {
 	return nil
 }
 
-func (m *MockSearchIndexRepositoryForSearch) DeleteDocument(collectionName string, documentID string) error {
+func (m *MockSearchIndexRepositoryForSearch) DeleteDocument(ctx context.Context, collectionName string, documentID string) error {
 	return nil
 }
 
-func (m *MockSearchIndexRepositoryForSearch) SearchDocuments(collectionName, query string, searchParams map[string]interface{}) ([]interface{}, error) {
+func (m *MockSearchIndexRepositoryForSearch) SearchDocuments(ctx context.Context, collectionName, query string, searchParams map[string]interface{}) (*domain.SearchResults, error) {
+	m.searchQuery = query
+	m.searchParams = searchParams
 	if m.searchError != nil {
 		return nil, m.searchError
 	}
+	if m.searchResults == nil {
+		return &domain.SearchResults{}, nil
+	}
 	return m.searchResults, nil
 }
 
-func (m *MockSearchIndexRepositoryForSearch) GetAllDocuments(collectionName string) ([]interface{}, error) {
-	return nil, nil
+// searchHits wraps documents in a result envelope, using _text_match as the score
+func searchHits(documents ...map[string]interface{}) *domain.SearchResults {
+	results := &domain.SearchResults{Found: len(documents)}
+	for _, document := range documents {
+		score, _ := document["_text_match"].(float64)
+		delete(document, "_text_match")
+		results.Hits = append(results.Hits, &domain.SearchHit{Document: document, Score: score})
+	}
+	return results
+}
+
+func (m *MockSearchIndexRepositoryForSearch) GetAllDocuments(ctx context.Context, collectionName string) ([]interface{}, error) {
+	return m.getAllResults, nil
+}
+
+func (m *MockSearchIndexRepositoryForSearch) SyncSynonyms(ctx context.Context, collectionName string, synonyms []*domain.Synonym) error {
+	if m.syncError != nil {
+		return m.syncError
+	}
+	m.syncedSynonyms = synonyms
+	return nil
+}
+
+func (m *MockSearchIndexRepositoryForSearch) SyncCurations(ctx context.Context, collectionName string, curations []*domain.Curation) error {
+	if m.syncError != nil {
+		return m.syncError
+	}
+	m.syncedCurations = curations
+	return nil
+}
+
+// fakeEmbedder embeds text deterministically as its word and character
+// counts, recording the texts it was given
+type fakeEmbedder struct {
+	texts []string
+	err   error
+}
+
+func (f *fakeEmbedder) Dimensions() int {
+	return 2
+}
+
+func (f *fakeEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	f.texts = append(f.texts, text)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []float32{float32(len(strings.Fields(text))), float32(len(text))}, nil
 }
 
 func TestNewSearchService(t *testing.T) {
@@ -113,7 +175,7 @@ func TestSearchPosts_DefaultValues(t *testing.T) {
 	}
 
 	// Mock search results
-	mockResults := []interface{}{
+	mockRepo.searchResults = searchHits(
 		map[string]interface{}{
 			"id":          "1",
 			"title":       "Test Post",
@@ -124,8 +186,7 @@ func TestSearchPosts_DefaultValues(t *testing.T) {
 			"updated_at":  "2023-01-01 00:00:00",
 			"_text_match": float64(0.95),
 		},
-	}
-	mockRepo.searchResults = mockResults
+	)
 
 	result, err := service.SearchPosts(context.Background(), params)
 	if err != nil {
@@ -151,7 +212,7 @@ func TestSearchPosts_MaxPerPage(t *testing.T) {
 		PerPage: 150, // Should be capped at 100
 	}
 
-	mockRepo.searchResults = []interface{}{}
+	mockRepo.searchResults = searchHits()
 
 	result, err := service.SearchPosts(context.Background(), params)
 	if err != nil {
@@ -171,11 +232,11 @@ func TestSearchPosts_SuccessfulSearch(t *testing.T) {
 		Query:   "test query",
 		Page:    1,
 		PerPage: 10,
-		SortBy:  "created_at:desc",
+		SortBy:  "newest",
 	}
 
 	// Mock search results
-	mockResults := []interface{}{
+	mockRepo.searchResults = searchHits(
 		map[string]interface{}{
 			"id":          "1",
 			"title":       "Test Post 1",
@@ -196,8 +257,7 @@ func TestSearchPosts_SuccessfulSearch(t *testing.T) {
 			"updated_at":  "2023-01-02 00:00:00",
 			"_text_match": float64(0.85),
 		},
-	}
-	mockRepo.searchResults = mockResults
+	)
 
 	result, err := service.SearchPosts(context.Background(), params)
 	if err != nil {
@@ -245,10 +305,10 @@ func TestSearchPosts_Pagination(t *testing.T) {
 		PerPage: 5,
 	}
 
-	// Mock 12 search results
-	mockResults := make([]interface{}, 12)
-	for i := 0; i < 12; i++ {
-		mockResults[i] = map[string]interface{}{
+	// Mock the second page of 12 search results
+	var documents []map[string]interface{}
+	for i := 5; i < 10; i++ {
+		documents = append(documents, map[string]interface{}{
 			"id":          fmt.Sprintf("%d", i+1),
 			"title":       "Test Post",
 			"image":       "test.jpg",
@@ -257,19 +317,393 @@ func TestSearchPosts_Pagination(t *testing.T) {
 			"created_at":  "2023-01-01 00:00:00",
 			"updated_at":  "2023-01-01 00:00:00",
 			"_text_match": float64(0.9),
-		}
+		})
 	}
-	mockRepo.searchResults = mockResults
+	mockRepo.searchResults = searchHits(documents...)
+	mockRepo.searchResults.Found = 12
 
 	result, err := service.SearchPosts(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
 
+	// The total comes from the index, not the size of the page
+	if result.Total != 12 {
+		t.Errorf("Expected total to be 12, got: %d", result.Total)
+	}
 	expectedTotalPages := 3 // 12 results / 5 per page = 3 pages
 	if result.TotalPages != expectedTotalPages {
 		t.Errorf("Expected total pages to be %d, got: %d", expectedTotalPages, result.TotalPages)
 	}
+	if len(result.Results) != 5 {
+		t.Errorf("Expected 5 results on the page, got: %d", len(result.Results))
+	}
+	if mockRepo.searchParams["page"] != 2 || mockRepo.searchParams["per_page"] != 5 {
+		t.Errorf("Expected page 2 of 5 to be requested, got: %v", mockRepo.searchParams)
+	}
+}
+
+func TestSearchPosts_SearchMetadata(t *testing.T) {
+	mockRepo := &MockSearchIndexRepositoryForSearch{
+		searchResults: &domain.SearchResults{
+			Hits: []*domain.SearchHit{{
+				Document:   map[string]interface{}{"id": "1", "title": "Test Post"},
+				Score:      7,
+				Highlights: map[string][]string{"title": {"<mark>Test</mark> Post"}},
+			}},
+			Found:        1,
+			OutOf:        40,
+			SearchTimeMS: 3,
+			Facets:       []domain.FacetCounts{{Field: "tags", Counts: []domain.FacetValueCount{{Value: "go", Count: 1}}}},
+		},
+	}
+	service := NewSearchService(mockRepo)
+
+	result, err := service.SearchPosts(context.Background(), SearchParams{Query: "test", SortBy: "newest", Filters: SearchFilters{Category: "News"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if result.OutOf != 40 || result.SearchTimeMS != 3 || len(result.Facets) != 1 {
+		t.Errorf("Expected index metadata to be passed through, got: %+v", result)
+	}
+	if result.Filters == nil || result.Filters.Category != "News" || !reflect.DeepEqual(mockRepo.searchParams["filters"], []domain.SearchFilter{{Field: "category", Op: domain.FilterEqual, Value: "News"}}) {
+		t.Errorf("Expected the filter to be applied and echoed, got: %+v", result.Filters)
+	}
+	if result.SortBy != "newest" || mockRepo.searchParams["sort_by"] != "created_at:desc" {
+		t.Errorf("Expected the sort to be applied and echoed, got: %q", result.SortBy)
+	}
+	if len(result.Results) != 1 || result.Results[0].Score != 7 || result.Results[0].Highlights["title"][0] != "<mark>Test</mark> Post" {
+		t.Errorf("Expected the hit score and highlights, got: %+v", result.Results)
+	}
+}
+
+func TestSearchPosts_Sorts(t *testing.T) {
+	tests := []struct {
+		sortBy   string
+		expected string
+	}{
+		{"", "_text_match:desc,created_at:desc"},
+		{"relevance", "_text_match:desc,created_at:desc"},
+		{"newest", "created_at:desc"},
+		{"oldest", "created_at:asc"},
+		{"updated", "updated_at:desc"},
+	}
+
+	for _, tt := range tests {
+		mockRepo := &MockSearchIndexRepositoryForSearch{}
+		service := NewSearchService(mockRepo)
+
+		if _, err := service.SearchPosts(context.Background(), SearchParams{Query: "test", SortBy: tt.sortBy}); err != nil {
+			t.Fatalf("Expected no error for sort %q, got: %v", tt.sortBy, err)
+		}
+		if mockRepo.searchParams["sort_by"] != tt.expected {
+			t.Errorf("Expected sort %q for %q, got %v", tt.expected, tt.sortBy, mockRepo.searchParams["sort_by"])
+		}
+	}
+
+	// Raw index clauses are not accepted
+	mockRepo := &MockSearchIndexRepositoryForSearch{}
+	_, err := NewSearchService(mockRepo).SearchPosts(context.Background(), SearchParams{Query: "test", SortBy: "title:asc"})
+	if !errors.Is(err, domain.ErrInvalidSearch) {
+		t.Errorf("Expected ErrInvalidSearch for an unknown sort, got: %v", err)
+	}
+	if mockRepo.searchParams != nil {
+		t.Error("Expected the index not to be queried")
+	}
+}
+
+func TestSearchPosts_Filters(t *testing.T) {
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	hasImage := false
+
+	mockRepo := &MockSearchIndexRepositoryForSearch{}
+	service := NewSearchService(mockRepo)
+
+	result, err := service.SearchPosts(context.Background(), SearchParams{
+		Query: "test",
+		Filters: SearchFilters{
+			CreatedAfter:  &after,
+			CreatedBefore: &before,
+			HasImage:      &hasImage,
+			Tags:          []string{"Go", " cdc "},
+			Category:      " Deep Dives ",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []domain.SearchFilter{
+		{Field: "created_at", Op: domain.FilterAtLeast, Value: int64(1704067200)},
+		{Field: "created_at", Op: domain.FilterBelow, Value: int64(1706745600)},
+		{Field: "has_image", Op: domain.FilterEqual, Value: false},
+		{Field: "tags", Op: domain.FilterEqual, Value: "go"},
+		{Field: "tags", Op: domain.FilterEqual, Value: "cdc"},
+		{Field: "category", Op: domain.FilterEqual, Value: "Deep Dives"},
+	}
+	if !reflect.DeepEqual(mockRepo.searchParams["filters"], expected) {
+		t.Errorf("Expected filters %+v, got %+v", expected, mockRepo.searchParams["filters"])
+	}
+	// The normalized filters are echoed
+	if result.Filters == nil || result.Filters.Category != "Deep Dives" || len(result.Filters.Tags) != 2 || result.Filters.Tags[0] != "go" {
+		t.Errorf("Expected normalized filters, got: %+v", result.Filters)
+	}
+
+	// No filters, no filters parameter
+	if _, err := service.SearchPosts(context.Background(), SearchParams{Query: "test"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, ok := mockRepo.searchParams["filters"]; ok {
+		t.Errorf("Expected no filters, got %v", mockRepo.searchParams["filters"])
+	}
+}
+
+func TestSearchPosts_Facets(t *testing.T) {
+	mockRepo := &MockSearchIndexRepositoryForSearch{
+		searchResults: &domain.SearchResults{
+			Found: 4,
+			Facets: []domain.FacetCounts{
+				{Field: "tags", Counts: []domain.FacetValueCount{}},
+				{Field: "author", Counts: []domain.FacetValueCount{{Value: "", Count: 3}, {Value: "Ada", Count: 1}}},
+				{Field: "year", Counts: []domain.FacetValueCount{{Value: "2024", Count: 4}}},
+			},
+		},
+	}
+	service := NewSearchService(mockRepo)
+
+	result, err := service.SearchPosts(context.Background(), SearchParams{
+		Query:   "test",
+		Filters: SearchFilters{Author: "Ada", Year: 2024, Month: "2024-03"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if mockRepo.searchParams["facet_by"] != "tags,category,author,language,year,month,has_image" {
+		t.Errorf("Expected every facet to be requested, got %v", mockRepo.searchParams["facet_by"])
+	}
+	expected := []domain.SearchFilter{
+		{Field: "author", Op: domain.FilterEqual, Value: "Ada"},
+		{Field: "year", Op: domain.FilterEqual, Value: "2024"},
+		{Field: "month", Op: domain.FilterEqual, Value: "2024-03"},
+	}
+	if !reflect.DeepEqual(mockRepo.searchParams["filters"], expected) {
+		t.Errorf("Expected filters %+v, got %+v", expected, mockRepo.searchParams["filters"])
+	}
+
+	// Empty values and facets without counts are left out
+	if len(result.Facets) != 2 || result.Facets[0].Field != "author" || len(result.Facets[0].Counts) != 1 || result.Facets[0].Counts[0].Value != "Ada" {
+		t.Errorf("Expected author and year facets without empty values, got: %+v", result.Facets)
+	}
+}
+
+func TestSearchPosts_Hybrid(t *testing.T) {
+	mockRepo := &MockSearchIndexRepositoryForSearch{}
+	embedder := &fakeEmbedder{}
+	service := NewSearchService(mockRepo)
+	service.SetEmbedder(embedder, 0.6)
+
+	if _, err := service.SearchPosts(context.Background(), SearchParams{Query: "posts about concurrency"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(mockRepo.searchParams["vector"], []float32{3, 23}) || mockRepo.searchParams["alpha"] != 0.6 {
+		t.Errorf("Expected the query vector and alpha to be sent, got %v", mockRepo.searchParams)
+	}
+	if mockRepo.searchQuery != "posts about concurrency" {
+		t.Errorf("Expected the keyword query to be kept, got %q", mockRepo.searchQuery)
+	}
+
+	// A failing embedder falls back to keyword search
+	embedder.err = errors.New("embedder failed")
+	if _, err := service.SearchPosts(context.Background(), SearchParams{Query: "concurrency"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, ok := mockRepo.searchParams["vector"]; ok {
+		t.Errorf("Expected no vector after the embedder failed, got %v", mockRepo.searchParams)
+	}
+
+	// Out of range alphas use the default
+	service.SetEmbedder(&fakeEmbedder{}, 1.5)
+	service.SearchPosts(context.Background(), SearchParams{Query: "concurrency"})
+	if mockRepo.searchParams["alpha"] != DefaultHybridAlpha {
+		t.Errorf("Expected the default alpha, got %v", mockRepo.searchParams["alpha"])
+	}
+}
+
+func TestSearchPosts_Language(t *testing.T) {
+	results := searchHits(map[string]interface{}{"id": "1", "title": "همروندی", "language": "fa"})
+	results.Hits[0].Highlights = map[string][]string{"title_fa": {"<mark>همروندی</mark>"}}
+	mockRepo := &MockSearchIndexRepositoryForSearch{searchResults: results}
+	service := NewSearchService(mockRepo)
+
+	// The query is routed to the fields of the language it is written in
+	response, err := service.SearchPosts(context.Background(), SearchParams{Query: "همروندی در گو"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if mockRepo.searchParams["query_by"] != "title_fa,excerpt_fa,body_fa,title,excerpt,body" || response.Language != "fa" {
+		t.Errorf("Expected the Persian fields first, got %v for %q", mockRepo.searchParams["query_by"], response.Language)
+	}
+	if _, ok := mockRepo.searchParams["filters"]; ok {
+		t.Errorf("Expected a detected language not to filter, got %v", mockRepo.searchParams["filters"])
+	}
+	if result := response.Results[0]; result.Post.Language != "fa" || result.Highlights["title"][0] != "<mark>همروندی</mark>" || result.Highlights["title_fa"] != nil {
+		t.Errorf("Expected the language and the title highlight, got %+v", result)
+	}
+
+	// A language filter routes the query and narrows the results
+	service.SearchPosts(context.Background(), SearchParams{Query: "Datenbank", Filters: SearchFilters{Language: "DE"}})
+	if mockRepo.searchParams["query_by"] != "title_de,excerpt_de,body_de,title,excerpt,body" ||
+		!reflect.DeepEqual(mockRepo.searchParams["filters"], []domain.SearchFilter{{Field: "language", Op: domain.FilterEqual, Value: "de"}}) {
+		t.Errorf("Expected the German fields and filter, got %v", mockRepo.searchParams)
+	}
+
+	// Languages without fields of their own use the default fields
+	service.SearchPosts(context.Background(), SearchParams{Query: "concurrency", Filters: SearchFilters{Language: "en"}})
+	if mockRepo.searchParams["query_by"] != "title,excerpt,body" {
+		t.Errorf("Expected the default fields, got %v", mockRepo.searchParams["query_by"])
+	}
+
+	_, err = service.SearchPosts(context.Background(), SearchParams{Query: "go", Filters: SearchFilters{Language: "english!"}})
+	if !errors.Is(err, domain.ErrInvalidSearch) {
+		t.Errorf("Expected ErrInvalidSearch for an invalid language, got %v", err)
+	}
+}
+
+func TestSuggestPosts(t *testing.T) {
+	results := searchHits(
+		map[string]interface{}{"id": "1", "title": "Change Data Capture", "image": "cdc.png"},
+		map[string]interface{}{"id": "2", "title": "Change Data Capture"},
+		map[string]interface{}{"title": "No ID"},
+		map[string]interface{}{"id": "3", "title": "Changelog"},
+	)
+	results.Hits[0].Highlights = map[string][]string{"title": {"<mark>Chan</mark>ge Data Capture"}}
+	mockRepo := &MockSearchIndexRepositoryForSearch{searchResults: results}
+	service := NewSearchService(mockRepo)
+
+	response, err := service.SuggestPosts(context.Background(), "  CHAN ")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if mockRepo.searchQuery != "chan" {
+		t.Errorf("Expected the normalized query to be searched, got %q", mockRepo.searchQuery)
+	}
+	for key, expected := range map[string]interface{}{"query_by": "title", "prefix": "true", "num_typos": "1", "per_page": SuggestLimit} {
+		if mockRepo.searchParams[key] != expected {
+			t.Errorf("Expected %s %v, got %v", key, expected, mockRepo.searchParams[key])
+		}
+	}
+
+	if !reflect.DeepEqual(response.Completions, []string{"Change Data Capture", "Changelog"}) {
+		t.Errorf("Expected distinct title completions, got %v", response.Completions)
+	}
+	if len(response.Posts) != 3 {
+		t.Fatalf("Expected 3 posts, got %d", len(response.Posts))
+	}
+	if post := response.Posts[0]; post.ID != 1 || post.Image != "cdc.png" || post.Highlight != "<mark>Chan</mark>ge Data Capture" {
+		t.Errorf("Unexpected first post: %+v", post)
+	}
+}
+
+func TestSuggestPosts_EmptyQuery(t *testing.T) {
+	mockRepo := &MockSearchIndexRepositoryForSearch{}
+	response, err := NewSearchService(mockRepo).SuggestPosts(context.Background(), "   ")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if mockRepo.searchParams != nil || len(response.Completions) != 0 || len(response.Posts) != 0 {
+		t.Errorf("Expected an empty response without searching, got %+v", response)
+	}
+}
+
+func TestRelatedPosts_MoreLikeThis(t *testing.T) {
+	mockRepo := &MockSearchIndexRepositoryForSearch{
+		searchResults: searchHits(
+			map[string]interface{}{"id": "1", "title": "Change Data Capture with Debezium"},
+			map[string]interface{}{"id": "2", "title": "Streaming Changes"},
+			map[string]interface{}{"id": "3", "title": "Debezium in Production"},
+		),
+	}
+	service := NewSearchService(mockRepo)
+
+	post := &domain.Post{ID: 1, Title: "Change Data Capture with Debezium", Excerpt: "How to stream the changes of a database"}
+	related, err := service.RelatedPosts(context.Background(), post)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if mockRepo.searchQuery != "change data capture debezium stream changes database" {
+		t.Errorf("Expected the title and excerpt words without stop words, got %q", mockRepo.searchQuery)
+	}
+	if mockRepo.searchParams["query_by"] != "title,excerpt" || mockRepo.searchParams["per_page"] != RelatedPostsLimit+1 {
+		t.Errorf("Unexpected search params: %v", mockRepo.searchParams)
+	}
+	if len(related) != 2 || related[0].ID != 2 || related[1].ID != 3 {
+		t.Errorf("Expected posts 2 and 3 without the post itself, got %v", related)
+	}
+}
+
+func TestRelatedPosts_Embedding(t *testing.T) {
+	mockRepo := &MockSearchIndexRepositoryForSearch{
+		searchResults: searchHits(map[string]interface{}{"id": "2", "title": "Goroutines"}),
+	}
+	service := NewSearchService(mockRepo)
+	embedder := &fakeEmbedder{}
+	service.SetEmbedder(embedder, DefaultHybridAlpha)
+
+	post := &domain.Post{ID: 1, Title: "Concurrency", Excerpt: "Threads", Body: "Workers"}
+	related, err := service.RelatedPosts(context.Background(), post)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(related) != 1 || related[0].ID != 2 {
+		t.Errorf("Expected post 2, got %v", related)
+	}
+	if mockRepo.searchQuery != "*" || !reflect.DeepEqual(mockRepo.searchParams["vector"], []float32{3, 27}) {
+		t.Errorf("Expected a search by the post embedding, got %q with %v", mockRepo.searchQuery, mockRepo.searchParams)
+	}
+	if _, ok := mockRepo.searchParams["alpha"]; ok {
+		t.Error("Expected no keyword weight in a search by embedding")
+	}
+
+	// Matching words is the fallback when the post cannot be embedded
+	embedder.err = errors.New("model not loaded")
+	if _, err := service.RelatedPosts(context.Background(), post); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if mockRepo.searchQuery != "concurrency threads" {
+		t.Errorf("Expected a more-like-this query, got %q", mockRepo.searchQuery)
+	}
+}
+
+func TestSearchPosts_InvalidFilters(t *testing.T) {
+	after := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		filters SearchFilters
+	}{
+		{"backtick in category", SearchFilters{Category: "news` || id:>0"}},
+		{"backtick in tag", SearchFilters{Tags: []string{"go`"}}},
+		{"empty date range", SearchFilters{CreatedAfter: &after, CreatedBefore: &before}},
+		{"backtick in author", SearchFilters{Author: "`"}},
+		{"malformed month", SearchFilters{Month: "2024-13"}},
+		{"negative year", SearchFilters{Year: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockSearchIndexRepositoryForSearch{}
+			_, err := NewSearchService(mockRepo).SearchPosts(context.Background(), SearchParams{Query: "test", Filters: tt.filters})
+			if !errors.Is(err, domain.ErrInvalidSearch) {
+				t.Errorf("Expected ErrInvalidSearch, got: %v", err)
+			}
+		})
+	}
 }
 
 func TestSearchPosts_InvalidResultData(t *testing.T) {
@@ -283,8 +717,7 @@ func TestSearchPosts_InvalidResultData(t *testing.T) {
 	}
 
 	// Mock invalid search results
-	mockResults := []interface{}{
-		"invalid result", // Not a map
+	mockRepo.searchResults = searchHits(
 		map[string]interface{}{
 			"title": "Valid Post", // Missing required fields
 		},
@@ -298,8 +731,7 @@ func TestSearchPosts_InvalidResultData(t *testing.T) {
 			"updated_at":  "2023-01-01 00:00:00",
 			"_text_match": float64(0.95),
 		},
-	}
-	mockRepo.searchResults = mockResults
+	)
 
 	result, err := service.SearchPosts(context.Background(), params)
 	if err != nil {
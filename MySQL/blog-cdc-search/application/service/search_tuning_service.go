@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"blog-cdc-search/domain"
+)
+
+// SearchTuningService manages synonyms and curations. The database holds the
+// definitions; after every change they are all pushed to the search index,
+// so a rebuilt index only needs a Sync to get them back.
+type SearchTuningService struct {
+	repo  domain.SearchTuningRepository
+	index domain.SearchIndexRepository
+}
+
+// NewSearchTuningService creates a new search tuning service
+func NewSearchTuningService(repo domain.SearchTuningRepository, index domain.SearchIndexRepository) *SearchTuningService {
+	return &SearchTuningService{
+		repo:  repo,
+		index: index,
+	}
+}
+
+// CreateSynonym stores a synonym and syncs the index. A root makes it one-way.
+func (s *SearchTuningService) CreateSynonym(ctx context.Context, root string, synonyms []string) (*domain.Synonym, error) {
+	synonym, err := domain.NewSynonym(root, synonyms)
+	if err != nil {
+		return nil, fmt.Errorf("invalid synonym: %w", err)
+	}
+
+	if err := s.repo.CreateSynonym(ctx, synonym); err != nil {
+		return nil, fmt.Errorf("failed to create synonym: %w", err)
+	}
+	if err := s.syncSynonyms(ctx); err != nil {
+		return synonym, fmt.Errorf("synonym saved but not synced to the search index: %w", err)
+	}
+	return synonym, nil
+}
+
+// ListSynonyms returns all synonyms
+func (s *SearchTuningService) ListSynonyms(ctx context.Context) ([]*domain.Synonym, error) {
+	synonyms, err := s.repo.ListSynonyms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list synonyms: %w", err)
+	}
+	return synonyms, nil
+}
+
+// DeleteSynonym removes a synonym and syncs the index
+func (s *SearchTuningService) DeleteSynonym(ctx context.Context, id int) error {
+	if err := s.repo.DeleteSynonym(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete synonym: %w", err)
+	}
+	if err := s.syncSynonyms(ctx); err != nil {
+		return fmt.Errorf("synonym deleted but not synced to the search index: %w", err)
+	}
+	return nil
+}
+
+// CreateCuration stores a curation and syncs the index
+func (s *SearchTuningService) CreateCuration(ctx context.Context, query, match string, pinned, hidden []int) (*domain.Curation, error) {
+	curation, err := domain.NewCuration(query, match, pinned, hidden)
+	if err != nil {
+		return nil, fmt.Errorf("invalid curation: %w", err)
+	}
+
+	if err := s.repo.CreateCuration(ctx, curation); err != nil {
+		return nil, fmt.Errorf("failed to create curation: %w", err)
+	}
+	if err := s.syncCurations(ctx); err != nil {
+		return curation, fmt.Errorf("curation saved but not synced to the search index: %w", err)
+	}
+	return curation, nil
+}
+
+// ListCurations returns all curations
+func (s *SearchTuningService) ListCurations(ctx context.Context) ([]*domain.Curation, error) {
+	curations, err := s.repo.ListCurations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list curations: %w", err)
+	}
+	return curations, nil
+}
+
+// DeleteCuration removes a curation and syncs the index
+func (s *SearchTuningService) DeleteCuration(ctx context.Context, id int) error {
+	if err := s.repo.DeleteCuration(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete curation: %w", err)
+	}
+	if err := s.syncCurations(ctx); err != nil {
+		return fmt.Errorf("curation deleted but not synced to the search index: %w", err)
+	}
+	return nil
+}
+
+// Sync pushes every stored synonym and curation to the search index,
+// replacing whatever it had. Run it after the index is recreated.
+func (s *SearchTuningService) Sync(ctx context.Context) error {
+	if err := s.syncSynonyms(ctx); err != nil {
+		return err
+	}
+	return s.syncCurations(ctx)
+}
+
+func (s *SearchTuningService) syncSynonyms(ctx context.Context) error {
+	synonyms, err := s.ListSynonyms(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.index.SyncSynonyms(ctx, "posts", synonyms); err != nil {
+		return fmt.Errorf("failed to sync synonyms: %w", err)
+	}
+	return nil
+}
+
+func (s *SearchTuningService) syncCurations(ctx context.Context) error {
+	curations, err := s.ListCurations(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.index.SyncCurations(ctx, "posts", curations); err != nil {
+		return fmt.Errorf("failed to sync curations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// recordingSink records applied changes and fails the first failures calls
+type recordingSink struct {
+	name     string
+	mu       sync.Mutex
+	applied  []*domain.Change
+	calls    int
+	failures int
+	block    chan struct{}
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Apply(ctx context.Context, change *domain.Change) error {
+	if s.block != nil {
+		select {
+		case <-s.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("sink unavailable")
+	}
+	s.applied = append(s.applied, change)
+	return nil
+}
+
+func (s *recordingSink) appliedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.applied)
+}
+
+type memoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]domain.SinkCheckpoint
+}
+
+func (m *memoryCheckpointStore) Load(ctx context.Context, sink string) (*domain.SinkCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if checkpoint, ok := m.checkpoints[sink]; ok {
+		return &checkpoint, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryCheckpointStore) Save(ctx context.Context, checkpoint *domain.SinkCheckpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[checkpoint.Sink] = *checkpoint
+	return nil
+}
+
+func testChange(id string, ts int64) *domain.Change {
+	return &domain.Change{
+		Op:    domain.ChangeOpUpsert,
+		ID:    id,
+		Event: &domain.CDCEvent{Database: "blog", Table: "posts", Type: domain.EventTypeInsert, TS: ts},
+	}
+}
+
+func statsByName(d *SinkDispatcher) map[string]SinkStats {
+	stats := map[string]SinkStats{}
+	for _, s := range d.Stats() {
+		stats[s.Name] = s
+	}
+	return stats
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("Retry %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+func TestSinkDispatcher_SyncSinkRetries(t *testing.T) {
+	sink := &recordingSink{name: "search", failures: 2}
+	d := NewSinkDispatcher(SinkConfig{Sink: sink, Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}})
+
+	if err := d.Dispatch(context.Background(), testChange("1", 10)); err != nil {
+		t.Fatalf("Expected delivery to succeed after retries, got: %v", err)
+	}
+
+	stats := statsByName(d)["search"]
+	if stats.Delivered != 1 || stats.Retried != 2 || stats.Failed != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.Checkpoint != 10 {
+		t.Errorf("Expected checkpoint 10, got %d", stats.Checkpoint)
+	}
+}
+
+func TestSinkDispatcher_SyncSinkErrorIsReturned(t *testing.T) {
+	sink := &recordingSink{name: "search", failures: 5}
+	d := NewSinkDispatcher(SinkConfig{Sink: sink, Retry: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}})
+
+	err := d.Dispatch(context.Background(), testChange("1", 10))
+	if err == nil {
+		t.Fatal("Expected error from failing synchronous sink")
+	}
+
+	stats := statsByName(d)["search"]
+	if stats.Failed != 1 || stats.LastError == "" || stats.Checkpoint != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestSinkDispatcher_AsyncSinkIsolated(t *testing.T) {
+	search := &recordingSink{name: "search"}
+	webhook := &recordingSink{name: "webhook", block: make(chan struct{})}
+	d := NewSinkDispatcher(
+		SinkConfig{Sink: search},
+		SinkConfig{Sink: webhook, Async: true, BufferSize: 2},
+	)
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+
+	// The webhook is stuck, yet changes reach the search sink while its
+	// buffer has room: one change is in its worker and two are buffered
+	for i := 1; i <= 3; i++ {
+		if err := d.Dispatch(context.Background(), testChange("1", int64(i))); err != nil {
+			t.Fatalf("Dispatch %d failed: %v", i, err)
+		}
+	}
+	if search.appliedCount() != 3 {
+		t.Errorf("Expected 3 changes in search sink, got %d", search.appliedCount())
+	}
+
+	// Once the buffer is full the dispatch fails, so the message is
+	// redelivered rather than the change lost
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelWait()
+	if err := d.Dispatch(waitCtx, testChange("1", 4)); err == nil {
+		t.Fatal("Expected dispatch to fail while the webhook buffer is full")
+	}
+
+	close(webhook.block)
+	if err := d.Dispatch(context.Background(), testChange("1", 4)); err != nil {
+		t.Fatalf("Expected the redelivery to be queued once the buffer drains, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("Failed to stop dispatcher: %v", err)
+	}
+
+	// The search sink skips the redelivery it had already applied
+	if stats := statsByName(d)["search"]; stats.Delivered != 4 || stats.Skipped != 1 {
+		t.Errorf("Expected 4 deliveries and 1 skipped redelivery to search, got %+v", stats)
+	}
+	if stats := statsByName(d)["webhook"]; stats.Delivered != 4 {
+		t.Errorf("Expected every change to reach the webhook, got %+v", stats)
+	}
+	if err := d.Dispatch(context.Background(), testChange("1", 6)); err == nil {
+		t.Error("Expected dispatch after stop to fail")
+	}
+}
+
+func TestSinkDispatcher_SyncFailureWithholdsAsync(t *testing.T) {
+	search := &recordingSink{name: "search", failures: 1}
+	webhook := &recordingSink{name: "webhook"}
+	d := NewSinkDispatcher(
+		SinkConfig{Sink: search},
+		SinkConfig{Sink: webhook, Async: true},
+	)
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+
+	// The webhook only sees the change once, after the redelivery succeeds
+	if err := d.Dispatch(context.Background(), testChange("1", 10)); err == nil {
+		t.Fatal("Expected error from failing synchronous sink")
+	}
+	if err := d.Dispatch(context.Background(), testChange("1", 10)); err != nil {
+		t.Fatalf("Expected the redelivery to succeed, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("Failed to stop dispatcher: %v", err)
+	}
+	if webhook.appliedCount() != 1 {
+		t.Errorf("Expected the webhook to get the change once, got %d", webhook.appliedCount())
+	}
+}
+
+func TestSinkDispatcher_StopAbandonsRetries(t *testing.T) {
+	sink := &recordingSink{name: "analytics", failures: 100}
+	d := NewSinkDispatcher(SinkConfig{Sink: sink, Async: true, Retry: RetryPolicy{MaxAttempts: 100, InitialBackoff: time.Hour}})
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+	if err := d.Dispatch(context.Background(), testChange("1", 1)); err != nil {
+		t.Fatalf("Dispatch failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := d.Stop(ctx); err == nil {
+		t.Error("Expected stop to report undrained sinks")
+	}
+	if stats := statsByName(d)["analytics"]; stats.Failed != 1 {
+		t.Errorf("Expected the abandoned change to count as failed, got %+v", stats)
+	}
+}
+
+func TestSinkDispatcher_Checkpoints(t *testing.T) {
+	applied := testChange("3", 100)
+	store := &memoryCheckpointStore{checkpoints: map[string]domain.SinkCheckpoint{
+		"search": {Sink: "search", Position: 100, Applied: []string{applied.Key()}, Delivered: 7},
+	}}
+	sink := &recordingSink{name: "search"}
+	d := NewSinkDispatcher(SinkConfig{Sink: sink})
+	d.SetCheckpointStore(store)
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+
+	if stats := statsByName(d)["search"]; stats.Checkpoint != 100 || stats.Delivered != 7 {
+		t.Errorf("Expected restored checkpoint, got %+v", stats)
+	}
+
+	// Only the change already applied is skipped; changes before the
+	// checkpoint, at it and after it are applied
+	for _, change := range []*domain.Change{testChange("1", 50), testChange("3", 100), testChange("2", 100), testChange("2", 150)} {
+		if err := d.Dispatch(context.Background(), change); err != nil {
+			t.Fatalf("Dispatch failed: %v", err)
+		}
+	}
+	if sink.appliedCount() != 3 || sink.applied[0].ID != "1" || sink.applied[2].Position() != 150 {
+		t.Errorf("Expected the three new changes to be applied, got %d", sink.appliedCount())
+	}
+	if stats := statsByName(d)["search"]; stats.Skipped != 1 {
+		t.Errorf("Expected 1 skipped change, got %+v", stats)
+	}
+
+	saved := store.checkpoints["search"]
+	if saved.Position != 150 || saved.Delivered != 10 || len(saved.Applied) != 4 {
+		t.Errorf("Unexpected saved checkpoint: %+v", saved)
+	}
+
+	// Changes without a position are always applied
+	if err := d.Dispatch(context.Background(), &domain.Change{Op: domain.ChangeOpDelete, ID: "4"}); err != nil || sink.appliedCount() != 4 {
+		t.Errorf("Expected a change without a position to be applied, got %d, %v", sink.appliedCount(), err)
+	}
+}
+
+func TestSinkDispatcher_RequeuedChangeAfterLaterOne(t *testing.T) {
+	sink := &recordingSink{name: "search"}
+	d := NewSinkDispatcher(SinkConfig{Sink: sink})
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+
+	// A message sent back to the queue returns after a later one; it is
+	// applied once and skipped when delivered again
+	for _, change := range []*domain.Change{testChange("2", 200), testChange("1", 100), testChange("1", 100)} {
+		if err := d.Dispatch(context.Background(), change); err != nil {
+			t.Fatalf("Dispatch failed: %v", err)
+		}
+	}
+	if sink.appliedCount() != 2 || sink.applied[1].Position() != 100 {
+		t.Errorf("Expected the earlier change to be applied after the later one, got %d", sink.appliedCount())
+	}
+	if stats := statsByName(d)["search"]; stats.Skipped != 1 || stats.Checkpoint != 200 {
+		t.Errorf("Expected 1 skipped change and checkpoint 200, got %+v", stats)
+	}
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// Headers sent with every webhook request
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Retries of failed deliveries are claimed from the delivery log in batches.
+// The lease keeps other processes off a claimed batch while it is retried.
+const (
+	webhookRetryInterval = time.Second
+	webhookRetryBatch    = 50
+	webhookRetryLease    = 5 * time.Minute
+)
+
+// WebhookPayload is the JSON body posted to endpoints
+type WebhookPayload struct {
+	Event      string                 `json:"event"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Post       *domain.SearchDocument `json:"post"`
+}
+
+// WebhookService registers endpoints and delivers post change events to them.
+// It is the webhook sink of the CDC service and backs the dashboard's
+// delivery log.
+type WebhookService struct {
+	repo          domain.WebhookRepository
+	client        *http.Client
+	retry         RetryPolicy
+	now           func() time.Time
+	retryInterval time.Duration
+}
+
+// NewWebhookService creates a webhook service; retry applies to each delivery
+// and is carried out by RunRetries
+func NewWebhookService(repo domain.WebhookRepository, client *http.Client, retry RetryPolicy) *WebhookService {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookService{
+		repo:          repo,
+		client:        client,
+		retry:         retry,
+		now:           time.Now,
+		retryInterval: webhookRetryInterval,
+	}
+}
+
+// SignWebhook returns the signature header value for a payload: the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the endpoint secret
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateEndpoint registers an endpoint, generating a secret when none is given
+func (s *WebhookService) CreateEndpoint(ctx context.Context, url, secret string, events []string) (*domain.WebhookEndpoint, error) {
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(random)
+	}
+
+	endpoint, err := domain.NewWebhookEndpoint(url, secret, events)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// ListEndpoints returns the registered endpoints without their secrets
+func (s *WebhookService) ListEndpoints(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	endpoints, err := s.repo.ListEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+	return endpoints, nil
+}
+
+// DeleteEndpoint removes an endpoint
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id int) error {
+	return s.repo.DeleteEndpoint(ctx, id)
+}
+
+// ListDeliveries returns the most recent deliveries, newest first
+func (s *WebhookService) ListDeliveries(ctx context.Context, limit int) ([]*domain.WebhookDelivery, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	return s.repo.ListDeliveries(ctx, limit)
+}
+
+// Name returns the sink name used in stats and checkpoints
+func (s *WebhookService) Name() string {
+	return "webhook"
+}
+
+// Apply sends the change to every subscribed endpoint, making one attempt per
+// endpoint side by side. Failed deliveries are left pending in the delivery
+// log for RunRetries, so a slow or broken endpoint holds up the sink for one
+// request timeout at most. Only storage errors are returned.
+//
+// Deliveries are logged as already scheduled for a retry one lease later, so
+// RunRetries picks up any whose first attempt never happened. When the change
+// is applied again, endpoints that already have a delivery for it are left
+// to RunRetries rather than sent the change twice.
+func (s *WebhookService) Apply(ctx context.Context, change *domain.Change) error {
+	event, ok := webhookEvent(change)
+	if !ok {
+		return nil
+	}
+
+	endpoints, err := s.repo.ListEndpoints(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	var postID int
+	fmt.Sscanf(change.ID, "%d", &postID)
+	post := change.Document
+	if post == nil {
+		post = &domain.SearchDocument{ID: change.ID}
+	} else if post.Embedding != nil || post.Localized != nil {
+		// Receivers get the post, not its search vector or the copies of its
+		// text in localized fields
+		withoutIndexFields := *post
+		withoutIndexFields.Embedding = nil
+		withoutIndexFields.Localized = nil
+		post = &withoutIndexFields
+	}
+	payload, err := json.Marshal(WebhookPayload{Event: event, OccurredAt: s.now().UTC(), Post: post})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	// Every delivery is logged before any is attempted, so a storage error
+	// redelivers the change without having notified some endpoints already
+	changeKey := change.Key()
+	retryAt := s.now().Add(webhookRetryLease)
+	var subscribed []*domain.WebhookEndpoint
+	var deliveries []*domain.WebhookDelivery
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribes(event) {
+			continue
+		}
+
+		delivery := &domain.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			Event:         event,
+			PostID:        postID,
+			ChangeKey:     changeKey,
+			Payload:       string(payload),
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: &retryAt,
+			CreatedAt:     s.now(),
+			UpdatedAt:     s.now(),
+		}
+		created, err := s.repo.CreateDelivery(ctx, delivery)
+		if err != nil {
+			return fmt.Errorf("failed to record webhook delivery: %w", err)
+		}
+		if !created {
+			continue
+		}
+		subscribed = append(subscribed, endpoint)
+		deliveries = append(deliveries, delivery)
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) {
+			defer wg.Done()
+			s.attempt(ctx, endpoint, delivery, s.retry.MaxAttempts)
+		}(subscribed[i], deliveries[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// Redeliver sends a logged delivery's payload again as a new delivery. It
+// makes a single attempt so the caller gets the outcome right away.
+func (s *WebhookService) Redeliver(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	original, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := s.repo.GetEndpoint(ctx, original.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	retryAt := s.now().Add(webhookRetryLease)
+	delivery := &domain.WebhookDelivery{
+		EndpointID:    original.EndpointID,
+		Event:         original.Event,
+		PostID:        original.PostID,
+		Payload:       original.Payload,
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: &retryAt,
+		CreatedAt:     s.now(),
+		UpdatedAt:     s.now(),
+	}
+	if _, err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	s.attempt(ctx, endpoint, delivery, 1)
+	return delivery, nil
+}
+
+// RunRetries retries due deliveries every second until ctx is done. Each
+// process consuming the CDC queue with the webhook sink should run it;
+// deliveries are claimed, so several processes can share the delivery log.
+func (s *WebhookService) RunRetries(ctx context.Context) {
+	ticker := time.NewTicker(s.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			retried, err := s.RetryDue(ctx)
+			if err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			if retried < webhookRetryBatch || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+}
+
+// RetryDue claims a batch of due deliveries and attempts each once, one
+// goroutine per endpoint. Once an endpoint fails, the rest of its batch is
+// put back without a request, so an endpoint that is down is not sent its
+// whole backlog on every pass. It returns the number of deliveries claimed.
+func (s *WebhookService) RetryDue(ctx context.Context) (int, error) {
+	due, err := s.repo.ClaimDueDeliveries(ctx, s.now(), webhookRetryLease, webhookRetryBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	byEndpoint := make(map[int][]*domain.WebhookDelivery)
+	var endpointIDs []int
+	for _, delivery := range due {
+		if _, ok := byEndpoint[delivery.EndpointID]; !ok {
+			endpointIDs = append(endpointIDs, delivery.EndpointID)
+		}
+		byEndpoint[delivery.EndpointID] = append(byEndpoint[delivery.EndpointID], delivery)
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range endpointIDs {
+		wg.Add(1)
+		go func(deliveries []*domain.WebhookDelivery) {
+			defer wg.Done()
+			s.retryEndpoint(ctx, deliveries)
+		}(byEndpoint[id])
+	}
+	wg.Wait()
+	return len(due), nil
+}
+
+// retryEndpoint attempts an endpoint's claimed deliveries in order
+func (s *WebhookService) retryEndpoint(ctx context.Context, deliveries []*domain.WebhookDelivery) {
+	endpoint, err := s.repo.GetEndpoint(ctx, deliveries[0].EndpointID)
+	if err != nil && err != domain.ErrWebhookNotFound {
+		// The lease runs out and the batch is claimed again
+		log.Printf("Failed to load webhook endpoint %d: %v", deliveries[0].EndpointID, err)
+		return
+	}
+
+	for i, delivery := range deliveries {
+		switch {
+		case endpoint == nil || !endpoint.Active:
+			delivery.Status = domain.WebhookDeliveryFailed
+			delivery.Error = "endpoint was removed or deactivated"
+			delivery.NextAttemptAt = nil
+			s.updateDelivery(ctx, delivery)
+		case ctx.Err() != nil:
+			s.postpone(ctx, delivery)
+		default:
+			if s.attempt(ctx, endpoint, delivery, s.retry.MaxAttempts) {
+				continue
+			}
+			for _, rest := range deliveries[i+1:] {
+				s.postpone(ctx, rest)
+			}
+			return
+		}
+	}
+}
+
+// attempt posts the payload once and records the outcome. A failed delivery
+// stays pending with its next attempt scheduled until it has been attempted
+// maxAttempts times. It reports whether the endpoint accepted the payload.
+func (s *WebhookService) attempt(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery, maxAttempts int) bool {
+	delivery.Attempts++
+	delivery.ResponseStatus, delivery.Error = s.post(ctx, endpoint, delivery)
+	delivery.NextAttemptAt = nil
+	switch {
+	case delivery.Error == "":
+		delivery.Status = domain.WebhookDeliverySucceeded
+		log.Printf("Delivered %s for post ID %d to %s", delivery.Event, delivery.PostID, endpoint.URL)
+	case delivery.Attempts >= maxAttempts:
+		delivery.Status = domain.WebhookDeliveryFailed
+		log.Printf("Webhook delivery %d to %s failed: %s", delivery.ID, endpoint.URL, delivery.Error)
+	default:
+		delivery.Status = domain.WebhookDeliveryPending
+		next := s.now().Add(s.retry.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		log.Printf("Webhook delivery %d to %s failed, retrying at %s: %s", delivery.ID, endpoint.URL, next.Format(time.RFC3339), delivery.Error)
+	}
+	s.updateDelivery(ctx, delivery)
+	return delivery.Status == domain.WebhookDeliverySucceeded
+}
+
+// postpone puts a claimed delivery back without attempting it
+func (s *WebhookService) postpone(ctx context.Context, delivery *domain.WebhookDelivery) {
+	next := s.now().Add(s.retry.backoff(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+	s.updateDelivery(ctx, delivery)
+}
+
+// updateDelivery saves the delivery, even when ctx has been cancelled
+func (s *WebhookService) updateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) {
+	delivery.UpdatedAt = s.now()
+	if err := s.repo.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		log.Printf("Failed to update webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// post sends one signed request, returning the response status and an error
+// message for anything but a 2xx response
+func (s *WebhookService) post(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) (int, string) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "blog-cdc-search-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(endpoint.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Sprintf("endpoint returned %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, ""
+}
+
+// webhookEvent maps a change to its webhook event. Bootstrap inserts are not
+// sent, so re-running the initial snapshot does not notify every endpoint.
+func webhookEvent(change *domain.Change) (string, bool) {
+	if change.Event == nil {
+		return "", false
+	}
+	switch change.Event.Type {
+	case domain.EventTypeInsert:
+		return domain.WebhookEventPostCreated, true
+	case domain.EventTypeUpdate:
+		return domain.WebhookEventPostUpdated, true
+	case domain.EventTypeDelete:
+		return domain.WebhookEventPostDeleted, true
+	default:
+		return "", false
+	}
+}
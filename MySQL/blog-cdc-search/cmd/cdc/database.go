@@ -0,0 +1,45 @@
+package main
+
+import (
+	"database/sql"
+
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/database"
+	"blog-cdc-search/infrastructure/repository"
+	"blog-cdc-search/infrastructure/tlsconfig"
+)
+
+// openDatabase connects to the blog database using the same DB_* environment
+// variables as the blog application
+func openDatabase() (*sql.DB, error) {
+	return database.NewMySQLConnection(database.MySQLConfig{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnv("DB_PORT", "3306"),
+		User:     getEnv("DB_USER", "bloguser"),
+		Password: getSecret("DB_PASSWORD", "blogpass"),
+		DBName:   getEnv("DB_NAME", "blog"),
+		TLS: tlsconfig.Config{
+			Enabled:            getEnvBool("DB_TLS", false),
+			CAFile:             getEnv("DB_TLS_CA", ""),
+			CertFile:           getEnv("DB_TLS_CERT", ""),
+			KeyFile:            getEnv("DB_TLS_KEY", ""),
+			ServerName:         getEnv("DB_TLS_SERVER_NAME", ""),
+			InsecureSkipVerify: getEnvBool("DB_TLS_INSECURE", false),
+		},
+	})
+}
+
+// newWebhookRepository returns the webhook store backed by db
+func newWebhookRepository(db *sql.DB) domain.WebhookRepository {
+	return repository.NewMySQLWebhookRepository(db)
+}
+
+// newPostVersionRepository returns the post history store backed by db
+func newPostVersionRepository(db *sql.DB) domain.PostVersionRepository {
+	return repository.NewMySQLPostVersionRepository(db)
+}
+
+// newPostRepository returns the posts table backed by db
+func newPostRepository(db *sql.DB) domain.PostRepository {
+	return repository.NewMySQLPostRepository(db)
+}
@@ -2,16 +2,26 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"flag"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"blog-cdc-search/application/service"
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/cache"
+	"blog-cdc-search/infrastructure/embedding"
 	"blog-cdc-search/infrastructure/messagequeue"
+	"blog-cdc-search/infrastructure/readmodel"
 	"blog-cdc-search/infrastructure/searchindex"
+	"blog-cdc-search/infrastructure/sink"
+	"blog-cdc-search/infrastructure/tlsconfig"
 )
 
 func getEnv(key, defaultValue string) string {
@@ -30,18 +40,159 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getSecret reads a secret from the file named by KEY_FILE (Docker/Kubernetes
+// secrets), falling back to the KEY environment variable
+func getSecret(key, defaultValue string) string {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read %s_FILE: %v", key, err)
+		}
+		return strings.TrimSpace(string(content))
+	}
+	return getEnv(key, defaultValue)
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitPairs parses comma-separated key:value pairs, skipping malformed ones
+func splitPairs(value string) map[string]string {
+	pairs := map[string]string{}
+	for _, item := range splitList(value) {
+		if key, val, ok := strings.Cut(item, ":"); ok {
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+	return pairs
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
 func main() {
 	// Parse command line flags
 	var (
 		rabbitMQHost     = flag.String("rabbitmq-host", getEnv("RABBITMQ_HOST", "localhost"), "RabbitMQ host")
 		rabbitMQPort     = flag.Int("rabbitmq-port", getEnvInt("RABBITMQ_PORT", 5672), "RabbitMQ port")
 		rabbitMQUser     = flag.String("rabbitmq-user", getEnv("RABBITMQ_USER", "admin"), "RabbitMQ username")
-		rabbitMQPassword = flag.String("rabbitmq-password", getEnv("RABBITMQ_PASSWORD", "admin123"), "RabbitMQ password")
+		rabbitMQPassword = flag.String("rabbitmq-password", getSecret("RABBITMQ_PASSWORD", "admin123"), "RabbitMQ password")
 		rabbitMQVHost    = flag.String("rabbitmq-vhost", getEnv("RABBITMQ_VHOST", "/"), "RabbitMQ vhost")
 		typesenseHost    = flag.String("typesense-host", getEnv("TYPESENSE_HOST", "localhost"), "Typesense host")
 		typesensePort    = flag.Int("typesense-port", getEnvInt("TYPESENSE_PORT", 8108), "Typesense port")
-		typesenseAPIKey  = flag.String("typesense-api-key", getEnv("TYPESENSE_API_KEY", "xyz"), "Typesense API key")
+		typesenseAPIKey  = flag.String("typesense-api-key", getSecret("TYPESENSE_API_KEY", "xyz"), "Typesense API key")
 		queueName        = flag.String("queue", getEnv("QUEUE_NAME", "cdc-posts"), "Queue name to consume from")
+		handlerTimeout   = flag.Duration("handler-timeout", getEnvDuration("HANDLER_TIMEOUT", 30*time.Second), "Maximum time to process a single message")
+		readTimeout      = flag.Duration("typesense-read-timeout", getEnvDuration("TYPESENSE_READ_TIMEOUT", 5*time.Second), "Timeout for Typesense read operations")
+		writeTimeout     = flag.Duration("typesense-write-timeout", getEnvDuration("TYPESENSE_WRITE_TIMEOUT", 10*time.Second), "Timeout for Typesense write operations")
+		reconnectMin     = flag.Duration("reconnect-initial-interval", getEnvDuration("RECONNECT_INITIAL_INTERVAL", 500*time.Millisecond), "Initial delay before reconnecting to RabbitMQ")
+		reconnectMax     = flag.Duration("reconnect-max-interval", getEnvDuration("RECONNECT_MAX_INTERVAL", 30*time.Second), "Maximum delay between RabbitMQ reconnect attempts")
+		pauseInterval    = flag.Duration("pause-interval", getEnvDuration("PAUSE_INTERVAL", 5*time.Second), "How long to pause consuming while Typesense is unavailable")
+		breakerThreshold = flag.Int("typesense-breaker-threshold", getEnvInt("TYPESENSE_BREAKER_THRESHOLD", 5), "Consecutive Typesense failures before the circuit opens")
+		breakerTimeout   = flag.Duration("typesense-breaker-timeout", getEnvDuration("TYPESENSE_BREAKER_TIMEOUT", 30*time.Second), "How long the Typesense circuit stays open before probing")
+		exchange         = flag.String("exchange", getEnv("RABBITMQ_EXCHANGE", "maxwell"), "Exchange the CDC connector publishes to (empty to skip binding)")
+		exchangeType     = flag.String("exchange-type", getEnv("RABBITMQ_EXCHANGE_TYPE", "fanout"), "Exchange type: direct, fanout, topic or headers")
+		bindingKeys      = flag.String("binding-keys", getEnv("RABBITMQ_BINDING_KEYS", "blog.posts"), "Comma-separated routing keys to bind the queue with (topic wildcards allowed)")
+		queueType        = flag.String("queue-type", getEnv("QUEUE_TYPE", ""), "Queue type: classic or quorum (empty for broker default)")
+		queueMessageTTL  = flag.Duration("queue-message-ttl", getEnvDuration("QUEUE_MESSAGE_TTL", 0), "Expire queued messages after this long (0 disables)")
+		queueMaxLength   = flag.Int("queue-max-length", getEnvInt("QUEUE_MAX_LENGTH", 0), "Maximum number of ready messages in the queue (0 disables)")
+		deadLetterExch   = flag.String("dead-letter-exchange", getEnv("QUEUE_DEAD_LETTER_EXCHANGE", ""), "Exchange for rejected and expired messages")
+		deadLetterKey    = flag.String("dead-letter-routing-key", getEnv("QUEUE_DEAD_LETTER_ROUTING_KEY", ""), "Routing key for dead-lettered messages")
+		consumerTag      = flag.String("consumer-tag", getEnv("CONSUMER_TAG", "blog-cdc-search"), "Consumer tag shown in the RabbitMQ management UI")
+		prefetchCount    = flag.Int("prefetch", getEnvInt("PREFETCH_COUNT", 1), "Number of unacknowledged messages delivered at once")
+		rabbitMQTLS      = flag.Bool("rabbitmq-tls", getEnvBool("RABBITMQ_TLS", false), "Connect to RabbitMQ over amqps://")
+		rabbitMQCACert   = flag.String("rabbitmq-ca-cert", getEnv("RABBITMQ_CA_CERT", ""), "PEM file with the CA that signed the RabbitMQ certificate")
+		rabbitMQCert     = flag.String("rabbitmq-client-cert", getEnv("RABBITMQ_CLIENT_CERT", ""), "PEM client certificate for RabbitMQ mutual TLS")
+		rabbitMQKey      = flag.String("rabbitmq-client-key", getEnv("RABBITMQ_CLIENT_KEY", ""), "PEM client key for RabbitMQ mutual TLS")
+		rabbitMQSrvName  = flag.String("rabbitmq-server-name", getEnv("RABBITMQ_SERVER_NAME", ""), "Expected server name in the RabbitMQ certificate (defaults to host)")
+		typesenseTLS     = flag.Bool("typesense-tls", getEnvBool("TYPESENSE_TLS", false), "Connect to Typesense over https://")
+		typesenseCACert  = flag.String("typesense-ca-cert", getEnv("TYPESENSE_CA_CERT", ""), "PEM file with the CA that signed the Typesense certificate")
+		typesenseCert    = flag.String("typesense-client-cert", getEnv("TYPESENSE_CLIENT_CERT", ""), "PEM client certificate for Typesense mutual TLS")
+		typesenseKey     = flag.String("typesense-client-key", getEnv("TYPESENSE_CLIENT_KEY", ""), "PEM client key for Typesense mutual TLS")
+		typesenseNodes   = flag.String("typesense-nodes", getEnv("TYPESENSE_NODES", ""), "Comma-separated Typesense cluster nodes (URLs or host:port); overrides host and port")
+		nearestNode      = flag.String("typesense-nearest-node", getEnv("TYPESENSE_NEAREST_NODE", ""), "Typesense node to try first, e.g. one in the same zone")
+		healthcheckIntvl = flag.Duration("typesense-healthcheck-interval", getEnvDuration("TYPESENSE_HEALTHCHECK_INTERVAL", time.Minute), "How long a failed Typesense node is skipped before retrying it")
+		numRetries       = flag.Int("typesense-num-retries", getEnvInt("TYPESENSE_NUM_RETRIES", 0), "Attempts per Typesense request across nodes (0 means one per node)")
+		retryInterval    = flag.Duration("typesense-retry-interval", getEnvDuration("TYPESENSE_RETRY_INTERVAL", 100*time.Millisecond), "Pause before trying the next Typesense node")
+		connTimeout      = flag.Duration("typesense-connection-timeout", getEnvDuration("TYPESENSE_CONNECTION_TIMEOUT", 5*time.Second), "Timeout for a single request to one Typesense node")
+		searchBackend    = flag.String("search-backend", getEnv("SEARCH_BACKEND", "typesense"), "Search index backend: typesense, elasticsearch, opensearch, meilisearch or bleve")
+		esURL            = flag.String("elasticsearch-url", getEnv("ELASTICSEARCH_URL", "http://localhost:9200"), "Elasticsearch/OpenSearch base URL")
+		esUsername       = flag.String("elasticsearch-username", getEnv("ELASTICSEARCH_USERNAME", ""), "Elasticsearch/OpenSearch basic auth username")
+		esPassword       = flag.String("elasticsearch-password", getSecret("ELASTICSEARCH_PASSWORD", ""), "Elasticsearch/OpenSearch basic auth password")
+		esAPIKey         = flag.String("elasticsearch-api-key", getSecret("ELASTICSEARCH_API_KEY", ""), "Elasticsearch API key (takes precedence over basic auth)")
+		esCACert         = flag.String("elasticsearch-ca-cert", getEnv("ELASTICSEARCH_CA_CERT", ""), "PEM file with the CA that signed the Elasticsearch certificate")
+		esRefresh        = flag.String("elasticsearch-refresh", getEnv("ELASTICSEARCH_REFRESH", ""), "Refresh policy for bulk writes: true, wait_for or empty")
+		esReadTimeout    = flag.Duration("elasticsearch-read-timeout", getEnvDuration("ELASTICSEARCH_READ_TIMEOUT", 5*time.Second), "Timeout for Elasticsearch read operations")
+		esWriteTimeout   = flag.Duration("elasticsearch-write-timeout", getEnvDuration("ELASTICSEARCH_WRITE_TIMEOUT", 10*time.Second), "Timeout for Elasticsearch write operations")
+		msURL            = flag.String("meilisearch-url", getEnv("MEILISEARCH_URL", "http://localhost:7700"), "Meilisearch base URL")
+		msAPIKey         = flag.String("meilisearch-api-key", getSecret("MEILISEARCH_API_KEY", ""), "Meilisearch API key")
+		msCACert         = flag.String("meilisearch-ca-cert", getEnv("MEILISEARCH_CA_CERT", ""), "PEM file with the CA that signed the Meilisearch certificate")
+		msPoll           = flag.Duration("meilisearch-task-poll-interval", getEnvDuration("MEILISEARCH_TASK_POLL_INTERVAL", 50*time.Millisecond), "How often to check whether a Meilisearch task has finished")
+		msReadTimeout    = flag.Duration("meilisearch-read-timeout", getEnvDuration("MEILISEARCH_READ_TIMEOUT", 5*time.Second), "Timeout for Meilisearch read operations")
+		msWriteTimeout   = flag.Duration("meilisearch-write-timeout", getEnvDuration("MEILISEARCH_WRITE_TIMEOUT", 30*time.Second), "Timeout for Meilisearch writes, including waiting for their tasks")
+		blevePath        = flag.String("bleve-path", getEnv("BLEVE_PATH", "data/bleve"), "Directory of the embedded Bleve index")
+		bleveAnalyzer    = flag.String("bleve-analyzer", getEnv("BLEVE_ANALYZER", "standard"), "Default Bleve analyzer for text fields")
+		bleveAnalyzers   = flag.String("bleve-field-analyzers", getEnv("BLEVE_FIELD_ANALYZERS", ""), "Comma-separated field:analyzer pairs, e.g. body:en")
+		bleveSnapDir     = flag.String("bleve-snapshot-dir", getEnv("BLEVE_SNAPSHOT_DIR", ""), "Directory receiving periodic Bleve snapshots")
+		bleveSnapIntvl   = flag.Duration("bleve-snapshot-interval", getEnvDuration("BLEVE_SNAPSHOT_INTERVAL", 0), "How often to snapshot the Bleve index (0 disables)")
+		bleveSnapKeep    = flag.Int("bleve-snapshot-keep", getEnvInt("BLEVE_SNAPSHOT_KEEP", 7), "Number of Bleve snapshots to keep (0 keeps all)")
+		sinks            = flag.String("sinks", getEnv("SINKS", ""), "Comma-separated sinks to deliver changes to besides the search index: cache, analytics, webhook, history, archive, readmodel")
+		analyticsLog     = flag.String("analytics-log-path", getEnv("ANALYTICS_LOG_PATH", "data/analytics.jsonl"), "File the analytics sink appends to (- for stdout)")
+		sinkAttempts     = flag.Int("sink-max-attempts", getEnvInt("SINK_MAX_ATTEMPTS", 5), "Delivery attempts per change for async sinks")
+		sinkAttemptsBy   = flag.String("sink-attempts", getEnv("SINK_ATTEMPTS", ""), "Comma-separated sink:attempts overrides, e.g. analytics:10")
+		sinkBackoffMin   = flag.Duration("sink-initial-backoff", getEnvDuration("SINK_INITIAL_BACKOFF", 500*time.Millisecond), "Delay before retrying a failed sink delivery")
+		sinkBackoffMax   = flag.Duration("sink-max-backoff", getEnvDuration("SINK_MAX_BACKOFF", 30*time.Second), "Maximum delay between sink delivery retries")
+		sinkBufferSize   = flag.Int("sink-buffer-size", getEnvInt("SINK_BUFFER_SIZE", 1000), "Changes buffered per async sink before dispatching waits for room")
+		sinkCheckpoints  = flag.String("sink-checkpoint-dir", getEnv("SINK_CHECKPOINT_DIR", "data/checkpoints"), "Directory for per-sink checkpoints (empty disables)")
+		sinkStatsIntvl   = flag.Duration("sink-stats-interval", getEnvDuration("SINK_STATS_INTERVAL", time.Minute), "How often to log per-sink delivery stats (0 disables)")
+		redisAddr        = flag.String("redis-addr", getEnv("REDIS_ADDR", ""), "Redis host:port holding the blog cache (required by the cache sink)")
+		redisUsername    = flag.String("redis-username", getEnv("REDIS_USERNAME", ""), "Redis ACL username")
+		redisPassword    = flag.String("redis-password", getSecret("REDIS_PASSWORD", ""), "Redis password")
+		redisDB          = flag.Int("redis-db", getEnvInt("REDIS_DB", 0), "Redis database number")
+		redisKeyPrefix   = flag.String("redis-key-prefix", getEnv("REDIS_KEY_PREFIX", "blog:"), "Prefix of the blog's cache keys")
+		redisTLS         = flag.Bool("redis-tls", getEnvBool("REDIS_TLS", false), "Connect to Redis over TLS")
+		redisCACert      = flag.String("redis-ca-cert", getEnv("REDIS_CA_CERT", ""), "PEM file with the CA that signed the Redis certificate")
+		webhookTimeout   = flag.Duration("webhook-timeout", getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second), "Timeout for a single webhook request")
+		webhookAttempts  = flag.Int("webhook-max-attempts", getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5), "Delivery attempts per webhook endpoint before a delivery is marked failed")
+		webhookMinDelay  = flag.Duration("webhook-initial-backoff", getEnvDuration("WEBHOOK_INITIAL_BACKOFF", time.Second), "Delay before retrying a failed webhook delivery")
+		webhookMaxDelay  = flag.Duration("webhook-max-backoff", getEnvDuration("WEBHOOK_MAX_BACKOFF", time.Minute), "Maximum delay between webhook delivery retries")
+		archiveDir       = flag.String("archive-dir", getEnv("ARCHIVE_DIR", "data/archive"), "Directory the archive sink writes to")
+		archiveFormat    = flag.String("archive-format", getEnv("ARCHIVE_FORMAT", "jsonl"), "Archive file format: jsonl or parquet")
+		archiveCompress  = flag.Bool("archive-compress", getEnvBool("ARCHIVE_COMPRESS", true), "Gzip JSONL archive files")
+		archiveMaxBytes  = flag.Int64("archive-max-bytes", int64(getEnvInt("ARCHIVE_MAX_BYTES", 64<<20)), "Rotate an archive file once it reaches this size (0 disables)")
+		archiveMaxAge    = flag.Duration("archive-max-age", getEnvDuration("ARCHIVE_MAX_AGE", time.Hour), "Rotate an archive file once it has been open this long (0 disables)")
+		readModelPath    = flag.String("readmodel-path", getEnv("READ_MODEL_PATH", "data/readmodel.db"), "SQLite database the readmodel sink maintains")
+		readModelResync  = flag.Bool("readmodel-resync", getEnvBool("READ_MODEL_RESYNC", false), "Rebuild the read model from the posts table before consuming the queue")
+		replayArchive    = flag.String("replay-archive", "", "Rebuild the search index from this archive directory and exit instead of consuming the queue")
+		replayUntil      = flag.String("replay-until", "", "Only replay changes up to this RFC 3339 time")
+		replayReset      = flag.Bool("replay-reset", false, "Delete every indexed post before replaying")
+		embedderKind     = flag.String("embedder", getEnv("EMBEDDER", ""), "Local embedder for semantic search: hashing or wordvectors (empty disables)")
+		embedderDims     = flag.Int("embedder-dimensions", getEnvInt("EMBEDDER_DIMENSIONS", embedding.DefaultHashingDimensions), "Vector size of the hashing embedder")
+		embedderVectors  = flag.String("embedder-vectors", getEnv("EMBEDDER_VECTORS_PATH", ""), "Word vectors file for the wordvectors embedder (GloVe or fastText text format, optionally gzipped)")
+		tlsInsecure      = flag.Bool("tls-insecure-skip-verify", getEnvBool("TLS_INSECURE_SKIP_VERIFY", false), "Skip certificate verification (testing only)")
 	)
 	flag.Parse()
 
@@ -56,19 +207,245 @@ func main() {
 		Username: *rabbitMQUser,
 		Password: *rabbitMQPassword,
 		VHost:    *rabbitMQVHost,
+		TLS: tlsconfig.Config{
+			Enabled:            *rabbitMQTLS,
+			CAFile:             *rabbitMQCACert,
+			CertFile:           *rabbitMQCert,
+			KeyFile:            *rabbitMQKey,
+			ServerName:         *rabbitMQSrvName,
+			InsecureSkipVerify: *tlsInsecure,
+		},
+
+		HandlerTimeout:           *handlerTimeout,
+		ReconnectInitialInterval: *reconnectMin,
+		ReconnectMaxInterval:     *reconnectMax,
+		PauseInterval:            *pauseInterval,
+
+		Exchange:     *exchange,
+		ExchangeType: *exchangeType,
+		BindingKeys:  splitList(*bindingKeys),
+		Queue: messagequeue.QueueOptions{
+			Type:                 *queueType,
+			MessageTTL:           *queueMessageTTL,
+			MaxLength:            *queueMaxLength,
+			DeadLetterExchange:   *deadLetterExch,
+			DeadLetterRoutingKey: *deadLetterKey,
+		},
+		ConsumerTag:   *consumerTag,
+		PrefetchCount: *prefetchCount,
+	}
+	if err := rabbitMQConfig.Validate(); err != nil {
+		log.Fatalf("Invalid RabbitMQ configuration: %v", err)
 	}
 	rabbitMQRepo := messagequeue.NewRabbitMQRepository(rabbitMQConfig)
 
 	// Create Typesense repository
 	typesenseConfig := searchindex.TypesenseConfig{
-		Host:   *typesenseHost,
-		Port:   *typesensePort,
-		APIKey: *typesenseAPIKey,
+		Host:         *typesenseHost,
+		Port:         *typesensePort,
+		APIKey:       *typesenseAPIKey,
+		ReadTimeout:  *readTimeout,
+		WriteTimeout: *writeTimeout,
+
+		Nodes:               splitList(*typesenseNodes),
+		NearestNode:         *nearestNode,
+		HealthcheckInterval: *healthcheckIntvl,
+		NumRetries:          *numRetries,
+		RetryInterval:       *retryInterval,
+		ConnectionTimeout:   *connTimeout,
+
+		TLS: tlsconfig.Config{
+			Enabled:            *typesenseTLS,
+			CAFile:             *typesenseCACert,
+			CertFile:           *typesenseCert,
+			KeyFile:            *typesenseKey,
+			InsecureSkipVerify: *tlsInsecure,
+		},
+
+		BreakerFailureThreshold: uint32(*breakerThreshold),
+		BreakerOpenTimeout:      *breakerTimeout,
+	}
+
+	// Create Elasticsearch/OpenSearch repository settings
+	elasticsearchConfig := searchindex.ElasticsearchConfig{
+		URL:      *esURL,
+		Username: *esUsername,
+		Password: *esPassword,
+		APIKey:   *esAPIKey,
+		TLS: tlsconfig.Config{
+			CAFile:             *esCACert,
+			InsecureSkipVerify: *tlsInsecure,
+		},
+		Refresh:      *esRefresh,
+		ReadTimeout:  *esReadTimeout,
+		WriteTimeout: *esWriteTimeout,
+	}
+
+	// Create Meilisearch repository settings
+	meilisearchConfig := searchindex.MeilisearchConfig{
+		URL:    *msURL,
+		APIKey: *msAPIKey,
+		TLS: tlsconfig.Config{
+			CAFile:             *msCACert,
+			InsecureSkipVerify: *tlsInsecure,
+		},
+		TaskPollInterval: *msPoll,
+		ReadTimeout:      *msReadTimeout,
+		WriteTimeout:     *msWriteTimeout,
+	}
+
+	// Create embedded Bleve index settings
+	bleveConfig := searchindex.BleveConfig{
+		Path:             *blevePath,
+		DefaultAnalyzer:  *bleveAnalyzer,
+		Analyzers:        splitPairs(*bleveAnalyzers),
+		SnapshotDir:      *bleveSnapDir,
+		SnapshotInterval: *bleveSnapIntvl,
+		SnapshotKeep:     *bleveSnapKeep,
+	}
+
+	// Pick the search index backend
+	var bleveRepo *searchindex.BleveRepository
+	var searchIndex domain.SearchIndexRepository
+	switch *searchBackend {
+	case "typesense":
+		searchIndex = searchindex.NewTypesenseRepository(typesenseConfig)
+	case "elasticsearch", "opensearch":
+		searchIndex = searchindex.NewElasticsearchRepository(elasticsearchConfig)
+	case "meilisearch":
+		searchIndex = searchindex.NewMeilisearchRepository(meilisearchConfig)
+	case "bleve":
+		bleveRepo = searchindex.NewBleveRepository(bleveConfig)
+		searchIndex = bleveRepo
+	default:
+		log.Fatalf("Unknown search backend: %s", *searchBackend)
 	}
-	typesenseRepo := searchindex.NewTypesenseRepository(typesenseConfig)
 
 	// Create CDC service
-	cdcService := service.NewCDCService(rabbitMQRepo, typesenseRepo)
+	cdcService := service.NewCDCService(rabbitMQRepo, searchIndex)
+
+	// Embed posts for hybrid search
+	if embedder := newEmbedder(*embedderKind, *embedderDims, *embedderVectors, *searchBackend); embedder != nil {
+		cdcService.SetEmbedder(embedder)
+	}
+
+	// Rebuild the search index from an archive instead of consuming the queue
+	if *replayArchive != "" {
+		runReplay(cdcService, *replayArchive, *replayUntil, *replayReset)
+		return
+	}
+
+	// Fan changes out to the extra sinks, each with its own retry policy
+	sinkRetry := func(name string) service.RetryPolicy {
+		attempts := *sinkAttempts
+		if value, ok := splitPairs(*sinkAttemptsBy)[name]; ok {
+			if n, err := strconv.Atoi(value); err == nil {
+				attempts = n
+			}
+		}
+		return service.RetryPolicy{MaxAttempts: attempts, InitialBackoff: *sinkBackoffMin, MaxBackoff: *sinkBackoffMax}
+	}
+	// The webhook, history and readmodel sinks share one connection to the blog database
+	var db *sql.DB
+	var webhookService *service.WebhookService
+	blogDB := func() *sql.DB {
+		if db == nil {
+			var err error
+			if db, err = openDatabase(); err != nil {
+				log.Fatalf("Failed to connect to database: %v", err)
+			}
+		}
+		return db
+	}
+	defer func() {
+		if db != nil {
+			db.Close()
+		}
+	}()
+	for _, name := range splitList(*sinks) {
+		switch name {
+		case "cache":
+			if *redisAddr == "" {
+				log.Fatalf("The cache sink needs --redis-addr")
+			}
+			redisCache, err := cache.NewRedisCache(cache.RedisConfig{
+				Addr:      *redisAddr,
+				Username:  *redisUsername,
+				Password:  *redisPassword,
+				DB:        *redisDB,
+				KeyPrefix: *redisKeyPrefix,
+				TLS: tlsconfig.Config{
+					Enabled:            *redisTLS,
+					CAFile:             *redisCACert,
+					InsecureSkipVerify: *tlsInsecure,
+				},
+			})
+			if err != nil {
+				log.Fatalf("Invalid Redis configuration: %v", err)
+			}
+			defer redisCache.Close()
+			cdcService.Sinks().Add(service.SinkConfig{Sink: service.NewCacheSink(redisCache), Async: true, BufferSize: *sinkBufferSize, Retry: sinkRetry(name)})
+		case "analytics":
+			analyticsSink, err := sink.NewAnalyticsLogSink(*analyticsLog)
+			if err != nil {
+				log.Fatalf("Failed to create analytics sink: %v", err)
+			}
+			defer analyticsSink.Close()
+			cdcService.Sinks().Add(service.SinkConfig{Sink: analyticsSink, Async: true, BufferSize: *sinkBufferSize, Retry: sinkRetry(name)})
+		case "webhook":
+			// The sink makes one attempt per endpoint and failed deliveries
+			// are retried from the delivery log in the background; the sink
+			// retry policy only covers failures to read endpoints or write
+			// the delivery log
+			webhookService = service.NewWebhookService(
+				newWebhookRepository(blogDB()),
+				&http.Client{Timeout: *webhookTimeout},
+				service.RetryPolicy{MaxAttempts: *webhookAttempts, InitialBackoff: *webhookMinDelay, MaxBackoff: *webhookMaxDelay},
+			)
+			cdcService.Sinks().Add(service.SinkConfig{Sink: webhookService, Async: true, BufferSize: *sinkBufferSize, Retry: sinkRetry(name)})
+		case "history":
+			// Synchronous, so no version is lost if the process stops
+			historySink := service.NewPostHistorySink(newPostVersionRepository(blogDB()))
+			cdcService.Sinks().Add(service.SinkConfig{Sink: historySink, Retry: sinkRetry(name)})
+		case "archive":
+			// Synchronous, so every change is on disk before the message is acked
+			archiveSink, err := sink.NewArchiveSink(sink.ArchiveConfig{
+				Dir:      *archiveDir,
+				Format:   *archiveFormat,
+				Compress: *archiveCompress,
+				MaxBytes: *archiveMaxBytes,
+				MaxAge:   *archiveMaxAge,
+			})
+			if err != nil {
+				log.Fatalf("Failed to create archive sink: %v", err)
+			}
+			defer archiveSink.Close()
+			cdcService.Sinks().Add(service.SinkConfig{Sink: archiveSink, Retry: sinkRetry(name)})
+		case "readmodel":
+			// Synchronous, so the read model never falls behind the queue
+			model, err := readmodel.Open(*readModelPath)
+			if err != nil {
+				log.Fatalf("Failed to open read model: %v", err)
+			}
+			defer model.Close()
+			if *readModelResync {
+				resyncReadModel(model, blogDB())
+			}
+			if status, err := model.Status(context.Background()); err == nil {
+				log.Printf("Read model at %s holds %d posts, position %d", *readModelPath, status.Posts, status.Position)
+			}
+			cdcService.Sinks().Add(service.SinkConfig{Sink: model, Retry: sinkRetry(name)})
+		default:
+			log.Fatalf("Unknown sink: %s", name)
+		}
+	}
+	if *sinkCheckpoints != "" {
+		checkpoints, err := sink.NewFileCheckpointStore(*sinkCheckpoints)
+		if err != nil {
+			log.Fatalf("Failed to create checkpoint store: %v", err)
+		}
+		cdcService.Sinks().SetCheckpointStore(checkpoints)
+	}
 
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -84,7 +461,33 @@ func main() {
 		cancel()
 	}()
 
-	// Start the CDC service
+	// Snapshot the embedded index in the background
+	if bleveRepo != nil {
+		go bleveRepo.RunSnapshots(ctx)
+	}
+
+	// Retry failed webhook deliveries in the background
+	if webhookService != nil {
+		go webhookService.RunRetries(ctx)
+	}
+
+	// Log per-sink delivery stats in the background
+	if *sinkStatsIntvl > 0 {
+		go func() {
+			ticker := time.NewTicker(*sinkStatsIntvl)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					cdcService.Sinks().LogStats()
+				}
+			}
+		}()
+	}
+
+	// Start the CDC service; it returns once the consumer has drained after a signal
 	log.Printf("Starting CDC service with queue: %s", *queueName)
 	if err := cdcService.StartCDC(ctx, *queueName); err != nil {
 		log.Fatalf("CDC service failed: %v", err)
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/readmodel"
+)
+
+// resyncReadModel replaces the read model with a snapshot of the posts table.
+// The position is taken before the snapshot is read, so changes committed
+// while it is read are applied again from the queue rather than skipped.
+func resyncReadModel(model *readmodel.SQLiteReadModel, db *sql.DB) {
+	ctx := context.Background()
+	position := domain.SourceTS(time.Now())
+
+	posts, err := newPostRepository(db).GetAll(ctx)
+	if err != nil {
+		log.Fatalf("Failed to read posts for the read model resync: %v", err)
+	}
+	if err := model.Resync(ctx, posts, position); err != nil {
+		log.Fatalf("Failed to resync read model: %v", err)
+	}
+	log.Printf("Read model resynced with %d posts", len(posts))
+}
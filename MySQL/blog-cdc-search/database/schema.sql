@@ -9,10 +9,108 @@ CREATE TABLE IF NOT EXISTS posts (
     image TEXT,
     excerpt TEXT,
     body TEXT NOT NULL,
+    author VARCHAR(255) NOT NULL DEFAULT '',
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    language VARCHAR(16) NOT NULL DEFAULT 'en',
+    tags VARCHAR(1000) NOT NULL DEFAULT '',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
 
+-- Webhook endpoints notified about post changes
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    events VARCHAR(255) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Log of webhook deliveries, one row per event and endpoint
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    endpoint_id INT NOT NULL,
+    event VARCHAR(64) NOT NULL,
+    post_id INT NOT NULL,
+    change_key VARCHAR(64) NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    response_status INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL,
+    next_attempt_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_webhook_deliveries_created_at (created_at),
+    INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+    -- One delivery per endpoint and change, however often the change is applied
+    UNIQUE KEY uq_webhook_deliveries_change (endpoint_id, event, post_id, change_key),
+    FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints(id) ON DELETE CASCADE
+);
+
+-- Every version of every post, recorded from CDC events
+CREATE TABLE IF NOT EXISTS post_versions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    post_id INT NOT NULL,
+    version INT NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    image TEXT NOT NULL,
+    excerpt TEXT NOT NULL,
+    body TEXT NOT NULL,
+    author VARCHAR(255) NOT NULL DEFAULT '',
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    language VARCHAR(16) NOT NULL DEFAULT '',
+    tags VARCHAR(1000) NOT NULL DEFAULT '',
+    changed_fields VARCHAR(255) NOT NULL DEFAULT '',
+    changed_at TIMESTAMP NOT NULL,
+    UNIQUE KEY uniq_post_versions_post_version (post_id, version)
+);
+
+-- Search synonyms, synced to the search index. Root is empty for multi-way
+-- synonyms; synonyms is comma-separated.
+CREATE TABLE IF NOT EXISTS search_synonyms (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    root VARCHAR(255) NOT NULL DEFAULT '',
+    synonyms TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Search curations pinning and hiding posts for a query, synced to the
+-- search index. Pinned and hidden are comma-separated post IDs.
+CREATE TABLE IF NOT EXISTS search_curations (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    query VARCHAR(255) NOT NULL,
+    match_type VARCHAR(16) NOT NULL DEFAULT 'exact',
+    pinned TEXT NOT NULL,
+    hidden TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Searches run by readers, for search analytics. Query is lowercased with
+-- its whitespace collapsed; session_id is a salted hash that changes daily.
+CREATE TABLE IF NOT EXISTS search_queries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    query VARCHAR(255) NOT NULL,
+    results INT NOT NULL,
+    latency_ms INT NOT NULL,
+    page INT NOT NULL DEFAULT 1,
+    session_id VARCHAR(32) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_search_queries_created_at (created_at)
+);
+
+-- Clicks on search results; position counts from 1 across pages
+CREATE TABLE IF NOT EXISTS search_clicks (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    search_id BIGINT NOT NULL,
+    post_id INT NOT NULL,
+    position INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_search_clicks_search_id (search_id)
+);
+
 -- Insert some sample data
 INSERT INTO posts (title, image, excerpt, body) VALUES
 ('Welcome to My Blog', 'https://picsum.photos/536/354?random=1', 'This is my first blog post where I share my thoughts and ideas.', 'Welcome to my new blog! I am excited to start sharing my thoughts, experiences, and ideas with you. This blog will cover various topics including technology, life, and everything in between. Stay tuned for more content!'),
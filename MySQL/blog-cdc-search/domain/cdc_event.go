@@ -58,6 +58,21 @@ func (e *CDCEvent) GetID() (int, bool) {
 	return 0, false
 }
 
+// Time returns when the change happened in the source database; Maxwell
+// reports ts in seconds. It is zero when the event has no timestamp.
+func (e *CDCEvent) Time() time.Time {
+	if e.TS == 0 {
+		return time.Time{}
+	}
+	return time.Unix(e.TS, 0).UTC()
+}
+
+// SourceTS converts a time to the unit of the event timestamps (seconds),
+// the inverse of Time
+func SourceTS(t time.Time) int64 {
+	return t.Unix()
+}
+
 // ToJSON converts the event to JSON
 func (e *CDCEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Post version operations
+const (
+	PostVersionCreated  = "created"
+	PostVersionUpdated  = "updated"
+	PostVersionDeleted  = "deleted"
+	PostVersionSnapshot = "snapshot" // state seen in a snapshot or before the first recorded change
+)
+
+// ErrPostVersionNotFound is returned for unknown post versions
+var ErrPostVersionNotFound = errors.New("post version not found")
+
+// PostVersion is the content of a post after one change
+type PostVersion struct {
+	ID            int64     `json:"id"`
+	PostID        int       `json:"post_id"`
+	Version       int       `json:"version"`
+	Operation     string    `json:"operation"`
+	Title         string    `json:"title"`
+	Image         string    `json:"image"`
+	Excerpt       string    `json:"excerpt"`
+	Body          string    `json:"body"`
+	Author        string    `json:"author"`
+	Category      string    `json:"category"`
+	Language      string    `json:"language"`
+	Tags          []string  `json:"tags"`
+	ChangedFields []string  `json:"changed_fields"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// PostVersionFields lists the versioned post fields in display order
+var PostVersionFields = []string{"title", "image", "excerpt", "body", "author", "category", "language", "tags"}
+
+// Field returns the value of a versioned field; tags are comma-separated
+func (v *PostVersion) Field(name string) string {
+	switch name {
+	case "title":
+		return v.Title
+	case "image":
+		return v.Image
+	case "excerpt":
+		return v.Excerpt
+	case "body":
+		return v.Body
+	case "author":
+		return v.Author
+	case "category":
+		return v.Category
+	case "language":
+		return v.Language
+	case "tags":
+		return JoinTags(v.Tags)
+	default:
+		return ""
+	}
+}
+
+// FieldChange is one field that differs between two versions
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// DiffPostVersions lists the fields that differ from previous to current; a
+// nil previous compares against an empty post
+func DiffPostVersions(previous, current *PostVersion) []FieldChange {
+	if previous == nil {
+		previous = &PostVersion{}
+	}
+
+	changes := []FieldChange{}
+	for _, field := range PostVersionFields {
+		if old, value := previous.Field(field), current.Field(field); old != value {
+			changes = append(changes, FieldChange{Field: field, Old: old, New: value})
+		}
+	}
+	return changes
+}
+
+// PostVersionDiff is a version along with the one before it
+type PostVersionDiff struct {
+	Version  *PostVersion  `json:"version"`
+	Previous *PostVersion  `json:"previous,omitempty"`
+	Changes  []FieldChange `json:"changes"`
+}
+
+// PostVersionRepository defines the interface for post history storage
+type PostVersionRepository interface {
+	CreateVersion(ctx context.Context, version *PostVersion) error
+	LatestVersion(ctx context.Context, postID int) (*PostVersion, error) // nil if the post has no history
+	GetVersion(ctx context.Context, postID, version int) (*PostVersion, error)
+	ListVersions(ctx context.Context, postID int) ([]*PostVersion, error) // newest first
+}
@@ -1,30 +1,91 @@
 package domain
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 )
 
 type SearchDocument struct {
-	ID        string `json:"id"`
-	Title     string `json:"title"`
-	Image     string `json:"image"`
-	Excerpt   string `json:"excerpt"`
-	Body      string `json:"body"`
-	CreatedAt int64  `json:"created_at"`
-	UpdatedAt int64  `json:"updated_at"`
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Image     string   `json:"image"`
+	Excerpt   string   `json:"excerpt"`
+	Body      string   `json:"body"`
+	Author    string   `json:"author"`
+	Category  string   `json:"category"`
+	Language  string   `json:"language"`
+	Tags      []string `json:"tags"`
+	HasImage  bool     `json:"has_image"`
+	Year      string   `json:"year"`
+	Month     string   `json:"month"`
+	CreatedAt int64    `json:"created_at"`
+	UpdatedAt int64    `json:"updated_at"`
+
+	// Embedding is the semantic vector of the post, set when embeddings are enabled
+	Embedding []float32 `json:"embedding,omitempty"`
+
+	// Localized holds the text fields again under the names of the
+	// language's own fields, such as title_fa, for languages in SearchLocales
+	Localized map[string]string `json:"-"`
+}
+
+// MarshalJSON encodes the document with its localized fields inlined
+func (d SearchDocument) MarshalJSON() ([]byte, error) {
+	type document SearchDocument
+	data, err := json.Marshal(document(d))
+	if err != nil || len(d.Localized) == 0 {
+		return data, err
+	}
+
+	localized, err := json.Marshal(d.Localized)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(data[:len(data)-1], ','), localized[1:]...), nil
+}
+
+// setLocalized copies the text fields to the fields of the document's
+// language, if it has its own
+func (d *SearchDocument) setLocalized() *SearchDocument {
+	d.Localized = nil
+	values := map[string]string{"title": d.Title, "excerpt": d.Excerpt, "body": d.Body}
+	for _, field := range LocalizedFields {
+		name := LocalizedField(field, d.Language)
+		if name == "" {
+			return d
+		}
+		if d.Localized == nil {
+			d.Localized = map[string]string{}
+		}
+		d.Localized[name] = values[field]
+	}
+	return d
+}
+
+// setPeriod fills in the year and month facets from the creation time, in UTC
+func (d *SearchDocument) setPeriod() *SearchDocument {
+	created := time.Unix(d.CreatedAt, 0).UTC()
+	d.Year = created.Format("2006")
+	d.Month = created.Format("2006-01")
+	return d
 }
 
 func NewSearchDocument(post *Post) *SearchDocument {
-	return &SearchDocument{
+	return (&SearchDocument{
 		ID:        fmt.Sprintf("%d", post.ID),
 		Title:     post.Title,
 		Image:     post.Image,
 		Excerpt:   post.Excerpt,
 		Body:      post.Body,
+		Author:    post.Author,
+		Category:  post.Category,
+		Language:  post.Language,
+		Tags:      NormalizeTags(post.Tags),
+		HasImage:  post.Image != "",
 		CreatedAt: post.CreatedAt.Unix(),
 		UpdatedAt: post.UpdatedAt.Unix(),
-	}
+	}).setPeriod().setLocalized()
 }
 
 func NewSearchDocumentFromMap(data map[string]interface{}) (*SearchDocument, error) {
@@ -49,6 +110,25 @@ func NewSearchDocumentFromMap(data map[string]interface{}) (*SearchDocument, err
 	image, _ := data["image"].(string)
 	excerpt, _ := data["excerpt"].(string)
 	body, _ := data["body"].(string)
+	author, _ := data["author"].(string)
+	category, _ := data["category"].(string)
+	language, _ := data["language"].(string)
+
+	// Tags arrive as the comma-separated column or, from the index, as a list
+	tags := []string{}
+	switch v := data["tags"].(type) {
+	case string:
+		tags = SplitTags(v)
+	case []string:
+		tags = NormalizeTags(v)
+	case []interface{}:
+		for _, tag := range v {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+		tags = NormalizeTags(tags)
+	}
 
 	var createdAt, updatedAt int64
 
@@ -89,13 +169,71 @@ func NewSearchDocumentFromMap(data map[string]interface{}) (*SearchDocument, err
 		updatedAt = time.Now().Unix()
 	}
 
-	return &SearchDocument{
+	return (&SearchDocument{
 		ID:        fmt.Sprintf("%d", postID),
 		Title:     title,
 		Image:     image,
 		Excerpt:   excerpt,
 		Body:      body,
+		Author:    author,
+		Category:  category,
+		Language:  language,
+		Tags:      tags,
+		HasImage:  image != "",
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
-	}, nil
+	}).setPeriod().setLocalized(), nil
+}
+
+// SearchFilter is a condition every search hit must meet. Value is a string,
+// an int64 or a bool. A search passes its filters as a []SearchFilter under
+// the "filters" search parameter and each backend compiles them into its own
+// syntax, so values never need escaping for another backend.
+type SearchFilter struct {
+	Field string
+	Op    FilterOp
+	Value interface{}
+}
+
+// FilterOp compares a document field with a filter value
+type FilterOp string
+
+// Supported filter comparisons
+const (
+	FilterEqual   FilterOp = "="
+	FilterAtLeast FilterOp = ">="
+	FilterBelow   FilterOp = "<"
+)
+
+// SearchHit is a single search result. Score and highlights are kept apart
+// from the stored document so backends can report them in a common shape.
+type SearchHit struct {
+	Document   map[string]interface{} `json:"document"`
+	Score      float64                `json:"score"`
+	Highlights map[string][]string    `json:"highlights,omitempty"`
+}
+
+// FacetValueCount is the number of matching documents with a facet value
+type FacetValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// FacetCounts holds the value counts of one faceted field
+type FacetCounts struct {
+	Field  string            `json:"field"`
+	Counts []FacetValueCount `json:"counts"`
+}
+
+// SearchResults is the envelope returned by a search. Found is the number of
+// documents matching the query across all pages and OutOf the number of
+// documents in the collection.
+type SearchResults struct {
+	Hits         []*SearchHit  `json:"hits"`
+	Found        int           `json:"found"`
+	OutOf        int           `json:"out_of"`
+	Page         int           `json:"page"`
+	PerPage      int           `json:"per_page"`
+	SearchTimeMS int           `json:"search_time_ms"`
+	Facets       []FacetCounts `json:"facets,omitempty"`
 }
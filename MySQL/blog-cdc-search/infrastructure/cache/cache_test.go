@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(2)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	c.Get(ctx, "a") // a is now the most recently used
+	c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, found, _ := c.Get(ctx, "b"); found {
+		t.Error("Expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, found, _ := c.Get(ctx, key); !found {
+			t.Errorf("Expected %s to stay cached", key)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestLRUCache_ExpiryAndDelete(t *testing.T) {
+	c := NewLRUCache(10)
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	c.Set(ctx, "short", []byte("1"), time.Second)
+	c.Set(ctx, "forever", []byte("2"), 0)
+	c.Set(ctx, "deleted", []byte("3"), 0)
+	c.Delete(ctx, "deleted", "unknown")
+
+	now = now.Add(2 * time.Second)
+	if _, found, _ := c.Get(ctx, "short"); found {
+		t.Error("Expected short to expire")
+	}
+	if value, found, _ := c.Get(ctx, "forever"); !found || string(value) != "2" {
+		t.Errorf("Expected forever to stay cached, got %q %v", value, found)
+	}
+	if _, found, _ := c.Get(ctx, "deleted"); found {
+		t.Error("Expected deleted to be gone")
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	c, err := NewRedisCache(RedisConfig{Addr: server.Addr(), KeyPrefix: "blog:"})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if _, found, err := c.Get(ctx, "post:1"); found || err != nil {
+		t.Fatalf("Expected a miss, got %v %v", found, err)
+	}
+
+	if err := c.Set(ctx, "post:1", []byte("one"), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := c.Set(ctx, "posts:all", []byte("all"), 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if !server.Exists("blog:post:1") {
+		t.Error("Expected the key to be prefixed")
+	}
+	if ttl := server.TTL("blog:post:1"); ttl != time.Minute {
+		t.Errorf("Expected TTL 1m, got %v", ttl)
+	}
+	if value, found, err := c.Get(ctx, "post:1"); !found || err != nil || string(value) != "one" {
+		t.Errorf("Expected 'one', got %q %v %v", value, found, err)
+	}
+
+	if err := c.Delete(ctx, "post:1", "posts:all"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if len(server.Keys()) != 0 {
+		t.Errorf("Expected no keys left, got %v", server.Keys())
+	}
+
+	server.Close()
+	if _, _, err := c.Get(ctx, "post:1"); err == nil {
+		t.Error("Expected an error once Redis is gone")
+	}
+}
+
+// failingCache fails every operation
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("unavailable")
+}
+
+func (failingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("unavailable")
+}
+
+func (failingCache) Delete(ctx context.Context, keys ...string) error {
+	return errors.New("unavailable")
+}
+
+func TestFallbackCache(t *testing.T) {
+	ctx := context.Background()
+
+	// A healthy primary is used and the fallback stays empty
+	primary := NewLRUCache(10)
+	local := NewLRUCache(10)
+	c := NewFallbackCache(primary, local, time.Second)
+	c.Set(ctx, "post:1", []byte("one"), time.Hour)
+	if local.Len() != 0 || primary.Len() != 1 {
+		t.Errorf("Expected the entry in the primary only, got primary=%d local=%d", primary.Len(), local.Len())
+	}
+
+	// A failing primary is bypassed, with the fallback TTL capping the entry
+	local = NewLRUCache(10)
+	now := time.Unix(1000, 0)
+	local.now = func() time.Time { return now }
+	c = NewFallbackCache(failingCache{}, local, time.Second)
+	if err := c.Set(ctx, "post:1", []byte("one"), time.Hour); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, found, err := c.Get(ctx, "post:1"); !found || err != nil || string(value) != "one" {
+		t.Errorf("Expected 'one' from the fallback, got %q %v %v", value, found, err)
+	}
+	now = now.Add(2 * time.Second)
+	if _, found, _ := c.Get(ctx, "post:1"); found {
+		t.Error("Expected the fallback entry to expire after the fallback TTL")
+	}
+
+	// Invalidation failures on the primary are reported so the sink retries
+	if err := c.Delete(ctx, "post:1"); err == nil {
+		t.Error("Expected delete to report the primary failure")
+	}
+}
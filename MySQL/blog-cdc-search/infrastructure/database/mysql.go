@@ -4,31 +4,63 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
+	"net"
 
-	_ "github.com/go-sql-driver/mysql"
+	"blog-cdc-search/infrastructure/tlsconfig"
+
+	"github.com/go-sql-driver/mysql"
 )
 
-// NewMySQLConnection creates a new MySQL database connection
-func NewMySQLConnection() (*sql.DB, error) {
-	// Get database connection details from environment variables
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "3306")
-	user := getEnv("DB_USER", "root")
-	password := getEnv("DB_PASSWORD", "password")
-	dbName := getEnv("DB_NAME", "blog")
+// MySQLConfig holds configuration for the MySQL connection
+type MySQLConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	DBName   string
+
+	// TLS encrypts the connection; certificates are only needed for private
+	// CAs or mutual TLS
+	TLS tlsconfig.Config
+}
+
+// driverConfig builds the go-sql-driver configuration
+func (c MySQLConfig) driverConfig() (*mysql.Config, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = c.User
+	cfg.Passwd = c.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(c.Host, c.Port)
+	cfg.DBName = c.DBName
+	cfg.ParseTime = true
+	cfg.Params = map[string]string{"charset": "utf8mb4"}
+
+	tlsConfig, err := c.TLS.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load MySQL TLS config: %w", err)
+	}
+	cfg.TLS = tlsConfig
 
-	// Create connection string
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4", user, password, host, port, dbName)
+	return cfg, nil
+}
+
+// NewMySQLConnection creates a new MySQL database connection
+func NewMySQLConnection(config MySQLConfig) (*sql.DB, error) {
+	cfg, err := config.driverConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	// Open database connection
-	db, err := sql.Open("mysql", dsn)
+	connector, err := mysql.NewConnector(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db := sql.OpenDB(connector)
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
@@ -39,11 +71,3 @@ func NewMySQLConnection() (*sql.DB, error) {
 	log.Println("Successfully connected to MySQL database")
 	return db, nil
 }
-
-// getEnv gets an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"testing"
+
+	"blog-cdc-search/infrastructure/tlsconfig"
+)
+
+func TestMySQLConfig_DriverConfig(t *testing.T) {
+	config := MySQLConfig{
+		Host:     "db",
+		Port:     "3306",
+		User:     "bloguser",
+		Password: "p@ss:word/",
+		DBName:   "blog",
+	}
+
+	cfg, err := config.driverConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Addr != "db:3306" {
+		t.Errorf("Expected addr db:3306, got %s", cfg.Addr)
+	}
+
+	if cfg.Passwd != config.Password {
+		t.Errorf("Expected password to be passed through unchanged, got %s", cfg.Passwd)
+	}
+
+	if !cfg.ParseTime {
+		t.Error("Expected parseTime to be enabled")
+	}
+
+	if cfg.TLS != nil {
+		t.Error("Expected TLS to be disabled by default")
+	}
+}
+
+func TestMySQLConfig_DriverConfigTLS(t *testing.T) {
+	config := MySQLConfig{
+		Host: "db",
+		Port: "3306",
+		TLS:  tlsconfig.Config{Enabled: true, ServerName: "mysql.internal"},
+	}
+
+	cfg, err := config.driverConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.TLS == nil || cfg.TLS.ServerName != "mysql.internal" {
+		t.Errorf("Expected TLS config with server name, got %+v", cfg.TLS)
+	}
+
+	config.TLS.CAFile = "/does/not/exist.pem"
+	if _, err := config.driverConfig(); err == nil {
+		t.Error("Expected error for missing CA file")
+	}
+}
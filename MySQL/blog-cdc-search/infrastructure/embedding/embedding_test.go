@@ -0,0 +1,124 @@
+package embedding
+
+import (
+	"compress/gzip"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func embed(t *testing.T, embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}, text string) []float32 {
+	t.Helper()
+	vector, err := embedder.Embed(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return vector
+}
+
+func TestHashingEmbedder(t *testing.T) {
+	embedder := NewHashingEmbedder(0)
+	if embedder.Dimensions() != DefaultHashingDimensions {
+		t.Fatalf("Expected %d dimensions, got %d", DefaultHashingDimensions, embedder.Dimensions())
+	}
+
+	vector := embed(t, embedder, "Concurrency in Go")
+	if len(vector) != DefaultHashingDimensions {
+		t.Fatalf("Expected %d values, got %d", DefaultHashingDimensions, len(vector))
+	}
+	if norm := math.Sqrt(cosine(vector, vector)); math.Abs(norm-1) > 1e-5 {
+		t.Errorf("Expected a unit vector, got norm %f", norm)
+	}
+	if !reflect.DeepEqual(vector, embed(t, embedder, "concurrency  in GO!")) {
+		t.Error("Expected case and punctuation to be ignored")
+	}
+
+	// Shared stems bring texts closer than unrelated words
+	related := cosine(vector, embed(t, embedder, "concurrent programs"))
+	unrelated := cosine(vector, embed(t, embedder, "baking bread"))
+	if related <= unrelated {
+		t.Errorf("Expected related text to be closer: %f <= %f", related, unrelated)
+	}
+
+	if vector := embed(t, embedder, " ... "); vector != nil {
+		t.Errorf("Expected nil for text without words, got %v", vector)
+	}
+}
+
+const testWordVectors = `4 3
+concurrency 1 0.1 0
+goroutines 0.9 0.2 0
+bread 0 0 1
+Concurrency 0 1 0
+`
+
+func TestReadWordVectors(t *testing.T) {
+	embedder, err := ReadWordVectors(strings.NewReader(testWordVectors))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if embedder.Dimensions() != 3 || len(embedder.vectors) != 3 {
+		t.Fatalf("Expected 3 words of 3 dimensions, got %d of %d", len(embedder.vectors), embedder.Dimensions())
+	}
+
+	query := embed(t, embedder, "posts about concurrency")
+	related := cosine(query, embed(t, embedder, "Goroutines explained"))
+	unrelated := cosine(query, embed(t, embedder, "Bread recipes"))
+	if related < 0.9 || unrelated > 0.1 {
+		t.Errorf("Expected goroutines close and bread far, got %f and %f", related, unrelated)
+	}
+
+	if vector := embed(t, embedder, "unknown words only"); vector != nil {
+		t.Errorf("Expected nil without known words, got %v", vector)
+	}
+}
+
+func TestReadWordVectors_Invalid(t *testing.T) {
+	for name, input := range map[string]string{
+		"ragged": "go 1 2\nsql 1\n",
+		"bad":    "go 1 x\n",
+		"empty":  "\n",
+	} {
+		if _, err := ReadWordVectors(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadWordVectors_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.txt.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	gz.Write([]byte(testWordVectors))
+	gz.Close()
+	file.Close()
+
+	embedder, err := LoadWordVectors(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if embedder.Dimensions() != 3 {
+		t.Errorf("Expected 3 dimensions, got %d", embedder.Dimensions())
+	}
+
+	if _, err := LoadWordVectors(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
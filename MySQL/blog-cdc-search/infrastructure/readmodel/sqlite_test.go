@@ -0,0 +1,205 @@
+package readmodel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+func openTestModel(t *testing.T) *SQLiteReadModel {
+	t.Helper()
+	model, err := Open(filepath.Join(t.TempDir(), "readmodel.db"))
+	if err != nil {
+		t.Fatalf("Failed to open read model: %v", err)
+	}
+	t.Cleanup(func() { model.Close() })
+	return model
+}
+
+func upsert(id, title, body string, ts int64) *domain.Change {
+	return &domain.Change{
+		Op:       domain.ChangeOpUpsert,
+		ID:       id,
+		Document: &domain.SearchDocument{ID: id, Title: title, Body: body, CreatedAt: ts, UpdatedAt: ts},
+		Event:    &domain.CDCEvent{Table: "posts", TS: ts},
+	}
+}
+
+func remove(id string, ts int64) *domain.Change {
+	return &domain.Change{Op: domain.ChangeOpDelete, ID: id, Event: &domain.CDCEvent{Table: "posts", TS: ts}}
+}
+
+func apply(t *testing.T, model *SQLiteReadModel, changes ...*domain.Change) {
+	t.Helper()
+	for _, change := range changes {
+		if err := model.Apply(context.Background(), change); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+	}
+}
+
+func TestSQLiteReadModel_Apply(t *testing.T) {
+	model := openTestModel(t)
+	repo := NewPostRepository(model)
+	ctx := context.Background()
+
+	apply(t, model,
+		upsert("1", "First", strings.Repeat("word ", 450), 10),
+		upsert("2", "Second", "Body", 20),
+		upsert("1", "First, edited", "Short body", 30),
+		upsert("1", "Stale", "Redelivered", 15), // older than the edit
+		remove("2", 40),
+		upsert("2", "Second", "Body", 20), // older than the delete
+	)
+
+	post, err := repo.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if post.Title != "First, edited" {
+		t.Errorf("Expected the edit to win over the older change, got %q", post.Title)
+	}
+	if _, err := repo.GetByID(ctx, 2); !errors.Is(err, domain.ErrPostNotFound) {
+		t.Errorf("Expected the deleted post to stay deleted, got %v", err)
+	}
+
+	var words, minutes int
+	model.db.QueryRow(`SELECT word_count, reading_minutes FROM posts WHERE id = 1`).Scan(&words, &minutes)
+	if words != 2 || minutes != 1 {
+		t.Errorf("Expected 2 words and 1 minute, got %d and %d", words, minutes)
+	}
+
+	status, err := model.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Position != 40 || status.Applied != 4 || status.Posts != 1 {
+		t.Errorf("Expected position 40 with 4 applied changes and 1 post, got %+v", status)
+	}
+}
+
+func TestSQLiteReadModel_Resync(t *testing.T) {
+	model := openTestModel(t)
+	repo := NewPostRepository(model)
+	ctx := context.Background()
+
+	apply(t, model, upsert("1", "Gone", "Body", 10), upsert("9", "Also gone", "Body", 20))
+
+	created := time.Unix(1700000000, 0)
+	posts := []*domain.Post{
+		{ID: 1, Title: "Older", Body: "One two three", CreatedAt: created, UpdatedAt: created},
+		{ID: 2, Title: "Newer", Body: "Body", CreatedAt: created.Add(time.Hour), UpdatedAt: created.Add(time.Hour)},
+	}
+	if err := model.Resync(ctx, posts, 100); err != nil {
+		t.Fatalf("Resync failed: %v", err)
+	}
+
+	// Changes from before the snapshot are already part of it
+	edit := upsert("2", "Newer, edited", "Body", 120)
+	edit.Document.CreatedAt = posts[1].CreatedAt.Unix()
+	apply(t, model, upsert("3", "Deleted before the snapshot", "Body", 50), edit)
+
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(all) != 2 || all[0].Title != "Newer, edited" || all[1].Title != "Older" {
+		t.Fatalf("Expected the snapshot plus the later edit, newest first, got %+v", all)
+	}
+	if !all[1].CreatedAt.Equal(created) {
+		t.Errorf("Expected created_at %v, got %v", created, all[1].CreatedAt)
+	}
+
+	status, _ := model.Status(ctx)
+	if status.SnapshotPosition != 100 || status.Position != 120 || status.ResyncedAt == nil {
+		t.Errorf("Expected the resync to be recorded, got %+v", status)
+	}
+}
+
+func TestSQLiteReadModel_PostFields(t *testing.T) {
+	model := openTestModel(t)
+	repo := NewPostRepository(model)
+	ctx := context.Background()
+
+	change := upsert("1", "Tagged", "Body", 10)
+	change.Document.Author = "Ada"
+	change.Document.Category = "Engineering"
+	change.Document.Language = "fa"
+	change.Document.Tags = []string{"go", "cdc"}
+	apply(t, model, change)
+
+	post, err := repo.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if post.Author != "Ada" || post.Category != "Engineering" || post.Language != "fa" || strings.Join(post.Tags, ",") != "go,cdc" {
+		t.Errorf("Expected author, category, language and tags from the change, got %+v", post)
+	}
+
+	posts := []*domain.Post{{ID: 2, Title: "Snapshot", Body: "Body", Author: "Grace", Category: "News", Language: "de", Tags: []string{"release"}}}
+	if err := model.Resync(ctx, posts, 100); err != nil {
+		t.Fatalf("Resync failed: %v", err)
+	}
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(all) != 1 || all[0].Author != "Grace" || all[0].Category != "News" || all[0].Language != "de" || strings.Join(all[0].Tags, ",") != "release" {
+		t.Errorf("Expected author, category, language and tags from the snapshot, got %+v", all)
+	}
+}
+
+func TestOpen_UpgradesOlderReadModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "readmodel.db")
+	old, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("Failed to create old read model: %v", err)
+	}
+	_, err = old.Exec(`
+		CREATE TABLE posts (
+			id INTEGER PRIMARY KEY, title TEXT NOT NULL, image TEXT NOT NULL, excerpt TEXT NOT NULL, body TEXT NOT NULL,
+			created_at INTEGER NOT NULL, updated_at INTEGER NOT NULL, word_count INTEGER NOT NULL,
+			reading_minutes INTEGER NOT NULL, deleted INTEGER NOT NULL DEFAULT 0, source_ts INTEGER NOT NULL
+		);
+		INSERT INTO posts VALUES (1, 'Old', '', '', 'Body', 10, 10, 1, 1, 0, 10);
+	`)
+	old.Close()
+	if err != nil {
+		t.Fatalf("Failed to create old read model: %v", err)
+	}
+
+	model, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open old read model: %v", err)
+	}
+	defer model.Close()
+
+	post, err := NewPostRepository(model).GetByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if post.Title != "Old" || post.Author != "" || post.Language != domain.DefaultLanguage || len(post.Tags) != 0 {
+		t.Errorf("Expected the old post with empty new fields, got %+v", post)
+	}
+}
+
+func TestPostRepository_ReadOnly(t *testing.T) {
+	repo := NewPostRepository(openTestModel(t))
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, &domain.Post{Title: "New", Body: "Body"}); !errors.Is(err, domain.ErrReadOnly) {
+		t.Errorf("Expected Create to fail with ErrReadOnly, got %v", err)
+	}
+	if err := repo.Update(ctx, &domain.Post{ID: 1}); !errors.Is(err, domain.ErrReadOnly) {
+		t.Errorf("Expected Update to fail with ErrReadOnly, got %v", err)
+	}
+	if err := repo.Delete(ctx, 1); !errors.Is(err, domain.ErrReadOnly) {
+		t.Errorf("Expected Delete to fail with ErrReadOnly, got %v", err)
+	}
+}
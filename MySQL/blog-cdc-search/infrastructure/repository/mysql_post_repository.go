@@ -21,11 +21,11 @@ func NewMySQLPostRepository(db *sql.DB) *MySQLPostRepository {
 // Create inserts a new post into the database
 func (r *MySQLPostRepository) Create(ctx context.Context, post *domain.Post) error {
 	query := `
-		INSERT INTO posts (title, image, excerpt, body, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO posts (title, image, excerpt, body, author, category, language, tags, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.ExecContext(ctx, query, post.Title, post.Image, post.Excerpt, post.Body, post.CreatedAt, post.UpdatedAt)
+	result, err := r.db.ExecContext(ctx, query, post.Title, post.Image, post.Excerpt, post.Body, post.Author, post.Category, post.Language, domain.JoinTags(post.Tags), post.CreatedAt, post.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create post: %w", err)
 	}
@@ -42,13 +42,14 @@ func (r *MySQLPostRepository) Create(ctx context.Context, post *domain.Post) err
 // GetByID retrieves a post by its ID
 func (r *MySQLPostRepository) GetByID(ctx context.Context, id int) (*domain.Post, error) {
 	query := `
-		SELECT id, title, image, excerpt, body, created_at, updated_at
+		SELECT id, title, image, excerpt, body, author, category, language, tags, created_at, updated_at
 		FROM posts WHERE id = ?
 	`
 
 	var post domain.Post
+	var tags string
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&post.ID, &post.Title, &post.Image, &post.Excerpt, &post.Body, &post.CreatedAt, &post.UpdatedAt,
+		&post.ID, &post.Title, &post.Image, &post.Excerpt, &post.Body, &post.Author, &post.Category, &post.Language, &tags, &post.CreatedAt, &post.UpdatedAt,
 	)
 
 	if err != nil {
@@ -58,13 +59,14 @@ func (r *MySQLPostRepository) GetByID(ctx context.Context, id int) (*domain.Post
 		return nil, fmt.Errorf("failed to get post: %w", err)
 	}
 
+	post.Tags = domain.SplitTags(tags)
 	return &post, nil
 }
 
 // GetAll retrieves all posts from the database
 func (r *MySQLPostRepository) GetAll(ctx context.Context) ([]*domain.Post, error) {
 	query := `
-		SELECT id, title, image, excerpt, body, created_at, updated_at
+		SELECT id, title, image, excerpt, body, author, category, language, tags, created_at, updated_at
 		FROM posts ORDER BY created_at DESC
 	`
 
@@ -77,10 +79,12 @@ func (r *MySQLPostRepository) GetAll(ctx context.Context) ([]*domain.Post, error
 	var posts []*domain.Post
 	for rows.Next() {
 		var post domain.Post
-		err := rows.Scan(&post.ID, &post.Title, &post.Image, &post.Excerpt, &post.Body, &post.CreatedAt, &post.UpdatedAt)
+		var tags string
+		err := rows.Scan(&post.ID, &post.Title, &post.Image, &post.Excerpt, &post.Body, &post.Author, &post.Category, &post.Language, &tags, &post.CreatedAt, &post.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
 		}
+		post.Tags = domain.SplitTags(tags)
 		posts = append(posts, &post)
 	}
 
@@ -95,11 +99,11 @@ func (r *MySQLPostRepository) GetAll(ctx context.Context) ([]*domain.Post, error
 func (r *MySQLPostRepository) Update(ctx context.Context, post *domain.Post) error {
 	query := `
 		UPDATE posts 
-		SET title = ?, image = ?, excerpt = ?, body = ?, updated_at = ?
+		SET title = ?, image = ?, excerpt = ?, body = ?, author = ?, category = ?, language = ?, tags = ?, updated_at = ?
 		WHERE id = ?
 	`
 
-	result, err := r.db.ExecContext(ctx, query, post.Title, post.Image, post.Excerpt, post.Body, post.UpdatedAt, post.ID)
+	result, err := r.db.ExecContext(ctx, query, post.Title, post.Image, post.Excerpt, post.Body, post.Author, post.Category, post.Language, domain.JoinTags(post.Tags), post.UpdatedAt, post.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update post: %w", err)
 	}
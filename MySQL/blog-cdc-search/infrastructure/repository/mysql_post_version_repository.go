@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"blog-cdc-search/domain"
+)
+
+// MySQLPostVersionRepository implements PostVersionRepository using MySQL
+type MySQLPostVersionRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPostVersionRepository creates a new MySQLPostVersionRepository instance
+func NewMySQLPostVersionRepository(db *sql.DB) *MySQLPostVersionRepository {
+	return &MySQLPostVersionRepository{db: db}
+}
+
+// CreateVersion inserts a new post version
+func (r *MySQLPostVersionRepository) CreateVersion(ctx context.Context, version *domain.PostVersion) error {
+	query := `
+		INSERT INTO post_versions (post_id, version, operation, title, image, excerpt, body, author, category, language, tags,
+			changed_fields, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		version.PostID, version.Version, version.Operation, version.Title, version.Image, version.Excerpt, version.Body,
+		version.Author, version.Category, version.Language, domain.JoinTags(version.Tags),
+		strings.Join(version.ChangedFields, ","), version.ChangedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create post version: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	version.ID = id
+	return nil
+}
+
+// LatestVersion retrieves the newest version of a post, or nil if it has none
+func (r *MySQLPostVersionRepository) LatestVersion(ctx context.Context, postID int) (*domain.PostVersion, error) {
+	query := `
+		SELECT id, post_id, version, operation, title, image, excerpt, body, author, category, language, tags,
+			changed_fields, changed_at
+		FROM post_versions WHERE post_id = ? ORDER BY version DESC LIMIT 1
+	`
+
+	version, err := scanPostVersion(r.db.QueryRowContext(ctx, query, postID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest post version: %w", err)
+	}
+	return version, nil
+}
+
+// GetVersion retrieves one version of a post
+func (r *MySQLPostVersionRepository) GetVersion(ctx context.Context, postID, version int) (*domain.PostVersion, error) {
+	query := `
+		SELECT id, post_id, version, operation, title, image, excerpt, body, author, category, language, tags,
+			changed_fields, changed_at
+		FROM post_versions WHERE post_id = ? AND version = ?
+	`
+
+	postVersion, err := scanPostVersion(r.db.QueryRowContext(ctx, query, postID, version))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrPostVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to get post version: %w", err)
+	}
+	return postVersion, nil
+}
+
+// ListVersions retrieves every version of a post, newest first
+func (r *MySQLPostVersionRepository) ListVersions(ctx context.Context, postID int) ([]*domain.PostVersion, error) {
+	query := `
+		SELECT id, post_id, version, operation, title, image, excerpt, body, author, category, language, tags,
+			changed_fields, changed_at
+		FROM post_versions WHERE post_id = ? ORDER BY version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query post versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*domain.PostVersion
+	for rows.Next() {
+		version, err := scanPostVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return versions, nil
+}
+
+func scanPostVersion(row rowScanner) (*domain.PostVersion, error) {
+	var version domain.PostVersion
+	var tags, changedFields string
+	err := row.Scan(
+		&version.ID, &version.PostID, &version.Version, &version.Operation, &version.Title, &version.Image,
+		&version.Excerpt, &version.Body, &version.Author, &version.Category, &version.Language, &tags,
+		&changedFields, &version.ChangedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	version.Tags = domain.SplitTags(tags)
+	version.ChangedFields = []string{}
+	if changedFields != "" {
+		version.ChangedFields = strings.Split(changedFields, ",")
+	}
+	return &version, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// MySQLSearchAnalyticsRepository implements SearchAnalyticsRepository using MySQL
+type MySQLSearchAnalyticsRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLSearchAnalyticsRepository creates a new MySQLSearchAnalyticsRepository instance
+func NewMySQLSearchAnalyticsRepository(db *sql.DB) *MySQLSearchAnalyticsRepository {
+	return &MySQLSearchAnalyticsRepository{db: db}
+}
+
+// RecordSearch inserts a search
+func (r *MySQLSearchAnalyticsRepository) RecordSearch(ctx context.Context, search *domain.SearchEvent) error {
+	query := `
+		INSERT INTO search_queries (query, results, latency_ms, page, session_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, search.Query, search.Results, search.LatencyMS, search.Page, search.Session, search.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record search: %w", err)
+	}
+
+	search.ID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	return nil
+}
+
+// RecordClick inserts a result click
+func (r *MySQLSearchAnalyticsRepository) RecordClick(ctx context.Context, click *domain.SearchClick) error {
+	query := `
+		INSERT INTO search_clicks (search_id, post_id, position, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, click.SearchID, click.PostID, click.Position, click.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record search click: %w", err)
+	}
+
+	click.ID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	return nil
+}
+
+// Summary counts the searches since a point in time
+func (r *MySQLSearchAnalyticsRepository) Summary(ctx context.Context, since time.Time) (*domain.SearchAnalyticsSummary, error) {
+	query := `
+		SELECT COUNT(*), COUNT(DISTINCT s.session_id), COUNT(DISTINCT s.query),
+			COALESCE(SUM(s.results = 0), 0), COUNT(c.search_id)
+		FROM search_queries s
+		LEFT JOIN (SELECT DISTINCT search_id FROM search_clicks) c ON c.search_id = s.id
+		WHERE s.created_at >= ?
+	`
+
+	var summary domain.SearchAnalyticsSummary
+	err := r.db.QueryRowContext(ctx, query, since).Scan(&summary.Searches, &summary.Sessions, &summary.Queries, &summary.ZeroResults, &summary.Clicked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize searches: %w", err)
+	}
+	return &summary, nil
+}
+
+// TopQueries returns the most searched queries since a point in time
+func (r *MySQLSearchAnalyticsRepository) TopQueries(ctx context.Context, since time.Time, limit int) ([]*domain.SearchQueryStats, error) {
+	query := `
+		SELECT s.query, COUNT(*), COUNT(DISTINCT s.session_id), AVG(s.results), AVG(s.latency_ms),
+			COUNT(c.search_id), MAX(s.created_at)
+		FROM search_queries s
+		LEFT JOIN (SELECT DISTINCT search_id FROM search_clicks) c ON c.search_id = s.id
+		WHERE s.created_at >= ?
+		GROUP BY s.query
+		ORDER BY COUNT(*) DESC, s.query
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top searches: %w", err)
+	}
+	return scanSearchQueryStats(rows)
+}
+
+// ZeroResultQueries returns the queries most often searched without results
+// since a point in time
+func (r *MySQLSearchAnalyticsRepository) ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]*domain.SearchQueryStats, error) {
+	query := `
+		SELECT query, COUNT(*), COUNT(DISTINCT session_id), 0, AVG(latency_ms), 0, MAX(created_at)
+		FROM search_queries
+		WHERE created_at >= ? AND results = 0
+		GROUP BY query
+		ORDER BY COUNT(*) DESC, query
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query zero-result searches: %w", err)
+	}
+	return scanSearchQueryStats(rows)
+}
+
+// scanSearchQueryStats reads rows of query, searches, sessions, average
+// results, average latency, clicked searches and the last search
+func scanSearchQueryStats(rows *sql.Rows) ([]*domain.SearchQueryStats, error) {
+	defer rows.Close()
+
+	stats := []*domain.SearchQueryStats{}
+	for rows.Next() {
+		var stat domain.SearchQueryStats
+		if err := rows.Scan(&stat.Query, &stat.Searches, &stat.Sessions, &stat.AvgResults, &stat.AvgLatencyMS, &stat.Clicked, &stat.LastSearched); err != nil {
+			return nil, fmt.Errorf("failed to scan search stats: %w", err)
+		}
+		stat.SetCTR()
+		stats = append(stats, &stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return stats, nil
+}
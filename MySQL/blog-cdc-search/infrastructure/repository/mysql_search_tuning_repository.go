@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"blog-cdc-search/domain"
+)
+
+// MySQLSearchTuningRepository implements SearchTuningRepository using MySQL
+type MySQLSearchTuningRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLSearchTuningRepository creates a new MySQLSearchTuningRepository instance
+func NewMySQLSearchTuningRepository(db *sql.DB) *MySQLSearchTuningRepository {
+	return &MySQLSearchTuningRepository{db: db}
+}
+
+// CreateSynonym inserts a new synonym
+func (r *MySQLSearchTuningRepository) CreateSynonym(ctx context.Context, synonym *domain.Synonym) error {
+	query := `
+		INSERT INTO search_synonyms (root, synonyms, created_at)
+		VALUES (?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, synonym.Root, strings.Join(synonym.Synonyms, ","), synonym.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create synonym: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	synonym.ID = int(id)
+	return nil
+}
+
+// ListSynonyms retrieves all synonyms
+func (r *MySQLSearchTuningRepository) ListSynonyms(ctx context.Context) ([]*domain.Synonym, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, root, synonyms, created_at FROM search_synonyms ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query synonyms: %w", err)
+	}
+	defer rows.Close()
+
+	var synonyms []*domain.Synonym
+	for rows.Next() {
+		var synonym domain.Synonym
+		var words string
+		if err := rows.Scan(&synonym.ID, &synonym.Root, &words, &synonym.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan synonym: %w", err)
+		}
+		synonym.Synonyms = domain.SplitTags(words)
+		synonyms = append(synonyms, &synonym)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return synonyms, nil
+}
+
+// DeleteSynonym removes a synonym
+func (r *MySQLSearchTuningRepository) DeleteSynonym(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM search_synonyms WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete synonym: %w", err)
+	}
+	return requireSearchTuningRow(result)
+}
+
+// CreateCuration inserts a new curation
+func (r *MySQLSearchTuningRepository) CreateCuration(ctx context.Context, curation *domain.Curation) error {
+	query := `
+		INSERT INTO search_curations (query, match_type, pinned, hidden, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, curation.Query, curation.Match, domain.JoinPostIDs(curation.Pinned), domain.JoinPostIDs(curation.Hidden), curation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create curation: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	curation.ID = int(id)
+	return nil
+}
+
+// ListCurations retrieves all curations
+func (r *MySQLSearchTuningRepository) ListCurations(ctx context.Context) ([]*domain.Curation, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, query, match_type, pinned, hidden, created_at FROM search_curations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query curations: %w", err)
+	}
+	defer rows.Close()
+
+	var curations []*domain.Curation
+	for rows.Next() {
+		var curation domain.Curation
+		var pinned, hidden string
+		if err := rows.Scan(&curation.ID, &curation.Query, &curation.Match, &pinned, &hidden, &curation.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan curation: %w", err)
+		}
+		curation.Pinned = domain.SplitPostIDs(pinned)
+		curation.Hidden = domain.SplitPostIDs(hidden)
+		curations = append(curations, &curation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return curations, nil
+}
+
+// DeleteCuration removes a curation
+func (r *MySQLSearchTuningRepository) DeleteCuration(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM search_curations WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete curation: %w", err)
+	}
+	return requireSearchTuningRow(result)
+}
+
+// requireSearchTuningRow turns a delete that matched nothing into ErrSearchTuningNotFound
+func requireSearchTuningRow(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrSearchTuningNotFound
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// MySQLWebhookRepository implements WebhookRepository using MySQL
+type MySQLWebhookRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLWebhookRepository creates a new MySQLWebhookRepository instance
+func NewMySQLWebhookRepository(db *sql.DB) *MySQLWebhookRepository {
+	return &MySQLWebhookRepository{db: db}
+}
+
+// CreateEndpoint inserts a new webhook endpoint
+func (r *MySQLWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (url, secret, events, active, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, endpoint.URL, endpoint.Secret, endpoint.EventsString(), endpoint.Active, endpoint.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	endpoint.ID = int(id)
+	return nil
+}
+
+// GetEndpoint retrieves a webhook endpoint by its ID
+func (r *MySQLWebhookRepository) GetEndpoint(ctx context.Context, id int) (*domain.WebhookEndpoint, error) {
+	query := `
+		SELECT id, url, secret, events, active, created_at
+		FROM webhook_endpoints WHERE id = ?
+	`
+
+	endpoint, err := scanWebhookEndpoint(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return endpoint, nil
+}
+
+// ListEndpoints retrieves all webhook endpoints
+func (r *MySQLWebhookRepository) ListEndpoints(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	query := `
+		SELECT id, url, secret, events, active, created_at
+		FROM webhook_endpoints ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []*domain.WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return endpoints, nil
+}
+
+// DeleteEndpoint removes a webhook endpoint and its deliveries
+func (r *MySQLWebhookRepository) DeleteEndpoint(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	return requireRowsAffected(result)
+}
+
+// CreateDelivery inserts a new delivery log entry, or loads the endpoint's
+// existing delivery for the same change
+func (r *MySQLWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) (bool, error) {
+	// The no-op update leaves a duplicate untouched and reports 0 affected rows
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event, post_id, change_key, payload, status, attempts, response_status, error, next_attempt_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = id
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		delivery.EndpointID, delivery.Event, delivery.PostID, nullableString(delivery.ChangeKey), delivery.Payload, delivery.Status,
+		delivery.Attempts, delivery.ResponseStatus, delivery.Error, delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		existing, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, `
+			SELECT `+webhookDeliveryColumns+`
+			FROM webhook_deliveries WHERE endpoint_id = ? AND event = ? AND post_id = ? AND change_key = ?
+		`, delivery.EndpointID, delivery.Event, delivery.PostID, delivery.ChangeKey))
+		if err != nil {
+			return false, fmt.Errorf("failed to get existing webhook delivery: %w", err)
+		}
+		*delivery = *existing
+		return false, nil
+	}
+
+	delivery.ID, err = result.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	return true, nil
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt
+func (r *MySQLWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, response_status = ?, error = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.Error, delivery.NextAttemptAt, delivery.UpdatedAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	// MySQL reports 0 affected rows when nothing changed, so only errors count
+	return nil
+}
+
+// GetDelivery retrieves a delivery by its ID
+func (r *MySQLWebhookRepository) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries WHERE id = ?
+	`
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// ListDeliveries retrieves the most recent deliveries, newest first
+func (r *MySQLWebhookRepository) ListDeliveries(ctx context.Context, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries ORDER BY id DESC LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+// ClaimDueDeliveries locks due pending deliveries and leases them in one
+// transaction; SKIP LOCKED keeps concurrent claims from waiting on each other
+func (r *MySQLWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.QueryContext(ctx, query, domain.WebhookDeliveryPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+
+	leased := now.Add(lease)
+	placeholders := make([]string, len(deliveries))
+	args := []interface{}{leased}
+	for i, delivery := range deliveries {
+		placeholders[i] = "?"
+		args = append(args, delivery.ID)
+		delivery.NextAttemptAt = &leased
+	}
+	update := `UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id IN (` + strings.Join(placeholders, ", ") + `)`
+	if _, err := tx.ExecContext(ctx, update, args...); err != nil {
+		return nil, fmt.Errorf("failed to lease webhook deliveries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return deliveries, nil
+}
+
+// scanWebhookDeliveries reads and closes rows of deliveries
+func scanWebhookDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return deliveries, nil
+}
+
+// webhookDeliveryColumns are the columns read by scanWebhookDelivery
+const webhookDeliveryColumns = `id, endpoint_id, event, post_id, change_key, payload, status, attempts, response_status, error, next_attempt_at, created_at, updated_at`
+
+// nullableString stores an empty string as NULL
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhookEndpoint(row rowScanner) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	var events string
+	if err := row.Scan(&endpoint.ID, &endpoint.URL, &endpoint.Secret, &events, &endpoint.Active, &endpoint.CreatedAt); err != nil {
+		return nil, err
+	}
+	endpoint.Events = domain.ParseWebhookEvents(events)
+	return &endpoint, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var changeKey sql.NullString
+	err := row.Scan(
+		&delivery.ID, &delivery.EndpointID, &delivery.Event, &delivery.PostID, &changeKey, &delivery.Payload, &delivery.Status,
+		&delivery.Attempts, &delivery.ResponseStatus, &delivery.Error, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.ChangeKey = changeKey.String
+	return &delivery, nil
+}
+
+// requireRowsAffected turns an update or delete that matched nothing into ErrWebhookNotFound
+func requireRowsAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
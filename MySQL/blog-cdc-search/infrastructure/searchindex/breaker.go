@@ -0,0 +1,72 @@
+package searchindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"blog-cdc-search/domain"
+
+	"github.com/sony/gobreaker"
+)
+
+// newCircuitBreaker creates the breaker guarding a search backend. It opens
+// after threshold consecutive failures and probes again after openTimeout.
+func newCircuitBreaker(name string, threshold uint32, openTimeout time.Duration, isSuccessful func(error) bool) *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:    name,
+		Timeout: openTimeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= threshold
+		},
+		IsSuccessful: isSuccessful,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("Circuit breaker %s changed from %s to %s", name, from, to)
+		},
+	})
+}
+
+// runWithBreaker runs fn through the breaker. While the breaker is open the
+// call fails fast with domain.ErrServiceUnavailable. A call the caller gave
+// up on does not count against the backend.
+func runWithBreaker(ctx context.Context, breaker *gobreaker.CircuitBreaker, fn func() error) error {
+	var err error
+	_, breakerErr := breaker.Execute(func() (interface{}, error) {
+		err = fn()
+		if abandoned(ctx, err) {
+			return nil, nil
+		}
+		return nil, err
+	})
+	if errors.Is(breakerErr, gobreaker.ErrOpenState) || errors.Is(breakerErr, gobreaker.ErrTooManyRequests) {
+		return fmt.Errorf("%s: %w", breaker.Name(), domain.ErrServiceUnavailable)
+	}
+	return err
+}
+
+// errOperationTimeout is the cause of a context whose operation timeout,
+// rather than the caller's deadline, expired
+var errOperationTimeout = errors.New("search backend operation timed out")
+
+// abandoned reports whether err only shows that the caller gave up: the
+// call was canceled, or ctx expired on the caller's deadline. Expiry of the
+// repository's own read or write timeout is still a backend failure.
+func abandoned(ctx context.Context, err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return true
+	case errors.Is(err, context.DeadlineExceeded):
+		return ctx.Err() != nil && !errors.Is(context.Cause(ctx), errOperationTimeout)
+	}
+	return false
+}
+
+// withTimeout derives a context bounded by the given operation timeout
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, errOperationTimeout)
+}
@@ -0,0 +1,782 @@
+package searchindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/tlsconfig"
+)
+
+// ElasticsearchRepository implements the SearchIndexRepository interface for
+// Elasticsearch and OpenSearch, which share the REST API used here
+type ElasticsearchRepository struct {
+	rest   *restClient
+	config ElasticsearchConfig
+}
+
+// ElasticsearchConfig holds the configuration for Elasticsearch connection
+type ElasticsearchConfig struct {
+	// URL is the base URL of the cluster, e.g. http://localhost:9200
+	URL string
+
+	// Username and Password enable basic authentication; APIKey takes
+	// precedence when set.
+	Username string
+	Password string
+	APIKey   string
+
+	// TLS holds certificates for https:// URLs.
+	TLS tlsconfig.Config
+
+	// Refresh is passed to the _bulk API. "wait_for" makes writes visible to
+	// search before the call returns; empty leaves it to the refresh interval.
+	Refresh string
+
+	// ReadTimeout bounds searches and scrolls.
+	// Zero means the caller's context is used as is.
+	ReadTimeout time.Duration
+	// WriteTimeout bounds index creation, upserts and deletes.
+	// Zero means the caller's context is used as is.
+	WriteTimeout time.Duration
+
+	// BreakerFailureThreshold is the number of consecutive failures that
+	// opens the circuit breaker.
+	BreakerFailureThreshold uint32
+	// BreakerOpenTimeout is how long the breaker stays open before letting
+	// a probe request through.
+	BreakerOpenTimeout time.Duration
+}
+
+// ElasticsearchError is returned when the cluster answers with an error status
+type ElasticsearchError struct {
+	Status int
+	Type   string
+	Reason string
+}
+
+func (e *ElasticsearchError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("elasticsearch returned status %d", e.Status)
+	}
+	return fmt.Sprintf("elasticsearch returned status %d: %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// scrollKeepAlive is how long a scroll context lives between pages
+const scrollKeepAlive = "1m"
+
+// scrollPageSize is the number of documents fetched per scroll page
+const scrollPageSize = 500
+
+// highlightFields are the text fields returned with highlighted fragments
+var highlightFields = []string{"title", "excerpt", "body"}
+
+// searchPage returns the requested page and page size, defaulting like Typesense
+func searchPage(searchParams map[string]interface{}) (page, perPage int) {
+	page, perPage = 1, 10
+	if p, ok := searchParams["page"].(int); ok && p > 0 {
+		page = p
+	}
+	if pp, ok := searchParams["per_page"].(int); ok && pp > 0 {
+		perPage = pp
+	}
+	return page, perPage
+}
+
+// searchPrefix reports whether the last word of the query should match as a
+// prefix, for search-as-you-type
+func searchPrefix(searchParams map[string]interface{}) bool {
+	prefix, _ := searchParams["prefix"].(string)
+	return prefix == "true"
+}
+
+// searchTypos returns the number of typos tolerated per word, if num_typos
+// is set
+func searchTypos(searchParams map[string]interface{}) (int, bool) {
+	numTypos, _ := searchParams["num_typos"].(string)
+	typos, err := strconv.Atoi(numTypos)
+	if err != nil || typos < 0 {
+		return 0, false
+	}
+	return typos, true
+}
+
+// facetValueLimit is the number of values counted per facet, as in Typesense
+const facetValueLimit = 10
+
+// facetFields returns the fields named in facet_by
+func facetFields(searchParams map[string]interface{}) []string {
+	facetBy, _ := searchParams["facet_by"].(string)
+	var fields []string
+	for _, field := range strings.Split(facetBy, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// searchFilters returns the typed filters of a search
+func searchFilters(searchParams map[string]interface{}) []domain.SearchFilter {
+	filters, _ := searchParams["filters"].([]domain.SearchFilter)
+	return filters
+}
+
+// NewElasticsearchRepository creates a new Elasticsearch repository instance
+func NewElasticsearchRepository(config ElasticsearchConfig) *ElasticsearchRepository {
+	if config.BreakerFailureThreshold == 0 {
+		config.BreakerFailureThreshold = defaultBreakerFailureThreshold
+	}
+	if config.BreakerOpenTimeout <= 0 {
+		config.BreakerOpenTimeout = defaultBreakerOpenTimeout
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+
+	rest := &restClient{
+		baseURL:    config.URL,
+		breaker:    newCircuitBreaker("elasticsearch", config.BreakerFailureThreshold, config.BreakerOpenTimeout, isElasticsearchAvailable),
+		parseError: parseElasticsearchError,
+		authorize: func(req *http.Request) {
+			switch {
+			case config.APIKey != "":
+				req.Header.Set("Authorization", "ApiKey "+config.APIKey)
+			case config.Username != "":
+				req.SetBasicAuth(config.Username, config.Password)
+			}
+		},
+	}
+
+	return &ElasticsearchRepository{
+		rest:   rest,
+		config: config,
+	}
+}
+
+// isElasticsearchAvailable reports whether an error still proves the cluster
+// is reachable. Client errors such as a missing index must not open the breaker.
+func isElasticsearchAvailable(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	var esErr *ElasticsearchError
+	if errors.As(err, &esErr) {
+		return esErr.Status < 500 && esErr.Status != http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Connect establishes a connection to Elasticsearch. The client is kept even
+// if the cluster is down, so requests start working once it is up.
+func (r *ElasticsearchRepository) Connect(ctx context.Context) error {
+	tlsConfig := r.config.TLS
+	tlsConfig.Enabled = tlsConfig.Enabled || strings.HasPrefix(r.config.URL, "https://")
+	if err := r.rest.connect(tlsConfig); err != nil {
+		return fmt.Errorf("failed to load Elasticsearch TLS config: %w", err)
+	}
+
+	// Test the connection by reading the cluster info
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
+
+	var info struct {
+		ClusterName string `json:"cluster_name"`
+		Version     struct {
+			Number       string `json:"number"`
+			Distribution string `json:"distribution"`
+		} `json:"version"`
+	}
+	if err := r.do(ctx, http.MethodGet, "/", nil, &info); err != nil {
+		return fmt.Errorf("failed to connect to Elasticsearch: %w", err)
+	}
+
+	distribution := info.Version.Distribution
+	if distribution == "" {
+		distribution = "elasticsearch"
+	}
+	log.Printf("Connected to %s %s cluster %s at %s", distribution, info.Version.Number, info.ClusterName, r.config.URL)
+	return nil
+}
+
+// Close closes the Elasticsearch connection
+func (r *ElasticsearchRepository) Close() error {
+	r.rest.close()
+	return nil
+}
+
+// do sends a request to the cluster and decodes the JSON response
+func (r *ElasticsearchRepository) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	return r.rest.do(ctx, method, path, body, out)
+}
+
+// parseElasticsearchError extracts the error type and reason from a response body
+func parseElasticsearchError(status int, data []byte) error {
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	esErr := &ElasticsearchError{Status: status}
+	if json.Unmarshal(data, &body) != nil || len(body.Error) == 0 {
+		return esErr
+	}
+
+	// The error is an object for most APIs but a plain string for some
+	var detail struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	if json.Unmarshal(body.Error, &detail) == nil {
+		esErr.Type, esErr.Reason = detail.Type, detail.Reason
+	} else {
+		json.Unmarshal(body.Error, &esErr.Reason)
+	}
+	return esErr
+}
+
+// CreateCollection creates an index with mappings translated from the
+// Typesense-style schema map
+func (r *ElasticsearchRepository) CreateCollection(ctx context.Context, schema map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	indexName, _ := schema["name"].(string)
+	if indexName == "" {
+		return fmt.Errorf("failed to create index: schema has no name")
+	}
+
+	// Check if the index already exists
+	err := r.do(ctx, http.MethodHead, "/"+url.PathEscape(indexName), nil, nil)
+	if err == nil {
+		log.Printf("Index %s already exists", indexName)
+		return nil
+	}
+	var esErr *ElasticsearchError
+	if !errors.As(err, &esErr) || esErr.Status != http.StatusNotFound {
+		return fmt.Errorf("failed to check index: %w", err)
+	}
+
+	// Create the index
+	body := map[string]interface{}{
+		"mappings": elasticsearchMappings(schema),
+	}
+	if err := r.do(ctx, http.MethodPut, "/"+url.PathEscape(indexName), body, nil); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	log.Printf("Created index: %s", indexName)
+	return nil
+}
+
+// elasticsearchMappings converts the schema fields to index mappings
+func elasticsearchMappings(schema map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+
+	fields, _ := schema["fields"].([]map[string]interface{})
+	for _, field := range fields {
+		name, _ := field["name"].(string)
+		fieldType, _ := field["type"].(string)
+		index, hasIndex := field["index"].(bool)
+		facet, _ := field["facet"].(bool)
+
+		mapping := map[string]interface{}{}
+		switch fieldType {
+		case "string", "string[]":
+			switch {
+			case name == "id":
+				mapping["type"] = "keyword"
+			case hasIndex && !index:
+				mapping["type"] = "keyword"
+			case facet:
+				// Filters and facets match whole values; the text subfield
+				// keeps the field searchable
+				mapping["type"] = "keyword"
+				mapping["fields"] = map[string]interface{}{
+					"text": map[string]interface{}{"type": "text"},
+				}
+			default:
+				mapping["type"] = "text"
+			}
+		case "int32":
+			mapping["type"] = "integer"
+		case "int64":
+			mapping["type"] = "long"
+		case "float":
+			mapping["type"] = "double"
+		case "bool":
+			mapping["type"] = "boolean"
+		default:
+			// Let dynamic mapping decide for types without a counterpart
+			continue
+		}
+
+		// Non-indexed fields stay sortable through doc values
+		if hasIndex && !index {
+			mapping["index"] = false
+		}
+		properties[name] = mapping
+	}
+
+	return map[string]interface{}{"properties": properties}
+}
+
+// bulkAction is a single operation in a _bulk request
+type bulkAction struct {
+	op       string
+	index    string
+	id       string
+	document interface{}
+}
+
+// bulk sends the actions in one _bulk request and reports the first item error
+func (r *ElasticsearchRepository) bulk(ctx context.Context, actions []bulkAction) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, action := range actions {
+		meta := map[string]interface{}{
+			action.op: map[string]string{"_index": action.index, "_id": action.id},
+		}
+		if err := encoder.Encode(meta); err != nil {
+			return fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+		if action.document != nil {
+			if err := encoder.Encode(action.document); err != nil {
+				return fmt.Errorf("failed to encode document: %w", err)
+			}
+		}
+	}
+
+	path := "/_bulk"
+	if r.config.Refresh != "" {
+		path += "?refresh=" + url.QueryEscape(r.config.Refresh)
+	}
+
+	var response struct {
+		Errors bool                        `json:"errors"`
+		Items  []map[string]bulkItemResult `json:"items"`
+	}
+	if err := r.do(ctx, http.MethodPost, path, ndjson(buf.Bytes()), &response); err != nil {
+		return err
+	}
+	if !response.Errors {
+		return nil
+	}
+
+	for _, item := range response.Items {
+		for op, result := range item {
+			// Deleting a document that is already gone is not a failure
+			if op == "delete" && result.Status == http.StatusNotFound {
+				continue
+			}
+			if result.Status >= 300 {
+				return &ElasticsearchError{Status: result.Status, Type: result.Error.Type, Reason: result.Error.Reason}
+			}
+		}
+	}
+	return nil
+}
+
+// bulkItemResult is the outcome of one _bulk action
+type bulkItemResult struct {
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// UpsertDocument upserts a document to an index
+func (r *ElasticsearchRepository) UpsertDocument(ctx context.Context, collectionName string, document interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	id, err := documentID(document)
+	if err != nil {
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+
+	err = r.bulk(ctx, []bulkAction{{op: "index", index: collectionName, id: id, document: document}})
+	if err != nil {
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+
+	return nil
+}
+
+// documentID reads the id field of a document so it can be used as _id
+func documentID(document interface{}) (string, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+
+	var fields struct {
+		ID interface{} `json:"id"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", err
+	}
+
+	switch id := fields.ID.(type) {
+	case string:
+		if id != "" {
+			return id, nil
+		}
+	case float64:
+		return strconv.FormatInt(int64(id), 10), nil
+	}
+	return "", fmt.Errorf("document has no id")
+}
+
+// DeleteDocument deletes a document from an index
+func (r *ElasticsearchRepository) DeleteDocument(ctx context.Context, collectionName string, documentID string) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	err := r.bulk(ctx, []bulkAction{{op: "delete", index: collectionName, id: documentID}})
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	return nil
+}
+
+// searchHits is the hits section shared by search and scroll responses
+type searchHits struct {
+	Total struct {
+		Value int `json:"value"`
+	} `json:"total"`
+	Hits []struct {
+		ID        string                 `json:"_id"`
+		Score     float64                `json:"_score"`
+		Source    map[string]interface{} `json:"_source"`
+		Highlight map[string][]string    `json:"highlight"`
+	} `json:"hits"`
+}
+
+// SyncSynonyms accepts and ignores the synonyms: Elasticsearch applies them
+// through analyzers, which cannot change without reindexing
+func (r *ElasticsearchRepository) SyncSynonyms(ctx context.Context, collectionName string, synonyms []*domain.Synonym) error {
+	return nil
+}
+
+// SyncCurations accepts and ignores the curations, as the pinned query is
+// not available in OpenSearch
+func (r *ElasticsearchRepository) SyncCurations(ctx context.Context, collectionName string, curations []*domain.Curation) error {
+	return nil
+}
+
+// SearchDocuments searches for documents in an index. The Typesense-style
+// parameters used by the search service are translated to the query DSL.
+func (r *ElasticsearchRepository) SearchDocuments(ctx context.Context, collectionName, query string, searchParams map[string]interface{}) (*domain.SearchResults, error) {
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
+
+	fields := highlightFields
+	if queryBy, ok := searchParams["query_by"].(string); ok && queryBy != "" {
+		fields = strings.Split(queryBy, ",")
+	}
+
+	multiMatch := map[string]interface{}{
+		"query":  query,
+		"fields": fields,
+	}
+	if searchPrefix(searchParams) {
+		multiMatch["type"] = "bool_prefix"
+	}
+	if typos, ok := searchTypos(searchParams); ok {
+		multiMatch["fuzziness"] = typos
+	}
+	var must interface{} = map[string]interface{}{"multi_match": multiMatch}
+	if query == "" || query == "*" {
+		must = map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+
+	boolQuery := map[string]interface{}{"must": must}
+	var filters []interface{}
+	if filterBy, ok := searchParams["filter_by"].(string); ok && filterBy != "" {
+		parsed, err := elasticsearchFilters(filterBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search documents: %w", err)
+		}
+		filters = parsed
+	}
+	typed, err := elasticsearchSearchFilters(searchFilters(searchParams))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	if filters = append(filters, typed...); len(filters) > 0 {
+		boolQuery["filter"] = filters
+	}
+
+	body := map[string]interface{}{
+		"query": map[string]interface{}{"bool": boolQuery},
+		"highlight": map[string]interface{}{
+			"pre_tags":  []string{"<mark>"},
+			"post_tags": []string{"</mark>"},
+			"fields":    highlightFieldsFor(fields),
+		},
+	}
+
+	// Add pagination parameters. Totals are counted exactly, not capped at 10000.
+	page, perPage := searchPage(searchParams)
+	body["size"] = perPage
+	body["from"] = (page - 1) * perPage
+	body["track_total_hits"] = true
+
+	// Scores are reported even when sorting by other fields
+	sortBy, _ := searchParams["sort_by"].(string)
+	body["sort"] = elasticsearchSort(sortBy)
+	body["track_scores"] = true
+
+	// Facets are counted with a terms aggregation per field
+	facets := facetFields(searchParams)
+	if len(facets) > 0 {
+		aggs := make(map[string]interface{}, len(facets))
+		for _, field := range facets {
+			aggs[field] = map[string]interface{}{
+				"terms": map[string]interface{}{"field": field, "size": facetValueLimit},
+			}
+		}
+		body["aggs"] = aggs
+	}
+
+	var response struct {
+		Took         int                                 `json:"took"`
+		Hits         searchHits                          `json:"hits"`
+		Aggregations map[string]elasticsearchAggregation `json:"aggregations"`
+	}
+	if err := r.do(ctx, http.MethodPost, "/"+url.PathEscape(collectionName)+"/_search", body, &response); err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	results := &domain.SearchResults{
+		Hits:         []*domain.SearchHit{},
+		Found:        response.Hits.Total.Value,
+		Page:         page,
+		PerPage:      perPage,
+		SearchTimeMS: response.Took,
+	}
+	for _, hit := range response.Hits.Hits {
+		document := hit.Source
+		if document == nil {
+			document = map[string]interface{}{}
+		}
+		if _, ok := document["id"]; !ok {
+			document["id"] = hit.ID
+		}
+
+		result := &domain.SearchHit{Document: document, Score: hit.Score}
+		if len(hit.Highlight) > 0 {
+			result.Highlights = hit.Highlight
+		}
+		results.Hits = append(results.Hits, result)
+	}
+
+	for _, field := range facets {
+		aggregation, ok := response.Aggregations[field]
+		if !ok {
+			continue
+		}
+		counts := domain.FacetCounts{Field: field, Counts: []domain.FacetValueCount{}}
+		for _, bucket := range aggregation.Buckets {
+			// Boolean keys are numbers with a string form
+			value := bucket.KeyAsString
+			if value == "" {
+				value = fmt.Sprint(bucket.Key)
+			}
+			counts.Counts = append(counts.Counts, domain.FacetValueCount{Value: value, Count: bucket.DocCount})
+		}
+		results.Facets = append(results.Facets, counts)
+	}
+
+	return results, nil
+}
+
+// elasticsearchAggregation is the result of a terms aggregation
+type elasticsearchAggregation struct {
+	Buckets []struct {
+		Key         interface{} `json:"key"`
+		KeyAsString string      `json:"key_as_string"`
+		DocCount    int         `json:"doc_count"`
+	} `json:"buckets"`
+}
+
+// highlightFieldsFor builds the highlight field map, dropping boosts like title^2
+func highlightFieldsFor(fields []string) map[string]interface{} {
+	highlight := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		name, _, _ := strings.Cut(strings.TrimSpace(field), "^")
+		highlight[name] = map[string]interface{}{}
+	}
+	return highlight
+}
+
+// elasticsearchSort converts a Typesense sort_by such as
+// "_text_match:desc,created_at:desc" into a sort clause
+func elasticsearchSort(sortBy string) []interface{} {
+	sort := []interface{}{}
+	for _, clause := range strings.Split(sortBy, ",") {
+		field, order, _ := strings.Cut(strings.TrimSpace(clause), ":")
+		if field == "" {
+			continue
+		}
+		if field == "_text_match" {
+			field = "_score"
+		}
+		if order == "" {
+			order = "asc"
+		}
+		sort = append(sort, map[string]interface{}{field: map[string]string{"order": strings.ToLower(order)}})
+	}
+	if len(sort) == 0 {
+		sort = append(sort, map[string]interface{}{"_score": map[string]string{"order": "desc"}})
+	}
+	// The id breaks ties so pages do not overlap
+	return append(sort, map[string]interface{}{"id": map[string]string{"order": "asc"}})
+}
+
+// elasticsearchFilters converts a Typesense filter_by made of clauses joined
+// with && into filter clauses. Supported forms are field:value, field:=value,
+// field:[a,b] and numeric comparisons such as field:>=10.
+func elasticsearchFilters(filterBy string) ([]interface{}, error) {
+	var filters []interface{}
+	for _, clause := range strings.Split(filterBy, "&&") {
+		field, expr, ok := strings.Cut(strings.TrimSpace(clause), ":")
+		field, expr = strings.TrimSpace(field), strings.TrimSpace(expr)
+		if !ok || field == "" || expr == "" {
+			return nil, fmt.Errorf("unsupported filter clause: %q", clause)
+		}
+
+		// Range comparisons
+		for _, op := range []struct{ symbol, name string }{{">=", "gte"}, {"<=", "lte"}, {">", "gt"}, {"<", "lt"}} {
+			if value, found := strings.CutPrefix(expr, op.symbol); found {
+				filters = append(filters, map[string]interface{}{
+					"range": map[string]interface{}{field: map[string]string{op.name: strings.TrimSpace(value)}},
+				})
+				expr = ""
+				break
+			}
+		}
+		if expr == "" {
+			continue
+		}
+
+		expr = strings.TrimPrefix(expr, "=")
+		if strings.HasPrefix(expr, "[") && strings.HasSuffix(expr, "]") {
+			var values []string
+			for _, value := range strings.Split(expr[1:len(expr)-1], ",") {
+				values = append(values, strings.Trim(strings.TrimSpace(value), "`"))
+			}
+			filters = append(filters, map[string]interface{}{"terms": map[string]interface{}{field: values}})
+			continue
+		}
+
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{field: strings.Trim(expr, "`")},
+		})
+	}
+	return filters, nil
+}
+
+// elasticsearchRanges maps filter comparisons to range query bounds
+var elasticsearchRanges = map[domain.FilterOp]string{
+	domain.FilterAtLeast: "gte",
+	domain.FilterBelow:   "lt",
+}
+
+// elasticsearchSearchFilters converts typed search filters into filter
+// clauses, passing values through as JSON
+func elasticsearchSearchFilters(filters []domain.SearchFilter) ([]interface{}, error) {
+	var clauses []interface{}
+	for _, filter := range filters {
+		if filter.Op == domain.FilterEqual {
+			clauses = append(clauses, map[string]interface{}{
+				"term": map[string]interface{}{filter.Field: filter.Value},
+			})
+			continue
+		}
+		bound, ok := elasticsearchRanges[filter.Op]
+		if !ok {
+			return nil, fmt.Errorf("unsupported filter comparison: %q", filter.Op)
+		}
+		clauses = append(clauses, map[string]interface{}{
+			"range": map[string]interface{}{filter.Field: map[string]interface{}{bound: filter.Value}},
+		})
+	}
+	return clauses, nil
+}
+
+// GetAllDocuments retrieves all documents from an index using the scroll API
+func (r *ElasticsearchRepository) GetAllDocuments(ctx context.Context, collectionName string) ([]interface{}, error) {
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
+
+	var page struct {
+		ScrollID string     `json:"_scroll_id"`
+		Hits     searchHits `json:"hits"`
+	}
+
+	// Open the scroll context with the first page, in index order
+	body := map[string]interface{}{
+		"size": scrollPageSize,
+		"sort": []string{"_doc"},
+	}
+	path := "/" + url.PathEscape(collectionName) + "/_search?scroll=" + scrollKeepAlive
+	if err := r.do(ctx, http.MethodPost, path, body, &page); err != nil {
+		return nil, fmt.Errorf("failed to export documents: %w", err)
+	}
+
+	var results []interface{}
+	for {
+		for _, hit := range page.Hits.Hits {
+			document := hit.Source
+			if document == nil {
+				document = map[string]interface{}{}
+			}
+			if _, ok := document["id"]; !ok {
+				document["id"] = hit.ID
+			}
+			results = append(results, document)
+		}
+
+		if len(page.Hits.Hits) < scrollPageSize || page.ScrollID == "" {
+			break
+		}
+
+		scrollID := page.ScrollID
+		page.ScrollID, page.Hits.Hits = "", nil
+		next := map[string]string{"scroll": scrollKeepAlive, "scroll_id": scrollID}
+		if err := r.do(ctx, http.MethodPost, "/_search/scroll", next, &page); err != nil {
+			r.clearScroll(scrollID)
+			return nil, fmt.Errorf("failed to export documents: %w", err)
+		}
+	}
+
+	r.clearScroll(page.ScrollID)
+	return results, nil
+}
+
+// clearScroll releases a scroll context; failures only cost server memory
+// until the keep-alive expires, so they are logged
+func (r *ElasticsearchRepository) clearScroll(scrollID string) {
+	if scrollID == "" {
+		return
+	}
+
+	ctx, cancel := withTimeout(context.Background(), r.config.WriteTimeout)
+	defer cancel()
+
+	body := map[string]interface{}{"scroll_id": []string{scrollID}}
+	if err := r.do(ctx, http.MethodDelete, "/_search/scroll", body, nil); err != nil {
+		log.Printf("Failed to clear scroll: %v", err)
+	}
+}
@@ -0,0 +1,606 @@
+package searchindex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/tlsconfig"
+)
+
+// MeilisearchRepository implements the SearchIndexRepository interface for
+// Meilisearch
+type MeilisearchRepository struct {
+	rest   *restClient
+	config MeilisearchConfig
+}
+
+// MeilisearchConfig holds the configuration for Meilisearch connection
+type MeilisearchConfig struct {
+	// URL is the base URL of the instance, e.g. http://localhost:7700
+	URL string
+	// APIKey is sent as a bearer token; use a key with document and index
+	// permissions for the CDC service and a search key for the blog.
+	APIKey string
+
+	// TLS holds certificates for https:// URLs.
+	TLS tlsconfig.Config
+
+	// TaskPollInterval is how often an enqueued task is checked while
+	// waiting for it to finish.
+	TaskPollInterval time.Duration
+
+	// ReadTimeout bounds searches and document listings.
+	// Zero means the caller's context is used as is.
+	ReadTimeout time.Duration
+	// WriteTimeout bounds index changes, including waiting for their tasks.
+	// Zero means the caller's context is used as is.
+	WriteTimeout time.Duration
+
+	// BreakerFailureThreshold is the number of consecutive failures that
+	// opens the circuit breaker.
+	BreakerFailureThreshold uint32
+	// BreakerOpenTimeout is how long the breaker stays open before letting
+	// a probe request through.
+	BreakerOpenTimeout time.Duration
+}
+
+// MeilisearchError is returned for error responses and failed tasks. Status
+// is zero for tasks that were accepted but failed while processing.
+type MeilisearchError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *MeilisearchError) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("meilisearch task failed: %s: %s", e.Code, e.Message)
+	}
+	if e.Code == "" {
+		return fmt.Sprintf("meilisearch returned status %d", e.Status)
+	}
+	return fmt.Sprintf("meilisearch returned status %d: %s: %s", e.Status, e.Code, e.Message)
+}
+
+// defaultTaskPollInterval is used when the config leaves TaskPollInterval unset
+const defaultTaskPollInterval = 50 * time.Millisecond
+
+// meilisearchPageSize is the number of documents fetched per listing page
+const meilisearchPageSize = 1000
+
+// NewMeilisearchRepository creates a new Meilisearch repository instance
+func NewMeilisearchRepository(config MeilisearchConfig) *MeilisearchRepository {
+	if config.BreakerFailureThreshold == 0 {
+		config.BreakerFailureThreshold = defaultBreakerFailureThreshold
+	}
+	if config.BreakerOpenTimeout <= 0 {
+		config.BreakerOpenTimeout = defaultBreakerOpenTimeout
+	}
+	if config.TaskPollInterval <= 0 {
+		config.TaskPollInterval = defaultTaskPollInterval
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+
+	rest := &restClient{
+		baseURL:    config.URL,
+		breaker:    newCircuitBreaker("meilisearch", config.BreakerFailureThreshold, config.BreakerOpenTimeout, isMeilisearchAvailable),
+		parseError: parseMeilisearchError,
+		authorize: func(req *http.Request) {
+			if config.APIKey != "" {
+				req.Header.Set("Authorization", "Bearer "+config.APIKey)
+			}
+		},
+	}
+
+	return &MeilisearchRepository{
+		rest:   rest,
+		config: config,
+	}
+}
+
+// isMeilisearchAvailable reports whether an error still proves Meilisearch is
+// reachable. Client errors such as a missing index must not open the breaker.
+func isMeilisearchAvailable(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	var meiliErr *MeilisearchError
+	if errors.As(err, &meiliErr) {
+		return meiliErr.Status < 500
+	}
+	return false
+}
+
+// parseMeilisearchError extracts the error code and message from a response body
+func parseMeilisearchError(status int, data []byte) error {
+	meiliErr := &MeilisearchError{Status: status}
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		meiliErr.Code, meiliErr.Message = body.Code, body.Message
+	}
+	return meiliErr
+}
+
+// Connect establishes a connection to Meilisearch. The client is kept even if
+// the health check fails, so requests start working once Meilisearch is up.
+func (r *MeilisearchRepository) Connect(ctx context.Context) error {
+	tlsConfig := r.config.TLS
+	tlsConfig.Enabled = tlsConfig.Enabled || strings.HasPrefix(r.config.URL, "https://")
+	if err := r.rest.connect(tlsConfig); err != nil {
+		return fmt.Errorf("failed to load Meilisearch TLS config: %w", err)
+	}
+
+	// Test the connection with the health endpoint
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
+
+	if err := r.rest.do(ctx, http.MethodGet, "/health", nil, nil); err != nil {
+		return fmt.Errorf("failed to connect to Meilisearch: %w", err)
+	}
+
+	log.Printf("Connected to Meilisearch at %s", r.config.URL)
+	return nil
+}
+
+// Close closes the Meilisearch connection
+func (r *MeilisearchRepository) Close() error {
+	r.rest.close()
+	return nil
+}
+
+// meilisearchTask is the summary returned for every asynchronous operation
+type meilisearchTask struct {
+	TaskUID int64  `json:"taskUid"`
+	Status  string `json:"status"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// enqueue sends a request that creates a task and waits for the task to finish
+func (r *MeilisearchRepository) enqueue(ctx context.Context, method, path string, body interface{}) error {
+	var task meilisearchTask
+	if err := r.rest.do(ctx, method, path, body, &task); err != nil {
+		return err
+	}
+	return r.waitForTask(ctx, task.TaskUID)
+}
+
+// waitForTask polls a task until it succeeds, fails or ctx is done
+func (r *MeilisearchRepository) waitForTask(ctx context.Context, taskUID int64) error {
+	ticker := time.NewTicker(r.config.TaskPollInterval)
+	defer ticker.Stop()
+
+	for {
+		var task meilisearchTask
+		if err := r.rest.do(ctx, http.MethodGet, fmt.Sprintf("/tasks/%d", taskUID), nil, &task); err != nil {
+			return fmt.Errorf("failed to check task %d: %w", taskUID, err)
+		}
+
+		switch task.Status {
+		case "succeeded":
+			return nil
+		case "failed", "canceled":
+			taskErr := &MeilisearchError{Code: task.Status}
+			if task.Error != nil {
+				taskErr.Code, taskErr.Message = task.Error.Code, task.Error.Message
+			}
+			return fmt.Errorf("task %d %s: %w", taskUID, task.Status, taskErr)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("task %d still %s: %w", taskUID, task.Status, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// meilisearchSettings derives index settings from the Typesense-style schema
+func meilisearchSettings(schema map[string]interface{}) map[string]interface{} {
+	searchable := []string{}
+	sortable := []string{}
+	filterable := []string{}
+
+	fields, _ := schema["fields"].([]map[string]interface{})
+	for _, field := range fields {
+		name, _ := field["name"].(string)
+		fieldType, _ := field["type"].(string)
+		index, hasIndex := field["index"].(bool)
+		facet, _ := field["facet"].(bool)
+		indexed := !hasIndex || index
+
+		switch fieldType {
+		case "string", "string[]":
+			if indexed && name != "id" {
+				searchable = append(searchable, name)
+			}
+		case "int32", "int64", "float", "bool":
+			// Numeric fields stay sortable even when they are not indexed
+			sortable = append(sortable, name)
+			if indexed {
+				filterable = append(filterable, name)
+			}
+		}
+
+		if facet && !slices.Contains(filterable, name) {
+			filterable = append(filterable, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"searchableAttributes": searchable,
+		"sortableAttributes":   sortable,
+		"filterableAttributes": filterable,
+	}
+}
+
+// CreateCollection creates an index and applies the attribute settings
+// derived from the schema
+func (r *MeilisearchRepository) CreateCollection(ctx context.Context, schema map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	indexName, _ := schema["name"].(string)
+	if indexName == "" {
+		return fmt.Errorf("failed to create index: schema has no name")
+	}
+	indexPath := "/indexes/" + url.PathEscape(indexName)
+
+	// Check if the index already exists
+	err := r.rest.do(ctx, http.MethodGet, indexPath, nil, nil)
+	var meiliErr *MeilisearchError
+	switch {
+	case err == nil:
+		log.Printf("Index %s already exists", indexName)
+	case errors.As(err, &meiliErr) && meiliErr.Code == "index_not_found":
+		// Create the index with id as primary key
+		body := map[string]string{"uid": indexName, "primaryKey": "id"}
+		if err := r.enqueue(ctx, http.MethodPost, "/indexes", body); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+		log.Printf("Created index: %s", indexName)
+	default:
+		return fmt.Errorf("failed to check index: %w", err)
+	}
+
+	// Settings are applied every time so schema changes reach existing indexes
+	if err := r.enqueue(ctx, http.MethodPatch, indexPath+"/settings", meilisearchSettings(schema)); err != nil {
+		return fmt.Errorf("failed to update index settings: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertDocument adds or replaces a document and waits until it is indexed
+func (r *MeilisearchRepository) UpsertDocument(ctx context.Context, collectionName string, document interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	if _, err := documentID(document); err != nil {
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+
+	path := "/indexes/" + url.PathEscape(collectionName) + "/documents?primaryKey=id"
+	if err := r.enqueue(ctx, http.MethodPost, path, []interface{}{document}); err != nil {
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDocument deletes a document and waits until it is removed. Deleting
+// a missing document succeeds, as in Meilisearch itself.
+func (r *MeilisearchRepository) DeleteDocument(ctx context.Context, collectionName string, documentID string) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	path := "/indexes/" + url.PathEscape(collectionName) + "/documents/" + url.PathEscape(documentID)
+	if err := r.enqueue(ctx, http.MethodDelete, path, nil); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	return nil
+}
+
+// SyncSynonyms replaces the synonyms setting of the index. Meilisearch maps
+// each word to the words it also finds, so a multi-way synonym lists every
+// word against all the others.
+func (r *MeilisearchRepository) SyncSynonyms(ctx context.Context, collectionName string, synonyms []*domain.Synonym) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	path := "/indexes/" + url.PathEscape(collectionName) + "/settings/synonyms"
+	if err := r.enqueue(ctx, http.MethodPut, path, meilisearchSynonyms(synonyms)); err != nil {
+		return fmt.Errorf("failed to update synonyms: %w", err)
+	}
+
+	return nil
+}
+
+// meilisearchSynonyms merges synonyms into the word to words map Meilisearch
+// expects
+func meilisearchSynonyms(synonyms []*domain.Synonym) map[string][]string {
+	words := map[string][]string{}
+	add := func(word string, others []string) {
+		for _, other := range others {
+			if other != word && !slices.Contains(words[word], other) {
+				words[word] = append(words[word], other)
+			}
+		}
+	}
+	for _, synonym := range synonyms {
+		if synonym.Root != "" {
+			add(synonym.Root, synonym.Synonyms)
+			continue
+		}
+		for _, word := range synonym.Synonyms {
+			add(word, synonym.Synonyms)
+		}
+	}
+	return words
+}
+
+// SyncCurations accepts and ignores the curations: Meilisearch cannot pin or
+// hide documents per query
+func (r *MeilisearchRepository) SyncCurations(ctx context.Context, collectionName string, curations []*domain.Curation) error {
+	return nil
+}
+
+// SearchDocuments searches for documents in an index. The Typesense-style
+// parameters used by the search service are translated to Meilisearch ones.
+func (r *MeilisearchRepository) SearchDocuments(ctx context.Context, collectionName, query string, searchParams map[string]interface{}) (*domain.SearchResults, error) {
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
+
+	fields := highlightFields
+	if queryBy, ok := searchParams["query_by"].(string); ok && queryBy != "" {
+		fields = strings.Split(queryBy, ",")
+	}
+	if query == "*" {
+		query = ""
+	}
+
+	// Meilisearch always completes the last word and sets typo tolerance per
+	// index, so prefix and num_typos need no translation
+
+	body := map[string]interface{}{
+		"q":                     query,
+		"attributesToSearchOn":  fields,
+		"attributesToHighlight": fields,
+		"highlightPreTag":       "<mark>",
+		"highlightPostTag":      "</mark>",
+		"showRankingScore":      true,
+	}
+
+	// Add pagination parameters. Asking for a page makes totalHits exact.
+	page, perPage := searchPage(searchParams)
+	body["page"] = page
+	body["hitsPerPage"] = perPage
+
+	if sortBy, ok := searchParams["sort_by"].(string); ok && sortBy != "" {
+		if sort := meilisearchSort(sortBy); len(sort) > 0 {
+			body["sort"] = sort
+		}
+	}
+
+	var filters []string
+	if filterBy, ok := searchParams["filter_by"].(string); ok && filterBy != "" {
+		filter, err := meilisearchFilter(filterBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search documents: %w", err)
+		}
+		filters = append(filters, filter)
+	}
+	if typed := searchFilters(searchParams); len(typed) > 0 {
+		filter, err := meilisearchSearchFilter(typed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search documents: %w", err)
+		}
+		filters = append(filters, filter)
+	}
+	if len(filters) > 0 {
+		body["filter"] = strings.Join(filters, " AND ")
+	}
+
+	facets := facetFields(searchParams)
+	if len(facets) > 0 {
+		body["facets"] = facets
+	}
+
+	var response struct {
+		Hits              []map[string]interface{}  `json:"hits"`
+		TotalHits         int                       `json:"totalHits"`
+		ProcessingTimeMs  int                       `json:"processingTimeMs"`
+		FacetDistribution map[string]map[string]int `json:"facetDistribution"`
+	}
+	path := "/indexes/" + url.PathEscape(collectionName) + "/search"
+	if err := r.rest.do(ctx, http.MethodPost, path, body, &response); err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	results := &domain.SearchResults{
+		Hits:         []*domain.SearchHit{},
+		Found:        response.TotalHits,
+		Page:         page,
+		PerPage:      perPage,
+		SearchTimeMS: response.ProcessingTimeMs,
+	}
+	for _, hit := range response.Hits {
+		formatted, _ := hit["_formatted"].(map[string]interface{})
+		delete(hit, "_formatted")
+
+		result := &domain.SearchHit{Document: hit}
+		if score, ok := hit["_rankingScore"].(float64); ok {
+			result.Score = score
+		}
+		delete(hit, "_rankingScore")
+
+		// Only fields with a match are reported as highlights
+		highlights := map[string][]string{}
+		for _, field := range fields {
+			if value, ok := formatted[field].(string); ok && strings.Contains(value, "<mark>") {
+				highlights[field] = []string{value}
+			}
+		}
+		if len(highlights) > 0 {
+			result.Highlights = highlights
+		}
+
+		results.Hits = append(results.Hits, result)
+	}
+
+	for _, field := range facets {
+		if distribution, ok := response.FacetDistribution[field]; ok {
+			results.Facets = append(results.Facets, meilisearchFacet(field, distribution))
+		}
+	}
+
+	return results, nil
+}
+
+// meilisearchFacet orders a facet distribution by count, then value, and
+// keeps the most frequent values as Typesense does
+func meilisearchFacet(field string, distribution map[string]int) domain.FacetCounts {
+	counts := make([]domain.FacetValueCount, 0, len(distribution))
+	for value, count := range distribution {
+		counts = append(counts, domain.FacetValueCount{Value: value, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	return domain.FacetCounts{Field: field, Counts: counts[:min(len(counts), facetValueLimit)]}
+}
+
+// meilisearchSort converts a Typesense sort_by into sort expressions.
+// Relevance is Meilisearch's own ranking, so _text_match is dropped.
+func meilisearchSort(sortBy string) []string {
+	var sort []string
+	for _, clause := range strings.Split(sortBy, ",") {
+		field, order, _ := strings.Cut(strings.TrimSpace(clause), ":")
+		if field == "" || field == "_text_match" {
+			continue
+		}
+		if order == "" {
+			order = "asc"
+		}
+		sort = append(sort, field+":"+strings.ToLower(order))
+	}
+	return sort
+}
+
+// meilisearchFilter converts a Typesense filter_by made of clauses joined
+// with && into a filter expression. Supported forms are field:value,
+// field:=value, field:[a,b] and numeric comparisons such as field:>=10.
+func meilisearchFilter(filterBy string) (string, error) {
+	var clauses []string
+	for _, clause := range strings.Split(filterBy, "&&") {
+		field, expr, ok := strings.Cut(strings.TrimSpace(clause), ":")
+		field, expr = strings.TrimSpace(field), strings.TrimSpace(expr)
+		if !ok || field == "" || expr == "" {
+			return "", fmt.Errorf("unsupported filter clause: %q", clause)
+		}
+
+		switch {
+		case strings.HasPrefix(expr, ">="), strings.HasPrefix(expr, "<="):
+			clauses = append(clauses, fmt.Sprintf("%s %s %s", field, expr[:2], strings.TrimSpace(expr[2:])))
+		case strings.HasPrefix(expr, ">"), strings.HasPrefix(expr, "<"):
+			clauses = append(clauses, fmt.Sprintf("%s %s %s", field, expr[:1], strings.TrimSpace(expr[1:])))
+		case strings.HasPrefix(expr, "[") && strings.HasSuffix(expr, "]"):
+			var values []string
+			for _, value := range strings.Split(expr[1:len(expr)-1], ",") {
+				values = append(values, quoteMeilisearchValue(value))
+			}
+			clauses = append(clauses, fmt.Sprintf("%s IN [%s]", field, strings.Join(values, ", ")))
+		default:
+			clauses = append(clauses, fmt.Sprintf("%s = %s", field, quoteMeilisearchValue(strings.TrimPrefix(expr, "="))))
+		}
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// meilisearchSearchFilter converts typed search filters into a filter
+// expression
+func meilisearchSearchFilter(filters []domain.SearchFilter) (string, error) {
+	var clauses []string
+	for _, filter := range filters {
+		switch filter.Op {
+		case domain.FilterEqual, domain.FilterAtLeast, domain.FilterBelow:
+		default:
+			return "", fmt.Errorf("unsupported filter comparison: %q", filter.Op)
+		}
+
+		var value string
+		switch v := filter.Value.(type) {
+		case string:
+			value = quoteMeilisearchString(v)
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			return "", fmt.Errorf("unsupported filter value for %s: %T", filter.Field, filter.Value)
+		}
+		clauses = append(clauses, fmt.Sprintf("%s %s %s", filter.Field, filter.Op, value))
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// quoteMeilisearchValue quotes a filter value, dropping Typesense backticks
+func quoteMeilisearchValue(value string) string {
+	return quoteMeilisearchString(strings.Trim(strings.TrimSpace(value), "`"))
+}
+
+// quoteMeilisearchString quotes a string for a filter expression, escaping
+// backslashes and double quotes
+func quoteMeilisearchString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+// GetAllDocuments retrieves all documents from an index page by page
+func (r *MeilisearchRepository) GetAllDocuments(ctx context.Context, collectionName string) ([]interface{}, error) {
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
+
+	var results []interface{}
+	for offset := 0; ; offset += meilisearchPageSize {
+		var page struct {
+			Results []map[string]interface{} `json:"results"`
+			Total   int                      `json:"total"`
+		}
+		path := fmt.Sprintf("/indexes/%s/documents?limit=%d&offset=%d", url.PathEscape(collectionName), meilisearchPageSize, offset)
+		if err := r.rest.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to export documents: %w", err)
+		}
+
+		for _, document := range page.Results {
+			results = append(results, document)
+		}
+
+		if len(page.Results) < meilisearchPageSize || offset+len(page.Results) >= page.Total {
+			break
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,458 @@
+package searchindex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// fakeMeilisearch is an in-memory stand-in for the parts of the Meilisearch
+// REST API used by the repository. Tasks stay "processing" for one poll.
+type fakeMeilisearch struct {
+	mu        sync.Mutex
+	indices   map[string]map[string]map[string]interface{}
+	settings  map[string]map[string]interface{}
+	synonyms  map[string]map[string][]string
+	tasks     map[int]*fakeTask
+	searches  []map[string]interface{}
+	failTasks bool
+	// facetDistribution is returned for searches that request facets
+	facetDistribution map[string]map[string]int
+}
+
+type fakeTask struct {
+	status string
+	polls  int
+	apply  func()
+}
+
+func newFakeMeilisearch(t *testing.T) (*fakeMeilisearch, *httptest.Server) {
+	fake := &fakeMeilisearch{
+		indices:  map[string]map[string]map[string]interface{}{},
+		settings: map[string]map[string]interface{}{},
+		synonyms: map[string]map[string][]string{},
+		tasks:    map[int]*fakeTask{},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+// enqueue registers a task whose effect is applied once it completes
+func (f *fakeMeilisearch) enqueue(w http.ResponseWriter, apply func()) {
+	uid := len(f.tasks) + 1
+	f.tasks[uid] = &fakeTask{status: "enqueued", apply: apply}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"taskUid": uid, "status": "enqueued"})
+}
+
+func (f *fakeMeilisearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer master-key" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"code": "invalid_api_key", "message": "bad key"})
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case parts[0] == "health":
+		writeJSON(w, http.StatusOK, map[string]string{"status": "available"})
+
+	case parts[0] == "tasks" && len(parts) == 2:
+		var uid int
+		fmt.Sscanf(parts[1], "%d", &uid)
+		task := f.tasks[uid]
+		task.polls++
+		if task.polls > 1 && task.status != "succeeded" && task.status != "failed" {
+			task.status = "succeeded"
+			if f.failTasks {
+				task.status = "failed"
+			} else {
+				task.apply()
+			}
+		} else if task.polls == 1 {
+			task.status = "processing"
+		}
+		response := map[string]interface{}{"uid": uid, "status": task.status}
+		if task.status == "failed" {
+			response["error"] = map[string]string{"code": "invalid_document_id", "message": "bad id"}
+		}
+		writeJSON(w, http.StatusOK, response)
+
+	case parts[0] == "indexes" && len(parts) == 1 && r.Method == http.MethodPost:
+		var req map[string]string
+		json.Unmarshal(body, &req)
+		f.enqueue(w, func() { f.indices[req["uid"]] = map[string]map[string]interface{}{} })
+
+	case parts[0] == "indexes" && len(parts) == 2 && r.Method == http.MethodGet:
+		if _, ok := f.indices[parts[1]]; !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"code": "index_not_found", "message": "no index"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"uid": parts[1], "primaryKey": "id"})
+
+	case parts[0] == "indexes" && len(parts) == 3 && parts[2] == "settings":
+		var req map[string]interface{}
+		json.Unmarshal(body, &req)
+		f.enqueue(w, func() { f.settings[parts[1]] = req })
+
+	case parts[0] == "indexes" && len(parts) == 4 && parts[2] == "settings" && parts[3] == "synonyms" && r.Method == http.MethodPut:
+		var req map[string][]string
+		json.Unmarshal(body, &req)
+		f.enqueue(w, func() { f.synonyms[parts[1]] = req })
+
+	case parts[0] == "indexes" && len(parts) == 3 && parts[2] == "documents" && r.Method == http.MethodPost:
+		var documents []map[string]interface{}
+		json.Unmarshal(body, &documents)
+		f.enqueue(w, func() {
+			if f.indices[parts[1]] == nil {
+				f.indices[parts[1]] = map[string]map[string]interface{}{}
+			}
+			for _, document := range documents {
+				f.indices[parts[1]][document["id"].(string)] = document
+			}
+		})
+
+	case parts[0] == "indexes" && len(parts) == 3 && parts[2] == "documents" && r.Method == http.MethodGet:
+		documents := f.sortedDocuments(parts[1])
+		var limit, offset int
+		fmt.Sscanf(r.URL.Query().Get("limit"), "%d", &limit)
+		fmt.Sscanf(r.URL.Query().Get("offset"), "%d", &offset)
+		end := min(offset+limit, len(documents))
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"results": documents[min(offset, end):end],
+			"offset":  offset,
+			"limit":   limit,
+			"total":   len(documents),
+		})
+
+	case parts[0] == "indexes" && len(parts) == 4 && parts[2] == "documents" && r.Method == http.MethodDelete:
+		f.enqueue(w, func() { delete(f.indices[parts[1]], parts[3]) })
+
+	case parts[0] == "indexes" && len(parts) == 3 && parts[2] == "search":
+		var req map[string]interface{}
+		json.Unmarshal(body, &req)
+		f.searches = append(f.searches, req)
+
+		var hits []map[string]interface{}
+		for _, document := range f.sortedDocuments(parts[1]) {
+			hit := map[string]interface{}{"_rankingScore": 0.75}
+			formatted := map[string]interface{}{}
+			for key, value := range document {
+				hit[key] = value
+				formatted[key] = value
+			}
+			formatted["title"] = strings.ReplaceAll(document["title"].(string), "match", "<mark>match</mark>")
+			hit["_formatted"] = formatted
+			hits = append(hits, hit)
+		}
+		response := map[string]interface{}{"hits": hits, "totalHits": len(hits), "processingTimeMs": 2}
+		if _, ok := req["facets"]; ok && f.facetDistribution != nil {
+			response["facetDistribution"] = f.facetDistribution
+		}
+		writeJSON(w, http.StatusOK, response)
+
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"code": "not_found", "message": r.URL.Path})
+	}
+}
+
+func (f *fakeMeilisearch) sortedDocuments(index string) []map[string]interface{} {
+	var ids []string
+	for id := range f.indices[index] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	documents := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		documents = append(documents, f.indices[index][id])
+	}
+	return documents
+}
+
+func newTestMeilisearchRepository(t *testing.T, serverURL string) *MeilisearchRepository {
+	t.Helper()
+
+	repo := NewMeilisearchRepository(MeilisearchConfig{
+		URL:              serverURL,
+		APIKey:           "master-key",
+		TaskPollInterval: time.Millisecond,
+	})
+	if err := repo.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	return repo
+}
+
+func TestMeilisearchRepository_CreateCollection(t *testing.T) {
+	fake, server := newFakeMeilisearch(t)
+	repo := newTestMeilisearchRepository(t, server.URL)
+
+	schema := map[string]interface{}{
+		"name": "posts",
+		"fields": []map[string]interface{}{
+			{"name": "id", "type": "string"},
+			{"name": "title", "type": "string", "facet": false, "index": true},
+			{"name": "image", "type": "string", "optional": true, "facet": false, "index": false},
+			{"name": "body", "type": "string", "facet": false, "index": true},
+			{"name": "created_at", "type": "int64", "facet": false, "index": true},
+			{"name": "updated_at", "type": "int64", "facet": false, "index": false},
+		},
+	}
+
+	if err := repo.CreateCollection(context.Background(), schema); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, ok := fake.indices["posts"]; !ok {
+		t.Fatal("Expected index to be created")
+	}
+
+	expected := map[string]interface{}{
+		"searchableAttributes": []interface{}{"title", "body"},
+		"sortableAttributes":   []interface{}{"created_at", "updated_at"},
+		"filterableAttributes": []interface{}{"created_at"},
+	}
+	if !reflect.DeepEqual(fake.settings["posts"], expected) {
+		t.Errorf("Unexpected settings: %#v", fake.settings["posts"])
+	}
+
+	// An existing index only gets its settings refreshed
+	if err := repo.CreateCollection(context.Background(), schema); err != nil {
+		t.Errorf("Expected existing index to be accepted, got %v", err)
+	}
+
+	// A schema without a name is rejected rather than panicking
+	if err := repo.CreateCollection(context.Background(), map[string]interface{}{"fields": schema["fields"]}); err == nil {
+		t.Error("Expected an error for a schema without a name")
+	}
+}
+
+func TestMeilisearchRepository_UpsertAndDeleteWaitForTasks(t *testing.T) {
+	fake, server := newFakeMeilisearch(t)
+	repo := newTestMeilisearchRepository(t, server.URL)
+	ctx := context.Background()
+
+	doc := &domain.SearchDocument{ID: "7", Title: "Hello"}
+	if err := repo.UpsertDocument(ctx, "posts", doc); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The task has completed by the time the call returns
+	if fake.indices["posts"]["7"]["title"] != "Hello" {
+		t.Errorf("Expected document to be indexed, got %v", fake.indices["posts"]["7"])
+	}
+
+	if err := repo.DeleteDocument(ctx, "posts", "7"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := fake.indices["posts"]["7"]; ok {
+		t.Error("Expected document to be deleted")
+	}
+}
+
+func TestMeilisearchRepository_FailedTask(t *testing.T) {
+	fake, server := newFakeMeilisearch(t)
+	repo := newTestMeilisearchRepository(t, server.URL)
+	fake.failTasks = true
+
+	err := repo.UpsertDocument(context.Background(), "posts", map[string]interface{}{"id": "1"})
+	var meiliErr *MeilisearchError
+	if !errors.As(err, &meiliErr) || meiliErr.Code != "invalid_document_id" {
+		t.Errorf("Expected failed task error, got %v", err)
+	}
+}
+
+func TestMeilisearchRepository_SearchDocuments(t *testing.T) {
+	fake, server := newFakeMeilisearch(t)
+	repo := newTestMeilisearchRepository(t, server.URL)
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "1", Title: "a match", Body: "plain"})
+
+	results, err := repo.SearchDocuments(ctx, "posts", "match", map[string]interface{}{
+		"query_by":  "title,excerpt,body",
+		"sort_by":   "_text_match:desc,created_at:desc",
+		"filter_by": "created_at:>=100 && id:[1,2]",
+		"page":      2,
+		"per_page":  5,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	request := fake.searches[0]
+	if request["page"] != float64(2) || request["hitsPerPage"] != float64(5) {
+		t.Errorf("Expected page=2 hitsPerPage=5, got %v and %v", request["page"], request["hitsPerPage"])
+	}
+	if !reflect.DeepEqual(request["sort"], []interface{}{"created_at:desc"}) {
+		t.Errorf("Unexpected sort: %v", request["sort"])
+	}
+	if request["filter"] != `created_at >= 100 AND id IN ["1", "2"]` {
+		t.Errorf("Unexpected filter: %v", request["filter"])
+	}
+
+	if len(results.Hits) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results.Hits))
+	}
+	if results.Found != 1 || results.Page != 2 || results.PerPage != 5 || results.SearchTimeMS != 2 {
+		t.Errorf("Unexpected totals: found %d, page %d of %d, %dms", results.Found, results.Page, results.PerPage, results.SearchTimeMS)
+	}
+	hit := results.Hits[0]
+	if hit.Score != 0.75 {
+		t.Errorf("Expected score 0.75, got %v", hit.Score)
+	}
+	for _, field := range []string{"_formatted", "_rankingScore"} {
+		if _, ok := hit.Document[field]; ok {
+			t.Errorf("Expected %s to be removed", field)
+		}
+	}
+
+	// Only the matching field is reported, in the shape SearchService expects
+	expected := map[string][]string{"title": {"a <mark>match</mark>"}}
+	if !reflect.DeepEqual(hit.Highlights, expected) {
+		t.Errorf("Unexpected highlights: %v", hit.Highlights)
+	}
+}
+
+func TestMeilisearchRepository_SearchDocumentsTypedFilters(t *testing.T) {
+	fake, server := newFakeMeilisearch(t)
+	repo := newTestMeilisearchRepository(t, server.URL)
+
+	_, err := repo.SearchDocuments(context.Background(), "posts", "*", map[string]interface{}{
+		"filter_by": "id:[1,2]",
+		"filters": []domain.SearchFilter{
+			{Field: "created_at", Op: domain.FilterAtLeast, Value: int64(100)},
+			{Field: "has_image", Op: domain.FilterEqual, Value: true},
+			{Field: "category", Op: domain.FilterEqual, Value: `Q&A && "notes": C:\temp`},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Values are quoted and escaped rather than parsed as filter syntax
+	expected := `id IN ["1", "2"] AND created_at >= 100 AND has_image = true AND category = "Q&A && \"notes\": C:\\temp"`
+	if filter := fake.searches[0]["filter"]; filter != expected {
+		t.Errorf("Expected filter %s, got %v", expected, filter)
+	}
+
+	_, err = repo.SearchDocuments(context.Background(), "posts", "*", map[string]interface{}{
+		"filters": []domain.SearchFilter{{Field: "tags", Op: domain.FilterEqual, Value: 1.5}},
+	})
+	if err == nil {
+		t.Error("Expected an error for an unsupported filter value")
+	}
+}
+
+func TestMeilisearchRepository_SearchDocumentsFacets(t *testing.T) {
+	fake, server := newFakeMeilisearch(t)
+	repo := newTestMeilisearchRepository(t, server.URL)
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "1", Title: "a match"})
+	tags := map[string]int{"go": 2, "cdc": 5, "sql": 2}
+	for i := 0; i < 12; i++ {
+		tags[fmt.Sprintf("tag-%02d", i)] = 1
+	}
+	fake.facetDistribution = map[string]map[string]int{"tags": tags, "year": {"2024": 1}}
+
+	results, err := repo.SearchDocuments(ctx, "posts", "match", map[string]interface{}{
+		"query_by": "title",
+		"facet_by": "tags,category",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(fake.searches[0]["facets"], []interface{}{"tags", "category"}) {
+		t.Errorf("Unexpected facets request: %v", fake.searches[0]["facets"])
+	}
+
+	// Only requested fields come back, ordered by count then value and capped
+	if len(results.Facets) != 1 || results.Facets[0].Field != "tags" {
+		t.Fatalf("Expected only the tags facet, got %+v", results.Facets)
+	}
+	counts := results.Facets[0].Counts
+	if len(counts) != 10 {
+		t.Fatalf("Expected 10 tag counts, got %d", len(counts))
+	}
+	head := []domain.FacetValueCount{{Value: "cdc", Count: 5}, {Value: "go", Count: 2}, {Value: "sql", Count: 2}, {Value: "tag-00", Count: 1}}
+	if !reflect.DeepEqual(counts[:4], head) {
+		t.Errorf("Unexpected tag counts: %+v", counts[:4])
+	}
+}
+
+func TestMeilisearchRepository_GetAllDocuments(t *testing.T) {
+	_, server := newFakeMeilisearch(t)
+	repo := newTestMeilisearchRepository(t, server.URL)
+	ctx := context.Background()
+
+	documents := make([]interface{}, 0, meilisearchPageSize+1)
+	for i := 0; i < meilisearchPageSize+1; i++ {
+		documents = append(documents, map[string]interface{}{"id": fmt.Sprintf("%05d", i)})
+	}
+	// One request with many documents keeps the test fast
+	if err := repo.enqueue(ctx, http.MethodPost, "/indexes/posts/documents", documents); err != nil {
+		t.Fatalf("Failed to seed documents: %v", err)
+	}
+
+	results, err := repo.GetAllDocuments(ctx, "posts")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(results) != meilisearchPageSize+1 {
+		t.Errorf("Expected %d documents, got %d", meilisearchPageSize+1, len(results))
+	}
+}
+
+func TestMeilisearchRepository_SyncSynonyms(t *testing.T) {
+	fake, server := newFakeMeilisearch(t)
+	repo := newTestMeilisearchRepository(t, server.URL)
+
+	err := repo.SyncSynonyms(context.Background(), "posts", []*domain.Synonym{
+		{ID: 1, Synonyms: []string{"cdc", "change data capture", "replication"}},
+		{ID: 2, Root: "cdc", Synonyms: []string{"debezium", "replication"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Multi-way synonyms map every word to the others; one-way ones only the root
+	expected := map[string][]string{
+		"cdc":                 {"change data capture", "replication", "debezium"},
+		"change data capture": {"cdc", "replication"},
+		"replication":         {"cdc", "change data capture"},
+	}
+	if !reflect.DeepEqual(fake.synonyms["posts"], expected) {
+		t.Errorf("Unexpected synonyms: %v", fake.synonyms["posts"])
+	}
+}
+
+func TestMeilisearchRepository_Unauthorized(t *testing.T) {
+	_, server := newFakeMeilisearch(t)
+	repo := NewMeilisearchRepository(MeilisearchConfig{URL: server.URL, APIKey: "wrong"})
+
+	err := repo.Connect(context.Background())
+	var meiliErr *MeilisearchError
+	if !errors.As(err, &meiliErr) || meiliErr.Code != "invalid_api_key" {
+		t.Errorf("Expected invalid_api_key error, got %v", err)
+	}
+}
@@ -0,0 +1,512 @@
+// Package searchindextest provides a conformance suite for implementations of
+// domain.SearchIndexRepository, so every backend behaves the same way for
+// the search service.
+package searchindextest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// Factory returns a connected repository. It is called once per subtest and
+// should register any cleanup with t.Cleanup.
+type Factory func(t *testing.T) domain.SearchIndexRepository
+
+// collectionSeq keeps collection names unique within a test binary
+var collectionSeq atomic.Int64
+
+// PostsSchema returns the posts schema used by the CDC service under the
+// given collection name
+func PostsSchema(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"fields": []map[string]interface{}{
+			{"name": "id", "type": "string"},
+			{"name": "title", "type": "string", "facet": false, "index": true},
+			{"name": "image", "type": "string", "optional": true, "facet": false, "index": false},
+			{"name": "excerpt", "type": "string", "optional": true, "facet": false, "index": true},
+			{"name": "body", "type": "string", "facet": false, "index": true},
+			{"name": "author", "type": "string", "optional": true, "facet": true},
+			{"name": "category", "type": "string", "optional": true, "facet": true},
+			{"name": "tags", "type": "string[]", "optional": true, "facet": true},
+			{"name": "has_image", "type": "bool", "optional": true, "facet": true},
+			{"name": "year", "type": "string", "optional": true, "facet": true},
+			{"name": "month", "type": "string", "optional": true, "facet": true},
+			{"name": "created_at", "type": "int64", "facet": false, "index": true},
+			{"name": "updated_at", "type": "int64", "facet": false, "index": true},
+		},
+		"default_sorting_field": "created_at",
+	}
+}
+
+// Run runs the conformance suite against repositories created by newRepository.
+// Each subtest uses its own collection, named so that runs against a shared
+// server do not collide; use a disposable server as collections are left behind.
+func Run(t *testing.T, newRepository Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo domain.SearchIndexRepository, collection string)
+	}{
+		{"CollectionIdempotency", testCollectionIdempotency},
+		{"UpsertReplaces", testUpsertReplaces},
+		{"DeleteMissing", testDeleteMissing},
+		{"Pagination", testPagination},
+		{"TaxonomyFilters", testTaxonomyFilters},
+		{"FacetCounts", testFacetCounts},
+		{"PrefixSearch", testPrefixSearch},
+		{"SortStability", testSortStability},
+		{"HighlightShape", testHighlightShape},
+		{"ExportCompleteness", testExportCompleteness},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepository(t)
+			collection := fmt.Sprintf("conformance_%d_%d", time.Now().UnixNano(), collectionSeq.Add(1))
+			if err := repo.CreateCollection(context.Background(), PostsSchema(collection)); err != nil {
+				t.Fatalf("Failed to create collection: %v", err)
+			}
+			tt.run(t, repo, collection)
+		})
+	}
+}
+
+// upsert indexes documents, failing the test on the first error
+func upsert(t *testing.T, repo domain.SearchIndexRepository, collection string, documents ...*domain.SearchDocument) {
+	t.Helper()
+	for _, document := range documents {
+		if err := repo.UpsertDocument(context.Background(), collection, document); err != nil {
+			t.Fatalf("Failed to upsert document %s: %v", document.ID, err)
+		}
+	}
+}
+
+// searchResults runs a query, failing the test on error
+func searchResults(t *testing.T, repo domain.SearchIndexRepository, collection, query string, params map[string]interface{}) *domain.SearchResults {
+	t.Helper()
+	results, err := repo.SearchDocuments(context.Background(), collection, query, params)
+	if err != nil {
+		t.Fatalf("Failed to search %q: %v", query, err)
+	}
+	if results == nil {
+		t.Fatalf("Expected results for %q, got nil", query)
+	}
+
+	for _, hit := range results.Hits {
+		if hit == nil || hit.Document == nil {
+			t.Fatalf("Expected hits to carry a document, got %+v", hit)
+		}
+	}
+	return results
+}
+
+// search runs a query and returns its hits, failing the test on error
+func search(t *testing.T, repo domain.SearchIndexRepository, collection, query string, params map[string]interface{}) []*domain.SearchHit {
+	t.Helper()
+	return searchResults(t, repo, collection, query, params).Hits
+}
+
+// export reads all documents keyed by id, failing the test on error
+func export(t *testing.T, repo domain.SearchIndexRepository, collection string) map[string]map[string]interface{} {
+	t.Helper()
+	results, err := repo.GetAllDocuments(context.Background(), collection)
+	if err != nil {
+		t.Fatalf("Failed to export documents: %v", err)
+	}
+
+	documents := map[string]map[string]interface{}{}
+	for _, result := range results {
+		document, ok := result.(map[string]interface{})
+		if !ok {
+			t.Fatalf("Expected exported documents to be maps, got %T", result)
+		}
+		id := documentID(t, document)
+		if _, duplicate := documents[id]; duplicate {
+			t.Errorf("Document %s exported twice", id)
+		}
+		documents[id] = document
+	}
+	return documents
+}
+
+// documentID returns the id of a result, which must be a string as in Typesense
+func documentID(t *testing.T, document map[string]interface{}) string {
+	t.Helper()
+	id, ok := document["id"].(string)
+	if !ok {
+		t.Fatalf("Expected id to be a string, got %T (%v)", document["id"], document["id"])
+	}
+	return id
+}
+
+// ids returns the ids of hits in order
+func ids(t *testing.T, hits []*domain.SearchHit) []string {
+	t.Helper()
+	result := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		result = append(result, documentID(t, hit.Document))
+	}
+	return result
+}
+
+// number converts a numeric field of a decoded document to int64
+func number(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+func testCollectionIdempotency(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	upsert(t, repo, collection, &domain.SearchDocument{ID: "1", Title: "Kept", Body: "body", CreatedAt: 1})
+
+	// Creating the collection again succeeds and keeps its documents
+	if err := repo.CreateCollection(context.Background(), PostsSchema(collection)); err != nil {
+		t.Fatalf("Expected creating an existing collection to succeed, got %v", err)
+	}
+	if documents := export(t, repo, collection); len(documents) != 1 {
+		t.Errorf("Expected 1 document after recreating the collection, got %d", len(documents))
+	}
+}
+
+func testUpsertReplaces(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	upsert(t, repo, collection,
+		&domain.SearchDocument{ID: "1", Title: "Original headline", Excerpt: "first", Body: "body", CreatedAt: 1, UpdatedAt: 1},
+		&domain.SearchDocument{ID: "1", Title: "Revised headline", Body: "body", CreatedAt: 1, UpdatedAt: 2},
+	)
+
+	documents := export(t, repo, collection)
+	if len(documents) != 1 {
+		t.Fatalf("Expected 1 document, got %d", len(documents))
+	}
+	document := documents["1"]
+	if document["title"] != "Revised headline" {
+		t.Errorf("Expected the replaced title, got %v", document["title"])
+	}
+	// The whole document is replaced, not merged
+	if excerpt, _ := document["excerpt"].(string); excerpt != "" {
+		t.Errorf("Expected the excerpt to be cleared, got %v", document["excerpt"])
+	}
+	if updatedAt, _ := number(document["updated_at"]); updatedAt != 2 {
+		t.Errorf("Expected updated_at 2, got %v", document["updated_at"])
+	}
+
+	if results := search(t, repo, collection, "original", map[string]interface{}{"query_by": "title"}); len(results) != 0 {
+		t.Errorf("Expected the old title to be unsearchable, got %v", ids(t, results))
+	}
+	if results := search(t, repo, collection, "revised", map[string]interface{}{"query_by": "title"}); len(results) != 1 {
+		t.Errorf("Expected the new title to be searchable, got %d results", len(results))
+	}
+}
+
+func testDeleteMissing(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	ctx := context.Background()
+	upsert(t, repo, collection, &domain.SearchDocument{ID: "1", Title: "Doomed", Body: "body", CreatedAt: 1})
+
+	if err := repo.DeleteDocument(ctx, collection, "404"); err != nil {
+		t.Errorf("Expected deleting a missing document to succeed, got %v", err)
+	}
+	if err := repo.DeleteDocument(ctx, collection, "1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := repo.DeleteDocument(ctx, collection, "1"); err != nil {
+		t.Errorf("Expected deleting a document twice to succeed, got %v", err)
+	}
+
+	if documents := export(t, repo, collection); len(documents) != 0 {
+		t.Errorf("Expected no documents, got %d", len(documents))
+	}
+	if results := search(t, repo, collection, "doomed", map[string]interface{}{"query_by": "title"}); len(results) != 0 {
+		t.Errorf("Expected deleted document to be unsearchable, got %v", ids(t, results))
+	}
+}
+
+func testPagination(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	for i := 1; i <= 7; i++ {
+		upsert(t, repo, collection, &domain.SearchDocument{
+			ID: fmt.Sprint(i), Title: fmt.Sprintf("Post %d", i), Body: "paged", CreatedAt: int64(i * 100),
+		})
+	}
+
+	var seen []string
+	for page, expected := range []int{3, 3, 1, 0} {
+		results := searchResults(t, repo, collection, "*", map[string]interface{}{
+			"query_by": "title,excerpt,body",
+			"sort_by":  "created_at:desc",
+			"page":     page + 1,
+			"per_page": 3,
+		})
+		if len(results.Hits) != expected {
+			t.Errorf("Expected %d results on page %d, got %d", expected, page+1, len(results.Hits))
+		}
+		// The total counts every match, not just the current page
+		if results.Found != 7 {
+			t.Errorf("Expected 7 found on page %d, got %d", page+1, results.Found)
+		}
+		if results.Page != page+1 || results.PerPage != 3 {
+			t.Errorf("Expected page %d of 3 per page, got page %d of %d", page+1, results.Page, results.PerPage)
+		}
+		if results.OutOf != 0 && results.OutOf != 7 {
+			t.Errorf("Expected out_of to be 7 when reported, got %d", results.OutOf)
+		}
+		seen = append(seen, ids(t, results.Hits)...)
+	}
+
+	// Filtering narrows the total as well as the hits
+	results := searchResults(t, repo, collection, "*", map[string]interface{}{
+		"query_by":  "title,excerpt,body",
+		"filter_by": "created_at:>=500",
+		"per_page":  2,
+	})
+	if results.Found != 3 || len(results.Hits) != 2 {
+		t.Errorf("Expected 2 of 3 filtered results, got %d of %d", len(results.Hits), results.Found)
+	}
+	if results.Page != 1 || results.PerPage != 2 {
+		t.Errorf("Expected the default page 1, got page %d of %d", results.Page, results.PerPage)
+	}
+
+	expected := []string{"7", "6", "5", "4", "3", "2", "1"}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Errorf("Expected pages in created_at order %v, got %v", expected, seen)
+	}
+}
+
+func testTaxonomyFilters(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	upsert(t, repo, collection,
+		&domain.SearchDocument{ID: "1", Title: "One", Body: "body", Category: "Deep Dives", Tags: []string{"go", "cdc"}, HasImage: true, CreatedAt: 1},
+		&domain.SearchDocument{ID: "2", Title: "Two", Body: "body", Category: "News", Tags: []string{"go"}, CreatedAt: 2},
+		&domain.SearchDocument{ID: "3", Title: "Three", Body: "body", Category: "Deep Dives", HasImage: true, CreatedAt: 3},
+		&domain.SearchDocument{ID: "4", Title: "Four", Body: "body", Category: `Q&A && notes: C:\temp`, CreatedAt: 4},
+	)
+
+	// Filters as the search service passes them: exact, case-sensitive values
+	equal := func(field string, value interface{}) domain.SearchFilter {
+		return domain.SearchFilter{Field: field, Op: domain.FilterEqual, Value: value}
+	}
+	tests := []struct {
+		filters  []domain.SearchFilter
+		expected []string
+	}{
+		{[]domain.SearchFilter{equal("has_image", true)}, []string{"3", "1"}},
+		{[]domain.SearchFilter{equal("has_image", false)}, []string{"4", "2"}},
+		{[]domain.SearchFilter{equal("tags", "go")}, []string{"2", "1"}},
+		{[]domain.SearchFilter{equal("tags", "go"), equal("tags", "cdc")}, []string{"1"}},
+		{[]domain.SearchFilter{equal("category", "Deep Dives")}, []string{"3", "1"}},
+		{[]domain.SearchFilter{equal("category", "Deep Dives"), equal("has_image", true), {Field: "created_at", Op: domain.FilterBelow, Value: int64(3)}}, []string{"1"}},
+		{[]domain.SearchFilter{{Field: "created_at", Op: domain.FilterAtLeast, Value: int64(2)}, {Field: "created_at", Op: domain.FilterBelow, Value: int64(4)}}, []string{"3", "2"}},
+		{[]domain.SearchFilter{equal("category", "deep dives")}, []string{}},
+		// Values are never parsed as filter syntax
+		{[]domain.SearchFilter{equal("category", `Q&A && notes: C:\temp`)}, []string{"4"}},
+		{[]domain.SearchFilter{equal("category", "Q&A")}, []string{}},
+	}
+	for _, tt := range tests {
+		hits := search(t, repo, collection, "*", map[string]interface{}{
+			"query_by": "title,excerpt,body",
+			"sort_by":  "created_at:desc",
+			"filters":  tt.filters,
+		})
+		if got := ids(t, hits); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Expected %v for %+v, got %v", tt.expected, tt.filters, got)
+		}
+	}
+}
+
+func testFacetCounts(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	upsert(t, repo, collection,
+		&domain.SearchDocument{ID: "1", Title: "One", Body: "go", Author: "Ada", Category: "News", Tags: []string{"go", "cdc"}, HasImage: true, Year: "2024", Month: "2024-03", CreatedAt: 1},
+		&domain.SearchDocument{ID: "2", Title: "Two", Body: "go", Author: "Ada", Category: "News", Tags: []string{"go"}, Year: "2024", Month: "2024-05", CreatedAt: 2},
+		&domain.SearchDocument{ID: "3", Title: "Three", Body: "go", Author: "Grace", Category: "Deep Dives", HasImage: true, Year: "2023", Month: "2023-11", CreatedAt: 3},
+		&domain.SearchDocument{ID: "4", Title: "Four", Body: "other", Author: "Grace", Tags: []string{"sql"}, Year: "2022", Month: "2022-01", CreatedAt: 4},
+	)
+
+	results := searchResults(t, repo, collection, "go", map[string]interface{}{
+		"query_by": "title,excerpt,body",
+		"facet_by": "tags,author,year,has_image",
+		"per_page": 1,
+	})
+
+	// Counts cover every match, not just the page, most frequent first
+	expected := []domain.FacetCounts{
+		{Field: "tags", Counts: []domain.FacetValueCount{{Value: "go", Count: 2}, {Value: "cdc", Count: 1}}},
+		{Field: "author", Counts: []domain.FacetValueCount{{Value: "Ada", Count: 2}, {Value: "Grace", Count: 1}}},
+		{Field: "year", Counts: []domain.FacetValueCount{{Value: "2024", Count: 2}, {Value: "2023", Count: 1}}},
+		{Field: "has_image", Counts: []domain.FacetValueCount{{Value: "true", Count: 2}, {Value: "false", Count: 1}}},
+	}
+	if !reflect.DeepEqual(results.Facets, expected) {
+		t.Errorf("Expected facets %+v, got %+v", expected, results.Facets)
+	}
+
+	// Facet values filter back to their posts
+	hits := search(t, repo, collection, "*", map[string]interface{}{
+		"query_by": "title,excerpt,body",
+		"sort_by":  "created_at:desc",
+		"filters": []domain.SearchFilter{
+			{Field: "year", Op: domain.FilterEqual, Value: "2024"},
+			{Field: "author", Op: domain.FilterEqual, Value: "Ada"},
+			{Field: "month", Op: domain.FilterEqual, Value: "2024-05"},
+		},
+	})
+	if got := ids(t, hits); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Errorf("Expected post 2 for its facet values, got %v", got)
+	}
+}
+
+func testPrefixSearch(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	upsert(t, repo, collection,
+		&domain.SearchDocument{ID: "1", Title: "Change Data Capture", Body: "one", CreatedAt: 1},
+		&domain.SearchDocument{ID: "2", Title: "Changelog", Body: "two", CreatedAt: 2},
+		&domain.SearchDocument{ID: "3", Title: "Other post", Body: "change", CreatedAt: 3},
+	)
+
+	// The last word is completed, and only titles are searched
+	hits := search(t, repo, collection, "chang", map[string]interface{}{
+		"query_by": "title",
+		"sort_by":  "created_at:desc",
+		"prefix":   "true",
+	})
+	if got := ids(t, hits); !reflect.DeepEqual(got, []string{"2", "1"}) {
+		t.Errorf("Expected posts 2 and 1 for the prefix, got %v", got)
+	}
+
+	hits = search(t, repo, collection, "data cap", map[string]interface{}{
+		"query_by":  "title",
+		"prefix":    "true",
+		"num_typos": "1",
+	})
+	if got := ids(t, hits); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Expected post 1 for a partly typed title, got %v", got)
+	}
+}
+
+func testSortStability(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	// Every document ties on the sort field
+	for i := 1; i <= 6; i++ {
+		upsert(t, repo, collection, &domain.SearchDocument{
+			ID: fmt.Sprint(i), Title: "Same day", Body: "tie", CreatedAt: 1000,
+		})
+	}
+
+	pages := func() []string {
+		var seen []string
+		for page := 1; page <= 3; page++ {
+			results := search(t, repo, collection, "*", map[string]interface{}{
+				"query_by": "title,excerpt,body",
+				"sort_by":  "_text_match:desc,created_at:desc",
+				"page":     page,
+				"per_page": 2,
+			})
+			seen = append(seen, ids(t, results)...)
+		}
+		return seen
+	}
+
+	first := pages()
+	sorted := append([]string(nil), first...)
+	sort.Strings(sorted)
+	if !reflect.DeepEqual(sorted, []string{"1", "2", "3", "4", "5", "6"}) {
+		t.Errorf("Expected every tied document exactly once across pages, got %v", first)
+	}
+	if second := pages(); !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected the same order on every query, got %v then %v", first, second)
+	}
+}
+
+func testHighlightShape(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	upsert(t, repo, collection,
+		&domain.SearchDocument{ID: "1", Title: "Change data capture", Excerpt: "Short summary", Body: "Streaming rows with change data capture", CreatedAt: 1},
+		&domain.SearchDocument{ID: "2", Title: "Unrelated", Body: "Nothing to see", CreatedAt: 2},
+	)
+
+	results := search(t, repo, collection, "capture", map[string]interface{}{
+		"query_by": "title,excerpt,body",
+		"sort_by":  "_text_match:desc,created_at:desc",
+		"page":     1,
+		"per_page": 10,
+	})
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	result := results[0]
+
+	if result.Score <= 0 {
+		t.Errorf("Expected a positive score, got %v", result.Score)
+	}
+	for _, field := range []string{"_text_match", "highlights"} {
+		if _, ok := result.Document[field]; ok {
+			t.Errorf("Expected %s to be kept out of the document, got %v", field, result.Document[field])
+		}
+	}
+
+	for _, field := range []string{"title", "body"} {
+		snippets := result.Highlights[field]
+		if len(snippets) == 0 {
+			t.Errorf("Expected %s highlights as a non-empty list, got %v", field, snippets)
+			continue
+		}
+		for _, snippet := range snippets {
+			if !strings.Contains(snippet, "<mark>") || !strings.Contains(strings.ToLower(snippet), "capture</mark>") {
+				t.Errorf("Expected %s snippet to mark the match, got %v", field, snippet)
+			}
+		}
+	}
+	if _, ok := result.Highlights["excerpt"]; ok {
+		t.Errorf("Expected no highlights for a field without a match, got %v", result.Highlights["excerpt"])
+	}
+}
+
+func testExportCompleteness(t *testing.T, repo domain.SearchIndexRepository, collection string) {
+	const count = 25
+	for i := 1; i <= count; i++ {
+		upsert(t, repo, collection, &domain.SearchDocument{
+			ID:        fmt.Sprint(i),
+			Title:     fmt.Sprintf("Title %d", i),
+			Image:     fmt.Sprintf("/images/%d.png", i),
+			Excerpt:   fmt.Sprintf("Excerpt %d", i),
+			Body:      fmt.Sprintf("Body %d", i),
+			CreatedAt: int64(i),
+			UpdatedAt: int64(i + 1),
+		})
+	}
+
+	documents := export(t, repo, collection)
+	if len(documents) != count {
+		t.Fatalf("Expected %d documents, got %d", count, len(documents))
+	}
+
+	for i := 1; i <= count; i++ {
+		document, ok := documents[fmt.Sprint(i)]
+		if !ok {
+			t.Errorf("Expected document %d to be exported", i)
+			continue
+		}
+		for field, expected := range map[string]string{
+			"title":   fmt.Sprintf("Title %d", i),
+			"image":   fmt.Sprintf("/images/%d.png", i),
+			"excerpt": fmt.Sprintf("Excerpt %d", i),
+			"body":    fmt.Sprintf("Body %d", i),
+		} {
+			if document[field] != expected {
+				t.Errorf("Expected document %d %s %q, got %v", i, field, expected, document[field])
+			}
+		}
+		if createdAt, _ := number(document["created_at"]); createdAt != int64(i) {
+			t.Errorf("Expected document %d created_at %d, got %v", i, i, document["created_at"])
+		}
+		if updatedAt, _ := number(document["updated_at"]); updatedAt != int64(i+1) {
+			t.Errorf("Expected document %d updated_at %d, got %v", i, i+1, document["updated_at"])
+		}
+	}
+}
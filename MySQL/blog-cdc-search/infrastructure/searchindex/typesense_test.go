@@ -1,7 +1,29 @@
 package searchindex
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/tlsconfig"
 )
 
 func TestNewTypesenseRepository(t *testing.T) {
@@ -84,3 +106,920 @@ func TestTypesenseRepository_ensurePostsCollection(t *testing.T) {
 		}
 	}
 }
+
+func TestWithTimeout(t *testing.T) {
+	ctx, cancel := withTimeout(context.Background(), 0)
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected no deadline when timeout is zero")
+	}
+
+	ctx, cancel = withTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("Expected a deadline when timeout is set")
+	}
+	if time.Until(deadline) > time.Second {
+		t.Errorf("Expected deadline within 1s, got %v", time.Until(deadline))
+	}
+}
+
+func TestTypesenseRepository_CircuitBreakerOpens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "unavailable"}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{
+		APIKey:                  "test-key",
+		BreakerFailureThreshold: 2,
+		BreakerOpenTimeout:      time.Minute,
+	})
+
+	// Connect fails but counts towards the threshold
+	if err := repo.Connect(context.Background()); err == nil {
+		t.Fatal("Expected connect to fail against an unavailable server")
+	}
+
+	err := repo.UpsertDocument(context.Background(), "posts", map[string]interface{}{"id": "1"})
+	if err == nil || errors.Is(err, domain.ErrServiceUnavailable) {
+		t.Fatalf("Expected a plain upstream error before the breaker opens, got: %v", err)
+	}
+
+	err = repo.UpsertDocument(context.Background(), "posts", map[string]interface{}{"id": "1"})
+	if !errors.Is(err, domain.ErrServiceUnavailable) {
+		t.Errorf("Expected ErrServiceUnavailable once the breaker is open, got: %v", err)
+	}
+}
+
+func TestTypesenseRepository_ClientErrorsKeepBreakerClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{
+		APIKey:                  "test-key",
+		BreakerFailureThreshold: 1,
+	})
+	repo.Connect(context.Background())
+
+	for i := 0; i < 3; i++ {
+		err := repo.UpsertDocument(context.Background(), "missing", map[string]interface{}{"id": "42"})
+		if err == nil {
+			t.Fatal("Expected not found error")
+		}
+		if errors.Is(err, domain.ErrServiceUnavailable) {
+			t.Fatalf("Expected 404 responses not to open the breaker, got: %v", err)
+		}
+	}
+}
+
+// newTestTypesenseRepository points a repository at a test server
+func newTestTypesenseRepository(t *testing.T, serverURL string, config TypesenseConfig) *TypesenseRepository {
+	t.Helper()
+
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatalf("Failed to parse server port: %v", err)
+	}
+
+	config.Host = u.Hostname()
+	config.Port = port
+	return NewTypesenseRepository(config)
+}
+
+func TestTypesenseRepository_ConnectTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	// Trust the test server's self-signed certificate
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{
+		APIKey: "test-key",
+		TLS:    tlsconfig.Config{Enabled: true, CAFile: caFile},
+	})
+
+	if got := repo.config.serverURL(); !strings.HasPrefix(got, "https://") {
+		t.Errorf("Expected https server URL, got %s", got)
+	}
+
+	if err := repo.Connect(context.Background()); err != nil {
+		t.Fatalf("Expected TLS connection to succeed, got %v", err)
+	}
+}
+
+func TestTypesenseConfig_NodeURLs(t *testing.T) {
+	config := TypesenseConfig{Host: "localhost", Port: 8108}
+	if nodes := config.nodeURLs(); len(nodes) != 1 || nodes[0] != "http://localhost:8108" {
+		t.Errorf("Expected Host and Port as the only node, got %v", nodes)
+	}
+
+	config.Nodes = []string{"ts-1:8108", "https://ts-2:443"}
+	config.TLS.Enabled = true
+	nodes := config.nodeURLs()
+	expected := []string{"https://ts-1:8108", "https://ts-2:443"}
+	for i := range expected {
+		if nodes[i] != expected[i] {
+			t.Errorf("Expected node %d to be %s, got %s", i, expected[i], nodes[i])
+		}
+	}
+
+	config.NearestNode = "ts-local:8108"
+	if nearest := config.clientConfig().NearestNode; nearest != "https://ts-local:8108" {
+		t.Errorf("Expected nearest node https://ts-local:8108, got %s", nearest)
+	}
+}
+
+func TestTypesenseRepository_FailsOverToHealthyNode(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer up.Close()
+
+	repo := NewTypesenseRepository(TypesenseConfig{
+		Nodes:         []string{down.URL, up.URL},
+		APIKey:        "test-key",
+		RetryInterval: time.Millisecond,
+	})
+
+	if err := repo.Connect(context.Background()); err != nil {
+		t.Fatalf("Expected request to fail over to the healthy node, got %v", err)
+	}
+}
+
+func TestTypesenseRepository_DeleteMissingDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Could not find a document with id: 42"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{APIKey: "test-key"})
+	repo.Connect(context.Background())
+
+	if err := repo.DeleteDocument(context.Background(), "posts", "42"); err != nil {
+		t.Errorf("Expected deleting a missing document to succeed, got %v", err)
+	}
+}
+
+func TestTypesenseRepository_MigratesExistingCollection(t *testing.T) {
+	fake := &fakeTypesense{collections: map[string]*fakeTypesenseCollection{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{APIKey: "test-key"})
+	repo.Connect(context.Background())
+
+	old := map[string]interface{}{
+		"name": "posts",
+		"fields": []map[string]interface{}{
+			{"name": "title", "type": "string", "facet": false, "index": true},
+			{"name": "updated_at", "type": "int64", "facet": false, "index": false},
+		},
+	}
+	if err := repo.CreateCollection(context.Background(), old); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	current := map[string]interface{}{
+		"name": "posts",
+		"fields": []map[string]interface{}{
+			{"name": "title", "type": "string", "facet": false, "index": true},
+			{"name": "tags", "type": "string[]", "optional": true, "facet": true},
+			{"name": "updated_at", "type": "int64", "facet": false, "index": true},
+		},
+	}
+	// Migrating twice leaves a single definition of each field
+	for i := 0; i < 2; i++ {
+		if err := repo.CreateCollection(context.Background(), current); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	fields := map[string]map[string]interface{}{}
+	for _, field := range fake.collections["posts"].schema["fields"].([]interface{}) {
+		field := field.(map[string]interface{})
+		fields[field["name"].(string)] = field
+	}
+	if len(fields) != 3 || len(fake.collections["posts"].schema["fields"].([]interface{})) != 3 {
+		t.Fatalf("Expected 3 fields, got %v", fake.collections["posts"].schema["fields"])
+	}
+	if fields["tags"]["facet"] != true {
+		t.Errorf("Expected tags to be added as a facet, got %v", fields["tags"])
+	}
+	if fields["updated_at"]["index"] != true {
+		t.Errorf("Expected updated_at to be reindexed, got %v", fields["updated_at"])
+	}
+}
+
+func TestTypesenseRepository_MigratesVectorDimensions(t *testing.T) {
+	fake := &fakeTypesense{collections: map[string]*fakeTypesenseCollection{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{APIKey: "test-key"})
+	repo.Connect(context.Background())
+
+	schema := func(dimensions int) map[string]interface{} {
+		return map[string]interface{}{
+			"name": "posts",
+			"fields": []map[string]interface{}{
+				{"name": "title", "type": "string"},
+				{"name": "embedding", "type": "float[]", "num_dim": dimensions, "optional": true},
+			},
+		}
+	}
+	for _, dimensions := range []int{3, 3, 4} {
+		if err := repo.CreateCollection(context.Background(), schema(dimensions)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	fields := fake.collections["posts"].schema["fields"].([]interface{})
+	if len(fields) != 2 {
+		t.Fatalf("Expected 2 fields, got %v", fields)
+	}
+	if embedding := fields[1].(map[string]interface{}); embedding["num_dim"] != float64(4) {
+		t.Errorf("Expected the embedding field to be recreated with 4 dimensions, got %v", embedding)
+	}
+}
+
+func TestTypesenseRepository_HybridSearch(t *testing.T) {
+	var request struct {
+		Searches []map[string]interface{} `json:"searches"`
+	}
+	searchError := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/multi_search" {
+			// Connect checks the collections
+			writeJSON(w, http.StatusOK, []interface{}{})
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		if searchError != "" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"results": []map[string]interface{}{{"code": 400, "error": searchError}},
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"results": []map[string]interface{}{{
+				"found": 1,
+				"hits": []map[string]interface{}{{
+					"document":   map[string]interface{}{"id": "7", "title": "Goroutines"},
+					"text_match": 42,
+				}},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{APIKey: "test-key"})
+	repo.Connect(context.Background())
+
+	params := map[string]interface{}{
+		"query_by":  "title,excerpt,body",
+		"filter_by": "has_image:=true",
+		"vector":    []float32{0.5, -0.25, 1},
+		"alpha":     0.3,
+	}
+	results, err := repo.SearchDocuments(context.Background(), "posts", "concurrency", params)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(request.Searches) != 1 {
+		t.Fatalf("Expected one search, got %v", request.Searches)
+	}
+	search := request.Searches[0]
+	expected := map[string]interface{}{
+		"collection":     "posts",
+		"q":              "concurrency",
+		"query_by":       "title,excerpt,body",
+		"filter_by":      "has_image:=true",
+		"vector_query":   "embedding:([0.5,-0.25,1], alpha: 0.3)",
+		"exclude_fields": "embedding",
+	}
+	for key, value := range expected {
+		if search[key] != value {
+			t.Errorf("Expected %s %v, got %v", key, value, search[key])
+		}
+	}
+	if results.Found != 1 || len(results.Hits) != 1 || results.Hits[0].Document["id"] != "7" || results.Hits[0].Score != 42 {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	// Without a query only the nearest posts are searched for
+	if _, err := repo.SearchDocuments(context.Background(), "posts", "*", map[string]interface{}{"vector": []float32{1, 0}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if query := request.Searches[0]["vector_query"]; query != "embedding:([1,0])" {
+		t.Errorf("Expected a vector query without alpha, got %v", query)
+	}
+
+	// Errors of the search itself are reported, without opening the breaker
+	searchError = "Field `embedding` not found"
+	if _, err := repo.SearchDocuments(context.Background(), "posts", "concurrency", params); err == nil || !strings.Contains(err.Error(), "embedding") {
+		t.Errorf("Expected the search error, got %v", err)
+	}
+	if repo.breaker.State().String() != "closed" {
+		t.Errorf("Expected the breaker to stay closed, got %s", repo.breaker.State())
+	}
+}
+
+func TestTypesenseRepository_SyncSynonymsAndCurations(t *testing.T) {
+	fake := &fakeTypesense{collections: map[string]*fakeTypesenseCollection{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{APIKey: "test-key"})
+	repo.Connect(context.Background())
+	ctx := context.Background()
+
+	if err := repo.CreateCollection(ctx, map[string]interface{}{"name": "posts", "fields": []map[string]interface{}{{"name": "title", "type": "string"}}}); err != nil {
+		t.Fatalf("Failed to create collection: %v", err)
+	}
+	rules := fake.collections["posts"].rules
+	rules["synonyms"]["stale"] = map[string]interface{}{"id": "stale", "synonyms": []string{"a", "b"}}
+
+	synonyms := []*domain.Synonym{
+		{ID: 1, Synonyms: []string{"cdc", "change data capture"}},
+		{ID: 2, Root: "db", Synonyms: []string{"database"}},
+	}
+	if err := repo.SyncSynonyms(ctx, "posts", synonyms); err != nil {
+		t.Fatalf("SyncSynonyms failed: %v", err)
+	}
+	if len(rules["synonyms"]) != 2 || rules["synonyms"]["stale"] != nil {
+		t.Fatalf("Expected exactly the two synced synonyms, got %v", rules["synonyms"])
+	}
+	if rules["synonyms"]["synonym-1"]["root"] != nil || rules["synonyms"]["synonym-2"]["root"] != "db" {
+		t.Errorf("Expected only the one-way synonym to have a root, got %v", rules["synonyms"])
+	}
+
+	curations := []*domain.Curation{{ID: 3, Query: "cdc", Match: domain.CurationMatchContains, Pinned: []int{7, 4}, Hidden: []int{9}}}
+	if err := repo.SyncCurations(ctx, "posts", curations); err != nil {
+		t.Fatalf("SyncCurations failed: %v", err)
+	}
+	override, _ := json.Marshal(rules["overrides"]["curation-3"])
+	expected := `{"excludes":[{"id":"9"}],"id":"curation-3","includes":[{"id":"7","position":1},{"id":"4","position":2}],"rule":{"match":"contains","query":"cdc"}}`
+	if string(override) != expected {
+		t.Errorf("Unexpected override: %s", override)
+	}
+
+	// Deleted definitions are removed from the index
+	if err := repo.SyncCurations(ctx, "posts", nil); err != nil {
+		t.Fatalf("SyncCurations failed: %v", err)
+	}
+	if len(rules["overrides"]) != 0 {
+		t.Errorf("Expected no overrides left, got %v", rules["overrides"])
+	}
+}
+
+func TestTypesenseRepository_SearchKeys(t *testing.T) {
+	fake := &fakeTypesense{collections: map[string]*fakeTypesenseCollection{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{APIKey: "test-key"})
+	repo.Connect(context.Background())
+	ctx := context.Background()
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	key, err := repo.CreateSearchKey(ctx, "blog", []string{"posts"}, expiresAt)
+	if err != nil {
+		t.Fatalf("CreateSearchKey failed: %v", err)
+	}
+	if key.ID != 1 || key.Value != "search-key-1" || !key.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("Unexpected key: %+v", key)
+	}
+	if actions := fake.keys[1]["actions"].([]interface{}); len(actions) != 1 || actions[0] != "documents:search" {
+		t.Errorf("Expected a search-only key, got actions %v", actions)
+	}
+
+	keys, err := repo.ListSearchKeys(ctx)
+	if err != nil || len(keys) != 1 || keys[0].Description != "blog" || keys[0].Value != "" {
+		t.Fatalf("Expected the key without its value, got %+v, %v", keys, err)
+	}
+
+	// Scoped keys are the parent's HMAC digest, its prefix and the params
+	scoped, err := repo.ScopeSearchKey(key, map[string]interface{}{"filter_by": "language:=`en`"})
+	if err != nil {
+		t.Fatalf("ScopeSearchKey failed: %v", err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(scoped)
+	if !strings.HasSuffix(string(raw), "sear{\"filter_by\":\"language:=`en`\"}") {
+		t.Errorf("Expected the parent prefix and params in the scoped key, got %s", raw)
+	}
+
+	if err := repo.DeleteSearchKey(ctx, 1); err != nil {
+		t.Fatalf("DeleteSearchKey failed: %v", err)
+	}
+	if err := repo.DeleteSearchKey(ctx, 1); err != nil {
+		t.Errorf("Expected deleting a missing key to succeed, got %v", err)
+	}
+}
+
+func TestTypesenseRepository_SearchEnvelope(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"found":          12,
+			"out_of":         40,
+			"page":           2,
+			"search_time_ms": 7,
+			"facet_counts": []map[string]interface{}{{
+				"field_name": "tags",
+				"counts": []map[string]interface{}{
+					{"value": "go", "count": 9},
+					{"value": "sql", "count": 3},
+				},
+			}},
+			"hits": []map[string]interface{}{{
+				"document":   map[string]interface{}{"id": "1", "title": "Go"},
+				"text_match": 100,
+			}},
+		})
+	}))
+	defer server.Close()
+
+	repo := newTestTypesenseRepository(t, server.URL, TypesenseConfig{APIKey: "test-key"})
+	repo.Connect(context.Background())
+
+	results, err := repo.SearchDocuments(context.Background(), "posts", "go", map[string]interface{}{
+		"query_by": "title",
+		"facet_by": "tags",
+		"page":     2,
+		"per_page": 5,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if query.Get("facet_by") != "tags" || query.Get("page") != "2" || query.Get("per_page") != "5" {
+		t.Errorf("Unexpected search parameters: %v", query)
+	}
+
+	if results.Found != 12 || results.OutOf != 40 || results.Page != 2 || results.PerPage != 5 || results.SearchTimeMS != 7 {
+		t.Errorf("Unexpected totals: %+v", results)
+	}
+	if len(results.Hits) != 1 || results.Hits[0].Score != 100 || results.Hits[0].Document["title"] != "Go" {
+		t.Errorf("Unexpected hits: %+v", results.Hits)
+	}
+	expected := []domain.FacetCounts{{Field: "tags", Counts: []domain.FacetValueCount{{Value: "go", Count: 9}, {Value: "sql", Count: 3}}}}
+	if !reflect.DeepEqual(results.Facets, expected) {
+		t.Errorf("Expected facets %+v, got %+v", expected, results.Facets)
+	}
+}
+
+// fakeTypesense is an in-memory stand-in for the parts of the Typesense API
+// used by the repository. Searches match whole lowercase tokens and score a
+// hit by the number of query tokens it contains.
+type fakeTypesense struct {
+	mu          sync.Mutex
+	collections map[string]*fakeTypesenseCollection
+	// keys holds the API keys by ID
+	keys map[int64]map[string]interface{}
+}
+
+type fakeTypesenseCollection struct {
+	schema    map[string]interface{}
+	documents map[string]map[string]interface{}
+	// seq records insertion order, which breaks ties as in Typesense
+	seq     map[string]int
+	nextSeq int
+	// rules holds synonyms and overrides by kind, then id
+	rules map[string]map[string]map[string]interface{}
+}
+
+// serveKeys creates, lists and deletes API keys
+func (f *fakeTypesense) serveKeys(w http.ResponseWriter, r *http.Request, path []string, body []byte) {
+	if f.keys == nil {
+		f.keys = map[int64]map[string]interface{}{}
+	}
+
+	switch {
+	case len(path) == 0 && r.Method == http.MethodPost:
+		var key map[string]interface{}
+		json.Unmarshal(body, &key)
+		id := int64(len(f.keys) + 1)
+		key["id"] = id
+		key["value"] = fmt.Sprintf("search-key-%d", id)
+		f.keys[id] = key
+		writeJSON(w, http.StatusCreated, key)
+	case len(path) == 0 && r.Method == http.MethodGet:
+		keys := []map[string]interface{}{}
+		for _, key := range f.keys {
+			listed := map[string]interface{}{}
+			for name, value := range key {
+				listed[name] = value
+			}
+			listed["value_prefix"] = key["value"].(string)[:4]
+			delete(listed, "value")
+			keys = append(keys, listed)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+	case len(path) == 1 && r.Method == http.MethodDelete:
+		id, _ := strconv.ParseInt(path[0], 10, 64)
+		if _, ok := f.keys[id]; !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Could not find api key."})
+			return
+		}
+		delete(f.keys, id)
+		writeJSON(w, http.StatusOK, map[string]int64{"id": id})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	}
+}
+
+// fakeTypesenseToken matches the words the fake tokenizes on
+var fakeTypesenseToken = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+func newFakeTypesense(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(&fakeTypesense{collections: map[string]*fakeTypesenseCollection{}})
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeTypesense) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	if parts[0] == "health" {
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+		return
+	}
+	if parts[0] == "keys" {
+		f.serveKeys(w, r, parts[1:], body)
+		return
+	}
+	if parts[0] != "collections" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			collections := []map[string]interface{}{}
+			for _, collection := range f.collections {
+				collections = append(collections, collection.schema)
+			}
+			writeJSON(w, http.StatusOK, collections)
+		case http.MethodPost:
+			var schema map[string]interface{}
+			json.Unmarshal(body, &schema)
+			name, _ := schema["name"].(string)
+			if _, exists := f.collections[name]; exists {
+				writeJSON(w, http.StatusConflict, map[string]string{"message": "A collection with name `" + name + "` already exists."})
+				return
+			}
+			f.collections[name] = &fakeTypesenseCollection{
+				schema:    schema,
+				documents: map[string]map[string]interface{}{},
+				seq:       map[string]int{},
+				rules:     map[string]map[string]map[string]interface{}{"synonyms": {}, "overrides": {}},
+			}
+			writeJSON(w, http.StatusCreated, schema)
+		}
+		return
+	}
+
+	collection, ok := f.collections[parts[1]]
+	if ok && len(parts) == 2 && r.Method == http.MethodPatch {
+		var update struct {
+			Fields []map[string]interface{} `json:"fields"`
+		}
+		json.Unmarshal(body, &update)
+		fields, _ := collection.schema["fields"].([]interface{})
+		for _, change := range update.Fields {
+			if drop, _ := change["drop"].(bool); drop {
+				kept := []interface{}{}
+				for _, field := range fields {
+					if field.(map[string]interface{})["name"] != change["name"] {
+						kept = append(kept, field)
+					}
+				}
+				fields = kept
+				continue
+			}
+			fields = append(fields, change)
+		}
+		collection.schema["fields"] = fields
+		writeJSON(w, http.StatusOK, update)
+		return
+	}
+	if ok && len(parts) >= 3 && (parts[2] == "synonyms" || parts[2] == "overrides") {
+		collection.serveRules(w, r, parts[2], parts[3:], body)
+		return
+	}
+	if !ok || len(parts) < 3 || parts[2] != "documents" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodPost:
+		var document map[string]interface{}
+		json.Unmarshal(body, &document)
+		id, _ := document["id"].(string)
+		if _, exists := collection.seq[id]; !exists {
+			collection.nextSeq++
+			collection.seq[id] = collection.nextSeq
+		}
+		collection.documents[id] = document
+		writeJSON(w, http.StatusCreated, document)
+
+	case len(parts) == 4 && parts[3] == "search":
+		f.search(w, r, collection)
+
+	case len(parts) == 4 && parts[3] == "export":
+		w.Header().Set("Content-Type", "text/plain")
+		for _, id := range collection.ids() {
+			line, _ := json.Marshal(collection.documents[id])
+			w.Write(append(line, '\n'))
+		}
+
+	case len(parts) == 4 && r.Method == http.MethodDelete:
+		document, exists := collection.documents[parts[3]]
+		if !exists {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Could not find a document with id: " + parts[3]})
+			return
+		}
+		delete(collection.documents, parts[3])
+		delete(collection.seq, parts[3])
+		writeJSON(w, http.StatusOK, document)
+
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	}
+}
+
+// serveRules lists, upserts and deletes the synonyms or overrides of a collection
+func (c *fakeTypesenseCollection) serveRules(w http.ResponseWriter, r *http.Request, kind string, id []string, body []byte) {
+	rules := c.rules[kind]
+	switch {
+	case len(id) == 0 && r.Method == http.MethodGet:
+		list := []map[string]interface{}{}
+		for _, rule := range rules {
+			list = append(list, rule)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{kind: list})
+
+	case len(id) == 1 && r.Method == http.MethodPut:
+		var rule map[string]interface{}
+		json.Unmarshal(body, &rule)
+		rule["id"] = id[0]
+		rules[id[0]] = rule
+		writeJSON(w, http.StatusOK, rule)
+
+	case len(id) == 1 && r.Method == http.MethodDelete:
+		if _, exists := rules[id[0]]; !exists {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+			return
+		}
+		delete(rules, id[0])
+		writeJSON(w, http.StatusOK, map[string]string{"id": id[0]})
+
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	}
+}
+
+// ids returns document ids in insertion order
+func (c *fakeTypesenseCollection) ids() []string {
+	ids := make([]string, 0, len(c.documents))
+	for id := range c.documents {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return c.seq[ids[i]] < c.seq[ids[j]] })
+	return ids
+}
+
+func (f *fakeTypesense) search(w http.ResponseWriter, r *http.Request, collection *fakeTypesenseCollection) {
+	params := r.URL.Query()
+	page, _ := strconv.Atoi(params.Get("page"))
+	perPage, _ := strconv.Atoi(params.Get("per_page"))
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 10
+	}
+
+	// With prefix=true the last query token also matches words it starts
+	tokens := map[string]bool{}
+	var prefix string
+	if q := params.Get("q"); q != "*" {
+		for _, token := range fakeTypesenseToken.FindAllString(strings.ToLower(q), -1) {
+			tokens[token] = true
+			prefix = token
+		}
+	}
+	if params.Get("prefix") != "true" {
+		prefix = ""
+	}
+	fields := strings.Split(params.Get("query_by"), ",")
+	filters := fakeTypesenseClauses(params.Get("filter_by"))
+
+	type fakeHit struct {
+		id         string
+		score      int64
+		highlights []map[string]interface{}
+	}
+	var hits []fakeHit
+	for _, id := range collection.ids() {
+		document := collection.documents[id]
+		if !fakeTypesenseFilter(document, filters) {
+			continue
+		}
+		hit := fakeHit{id: id}
+		matched := map[string]bool{}
+		for _, field := range fields {
+			value, _ := document[field].(string)
+			var fieldTokens []interface{}
+			snippet := fakeTypesenseToken.ReplaceAllStringFunc(value, func(word string) string {
+				lower := strings.ToLower(word)
+				if !tokens[lower] && (prefix == "" || !strings.HasPrefix(lower, prefix)) {
+					return word
+				}
+				matched[strings.ToLower(word)] = true
+				fieldTokens = append(fieldTokens, word)
+				return "<mark>" + word + "</mark>"
+			})
+			if len(fieldTokens) > 0 {
+				hit.highlights = append(hit.highlights, map[string]interface{}{
+					"field": field, "snippet": snippet, "matched_tokens": fieldTokens,
+				})
+			}
+		}
+		if len(tokens) > 0 && len(matched) == 0 {
+			continue
+		}
+		hit.score = int64(len(matched)) * 1000
+		hits = append(hits, hit)
+	}
+
+	sortBy := params.Get("sort_by")
+	if sortBy == "" {
+		sortBy = "_text_match:desc"
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		for _, clause := range strings.Split(sortBy, ",") {
+			field, order, _ := strings.Cut(clause, ":")
+			a, b := float64(hits[i].score), float64(hits[j].score)
+			if field != "_text_match" {
+				a, _ = collection.documents[hits[i].id][field].(float64)
+				b, _ = collection.documents[hits[j].id][field].(float64)
+			}
+			if a != b {
+				return (a > b) == (order == "desc")
+			}
+		}
+		// Newer documents win ties
+		return collection.seq[hits[i].id] > collection.seq[hits[j].id]
+	})
+
+	start := min((page-1)*perPage, len(hits))
+	end := min(start+perPage, len(hits))
+	results := []map[string]interface{}{}
+	for _, hit := range hits[start:end] {
+		results = append(results, map[string]interface{}{
+			"document":   collection.documents[hit.id],
+			"text_match": hit.score,
+			"highlights": hit.highlights,
+		})
+	}
+	// Facets count values across every hit, most frequent first
+	facetCounts := []map[string]interface{}{}
+	for _, field := range strings.Split(params.Get("facet_by"), ",") {
+		if field == "" {
+			continue
+		}
+		counts := map[string]int{}
+		for _, hit := range hits {
+			values, ok := collection.documents[hit.id][field].([]interface{})
+			if !ok {
+				values = []interface{}{collection.documents[hit.id][field]}
+			}
+			for _, value := range values {
+				if value != nil {
+					counts[fmt.Sprint(value)]++
+				}
+			}
+		}
+		values := make([]string, 0, len(counts))
+		for value := range counts {
+			values = append(values, value)
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if counts[values[i]] != counts[values[j]] {
+				return counts[values[i]] > counts[values[j]]
+			}
+			return values[i] < values[j]
+		})
+		facet := []map[string]interface{}{}
+		for _, value := range values[:min(len(values), 10)] {
+			facet = append(facet, map[string]interface{}{"value": value, "count": counts[value]})
+		}
+		facetCounts = append(facetCounts, map[string]interface{}{"field_name": field, "counts": facet})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"found":          len(hits),
+		"out_of":         len(collection.documents),
+		"page":           page,
+		"search_time_ms": 1,
+		"hits":           results,
+		"facet_counts":   facetCounts,
+	})
+}
+
+// fakeTypesenseClauses splits a filter_by on the && outside backticks
+func fakeTypesenseClauses(filterBy string) []string {
+	var clauses []string
+	quoted, start := false, 0
+	for i := 0; i < len(filterBy); i++ {
+		switch {
+		case filterBy[i] == '`':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(filterBy[i:], "&&"):
+			clauses = append(clauses, filterBy[start:i])
+			start = i + 2
+		}
+	}
+	return append(clauses, filterBy[start:])
+}
+
+// fakeTypesenseFilter reports whether a document passes filter clauses such
+// as created_at:>=100, has_image:=true or tags:=`go`
+func fakeTypesenseFilter(document map[string]interface{}, filters []string) bool {
+	for _, clause := range filters {
+		field, expr, ok := strings.Cut(strings.TrimSpace(clause), ":")
+		if !ok {
+			continue
+		}
+		if operand := strings.TrimPrefix(expr, "="); strings.HasPrefix(operand, "`") || operand == "true" || operand == "false" {
+			if !fakeTypesenseMatches(document[field], strings.Trim(operand, "`")) {
+				return false
+			}
+			continue
+		}
+		value, _ := document[field].(float64)
+		for _, op := range []string{">=", "<=", ">", "<", "="} {
+			operand, ok := strings.CutPrefix(expr, op)
+			if !ok {
+				continue
+			}
+			limit, _ := strconv.ParseFloat(operand, 64)
+			switch {
+			case op == ">=" && value < limit,
+				op == "<=" && value > limit,
+				op == ">" && value <= limit,
+				op == "<" && value >= limit,
+				op == "=" && value != limit:
+				return false
+			}
+			break
+		}
+	}
+	return true
+}
+
+// fakeTypesenseMatches reports whether a field value, or any element of an
+// array field, equals the operand
+func fakeTypesenseMatches(value interface{}, operand string) bool {
+	if values, ok := value.([]interface{}); ok {
+		for _, element := range values {
+			if fmt.Sprint(element) == operand {
+				return true
+			}
+		}
+		return false
+	}
+	return value != nil && fmt.Sprint(value) == operand
+}
@@ -0,0 +1,605 @@
+package sink
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-cdc-search/domain"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Archive file formats
+const (
+	ArchiveFormatJSONL   = "jsonl"
+	ArchiveFormatParquet = "parquet"
+)
+
+// archiveManifestFile lists every segment of an archive, oldest first
+const archiveManifestFile = "manifest.json"
+
+// ArchiveConfig configures the archive sink
+type ArchiveConfig struct {
+	Dir      string
+	Format   string        // jsonl (default) or parquet
+	Compress bool          // gzip JSONL segments; Parquet pages always use zstd
+	MaxBytes int64         // rotate a segment once it reaches this size (0 disables)
+	MaxAge   time.Duration // rotate a segment once it has been open this long (0 disables)
+}
+
+// ArchiveSegment describes one archive file in the manifest
+type ArchiveSegment struct {
+	Path      string     `json:"path"` // relative to the archive directory
+	Table     string     `json:"table"`
+	Date      string     `json:"date"`
+	Format    string     `json:"format"`
+	Events    int        `json:"events"`
+	Bytes     int64      `json:"bytes"`
+	FirstTS   int64      `json:"first_ts"`
+	LastTS    int64      `json:"last_ts"`
+	CreatedAt time.Time  `json:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"` // nil while the segment is being written
+}
+
+type archiveManifest struct {
+	Segments []*ArchiveSegment `json:"segments"`
+}
+
+// archiveRecord is the Parquet row of an event; the row data is kept as JSON
+// because its columns depend on the table
+type archiveRecord struct {
+	Database string `parquet:"database"`
+	Table    string `parquet:"table"`
+	Type     string `parquet:"type"`
+	TS       int64  `parquet:"ts"`
+	Xid      int64  `parquet:"xid"`
+	Xoffset  int64  `parquet:"xoffset"`
+	Data     string `parquet:"data"`
+	Old      string `parquet:"old"`
+}
+
+// ArchiveSink appends every change event to rolling files partitioned by
+// table and date, e.g. table=posts/date=2025-01-31/20250131T120000-000001.jsonl.gz.
+// Each event is flushed and synced to disk before Apply returns, so the sink
+// should run synchronously to make it durable before the message is acked.
+//
+// Segments are always written as JSONL. With the Parquet format a segment is
+// converted once it is rotated, so the durable copy never depends on a
+// Parquet file being finished.
+type ArchiveSink struct {
+	config   ArchiveConfig
+	mu       sync.Mutex
+	manifest archiveManifest
+	open     map[string]*archiveSegmentWriter // by table
+	seq      int
+	now      func() time.Time
+}
+
+type archiveSegmentWriter struct {
+	segment *ArchiveSegment
+	file    *os.File
+	gzip    *gzip.Writer
+	out     io.Writer
+}
+
+// NewArchiveSink opens the archive in config.Dir. Segments left open by a
+// previous run are sealed first.
+func NewArchiveSink(config ArchiveConfig) (*ArchiveSink, error) {
+	if config.Format == "" {
+		config.Format = ArchiveFormatJSONL
+	}
+	if config.Format != ArchiveFormatJSONL && config.Format != ArchiveFormatParquet {
+		return nil, fmt.Errorf("unknown archive format: %s", config.Format)
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	manifest, err := readArchiveManifest(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ArchiveSink{
+		config:   config,
+		manifest: *manifest,
+		open:     make(map[string]*archiveSegmentWriter),
+		seq:      len(manifest.Segments),
+		now:      time.Now,
+	}
+
+	for _, segment := range s.manifest.Segments {
+		if segment.ClosedAt == nil {
+			if err := s.seal(segment, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.writeManifest(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name returns the sink name used in stats and checkpoints
+func (s *ArchiveSink) Name() string {
+	return "archive"
+}
+
+// Apply appends the change's CDC event to its table's current segment. A
+// retried change may be archived twice; replaying it twice is harmless.
+func (s *ArchiveSink) Apply(ctx context.Context, change *domain.Change) error {
+	event := change.Event
+	if event == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive event: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writer, err := s.writerFor(event)
+	if err != nil {
+		return err
+	}
+	if err := writer.append(line); err != nil {
+		// Seal the segment as if after a crash, so a partial write is dropped
+		// and the retry starts a new segment
+		delete(s.open, writer.segment.Table)
+		writer.file.Close()
+		if sealErr := s.seal(writer.segment, true); sealErr != nil {
+			return errors.Join(err, sealErr)
+		}
+		return err
+	}
+
+	segment := writer.segment
+	if segment.Events == 0 {
+		segment.FirstTS = event.TS
+	}
+	segment.Events++
+	segment.LastTS = event.TS
+	if info, err := writer.file.Stat(); err == nil {
+		segment.Bytes = info.Size()
+	}
+	return nil
+}
+
+// append writes one line and syncs it to disk
+func (w *archiveSegmentWriter) append(line []byte) error {
+	if _, err := w.out.Write(line); err != nil {
+		return fmt.Errorf("failed to write archive event: %w", err)
+	}
+	if w.gzip != nil {
+		if err := w.gzip.Flush(); err != nil {
+			return fmt.Errorf("failed to flush archive segment: %w", err)
+		}
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive segment: %w", err)
+	}
+	return nil
+}
+
+// Close seals every open segment
+func (s *ArchiveSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for table, writer := range s.open {
+		errs = append(errs, s.closeWriter(writer))
+		delete(s.open, table)
+	}
+	errs = append(errs, s.writeManifest())
+	return errors.Join(errs...)
+}
+
+// writerFor returns the open segment for the event, rotating the table's
+// current segment when it is full, too old or for another date
+func (s *ArchiveSink) writerFor(event *domain.CDCEvent) (*archiveSegmentWriter, error) {
+	occurred := event.Time()
+	if occurred.IsZero() {
+		occurred = s.now().UTC()
+	}
+	date := occurred.Format("2006-01-02")
+	table := event.Table
+	if table == "" {
+		table = "unknown"
+	}
+
+	if writer, ok := s.open[table]; ok {
+		segment := writer.segment
+		full := s.config.MaxBytes > 0 && segment.Bytes >= s.config.MaxBytes
+		expired := s.config.MaxAge > 0 && s.now().Sub(segment.CreatedAt) >= s.config.MaxAge
+		if !full && !expired && segment.Date == date {
+			return writer, nil
+		}
+		delete(s.open, table)
+		if err := s.closeWriter(writer); err != nil {
+			return nil, err
+		}
+	}
+
+	s.seq++
+	created := s.now().UTC()
+	name := fmt.Sprintf("%s-%06d.jsonl", created.Format("20060102T150405"), s.seq)
+	if s.config.Compress {
+		name += ".gz"
+	}
+	segment := &ArchiveSegment{
+		Path:      filepath.Join("table="+table, "date="+date, name),
+		Table:     table,
+		Date:      date,
+		Format:    ArchiveFormatJSONL,
+		CreatedAt: created,
+	}
+
+	path := filepath.Join(s.config.Dir, segment.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive partition: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive segment: %w", err)
+	}
+
+	writer := &archiveSegmentWriter{segment: segment, file: file, out: file}
+	if s.config.Compress {
+		writer.gzip = gzip.NewWriter(file)
+		writer.out = writer.gzip
+	}
+
+	// The segment is listed before anything is written to it, so a crash
+	// never leaves an event in a file the manifest does not know about
+	s.manifest.Segments = append(s.manifest.Segments, segment)
+	if err := s.writeManifest(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	s.open[table] = writer
+	return writer, nil
+}
+
+// closeWriter finishes a segment, converts it to Parquet if configured and
+// records it as sealed
+func (s *ArchiveSink) closeWriter(writer *archiveSegmentWriter) error {
+	if writer.gzip != nil {
+		if err := writer.gzip.Close(); err != nil {
+			writer.file.Close()
+			return fmt.Errorf("failed to finish archive segment: %w", err)
+		}
+	}
+	if err := writer.file.Sync(); err != nil {
+		writer.file.Close()
+		return fmt.Errorf("failed to sync archive segment: %w", err)
+	}
+	if err := writer.file.Close(); err != nil {
+		return fmt.Errorf("failed to close archive segment: %w", err)
+	}
+	return s.seal(writer.segment, false)
+}
+
+// seal recounts a segment from disk, converts it to Parquet if configured
+// and marks it closed in the manifest. A segment recovered after a crash is
+// rewritten first, dropping any partial write at its end.
+func (s *ArchiveSink) seal(segment *ArchiveSegment, recovered bool) error {
+	if segment.Format == ArchiveFormatJSONL {
+		events, err := readArchiveSegment(s.config.Dir, segment)
+		if err != nil {
+			return err
+		}
+		segment.Events = len(events)
+		if len(events) > 0 {
+			segment.FirstTS = events[0].TS
+			segment.LastTS = events[len(events)-1].TS
+		}
+
+		switch {
+		case s.config.Format == ArchiveFormatParquet && len(events) > 0:
+			if err := s.convertToParquet(segment, events); err != nil {
+				return err
+			}
+		case recovered:
+			if err := s.rewriteSegment(segment, events); err != nil {
+				return err
+			}
+		}
+	}
+
+	if info, err := os.Stat(filepath.Join(s.config.Dir, segment.Path)); err == nil {
+		segment.Bytes = info.Size()
+	}
+	closed := s.now().UTC()
+	segment.ClosedAt = &closed
+	return s.writeManifest()
+}
+
+// rewriteSegment replaces a JSONL segment with a complete copy of events
+func (s *ArchiveSink) rewriteSegment(segment *ArchiveSegment, events []*domain.CDCEvent) error {
+	path := filepath.Join(s.config.Dir, segment.Path)
+	tmp := path + ".tmp"
+
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite archive segment: %w", err)
+	}
+	var out io.Writer = file
+	var gz *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		gz = gzip.NewWriter(file)
+		out = gz
+	}
+
+	encoder := json.NewEncoder(out)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to rewrite archive segment: %w", err)
+		}
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to rewrite archive segment: %w", err)
+		}
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync archive segment: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to rewrite archive segment: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace archive segment: %w", err)
+	}
+	return nil
+}
+
+// convertToParquet replaces a JSONL segment with a Parquet file
+func (s *ArchiveSink) convertToParquet(segment *ArchiveSegment, events []*domain.CDCEvent) error {
+	records := make([]archiveRecord, 0, len(events))
+	for _, event := range events {
+		record := archiveRecord{
+			Database: event.Database,
+			Table:    event.Table,
+			Type:     event.Type,
+			TS:       event.TS,
+			Xid:      event.Xid,
+			Xoffset:  event.Xoffset,
+		}
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			return fmt.Errorf("failed to encode archive row: %w", err)
+		}
+		record.Data = string(data)
+		if event.Old != nil {
+			old, err := json.Marshal(event.Old)
+			if err != nil {
+				return fmt.Errorf("failed to encode archive row: %w", err)
+			}
+			record.Old = string(old)
+		}
+		records = append(records, record)
+	}
+
+	jsonlPath := filepath.Join(s.config.Dir, segment.Path)
+	parquetRel := strings.TrimSuffix(strings.TrimSuffix(segment.Path, ".gz"), ".jsonl") + ".parquet"
+	parquetPath := filepath.Join(s.config.Dir, parquetRel)
+	tmp := parquetPath + ".tmp"
+
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create parquet segment: %w", err)
+	}
+	writer := parquet.NewGenericWriter[archiveRecord](file, parquet.Compression(&parquet.Zstd))
+	if _, err := writer.Write(records); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write parquet segment: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to finish parquet segment: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync parquet segment: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close parquet segment: %w", err)
+	}
+	if err := os.Rename(tmp, parquetPath); err != nil {
+		return fmt.Errorf("failed to replace parquet segment: %w", err)
+	}
+
+	// Point the manifest at the Parquet file before dropping the JSONL copy
+	segment.Path = parquetRel
+	segment.Format = ArchiveFormatParquet
+	if err := s.writeManifest(); err != nil {
+		return err
+	}
+	if err := os.Remove(jsonlPath); err != nil {
+		return fmt.Errorf("failed to remove converted segment: %w", err)
+	}
+	return nil
+}
+
+// writeManifest replaces the manifest atomically
+func (s *ArchiveSink) writeManifest() error {
+	content, err := json.MarshalIndent(s.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive manifest: %w", err)
+	}
+
+	path := filepath.Join(s.config.Dir, archiveManifestFile)
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync archive manifest: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace archive manifest: %w", err)
+	}
+	return nil
+}
+
+func readArchiveManifest(dir string) (*archiveManifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, archiveManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &archiveManifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive manifest: %w", err)
+	}
+
+	var manifest archiveManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode archive manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// readArchiveSegment loads every event of a segment. An unsealed JSONL
+// segment may end in a partial or corrupt write, which is ignored.
+func readArchiveSegment(dir string, segment *ArchiveSegment) ([]*domain.CDCEvent, error) {
+	path := filepath.Join(dir, segment.Path)
+	if segment.Format == ArchiveFormatParquet {
+		return readParquetSegment(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive segment: %w", err)
+	}
+	defer file.Close()
+
+	var in io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil && segment.ClosedAt == nil {
+			return nil, nil // nothing was flushed before the crash
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive segment %s: %w", segment.Path, err)
+		}
+		defer gz.Close()
+		in = gz
+	}
+
+	var events []*domain.CDCEvent
+	reader := bufio.NewReader(in)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			event, decodeErr := domain.FromJSON(line)
+			if decodeErr != nil {
+				return nil, fmt.Errorf("failed to decode event in %s: %w", segment.Path, decodeErr)
+			}
+			events = append(events, event)
+		}
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil && segment.ClosedAt == nil {
+			return events, nil // the rest was not synced before the crash
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive segment %s: %w", segment.Path, err)
+		}
+	}
+}
+
+func readParquetSegment(path string) ([]*domain.CDCEvent, error) {
+	records, err := parquet.ReadFile[archiveRecord](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parquet segment: %w", err)
+	}
+
+	events := make([]*domain.CDCEvent, 0, len(records))
+	for _, record := range records {
+		event := &domain.CDCEvent{
+			Database: record.Database,
+			Table:    record.Table,
+			Type:     record.Type,
+			TS:       record.TS,
+			Xid:      record.Xid,
+			Xoffset:  record.Xoffset,
+		}
+		if err := json.Unmarshal([]byte(record.Data), &event.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode archive row: %w", err)
+		}
+		if record.Old != "" {
+			if err := json.Unmarshal([]byte(record.Old), &event.Old); err != nil {
+				return nil, fmt.Errorf("failed to decode archive row: %w", err)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ArchiveReader replays the events of an archive in the order they were
+// written. It implements domain.EventSource.
+type ArchiveReader struct {
+	dir      string
+	segments []*ArchiveSegment
+	pending  []*domain.CDCEvent
+}
+
+// OpenArchive reads the manifest of the archive in dir
+func OpenArchive(dir string) (*ArchiveReader, error) {
+	manifest, err := readArchiveManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Segments) == 0 {
+		return nil, fmt.Errorf("no archive segments in %s", dir)
+	}
+	return &ArchiveReader{dir: dir, segments: manifest.Segments}, nil
+}
+
+// Next returns the next archived event, or io.EOF after the last one
+func (r *ArchiveReader) Next() (*domain.CDCEvent, error) {
+	for len(r.pending) == 0 {
+		if len(r.segments) == 0 {
+			return nil, io.EOF
+		}
+		events, err := readArchiveSegment(r.dir, r.segments[0])
+		if err != nil {
+			return nil, err
+		}
+		r.segments = r.segments[1:]
+		r.pending = events
+	}
+
+	event := r.pending[0]
+	r.pending = r.pending[1:]
+	return event, nil
+}
@@ -0,0 +1,69 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"blog-cdc-search/domain"
+)
+
+// FileCheckpointStore keeps one JSON file per sink in a directory
+type FileCheckpointStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileCheckpointStore creates a store rooted at dir
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+// Load reads the checkpoint of a sink, returning nil if it has none
+func (s *FileCheckpointStore) Load(ctx context.Context, sink string) (*domain.SinkCheckpoint, error) {
+	content, err := os.ReadFile(s.path(sink))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint domain.SinkCheckpoint
+	if err := json.Unmarshal(content, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// Save replaces the checkpoint of a sink atomically
+func (s *FileCheckpointStore) Save(ctx context.Context, checkpoint *domain.SinkCheckpoint) error {
+	content, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(checkpoint.Sink)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *FileCheckpointStore) path(sink string) string {
+	return filepath.Join(s.dir, sink+".json")
+}
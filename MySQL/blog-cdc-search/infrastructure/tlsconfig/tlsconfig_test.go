@@ -0,0 +1,106 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed certificate and its key to dir
+func writeTestCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestConfig_LoadDisabled(t *testing.T) {
+	tlsConfig, err := Config{CAFile: "/does/not/exist"}.Load()
+	if err != nil {
+		t.Fatalf("Expected no error when disabled, got: %v", err)
+	}
+	if tlsConfig != nil {
+		t.Error("Expected nil TLS config when disabled")
+	}
+}
+
+func TestConfig_LoadCertificates(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+
+	tlsConfig, err := Config{
+		Enabled:    true,
+		CAFile:     certFile,
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		ServerName: "rabbitmq.internal",
+	}.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if tlsConfig.RootCAs == nil {
+		t.Error("Expected root CAs to be set")
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Errorf("Expected 1 client certificate, got %d", len(tlsConfig.Certificates))
+	}
+	if tlsConfig.ServerName != "rabbitmq.internal" {
+		t.Errorf("Expected server name rabbitmq.internal, got %s", tlsConfig.ServerName)
+	}
+}
+
+func TestConfig_LoadErrors(t *testing.T) {
+	certFile, _ := writeTestCertificate(t, t.TempDir())
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "missing CA file", config: Config{Enabled: true, CAFile: "/does/not/exist"}},
+		{name: "CA file without certificates", config: Config{Enabled: true, CAFile: os.Args[0]}},
+		{name: "certificate without key", config: Config{Enabled: true, CertFile: certFile}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.config.Load(); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
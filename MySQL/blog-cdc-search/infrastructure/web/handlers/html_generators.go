@@ -3,6 +3,8 @@ package handlers
 import (
 	"blog-cdc-search/domain"
 	"fmt"
+	"html"
+	"strings"
 )
 
 // generateHomePageHTML generates the main blog page HTML (public view)
@@ -55,6 +57,30 @@ func generateHomePageHTML(posts []*domain.Post) string {
             color: #666;
             font-size: 20px;
         }
+        .suggestions {
+            position: absolute;
+            top: 100%;
+            left: 0;
+            right: 0;
+            z-index: 10;
+            margin-top: 5px;
+            background: white;
+            border: 1px solid #ddd;
+            border-radius: 10px;
+            box-shadow: 0 2px 10px rgba(0,0,0,0.1);
+            overflow: hidden;
+        }
+        .suggestion {
+            padding: 10px 20px;
+            cursor: pointer;
+        }
+        .suggestion:hover {
+            background: #f8f9fa;
+        }
+        .suggestion.post {
+            color: #666;
+            font-size: 14px;
+        }
         .search-filters {
             display: flex;
             gap: 15px;
@@ -145,6 +171,37 @@ func generateHomePageHTML(posts []*domain.Post) string {
         .post .excerpt { color: #666; margin-bottom: 15px; line-height: 1.5; }
         .post .meta { color: #999; font-size: 0.9em; margin-bottom: 15px; }
         .post .read-more { color: #007bff; font-weight: bold; text-align: right; }
+        .facets {
+            background: white;
+            border-radius: 10px;
+            padding: 15px 20px;
+            box-shadow: 0 2px 10px rgba(0,0,0,0.1);
+            margin-bottom: 20px;
+        }
+        .facet-group {
+            margin: 8px 0;
+        }
+        .facet-group strong {
+            display: inline-block;
+            min-width: 90px;
+            color: #333;
+            font-size: 14px;
+        }
+        .facet-chip {
+            display: inline-block;
+            margin: 3px;
+            padding: 4px 10px;
+            border: 1px solid #ddd;
+            border-radius: 15px;
+            background: #f8f9fa;
+            font-size: 13px;
+            cursor: pointer;
+        }
+        .facet-chip.active {
+            background: #007bff;
+            border-color: #007bff;
+            color: white;
+        }
         .pagination {
             display: flex;
             justify-content: center;
@@ -222,16 +279,17 @@ func generateHomePageHTML(posts []*domain.Post) string {
             <div class="search-input-container">
                 <input type="text" id="searchInput" class="search-input" placeholder="Search for posts by title, excerpt, or content..." autocomplete="off">
                 <div class="search-icon">🔍</div>
+                <div id="suggestions" class="suggestions" style="display: none;"></div>
             </div>
             
             <div class="search-filters">
                 <div class="filter-group">
                     <label for="sortBy">Sort By:</label>
                     <select id="sortBy">
-                        <option value="_text_match:desc,created_at:desc">Relevance</option>
-                        <option value="created_at:desc">Newest First</option>
-                        <option value="created_at:asc">Oldest First</option>
-                        <option value="title:asc">Title A-Z</option>
+                        <option value="relevance">Relevance</option>
+                        <option value="newest">Newest First</option>
+                        <option value="oldest">Oldest First</option>
+                        <option value="updated">Recently Updated</option>
                     </select>
                 </div>
                 
@@ -263,6 +321,8 @@ func generateHomePageHTML(posts []*domain.Post) string {
             <div class="results-query" id="resultsQuery"></div>
         </div>
         
+        <div id="facets" class="facets" style="display: none;"></div>
+        
         <div id="noResults" class="no-results" style="display: none;">
             <h3>No results found</h3>
             <p>Try adjusting your search terms or filters</p>
@@ -306,10 +366,13 @@ func generateHomePageHTML(posts []*domain.Post) string {
     <script>
         let currentPage = 1;
         let currentQuery = '';
-        let currentSortBy = '_text_match:desc,created_at:desc';
+        let currentSortBy = 'relevance';
         let currentPerPage = 10;
-        let searchTimeout;
+        let currentFilters = {};
+        let suggestTimeout;
+        let latestSuggestQuery = '';
         let isSearchMode = false;
+        let currentSearchId = 0;
 
         // Initialize search functionality
         document.addEventListener('DOMContentLoaded', function() {
@@ -318,16 +381,19 @@ func generateHomePageHTML(posts []*domain.Post) string {
             const sortBySelect = document.getElementById('sortBy');
             const perPageSelect = document.getElementById('perPage');
 
-            // Search on input with debouncing
+            // Suggest as you type, with debouncing; the full search runs on submit
             searchInput.addEventListener('input', function() {
-                clearTimeout(searchTimeout);
-                searchTimeout = setTimeout(() => {
+                clearTimeout(suggestTimeout);
+                suggestTimeout = setTimeout(() => {
                     if (this.value.trim().length >= 2) {
-                        performSearch();
-                    } else if (this.value.trim() === '') {
-                        showAllPosts();
+                        fetchSuggestions(this.value);
+                    } else {
+                        hideSuggestions();
+                        if (this.value.trim() === '') {
+                            showAllPosts();
+                        }
                     }
-                }, 300);
+                }, 200);
             });
 
             // Search on button click
@@ -340,6 +406,18 @@ func generateHomePageHTML(posts []*domain.Post) string {
                 }
             });
 
+            searchInput.addEventListener('keydown', function(e) {
+                if (e.key === 'Escape') {
+                    hideSuggestions();
+                }
+            });
+
+            document.addEventListener('click', function(e) {
+                if (!e.target.closest('.search-input-container')) {
+                    hideSuggestions();
+                }
+            });
+
             // Update search when filters change
             sortBySelect.addEventListener('change', function() {
                 currentSortBy = this.value;
@@ -357,7 +435,55 @@ func generateHomePageHTML(posts []*domain.Post) string {
             });
         });
 
+        function fetchSuggestions(value) {
+            const query = value.trim();
+            latestSuggestQuery = query;
+
+            fetch('/api/search/suggest?q=' + encodeURIComponent(query))
+            .then(response => response.json())
+            .then(data => {
+                // Ignore replies that arrive after the query has moved on
+                if (query === latestSuggestQuery) {
+                    displaySuggestions(data);
+                }
+            })
+            .catch(error => console.error('Suggest error:', error));
+        }
+
+        function displaySuggestions(data) {
+            const container = document.getElementById('suggestions');
+            container.innerHTML = '';
+
+            (data.completions || []).forEach(completion => {
+                const item = document.createElement('div');
+                item.className = 'suggestion';
+                item.textContent = completion;
+                item.onclick = () => {
+                    document.getElementById('searchInput').value = completion;
+                    performSearch();
+                };
+                container.appendChild(item);
+            });
+
+            (data.posts || []).forEach(post => {
+                const item = document.createElement('div');
+                item.className = 'suggestion post';
+                item.textContent = '→ ' + post.title;
+                item.onclick = () => window.location.href = '/post/' + post.id;
+                container.appendChild(item);
+            });
+
+            container.style.display = container.children.length > 0 ? 'block' : 'none';
+        }
+
+        function hideSuggestions() {
+            latestSuggestQuery = '';
+            document.getElementById('suggestions').style.display = 'none';
+        }
+
         function performSearch() {
+            hideSuggestions();
+            clearTimeout(suggestTimeout);
             const query = document.getElementById('searchInput').value.trim();
             if (!query) {
                 showAllPosts();
@@ -373,7 +499,8 @@ func generateHomePageHTML(posts []*domain.Post) string {
                 query: query,
                 page: currentPage,
                 per_page: currentPerPage,
-                sort_by: currentSortBy
+                sort_by: currentSortBy,
+                filters: currentFilters
             };
 
             fetch('/api/search', {
@@ -402,6 +529,9 @@ func generateHomePageHTML(posts []*domain.Post) string {
             const resultsQuery = document.getElementById('resultsQuery');
             const noResults = document.getElementById('noResults');
 
+            displayFacets(data.facets || []);
+            currentSearchId = data.search_id || 0;
+
             if (!data.results || data.results.length === 0) {
                 resultsContainer.innerHTML = '';
                 resultsInfo.style.display = 'none';
@@ -410,15 +540,16 @@ func generateHomePageHTML(posts []*domain.Post) string {
             }
 
             // Show results info
-            resultsCount.textContent = 'Found ' + data.total + ' result' + (data.total !== 1 ? 's' : '');
+            resultsCount.textContent = 'Found ' + data.total + ' result' + (data.total !== 1 ? 's' : '') +
+                (data.search_time_ms ? ' in ' + data.search_time_ms + ' ms' : '');
             resultsQuery.textContent = 'for "' + data.query + '"';
             resultsInfo.style.display = 'block';
             noResults.style.display = 'none';
 
             // Display results
             resultsContainer.innerHTML = '';
-            data.results.forEach(result => {
-                const resultItem = createResultItem(result);
+            data.results.forEach((result, index) => {
+                const resultItem = createResultItem(result, (data.page - 1) * data.per_page + index + 1);
                 resultsContainer.appendChild(resultItem);
             });
 
@@ -433,10 +564,13 @@ func generateHomePageHTML(posts []*domain.Post) string {
             reinitLazyLoading();
         }
 
-        function createResultItem(result) {
+        function createResultItem(result, position) {
             const item = document.createElement('div');
             item.className = 'post';
-            item.onclick = () => window.location.href = '/post/' + result.post.id;
+            item.onclick = () => {
+                recordClick(result.post.id, position);
+                window.location.href = '/post/' + result.post.id;
+            };
 
             const image = result.post.image || 'https://placehold.co/600x400?text=' + encodeURIComponent(result.post.title);
             const score = result.score ? 'Score: ' + result.score.toFixed(2) : '';
@@ -454,6 +588,103 @@ func generateHomePageHTML(posts []*domain.Post) string {
             return item;
         }
 
+        // Clicks are sent as beacons, so they are delivered even though the
+        // page navigates away right after
+        function recordClick(postId, position) {
+            if (!currentSearchId) {
+                return;
+            }
+            const click = JSON.stringify({ search_id: currentSearchId, post_id: postId, position: position });
+            if (navigator.sendBeacon) {
+                navigator.sendBeacon('/api/search/click', new Blob([click], { type: 'application/json' }));
+            } else {
+                fetch('/api/search/click', { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: click, keepalive: true });
+            }
+        }
+
+        const facetLabels = {
+            tags: 'Tags',
+            category: 'Category',
+            author: 'Author',
+            language: 'Language',
+            year: 'Year',
+            month: 'Month',
+            has_image: 'Has image'
+        };
+
+        // Facets are rendered as chips; clicking one toggles it as a filter.
+        // Active filters are listed too, so they can be removed even when
+        // nothing matches.
+        function displayFacets(facets) {
+            const container = document.getElementById('facets');
+            container.innerHTML = '';
+
+            const active = Object.keys(currentFilters);
+            if (active.length > 0) {
+                const group = document.createElement('div');
+                group.className = 'facet-group';
+                group.innerHTML = '<strong>Filtering by</strong>';
+                active.forEach(field => {
+                    const values = field === 'tags' ? currentFilters.tags : [String(currentFilters[field])];
+                    values.forEach(value => group.appendChild(createFacetChip(field, value, facetLabels[field] + ': ' + value)));
+                });
+                container.appendChild(group);
+            }
+
+            facets.forEach(facet => {
+                const group = document.createElement('div');
+                group.className = 'facet-group';
+                const label = document.createElement('strong');
+                label.textContent = facetLabels[facet.field] || facet.field;
+                group.appendChild(label);
+                facet.counts.forEach(count => {
+                    group.appendChild(createFacetChip(facet.field, count.value, count.value + ' (' + count.count + ')'));
+                });
+                container.appendChild(group);
+            });
+
+            container.style.display = container.children.length > 0 ? 'block' : 'none';
+        }
+
+        function createFacetChip(field, value, text) {
+            const chip = document.createElement('span');
+            chip.className = 'facet-chip' + (isFacetActive(field, value) ? ' active' : '');
+            chip.textContent = text;
+            chip.onclick = () => toggleFacet(field, value);
+            return chip;
+        }
+
+        function isFacetActive(field, value) {
+            if (field === 'tags') {
+                return (currentFilters.tags || []).includes(value);
+            }
+            return field in currentFilters && String(currentFilters[field]) === value;
+        }
+
+        function toggleFacet(field, value) {
+            if (field === 'tags') {
+                const tags = (currentFilters.tags || []).filter(tag => tag !== value);
+                if (tags.length === (currentFilters.tags || []).length) {
+                    tags.push(value);
+                }
+                if (tags.length > 0) {
+                    currentFilters.tags = tags;
+                } else {
+                    delete currentFilters.tags;
+                }
+            } else if (isFacetActive(field, value)) {
+                delete currentFilters[field];
+            } else if (field === 'year') {
+                currentFilters.year = parseInt(value);
+            } else if (field === 'has_image') {
+                currentFilters.has_image = value === 'true';
+            } else {
+                currentFilters[field] = value;
+            }
+            currentPage = 1;
+            performSearch();
+        }
+
         function displayPagination(data) {
             const pagination = document.getElementById('pagination');
             pagination.innerHTML = '';
@@ -503,10 +734,12 @@ func generateHomePageHTML(posts []*domain.Post) string {
             isSearchMode = false;
             currentQuery = '';
             currentPage = 1;
+            currentFilters = {};
             
             document.getElementById('allPosts').style.display = 'grid';
             document.getElementById('searchResults').style.display = 'none';
             document.getElementById('resultsInfo').style.display = 'none';
+            document.getElementById('facets').style.display = 'none';
             document.getElementById('noResults').style.display = 'none';
             document.getElementById('pagination').style.display = 'none';
             document.getElementById('loading').style.display = 'none';
@@ -621,10 +854,10 @@ func generateHomePageHTML(posts []*domain.Post) string {
 }
 
 // generatePostDetailHTML generates the post detail page HTML
-func generatePostDetailHTML(post *domain.Post) string {
+func generatePostDetailHTML(post *domain.Post, related []*domain.Post) string {
 	return fmt.Sprintf(`
 <!DOCTYPE html>
-<html lang="en">
+<html lang="%s" dir="auto">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -649,6 +882,13 @@ func generatePostDetailHTML(post *domain.Post) string {
         .post .meta { color: #999; font-size: 0.9em; margin-bottom: 20px; padding-bottom: 20px; border-bottom: 1px solid #eee; }
         .post .content { color: #333; line-height: 1.8; font-size: 1.1em; }
         .post .content p { margin-bottom: 20px; }
+        .related { margin-top: 30px; }
+        .related h2 { color: #333; font-size: 1.4em; margin: 0 0 15px 0; }
+        .related-posts { display: grid; grid-template-columns: repeat(auto-fill, minmax(180px, 1fr)); gap: 15px; }
+        .related-post { display: block; background: white; border-radius: 10px; padding: 15px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); color: #333; text-decoration: none; }
+        .related-post:hover { box-shadow: 0 4px 15px rgba(0,0,0,0.15); }
+        .related-post h3 { margin: 0 0 8px 0; font-size: 1.05em; }
+        .related-post p { margin: 0; color: #666; font-size: 0.9em; line-height: 1.4; }
     </style>
 </head>
 <body>
@@ -668,6 +908,7 @@ func generatePostDetailHTML(post *domain.Post) string {
                 %s
             </div>
         </div>
+%s
     </div>
 
     <script>
@@ -733,7 +974,31 @@ func generatePostDetailHTML(post *domain.Post) string {
         });
     </script>
 </body>
-</html>`, post.Title, post.Image, post.Title, post.Title, post.Title, post.CreatedAt.Format("January 02, 2006"), post.Body)
+</html>`, html.EscapeString(post.Language), post.Title, post.Image, post.Title, post.Title, post.Title, post.CreatedAt.Format("January 02, 2006"), post.Body, generateRelatedPostsHTML(related))
+}
+
+// generateRelatedPostsHTML generates the related posts block of the post
+// detail page, or nothing when there are none
+func generateRelatedPostsHTML(related []*domain.Post) string {
+	if len(related) == 0 {
+		return ""
+	}
+
+	var links strings.Builder
+	for _, post := range related {
+		links.WriteString(fmt.Sprintf(`
+                <a class="related-post" href="/post/%d">
+                    <h3>%s</h3>
+                    <p>%s</p>
+                </a>`, post.ID, html.EscapeString(post.Title), html.EscapeString(post.Excerpt)))
+	}
+
+	return fmt.Sprintf(`
+        <div class="related">
+            <h2>Related posts</h2>
+            <div class="related-posts">%s
+            </div>
+        </div>`, links.String())
 }
 
 // generateDashboardHTML generates the admin dashboard HTML
@@ -792,6 +1057,9 @@ func generateDashboardHTML(posts []*domain.Post) string {
         
         <div class="actions">
             <a href="/dashboard/create" class="btn">Create New Post</a>
+            <a href="/dashboard/webhooks" class="btn">Webhooks</a>
+            <a href="/dashboard/search" class="btn">Search Tuning</a>
+            <a href="/dashboard/search/analytics" class="btn">Search Analytics</a>
         </div>
         
         <div class="posts">
@@ -817,10 +1085,11 @@ func generateDashboardHTML(posts []*domain.Post) string {
                 <div class="meta">Created: %s</div>
                 <div class="actions">
                     <a href="/dashboard/edit?id=%d" class="btn btn-small btn-warning">Edit</a>
+                    <a href="/dashboard/history?id=%d" class="btn btn-small">History</a>
                     <button onclick="deletePost(%d)" class="btn btn-small btn-danger">Delete</button>
                 </div>
             </div>
-            `, post.Image, post.Title, post.Title, post.Title, post.Excerpt, post.CreatedAt.Format("Jan 02, 2006"), post.ID, post.ID)
+            `, post.Image, post.Title, post.Title, post.Title, post.Excerpt, post.CreatedAt.Format("Jan 02, 2006"), post.ID, post.ID, post.ID)
 		}
 	}
 
@@ -968,6 +1237,26 @@ func generateCreateFormHTML() string {
                 <textarea id="excerpt" name="excerpt" placeholder="Brief summary of your post..."></textarea>
             </div>
             
+            <div class="form-group">
+                <label for="author">Author</label>
+                <input type="text" id="author" name="author">
+            </div>
+            
+            <div class="form-group">
+                <label for="category">Category</label>
+                <input type="text" id="category" name="category" placeholder="e.g. Tutorials">
+            </div>
+            
+            <div class="form-group">
+                <label for="language">Language</label>
+                <input type="text" id="language" name="language" placeholder="Detected automatically, e.g. en, de, fa">
+            </div>
+            
+            <div class="form-group">
+                <label for="tags">Tags</label>
+                <input type="text" id="tags" name="tags" placeholder="Comma separated, e.g. go, databases">
+            </div>
+            
             <div class="form-group">
                 <label for="body">Body *</label>
                 <textarea id="body" name="body" required placeholder="Write your post content here..."></textarea>
@@ -988,7 +1277,11 @@ func generateCreateFormHTML() string {
                 title: document.getElementById('title').value,
                 image: document.getElementById('image').value,
                 excerpt: document.getElementById('excerpt').value,
-                body: document.getElementById('body').value
+                body: document.getElementById('body').value,
+                author: document.getElementById('author').value,
+                category: document.getElementById('category').value,
+                language: document.getElementById('language').value,
+                tags: document.getElementById('tags').value.split(',').map(t => t.trim()).filter(t => t)
             };
             
             fetch('/api/posts', {
@@ -1068,6 +1361,26 @@ func generateEditFormHTML(post *domain.Post) string {
                 <textarea id="excerpt" name="excerpt" placeholder="Brief summary of your post...">%s</textarea>
             </div>
             
+            <div class="form-group">
+                <label for="author">Author</label>
+                <input type="text" id="author" name="author" value="%s">
+            </div>
+            
+            <div class="form-group">
+                <label for="category">Category</label>
+                <input type="text" id="category" name="category" value="%s" placeholder="e.g. Tutorials">
+            </div>
+            
+            <div class="form-group">
+                <label for="language">Language</label>
+                <input type="text" id="language" name="language" value="%s" placeholder="e.g. en, de, fa">
+            </div>
+            
+            <div class="form-group">
+                <label for="tags">Tags</label>
+                <input type="text" id="tags" name="tags" value="%s" placeholder="Comma separated, e.g. go, databases">
+            </div>
+            
             <div class="form-group">
                 <label for="body">Body *</label>
                 <textarea id="body" name="body" required placeholder="Write your post content here...">%s</textarea>
@@ -1088,7 +1401,11 @@ func generateEditFormHTML(post *domain.Post) string {
                 title: document.getElementById('title').value,
                 image: document.getElementById('image').value,
                 excerpt: document.getElementById('excerpt').value,
-                body: document.getElementById('body').value
+                body: document.getElementById('body').value,
+                author: document.getElementById('author').value,
+                category: document.getElementById('category').value,
+                language: document.getElementById('language').value,
+                tags: document.getElementById('tags').value.split(',').map(t => t.trim()).filter(t => t)
             };
             
             fetch('/api/posts?id=%d', {
@@ -1111,5 +1428,636 @@ func generateEditFormHTML(post *domain.Post) string {
         });
     </script>
 </body>
-</html>`, post.Title, post.Image, post.Excerpt, post.Body, post.ID)
+</html>`, post.Title, post.Image, post.Excerpt, html.EscapeString(post.Author), html.EscapeString(post.Category), html.EscapeString(post.Language), html.EscapeString(strings.Join(post.Tags, ", ")), post.Body, post.ID)
+}
+
+// generateWebhooksHTML generates the webhook endpoints and delivery log page HTML
+func generateWebhooksHTML(endpoints []*domain.WebhookEndpoint, deliveries []*domain.WebhookDelivery) string {
+	page := `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Webhooks - Blog Admin</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 0; padding: 20px; background-color: #f5f5f5; }
+        .container { max-width: 1200px; margin: 0 auto; }
+        .header { text-align: center; margin-bottom: 40px; }
+        .header h1 { color: #333; font-size: 2.5em; margin-bottom: 10px; }
+        .header p { color: #666; font-size: 1.2em; }
+        .back-link { text-align: center; margin-bottom: 20px; }
+        .back-link a { color: #666; text-decoration: none; }
+        .back-link a:hover { color: #333; }
+        .panel { background: white; border-radius: 10px; padding: 20px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); margin-bottom: 30px; }
+        .panel h2 { color: #333; margin-top: 0; }
+        table { width: 100%; border-collapse: collapse; }
+        th, td { text-align: left; padding: 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+        th { color: #666; }
+        .btn { display: inline-block; padding: 8px 16px; background-color: #007bff; color: white; text-decoration: none; border: none; border-radius: 5px; cursor: pointer; font-size: 0.9em; }
+        .btn:hover { background-color: #0056b3; }
+        .btn-danger { background-color: #dc3545; }
+        .btn-danger:hover { background-color: #c82333; }
+        .status-succeeded { color: #28a745; font-weight: bold; }
+        .status-failed { color: #dc3545; font-weight: bold; }
+        .status-pending { color: #e0a800; font-weight: bold; }
+        .error { color: #999; font-size: 0.9em; }
+        .form-row { display: flex; gap: 10px; align-items: center; flex-wrap: wrap; }
+        .form-row input[type=text] { padding: 8px; border: 1px solid #ddd; border-radius: 5px; flex: 1; min-width: 250px; }
+        #secret { margin-top: 15px; padding: 10px; background: #fff3cd; border-radius: 5px; display: none; word-break: break-all; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Webhooks</h1>
+            <p>Endpoints notified when posts change</p>
+        </div>
+
+        <div class="back-link">
+            <a href="/dashboard">← Back to Dashboard</a>
+        </div>
+
+        <div class="panel">
+            <h2>Endpoints</h2>
+            <form id="endpointForm" class="form-row">
+                <input type="text" id="url" placeholder="https://example.com/hooks/blog" required>
+`
+	for _, event := range domain.WebhookEvents {
+		page += fmt.Sprintf(`                <label><input type="checkbox" name="events" value="%s"> %s</label>
+`, event, event)
+	}
+	page += `                <button type="submit" class="btn">Add Endpoint</button>
+            </form>
+            <div id="secret"></div>
+            <table>
+                <tr><th>ID</th><th>URL</th><th>Events</th><th>Created</th><th></th></tr>
+`
+
+	if len(endpoints) == 0 {
+		page += `                <tr><td colspan="5">No endpoints registered</td></tr>
+`
+	}
+	for _, endpoint := range endpoints {
+		events := "all"
+		if len(endpoint.Events) > 0 {
+			events = endpoint.EventsString()
+		}
+		page += fmt.Sprintf(`                <tr>
+                    <td>%d</td><td>%s</td><td>%s</td><td>%s</td>
+                    <td><button onclick="deleteEndpoint(%d)" class="btn btn-danger">Delete</button></td>
+                </tr>
+`, endpoint.ID, html.EscapeString(endpoint.URL), events, endpoint.CreatedAt.Format("Jan 02, 2006 15:04"), endpoint.ID)
+	}
+
+	page += `            </table>
+        </div>
+
+        <div class="panel">
+            <h2>Recent Deliveries</h2>
+            <table>
+                <tr><th>ID</th><th>Endpoint</th><th>Event</th><th>Post</th><th>Status</th><th>Attempts</th><th>Updated</th><th></th></tr>
+`
+
+	if len(deliveries) == 0 {
+		page += `                <tr><td colspan="8">No deliveries yet</td></tr>
+`
+	}
+	for _, delivery := range deliveries {
+		status := delivery.Status
+		if delivery.ResponseStatus != 0 {
+			status = fmt.Sprintf("%s (%d)", delivery.Status, delivery.ResponseStatus)
+		}
+		if delivery.Status == domain.WebhookDeliveryPending && delivery.NextAttemptAt != nil {
+			status += ", next attempt " + delivery.NextAttemptAt.Format("15:04:05")
+		}
+		page += fmt.Sprintf(`                <tr>
+                    <td>%d</td><td>%d</td><td>%s</td><td>%d</td>
+                    <td><span class="status-%s">%s</span><div class="error">%s</div></td>
+                    <td>%d</td><td>%s</td>
+                    <td><button onclick="redeliver(%d)" class="btn">Redeliver</button></td>
+                </tr>
+`, delivery.ID, delivery.EndpointID, delivery.Event, delivery.PostID,
+			delivery.Status, status, html.EscapeString(delivery.Error),
+			delivery.Attempts, delivery.UpdatedAt.Format("Jan 02, 2006 15:04:05"), delivery.ID)
+	}
+
+	page += `            </table>
+        </div>
+    </div>
+
+    <script>
+        document.getElementById('endpointForm').addEventListener('submit', function(e) {
+            e.preventDefault();
+            const events = Array.from(document.querySelectorAll('input[name=events]:checked')).map(input => input.value);
+
+            fetch('/api/webhooks', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ url: document.getElementById('url').value, events: events })
+            })
+            .then(response => {
+                if (!response.ok) {
+                    return response.text().then(text => { throw new Error(text); });
+                }
+                return response.json();
+            })
+            .then(endpoint => {
+                const secret = document.getElementById('secret');
+                secret.textContent = 'Signing secret (shown once): ' + endpoint.secret;
+                secret.style.display = 'block';
+            })
+            .catch(error => {
+                console.error('Error:', error);
+                alert('Failed to add endpoint: ' + error.message);
+            });
+        });
+
+        function deleteEndpoint(id) {
+            if (confirm('Delete this endpoint and its delivery log?')) {
+                fetch('/api/webhooks/' + id, { method: 'DELETE' })
+                    .then(response => {
+                        if (response.ok) {
+                            location.reload();
+                        } else {
+                            alert('Failed to delete endpoint');
+                        }
+                    })
+                    .catch(error => {
+                        console.error('Error:', error);
+                        alert('Failed to delete endpoint');
+                    });
+            }
+        }
+
+        function redeliver(id) {
+            fetch('/api/webhooks/deliveries/' + id + '/redeliver', { method: 'POST' })
+                .then(response => {
+                    if (response.ok) {
+                        location.reload();
+                    } else {
+                        alert('Failed to redeliver');
+                    }
+                })
+                .catch(error => {
+                    console.error('Error:', error);
+                    alert('Failed to redeliver');
+                });
+        }
+    </script>
+</body>
+</html>`
+
+	return page
+}
+
+// generatePostHistoryHTML generates the post history page HTML: the list of
+// versions and the field-level diff of the selected one
+func generatePostHistoryHTML(postID int, versions []*domain.PostVersion, selected *domain.PostVersionDiff) string {
+	page := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Post %d History - Blog Admin</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 0; padding: 20px; background-color: #f5f5f5; }
+        .container { max-width: 1200px; margin: 0 auto; }
+        .header { text-align: center; margin-bottom: 40px; }
+        .header h1 { color: #333; font-size: 2.5em; margin-bottom: 10px; }
+        .header p { color: #666; font-size: 1.2em; }
+        .back-link { text-align: center; margin-bottom: 20px; }
+        .back-link a { color: #666; text-decoration: none; }
+        .back-link a:hover { color: #333; }
+        .panel { background: white; border-radius: 10px; padding: 20px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); margin-bottom: 30px; }
+        .panel h2 { color: #333; margin-top: 0; }
+        table { width: 100%%; border-collapse: collapse; }
+        th, td { text-align: left; padding: 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+        th { color: #666; }
+        tr.selected { background-color: #e8f0fe; }
+        .btn { display: inline-block; padding: 8px 16px; background-color: #007bff; color: white; text-decoration: none; border: none; border-radius: 5px; cursor: pointer; font-size: 0.9em; }
+        .btn:hover { background-color: #0056b3; }
+        .btn-warning { background-color: #ffc107; color: #212529; }
+        .btn-warning:hover { background-color: #e0a800; }
+        .field { margin-bottom: 20px; }
+        .field h3 { color: #333; margin: 0 0 8px 0; text-transform: capitalize; }
+        .old, .new { padding: 10px; border-radius: 5px; white-space: pre-wrap; word-break: break-word; margin-bottom: 5px; }
+        .old { background-color: #fdecea; color: #a94442; text-decoration: line-through; }
+        .new { background-color: #e6f4ea; color: #1e7e34; }
+        .unchanged { color: #999; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Post %d History</h1>
+            <p>Every version recorded from change data capture</p>
+        </div>
+
+        <div class="back-link">
+            <a href="/dashboard">← Back to Dashboard</a>
+        </div>
+
+        <div class="panel">
+            <h2>Versions</h2>
+            <table>
+                <tr><th>Version</th><th>Operation</th><th>Changed Fields</th><th>Changed At</th><th></th></tr>
+`, postID, postID)
+
+	if len(versions) == 0 {
+		page += `                <tr><td colspan="5">No history recorded for this post</td></tr>
+`
+	}
+	for _, version := range versions {
+		class := ""
+		if selected != nil && selected.Version.Version == version.Version {
+			class = ` class="selected"`
+		}
+		page += fmt.Sprintf(`                <tr%s>
+                    <td>%d</td><td>%s</td><td>%s</td><td>%s</td>
+                    <td><a href="/dashboard/history?id=%d&version=%d" class="btn">View</a></td>
+                </tr>
+`, class, version.Version, version.Operation, strings.Join(version.ChangedFields, ", "),
+			version.ChangedAt.Format("Jan 02, 2006 15:04:05"), postID, version.Version)
+	}
+
+	page += `            </table>
+        </div>
+`
+
+	if selected != nil {
+		page += fmt.Sprintf(`
+        <div class="panel">
+            <h2>Version %d</h2>
+            <p><button onclick="restoreVersion(%d, %d)" class="btn btn-warning">Restore this version</button></p>
+`, selected.Version.Version, postID, selected.Version.Version)
+
+		changed := map[string]domain.FieldChange{}
+		for _, change := range selected.Changes {
+			changed[change.Field] = change
+		}
+		for _, field := range domain.PostVersionFields {
+			page += fmt.Sprintf(`            <div class="field">
+                <h3>%s</h3>
+`, field)
+			if change, ok := changed[field]; ok {
+				page += fmt.Sprintf(`                <div class="old">%s</div>
+                <div class="new">%s</div>
+`, html.EscapeString(change.Old), html.EscapeString(change.New))
+			} else {
+				page += fmt.Sprintf(`                <div class="unchanged">%s</div>
+`, html.EscapeString(selected.Version.Field(field)))
+			}
+			page += `            </div>
+`
+		}
+		page += `        </div>
+`
+	}
+
+	page += `    </div>
+
+    <script>
+        function restoreVersion(id, version) {
+            if (confirm('Restore version ' + version + '? This saves its content as a new version.')) {
+                fetch('/api/posts/' + id + '/history/' + version + '/restore', { method: 'POST' })
+                    .then(response => {
+                        if (response.ok) {
+                            window.location.href = '/dashboard';
+                        } else {
+                            return response.text().then(text => { throw new Error(text); });
+                        }
+                    })
+                    .catch(error => {
+                        console.error('Error:', error);
+                        alert('Failed to restore version: ' + error.message);
+                    });
+            }
+        }
+    </script>
+</body>
+</html>`
+
+	return page
+}
+
+// generateSearchTuningHTML generates the search tuning page HTML: synonyms
+// and curations, with forms to add them
+func generateSearchTuningHTML(synonyms []*domain.Synonym, curations []*domain.Curation) string {
+	page := `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Search Tuning - Blog Admin</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 0; padding: 20px; background-color: #f5f5f5; }
+        .container { max-width: 1200px; margin: 0 auto; }
+        .header { text-align: center; margin-bottom: 40px; }
+        .header h1 { color: #333; font-size: 2.5em; margin-bottom: 10px; }
+        .header p { color: #666; font-size: 1.2em; }
+        .back-link { text-align: center; margin-bottom: 20px; }
+        .back-link a { color: #666; text-decoration: none; }
+        .back-link a:hover { color: #333; }
+        .panel { background: white; border-radius: 10px; padding: 20px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); margin-bottom: 30px; }
+        .panel h2 { color: #333; margin-top: 0; }
+        .hint { color: #999; font-size: 0.9em; }
+        table { width: 100%; border-collapse: collapse; }
+        th, td { text-align: left; padding: 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+        th { color: #666; }
+        .btn { display: inline-block; padding: 8px 16px; background-color: #007bff; color: white; text-decoration: none; border: none; border-radius: 5px; cursor: pointer; font-size: 0.9em; }
+        .btn:hover { background-color: #0056b3; }
+        .btn-danger { background-color: #dc3545; }
+        .btn-danger:hover { background-color: #c82333; }
+        .form-row { display: flex; gap: 10px; align-items: center; flex-wrap: wrap; margin-bottom: 15px; }
+        .form-row input[type=text], .form-row select { padding: 8px; border: 1px solid #ddd; border-radius: 5px; }
+        .form-row input[type=text] { flex: 1; min-width: 150px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Search Tuning</h1>
+            <p>Synonyms and curated results</p>
+        </div>
+
+        <div class="back-link">
+            <a href="/dashboard">← Back to Dashboard</a>
+        </div>
+
+        <div class="panel">
+            <h2>Synonyms</h2>
+            <p class="hint">With a root, searching for the root also finds the synonyms. Without one, every word finds all the others.</p>
+            <form id="synonymForm" class="form-row">
+                <input type="text" id="root" placeholder="Root (optional)">
+                <input type="text" id="synonyms" placeholder="Synonyms, comma separated" required>
+                <button type="submit" class="btn">Add Synonym</button>
+            </form>
+            <table>
+                <tr><th>ID</th><th>Root</th><th>Synonyms</th><th>Created</th><th></th></tr>
+`
+
+	if len(synonyms) == 0 {
+		page += `                <tr><td colspan="5">No synonyms defined</td></tr>
+`
+	}
+	for _, synonym := range synonyms {
+		root := "(multi-way)"
+		if synonym.Root != "" {
+			root = html.EscapeString(synonym.Root)
+		}
+		page += fmt.Sprintf(`                <tr>
+                    <td>%d</td><td>%s</td><td>%s</td><td>%s</td>
+                    <td><button onclick="deleteRule('synonyms', %d)" class="btn btn-danger">Delete</button></td>
+                </tr>
+`, synonym.ID, root, html.EscapeString(strings.Join(synonym.Synonyms, ", ")), synonym.CreatedAt.Format("Jan 02, 2006 15:04"), synonym.ID)
+	}
+
+	page += `            </table>
+        </div>
+
+        <div class="panel">
+            <h2>Curations</h2>
+            <p class="hint">Pinned posts come first, in order; hidden posts never appear. Only Typesense applies curations.</p>
+            <form id="curationForm" class="form-row">
+                <input type="text" id="query" placeholder="Query" required>
+                <select id="match">
+                    <option value="exact">exact</option>
+                    <option value="contains">contains</option>
+                </select>
+                <input type="text" id="pinned" placeholder="Pinned post IDs, e.g. 3,1">
+                <input type="text" id="hidden" placeholder="Hidden post IDs">
+                <button type="submit" class="btn">Add Curation</button>
+            </form>
+            <table>
+                <tr><th>ID</th><th>Query</th><th>Match</th><th>Pinned</th><th>Hidden</th><th>Created</th><th></th></tr>
+`
+
+	if len(curations) == 0 {
+		page += `                <tr><td colspan="7">No curations defined</td></tr>
+`
+	}
+	for _, curation := range curations {
+		page += fmt.Sprintf(`                <tr>
+                    <td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td>
+                    <td><button onclick="deleteRule('curations', %d)" class="btn btn-danger">Delete</button></td>
+                </tr>
+`, curation.ID, html.EscapeString(curation.Query), curation.Match,
+			domain.JoinPostIDs(curation.Pinned), domain.JoinPostIDs(curation.Hidden),
+			curation.CreatedAt.Format("Jan 02, 2006 15:04"), curation.ID)
+	}
+
+	page += `            </table>
+        </div>
+
+        <div class="panel">
+            <h2>Search Index</h2>
+            <p class="hint">Changes are synced as they are made. Sync again after the search index has been rebuilt.</p>
+            <button onclick="syncIndex()" class="btn">Sync Now</button>
+        </div>
+    </div>
+
+    <script>
+        function splitList(value) {
+            return value.split(',').map(item => item.trim()).filter(item => item !== '');
+        }
+
+        function splitIDs(value) {
+            return splitList(value).map(item => parseInt(item, 10));
+        }
+
+        function submitRule(kind, body) {
+            fetch('/api/admin/search/' + kind, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body)
+            })
+            .then(response => {
+                if (!response.ok) {
+                    return response.text().then(text => { throw new Error(text); });
+                }
+                location.reload();
+            })
+            .catch(error => {
+                console.error('Error:', error);
+                alert('Failed to save: ' + error.message);
+            });
+        }
+
+        document.getElementById('synonymForm').addEventListener('submit', function(e) {
+            e.preventDefault();
+            submitRule('synonyms', {
+                root: document.getElementById('root').value,
+                synonyms: splitList(document.getElementById('synonyms').value)
+            });
+        });
+
+        document.getElementById('curationForm').addEventListener('submit', function(e) {
+            e.preventDefault();
+            submitRule('curations', {
+                query: document.getElementById('query').value,
+                match: document.getElementById('match').value,
+                pinned: splitIDs(document.getElementById('pinned').value),
+                hidden: splitIDs(document.getElementById('hidden').value)
+            });
+        });
+
+        function deleteRule(kind, id) {
+            if (confirm('Delete this rule?')) {
+                fetch('/api/admin/search/' + kind + '/' + id, { method: 'DELETE' })
+                    .then(response => {
+                        if (response.ok) {
+                            location.reload();
+                        } else {
+                            response.text().then(text => alert('Failed to delete: ' + text));
+                        }
+                    })
+                    .catch(error => {
+                        console.error('Error:', error);
+                        alert('Failed to delete');
+                    });
+            }
+        }
+
+        function syncIndex() {
+            fetch('/api/admin/search/sync', { method: 'POST' })
+                .then(response => {
+                    if (response.ok) {
+                        alert('Search index synced');
+                    } else {
+                        response.text().then(text => alert('Failed to sync: ' + text));
+                    }
+                })
+                .catch(error => {
+                    console.error('Error:', error);
+                    alert('Failed to sync');
+                });
+        }
+    </script>
+</body>
+</html>`
+
+	return page
+}
+
+// generateSearchAnalyticsHTML generates the search analytics page HTML: a
+// summary of the period, the top queries with their click-through rates and
+// the queries that found nothing
+func generateSearchAnalyticsHTML(report *domain.SearchAnalyticsReport, days int) string {
+	summary := report.Summary
+	page := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Search Analytics - Blog Admin</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 0; padding: 20px; background-color: #f5f5f5; }
+        .container { max-width: 1200px; margin: 0 auto; }
+        .header { text-align: center; margin-bottom: 40px; }
+        .header h1 { color: #333; font-size: 2.5em; margin-bottom: 10px; }
+        .header p { color: #666; font-size: 1.2em; }
+        .back-link { text-align: center; margin-bottom: 20px; }
+        .back-link a { color: #666; text-decoration: none; }
+        .back-link a:hover { color: #333; }
+        .periods { text-align: center; margin-bottom: 20px; }
+        .periods a { color: #007bff; text-decoration: none; margin: 0 8px; }
+        .periods a.active { color: #333; font-weight: bold; }
+        .stats { display: flex; gap: 20px; flex-wrap: wrap; margin-bottom: 30px; }
+        .stat { flex: 1; min-width: 150px; background: white; border-radius: 10px; padding: 20px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); text-align: center; }
+        .stat .value { color: #333; font-size: 2em; font-weight: bold; }
+        .stat .label { color: #666; }
+        .panel { background: white; border-radius: 10px; padding: 20px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); margin-bottom: 30px; }
+        .panel h2 { color: #333; margin-top: 0; }
+        .hint { color: #999; font-size: 0.9em; }
+        .hint a { color: #007bff; }
+        table { width: 100%%; border-collapse: collapse; }
+        th, td { text-align: left; padding: 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+        th { color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Search Analytics</h1>
+            <p>What readers searched for since %s</p>
+        </div>
+
+        <div class="back-link">
+            <a href="/dashboard">← Back to Dashboard</a>
+        </div>
+
+        <div class="periods">
+`, report.Since.Format("Jan 02, 2006"))
+
+	for _, period := range []int{1, 7, 30, 90} {
+		class := ""
+		if period == days {
+			class = ` class="active"`
+		}
+		page += fmt.Sprintf(`            <a href="/dashboard/search/analytics?days=%d"%s>Last %d days</a>
+`, period, class, period)
+	}
+
+	page += fmt.Sprintf(`        </div>
+
+        <div class="stats">
+            <div class="stat"><div class="value">%d</div><div class="label">Searches</div></div>
+            <div class="stat"><div class="value">%d</div><div class="label">Sessions</div></div>
+            <div class="stat"><div class="value">%d</div><div class="label">Distinct queries</div></div>
+            <div class="stat"><div class="value">%s</div><div class="label">Zero results</div></div>
+            <div class="stat"><div class="value">%s</div><div class="label">Click-through rate</div></div>
+        </div>
+
+        <div class="panel">
+            <h2>Top Queries</h2>
+            <p class="hint">CTR is the share of searches followed by at least one result click.</p>
+            <table>
+                <tr><th>Query</th><th>Searches</th><th>Sessions</th><th>Avg results</th><th>Avg latency</th><th>CTR</th><th>Last searched</th></tr>
+`, summary.Searches, summary.Sessions, summary.Queries, formatPercent(summary.ZeroResultRate), formatPercent(summary.CTR))
+
+	if len(report.TopQueries) == 0 {
+		page += `                <tr><td colspan="7">No searches recorded</td></tr>
+`
+	}
+	for _, stats := range report.TopQueries {
+		page += fmt.Sprintf(`                <tr><td>%s</td><td>%d</td><td>%d</td><td>%.1f</td><td>%.0f ms</td><td>%s</td><td>%s</td></tr>
+`, html.EscapeString(stats.Query), stats.Searches, stats.Sessions, stats.AvgResults, stats.AvgLatencyMS,
+			formatPercent(stats.CTR), stats.LastSearched.Format("Jan 02, 2006 15:04"))
+	}
+
+	page += `            </table>
+        </div>
+
+        <div class="panel">
+            <h2>Zero-Result Queries</h2>
+            <p class="hint">Readers found nothing for these. Consider writing about them, or add synonyms in <a href="/dashboard/search">Search Tuning</a>.</p>
+            <table>
+                <tr><th>Query</th><th>Searches</th><th>Sessions</th><th>Last searched</th></tr>
+`
+
+	if len(report.ZeroResultQueries) == 0 {
+		page += `                <tr><td colspan="4">No zero-result searches</td></tr>
+`
+	}
+	for _, stats := range report.ZeroResultQueries {
+		page += fmt.Sprintf(`                <tr><td>%s</td><td>%d</td><td>%d</td><td>%s</td></tr>
+`, html.EscapeString(stats.Query), stats.Searches, stats.Sessions, stats.LastSearched.Format("Jan 02, 2006 15:04"))
+	}
+
+	page += `            </table>
+        </div>
+    </div>
+</body>
+</html>`
+
+	return page
+}
+
+// formatPercent formats a rate between 0 and 1 as a percentage
+func formatPercent(rate float64) string {
+	return fmt.Sprintf("%.1f%%", rate*100)
 }
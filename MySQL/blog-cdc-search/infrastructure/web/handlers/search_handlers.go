@@ -3,12 +3,18 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"blog-cdc-search/application/service"
 	"blog-cdc-search/domain"
+
+	"github.com/gorilla/mux"
 )
 
 // SearchServiceAdapter adapts the actual SearchService to the interface
@@ -23,11 +29,10 @@ func (a *SearchServiceAdapter) SearchPosts(ctx context.Context, params interface
 		// Try to convert from map[string]interface{} if that's what was passed
 		if paramMap, ok := params.(map[string]interface{}); ok {
 			searchParams = service.SearchParams{
-				Query:    paramMap["query"].(string),
-				Page:     paramMap["page"].(int),
-				PerPage:  paramMap["per_page"].(int),
-				SortBy:   paramMap["sort_by"].(string),
-				FilterBy: paramMap["filter_by"].(string),
+				Query:   paramMap["query"].(string),
+				Page:    paramMap["page"].(int),
+				PerPage: paramMap["per_page"].(int),
+				SortBy:  paramMap["sort_by"].(string),
 			}
 		} else {
 			// Return error for unsupported type
@@ -38,13 +43,23 @@ func (a *SearchServiceAdapter) SearchPosts(ctx context.Context, params interface
 	return a.service.SearchPosts(ctx, searchParams)
 }
 
+func (a *SearchServiceAdapter) SuggestPosts(ctx context.Context, query string) (interface{}, error) {
+	return a.service.SuggestPosts(ctx, query)
+}
+
 func (a *SearchServiceAdapter) GetAllPostsFromIndex(ctx context.Context) ([]*domain.Post, error) {
 	return a.service.GetAllPostsFromIndex(ctx)
 }
 
+func (a *SearchServiceAdapter) RelatedPosts(ctx context.Context, post *domain.Post) ([]*domain.Post, error) {
+	return a.service.RelatedPosts(ctx, post)
+}
+
 // SearchHandlers handles search-related endpoints
 type SearchHandlers struct {
 	*BaseHandler
+	// Analytics records searches when set
+	Analytics SearchAnalyticsService
 }
 
 // NewSearchHandlers creates a new search handlers instance
@@ -60,11 +75,11 @@ func (h *SearchHandlers) SearchPosts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Query    string `json:"query"`
-		Page     int    `json:"page"`
-		PerPage  int    `json:"per_page"`
-		SortBy   string `json:"sort_by"`
-		FilterBy string `json:"filter_by"`
+		Query   string        `json:"query"`
+		Page    int           `json:"page"`
+		PerPage int           `json:"per_page"`
+		SortBy  string        `json:"sort_by"`
+		Filters searchFilters `json:"filters"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -72,24 +87,37 @@ func (h *SearchHandlers) SearchPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filters, err := req.Filters.parse()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Create search parameters
 	searchParams := service.SearchParams{
-		Query:    req.Query,
-		Page:     req.Page,
-		PerPage:  req.PerPage,
-		SortBy:   req.SortBy,
-		FilterBy: req.FilterBy,
+		Query:   req.Query,
+		Page:    req.Page,
+		PerPage: req.PerPage,
+		SortBy:  req.SortBy,
+		Filters: filters,
 	}
 
 	// Perform search
+	started := time.Now()
 	results, err := h.SearchService.SearchPosts(r.Context(), searchParams)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSearchError(w, err)
 		return
 	}
+	h.recordSearch(r, results, time.Since(started))
+
+	setSearchLinks(results, r.URL.Path)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(results)
+	err = json.NewEncoder(w).Encode(results)
+	if err != nil {
+		log.Println(err)
+	}
 }
 
 // SearchPostsGet handles GET /api/search for simple query-based searches
@@ -120,20 +148,224 @@ func (h *SearchHandlers) SearchPostsGet(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Typed filters; tag may be repeated
+	values := r.URL.Query()
+	filterValues := searchFilters{
+		CreatedAfter:  values.Get("created_after"),
+		CreatedBefore: values.Get("created_before"),
+		Tags:          values["tag"],
+		Category:      values.Get("category"),
+		Author:        values.Get("author"),
+		Month:         values.Get("month"),
+		Language:      values.Get("language"),
+	}
+	if year := values.Get("year"); year != "" {
+		parsed, err := strconv.Atoi(year)
+		if err != nil {
+			http.Error(w, "Invalid year, expected a number", http.StatusBadRequest)
+			return
+		}
+		filterValues.Year = parsed
+	}
+	if hasImage := values.Get("has_image"); hasImage != "" {
+		flag, err := strconv.ParseBool(hasImage)
+		if err != nil {
+			http.Error(w, "Invalid has_image, expected true or false", http.StatusBadRequest)
+			return
+		}
+		filterValues.HasImage = &flag
+	}
+	filters, err := filterValues.parse()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Create search parameters
 	searchParams := service.SearchParams{
 		Query:   query,
 		Page:    page,
 		PerPage: perPage,
+		SortBy:  values.Get("sort"),
+		Filters: filters,
 	}
 
 	// Perform search
+	started := time.Now()
 	results, err := h.SearchService.SearchPosts(r.Context(), searchParams)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSearchError(w, err)
 		return
 	}
+	h.recordSearch(r, results, time.Since(started))
+
+	setSearchLinks(results, r.URL.Path)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(results)
 }
+
+// suggestMaxAge is how long clients may reuse a suggestion response
+const suggestMaxAge = 30 * time.Second
+
+// SuggestPosts handles GET /api/search/suggest for search-as-you-type. An
+// empty query gets an empty response rather than an error.
+func (h *SearchHandlers) SuggestPosts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	suggestions, err := h.SearchService.SuggestPosts(r.Context(), r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(suggestMaxAge.Seconds())))
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+// RelatedPosts handles GET /api/posts/{id}/related
+func (h *SearchHandlers) RelatedPosts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.PostService.GetPost(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	related, err := h.SearchService.RelatedPosts(r.Context(), post)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(related)
+}
+
+// searchFilters are the filters as they arrive in a request, with dates as
+// RFC 3339 timestamps or YYYY-MM-DD days
+type searchFilters struct {
+	CreatedAfter  string   `json:"created_after"`
+	CreatedBefore string   `json:"created_before"`
+	HasImage      *bool    `json:"has_image"`
+	Tags          []string `json:"tags"`
+	Category      string   `json:"category"`
+	Author        string   `json:"author"`
+	Year          int      `json:"year"`
+	Month         string   `json:"month"`
+	Language      string   `json:"language"`
+}
+
+// parse converts request filters to service filters
+func (f searchFilters) parse() (service.SearchFilters, error) {
+	filters := service.SearchFilters{
+		HasImage: f.HasImage,
+		Tags:     f.Tags,
+		Category: f.Category,
+		Author:   f.Author,
+		Year:     f.Year,
+		Month:    f.Month,
+		Language: f.Language,
+	}
+	for _, date := range []struct {
+		name   string
+		value  string
+		target **time.Time
+	}{
+		{"created_after", f.CreatedAfter, &filters.CreatedAfter},
+		{"created_before", f.CreatedBefore, &filters.CreatedBefore},
+	} {
+		if date.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, date.value)
+		if err != nil {
+			parsed, err = time.Parse(time.DateOnly, date.value)
+		}
+		if err != nil {
+			return filters, fmt.Errorf("invalid %s, expected RFC 3339 or YYYY-MM-DD", date.name)
+		}
+		*date.target = &parsed
+	}
+	return filters, nil
+}
+
+// writeSearchError reports invalid searches as bad requests
+func writeSearchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrInvalidSearch) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// setSearchLinks fills in GET links to the current, next and previous pages
+// of a search response, so clients can page without rebuilding the query
+func setSearchLinks(results interface{}, path string) {
+	response, ok := results.(*service.SearchResponse)
+	if !ok || response == nil || response.Query == "" {
+		return
+	}
+
+	link := func(page int) string {
+		values := url.Values{}
+		values.Set("q", response.Query)
+		values.Set("page", strconv.Itoa(page))
+		values.Set("per_page", strconv.Itoa(response.PerPage))
+		if response.SortBy != "" && response.SortBy != service.DefaultSearchSort {
+			values.Set("sort", response.SortBy)
+		}
+		if filters := response.Filters; filters != nil {
+			if filters.CreatedAfter != nil {
+				values.Set("created_after", filters.CreatedAfter.Format(time.RFC3339))
+			}
+			if filters.CreatedBefore != nil {
+				values.Set("created_before", filters.CreatedBefore.Format(time.RFC3339))
+			}
+			if filters.HasImage != nil {
+				values.Set("has_image", strconv.FormatBool(*filters.HasImage))
+			}
+			for _, tag := range filters.Tags {
+				values.Add("tag", tag)
+			}
+			if filters.Category != "" {
+				values.Set("category", filters.Category)
+			}
+			if filters.Author != "" {
+				values.Set("author", filters.Author)
+			}
+			if filters.Year != 0 {
+				values.Set("year", strconv.Itoa(filters.Year))
+			}
+			if filters.Month != "" {
+				values.Set("month", filters.Month)
+			}
+			if filters.Language != "" {
+				values.Set("language", filters.Language)
+			}
+		}
+		return path + "?" + values.Encode()
+	}
+
+	response.Links = service.SearchLinks{Self: link(response.Page)}
+	if response.Page < response.TotalPages {
+		response.Links.Next = link(response.Page + 1)
+	}
+	if response.Page > 1 {
+		response.Links.Prev = link(min(response.Page-1, max(response.TotalPages, 1)))
+	}
+}
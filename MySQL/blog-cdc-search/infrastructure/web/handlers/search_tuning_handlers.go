@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"blog-cdc-search/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// SearchTuningService interface for mocking in tests
+type SearchTuningService interface {
+	CreateSynonym(ctx context.Context, root string, synonyms []string) (*domain.Synonym, error)
+	ListSynonyms(ctx context.Context) ([]*domain.Synonym, error)
+	DeleteSynonym(ctx context.Context, id int) error
+	CreateCuration(ctx context.Context, query, match string, pinned, hidden []int) (*domain.Curation, error)
+	ListCurations(ctx context.Context) ([]*domain.Curation, error)
+	DeleteCuration(ctx context.Context, id int) error
+	Sync(ctx context.Context) error
+}
+
+// SearchTuningHandlers handles synonym and curation management
+type SearchTuningHandlers struct {
+	TuningService SearchTuningService
+}
+
+// NewSearchTuningHandlers creates a new search tuning handlers instance
+func NewSearchTuningHandlers(tuningService SearchTuningService) *SearchTuningHandlers {
+	return &SearchTuningHandlers{TuningService: tuningService}
+}
+
+// ServeSearchTuning serves the dashboard page listing synonyms and curations
+func (h *SearchTuningHandlers) ServeSearchTuning(w http.ResponseWriter, r *http.Request) {
+	synonyms, err := h.TuningService.ListSynonyms(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	curations, err := h.TuningService.ListCurations(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	html := generateSearchTuningHTML(synonyms, curations)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(html))
+}
+
+// ListSynonyms handles GET /api/admin/search/synonyms
+func (h *SearchTuningHandlers) ListSynonyms(w http.ResponseWriter, r *http.Request) {
+	synonyms, err := h.TuningService.ListSynonyms(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if synonyms == nil {
+		synonyms = []*domain.Synonym{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(synonyms)
+}
+
+// CreateSynonym handles POST /api/admin/search/synonyms. Without a root the
+// synonym is multi-way.
+func (h *SearchTuningHandlers) CreateSynonym(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Root     string   `json:"root"`
+		Synonyms []string `json:"synonyms"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	synonym, err := h.TuningService.CreateSynonym(r.Context(), req.Root, req.Synonyms)
+	if err != nil {
+		writeSearchTuningCreateError(w, synonym != nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(synonym)
+}
+
+// DeleteSynonym handles DELETE /api/admin/search/synonyms/{id}
+func (h *SearchTuningHandlers) DeleteSynonym(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid synonym ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.TuningService.DeleteSynonym(r.Context(), id); err != nil {
+		writeSearchTuningError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCurations handles GET /api/admin/search/curations
+func (h *SearchTuningHandlers) ListCurations(w http.ResponseWriter, r *http.Request) {
+	curations, err := h.TuningService.ListCurations(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if curations == nil {
+		curations = []*domain.Curation{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(curations)
+}
+
+// CreateCuration handles POST /api/admin/search/curations
+func (h *SearchTuningHandlers) CreateCuration(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query  string `json:"query"`
+		Match  string `json:"match"`
+		Pinned []int  `json:"pinned"`
+		Hidden []int  `json:"hidden"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	curation, err := h.TuningService.CreateCuration(r.Context(), req.Query, req.Match, req.Pinned, req.Hidden)
+	if err != nil {
+		writeSearchTuningCreateError(w, curation != nil, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(curation)
+}
+
+// DeleteCuration handles DELETE /api/admin/search/curations/{id}
+func (h *SearchTuningHandlers) DeleteCuration(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid curation ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.TuningService.DeleteCuration(r.Context(), id); err != nil {
+		writeSearchTuningError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Sync handles POST /api/admin/search/sync, pushing every definition to the
+// search index again, e.g. after a reindex
+func (h *SearchTuningHandlers) Sync(w http.ResponseWriter, r *http.Request) {
+	if err := h.TuningService.Sync(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeSearchTuningCreateError reports a definition that was rejected as a
+// bad request, and one that was saved but not synced as a gateway error
+func writeSearchTuningCreateError(w http.ResponseWriter, saved bool, err error) {
+	if saved {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+func writeSearchTuningError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrSearchTuningNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"blog-cdc-search/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// mockSearchTuningService keeps definitions in memory; syncError makes
+// creates report a saved but unsynced definition
+type mockSearchTuningService struct {
+	synonyms  []*domain.Synonym
+	curations []*domain.Curation
+	syncError error
+	synced    int
+}
+
+func (m *mockSearchTuningService) CreateSynonym(ctx context.Context, root string, synonyms []string) (*domain.Synonym, error) {
+	synonym, err := domain.NewSynonym(root, synonyms)
+	if err != nil {
+		return nil, err
+	}
+	synonym.ID = len(m.synonyms) + 1
+	m.synonyms = append(m.synonyms, synonym)
+	return synonym, m.syncError
+}
+
+func (m *mockSearchTuningService) ListSynonyms(ctx context.Context) ([]*domain.Synonym, error) {
+	return m.synonyms, nil
+}
+
+func (m *mockSearchTuningService) DeleteSynonym(ctx context.Context, id int) error {
+	for i, synonym := range m.synonyms {
+		if synonym.ID == id {
+			m.synonyms = append(m.synonyms[:i], m.synonyms[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrSearchTuningNotFound
+}
+
+func (m *mockSearchTuningService) CreateCuration(ctx context.Context, query, match string, pinned, hidden []int) (*domain.Curation, error) {
+	curation, err := domain.NewCuration(query, match, pinned, hidden)
+	if err != nil {
+		return nil, err
+	}
+	curation.ID = len(m.curations) + 1
+	m.curations = append(m.curations, curation)
+	return curation, m.syncError
+}
+
+func (m *mockSearchTuningService) ListCurations(ctx context.Context) ([]*domain.Curation, error) {
+	return m.curations, nil
+}
+
+func (m *mockSearchTuningService) DeleteCuration(ctx context.Context, id int) error {
+	for i, curation := range m.curations {
+		if curation.ID == id {
+			m.curations = append(m.curations[:i], m.curations[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrSearchTuningNotFound
+}
+
+func (m *mockSearchTuningService) Sync(ctx context.Context) error {
+	if m.syncError != nil {
+		return m.syncError
+	}
+	m.synced++
+	return nil
+}
+
+func TestSearchTuningHandlers_ServeSearchTuning(t *testing.T) {
+	service := &mockSearchTuningService{
+		synonyms:  []*domain.Synonym{{ID: 1, Root: "<cdc>", Synonyms: []string{"debezium", "maxwell"}}},
+		curations: []*domain.Curation{{ID: 2, Query: "go tips", Match: domain.CurationMatchExact, Pinned: []int{3, 1}, Hidden: []int{7}}},
+	}
+	handler := NewSearchTuningHandlers(service)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeSearchTuning(recorder, httptest.NewRequest(http.MethodGet, "/dashboard/search", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	body := recorder.Body.String()
+	for _, want := range []string{"&lt;cdc&gt;", "debezium, maxwell", "go tips", "3,1", "deleteRule('curations', 2)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected page to contain %q", want)
+		}
+	}
+}
+
+func TestSearchTuningHandlers_CreateSynonym(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		syncError      error
+		expectedStatus int
+	}{
+		{"multi-way", `{"synonyms":["go","golang"]}`, nil, http.StatusCreated},
+		{"one-way", `{"root":"cdc","synonyms":["debezium"]}`, nil, http.StatusCreated},
+		{"too few words", `{"synonyms":["go"]}`, nil, http.StatusBadRequest},
+		{"invalid body", `{`, nil, http.StatusBadRequest},
+		{"saved but not synced", `{"synonyms":["go","golang"]}`, errors.New("index down"), http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSearchTuningHandlers(&mockSearchTuningService{syncError: tt.syncError})
+
+			recorder := httptest.NewRecorder()
+			handler.CreateSynonym(recorder, httptest.NewRequest(http.MethodPost, "/api/admin/search/synonyms", strings.NewReader(tt.body)))
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+			if tt.expectedStatus == http.StatusCreated {
+				var synonym domain.Synonym
+				if err := json.NewDecoder(recorder.Body).Decode(&synonym); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if synonym.ID != 1 {
+					t.Errorf("expected ID 1, got %d", synonym.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchTuningHandlers_CreateCuration(t *testing.T) {
+	service := &mockSearchTuningService{}
+	handler := NewSearchTuningHandlers(service)
+
+	recorder := httptest.NewRecorder()
+	body := `{"query":"Go Tips","match":"contains","pinned":[3],"hidden":[7]}`
+	handler.CreateCuration(recorder, httptest.NewRequest(http.MethodPost, "/api/admin/search/curations", strings.NewReader(body)))
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, recorder.Code, recorder.Body.String())
+	}
+	if len(service.curations) != 1 || service.curations[0].Query != "go tips" || service.curations[0].Match != domain.CurationMatchContains {
+		t.Errorf("unexpected curations: %+v", service.curations)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.CreateCuration(recorder, httptest.NewRequest(http.MethodPost, "/api/admin/search/curations", strings.NewReader(`{"query":"go"}`)))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for a curation without posts, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestSearchTuningHandlers_Delete(t *testing.T) {
+	service := &mockSearchTuningService{
+		synonyms:  []*domain.Synonym{{ID: 1, Synonyms: []string{"go", "golang"}}},
+		curations: []*domain.Curation{{ID: 1, Query: "go", Pinned: []int{1}}},
+	}
+	handler := NewSearchTuningHandlers(service)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/admin/search/synonyms/{id}", handler.DeleteSynonym).Methods("DELETE")
+	router.HandleFunc("/api/admin/search/curations/{id}", handler.DeleteCuration).Methods("DELETE")
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/api/admin/search/synonyms/1", http.StatusNoContent},
+		{"/api/admin/search/synonyms/1", http.StatusNotFound},
+		{"/api/admin/search/curations/1", http.StatusNoContent},
+		{"/api/admin/search/curations/9", http.StatusNotFound},
+		{"/api/admin/search/curations/abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, tt.path, nil))
+		if recorder.Code != tt.expectedStatus {
+			t.Errorf("DELETE %s: expected status %d, got %d", tt.path, tt.expectedStatus, recorder.Code)
+		}
+	}
+}
+
+func TestSearchTuningHandlers_Sync(t *testing.T) {
+	service := &mockSearchTuningService{}
+	handler := NewSearchTuningHandlers(service)
+
+	recorder := httptest.NewRecorder()
+	handler.Sync(recorder, httptest.NewRequest(http.MethodPost, "/api/admin/search/sync", nil))
+	if recorder.Code != http.StatusNoContent || service.synced != 1 {
+		t.Fatalf("expected a sync and status %d, got %d", http.StatusNoContent, recorder.Code)
+	}
+
+	service.syncError = errors.New("index down")
+	recorder = httptest.NewRecorder()
+	handler.Sync(recorder, httptest.NewRequest(http.MethodPost, "/api/admin/search/sync", nil))
+	if recorder.Code != http.StatusBadGateway {
+		t.Errorf("expected status %d, got %d", http.StatusBadGateway, recorder.Code)
+	}
+}
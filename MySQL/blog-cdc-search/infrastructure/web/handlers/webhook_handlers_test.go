@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"blog-cdc-search/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// mockWebhookService records calls and serves fixed data
+type mockWebhookService struct {
+	endpoints   []*domain.WebhookEndpoint
+	deliveries  []*domain.WebhookDelivery
+	redelivered []int64
+	limit       int
+}
+
+func (m *mockWebhookService) CreateEndpoint(ctx context.Context, url, secret string, events []string) (*domain.WebhookEndpoint, error) {
+	endpoint, err := domain.NewWebhookEndpoint(url, "generated", events)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.ID = len(m.endpoints) + 1
+	m.endpoints = append(m.endpoints, endpoint)
+	return endpoint, nil
+}
+
+func (m *mockWebhookService) ListEndpoints(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	return m.endpoints, nil
+}
+
+func (m *mockWebhookService) DeleteEndpoint(ctx context.Context, id int) error {
+	for i, endpoint := range m.endpoints {
+		if endpoint.ID == id {
+			m.endpoints = append(m.endpoints[:i], m.endpoints[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrWebhookNotFound
+}
+
+func (m *mockWebhookService) ListDeliveries(ctx context.Context, limit int) ([]*domain.WebhookDelivery, error) {
+	m.limit = limit
+	return m.deliveries, nil
+}
+
+func (m *mockWebhookService) Redeliver(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	for _, delivery := range m.deliveries {
+		if delivery.ID == id {
+			m.redelivered = append(m.redelivered, id)
+			return &domain.WebhookDelivery{ID: 100, EndpointID: delivery.EndpointID, Status: domain.WebhookDeliverySucceeded}, nil
+		}
+	}
+	return nil, domain.ErrWebhookNotFound
+}
+
+func TestWebhookHandlers_ServeWebhooks(t *testing.T) {
+	service := &mockWebhookService{
+		endpoints: []*domain.WebhookEndpoint{{ID: 1, URL: "https://example.com/<hook>", Active: true}},
+		deliveries: []*domain.WebhookDelivery{
+			{ID: 5, EndpointID: 1, Event: domain.WebhookEventPostUpdated, PostID: 3, Status: domain.WebhookDeliveryFailed, Attempts: 5, ResponseStatus: 500, Error: "endpoint returned 500"},
+		},
+	}
+	handler := NewWebhookHandlers(service)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeWebhooks(recorder, httptest.NewRequest(http.MethodGet, "/dashboard/webhooks", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	body := recorder.Body.String()
+	for _, want := range []string{"https://example.com/&lt;hook&gt;", "post.updated", "failed (500)", "endpoint returned 500", "redeliver(5)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("response should contain %q", want)
+		}
+	}
+}
+
+func TestWebhookHandlers_CreateEndpoint(t *testing.T) {
+	handler := NewWebhookHandlers(&mockWebhookService{})
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks", strings.NewReader(`{"url":"https://example.com/hook","events":["post.created"]}`))
+	handler.CreateEndpoint(recorder, req)
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, recorder.Code)
+	}
+
+	var endpoint domain.WebhookEndpoint
+	if err := json.NewDecoder(recorder.Body).Decode(&endpoint); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if endpoint.Secret == "" || len(endpoint.Events) != 1 {
+		t.Errorf("expected the secret and events in the response, got %+v", endpoint)
+	}
+
+	recorder = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/webhooks", strings.NewReader(`{"url":"ftp://example.com"}`))
+	handler.CreateEndpoint(recorder, req)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an invalid URL, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestWebhookHandlers_DeleteEndpoint(t *testing.T) {
+	service := &mockWebhookService{endpoints: []*domain.WebhookEndpoint{{ID: 1}}}
+	handler := NewWebhookHandlers(service)
+
+	tests := []struct {
+		id     string
+		status int
+	}{
+		{"1", http.StatusNoContent},
+		{"1", http.StatusNotFound},
+		{"abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/webhooks/"+tt.id, nil), map[string]string{"id": tt.id})
+		handler.DeleteEndpoint(recorder, req)
+		if recorder.Code != tt.status {
+			t.Errorf("deleting %s: expected status %d, got %d", tt.id, tt.status, recorder.Code)
+		}
+	}
+}
+
+func TestWebhookHandlers_ListDeliveries(t *testing.T) {
+	service := &mockWebhookService{}
+	handler := NewWebhookHandlers(service)
+
+	recorder := httptest.NewRecorder()
+	handler.ListDeliveries(recorder, httptest.NewRequest(http.MethodGet, "/api/webhooks/deliveries?limit=20", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if service.limit != 20 {
+		t.Errorf("expected limit 20, got %d", service.limit)
+	}
+	if strings.TrimSpace(recorder.Body.String()) != "[]" {
+		t.Errorf("expected an empty JSON array, got %s", recorder.Body.String())
+	}
+}
+
+func TestWebhookHandlers_Redeliver(t *testing.T) {
+	service := &mockWebhookService{deliveries: []*domain.WebhookDelivery{{ID: 5, EndpointID: 1}}}
+	handler := NewWebhookHandlers(service)
+
+	recorder := httptest.NewRecorder()
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/webhooks/deliveries/5/redeliver", nil), map[string]string{"id": "5"})
+	handler.Redeliver(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if len(service.redelivered) != 1 || service.redelivered[0] != 5 {
+		t.Errorf("expected delivery 5 to be redelivered, got %v", service.redelivered)
+	}
+
+	recorder = httptest.NewRecorder()
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/webhooks/deliveries/9/redeliver", nil), map[string]string{"id": "9"})
+	handler.Redeliver(recorder, req)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, recorder.Code)
+	}
+}
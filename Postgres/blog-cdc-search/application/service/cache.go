@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// AllPostsCacheKey caches the home page listing read from the search index
+const AllPostsCacheKey = "posts:all"
+
+// PostCacheKey caches a single post read from the database
+func PostCacheKey(id int) string {
+	return fmt.Sprintf("post:%d", id)
+}
+
+// RelatedPostsVersionKey holds the version the related posts of every post
+// are cached under. Any change can alter which posts are related to which,
+// so the cache sink deletes the version, leaving every cached list behind.
+const RelatedPostsVersionKey = "posts:related:version"
+
+// RelatedPostsCacheKey caches the related posts of a post under a version
+func RelatedPostsCacheKey(version string, id int) string {
+	return fmt.Sprintf("posts:related:%s:%d", version, id)
+}
+
+// cacheGet decodes a cached value into v. Cache errors are logged and
+// reported as a miss so reads fall through to the source.
+func cacheGet(ctx context.Context, cache domain.CacheRepository, key string, v interface{}) bool {
+	content, found, err := cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("Failed to read cache key %s: %v", key, err)
+		return false
+	}
+	if !found {
+		return false
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		log.Printf("Failed to decode cache key %s: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// cacheSet stores v under key, logging failures
+func cacheSet(ctx context.Context, cache domain.CacheRepository, key string, v interface{}, ttl time.Duration) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Failed to encode cache key %s: %v", key, err)
+		return
+	}
+	if err := cache.Set(ctx, key, content, ttl); err != nil {
+		log.Printf("Failed to write cache key %s: %v", key, err)
+	}
+}
+
+// CacheSink invalidates the cache keys affected by a change
+type CacheSink struct {
+	cache domain.CacheRepository
+}
+
+// NewCacheSink creates a sink that invalidates keys in cache
+func NewCacheSink(cache domain.CacheRepository) *CacheSink {
+	return &CacheSink{cache: cache}
+}
+
+// Name returns the sink name used in stats and checkpoints
+func (s *CacheSink) Name() string {
+	return "cache"
+}
+
+// Apply deletes the changed post, the listing that contains it and the version
+// of the related posts. Runs after the search index sink, so the next read
+// repopulates them with fresh data.
+func (s *CacheSink) Apply(ctx context.Context, change *domain.Change) error {
+	var id int
+	if _, err := fmt.Sscanf(change.ID, "%d", &id); err != nil {
+		return fmt.Errorf("invalid post ID %q: %w", change.ID, err)
+	}
+
+	if err := s.cache.Delete(ctx, PostCacheKey(id), AllPostsCacheKey, RelatedPostsVersionKey); err != nil {
+		return err
+	}
+	log.Printf("Invalidated cache for post ID: %d", id)
+	return nil
+}
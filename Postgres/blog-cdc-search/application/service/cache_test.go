@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// MockCacheRepository is a map-backed cache that can be made to fail
+type MockCacheRepository struct {
+	values map[string][]byte
+	err    error
+}
+
+func NewMockCacheRepository() *MockCacheRepository {
+	return &MockCacheRepository{values: make(map[string][]byte)}
+}
+
+func (m *MockCacheRepository) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	value, ok := m.values[key]
+	return value, ok, nil
+}
+
+func (m *MockCacheRepository) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.values[key] = value
+	return nil
+}
+
+func (m *MockCacheRepository) Delete(ctx context.Context, keys ...string) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, key := range keys {
+		delete(m.values, key)
+	}
+	return nil
+}
+
+// countingPostRepository counts GetByID calls
+type countingPostRepository struct {
+	*MockPostRepository
+	gets int
+}
+
+func (r *countingPostRepository) GetByID(ctx context.Context, id int) (*domain.Post, error) {
+	r.gets++
+	return r.MockPostRepository.GetByID(ctx, id)
+}
+
+func TestPostService_GetPost_ReadThroughCache(t *testing.T) {
+	repo := &countingPostRepository{MockPostRepository: NewMockPostRepository()}
+	postService := NewPostService(repo)
+	postCache := NewMockCacheRepository()
+	postService.SetCache(postCache, time.Minute)
+
+	post, err := postService.CreatePost(context.Background(), "Title", "", "", "Body", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := postService.GetPost(context.Background(), post.ID)
+		if err != nil {
+			t.Fatalf("GetPost failed: %v", err)
+		}
+		if got.Title != "Title" {
+			t.Errorf("Expected title 'Title', got '%s'", got.Title)
+		}
+	}
+	if repo.gets != 1 {
+		t.Errorf("Expected 1 database read, got %d", repo.gets)
+	}
+
+	// The cache sink drops the key, so the next read goes to the database
+	sink := NewCacheSink(postCache)
+	if err := sink.Apply(context.Background(), &domain.Change{Op: domain.ChangeOpUpsert, ID: "1"}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if _, err := postService.GetPost(context.Background(), post.ID); err != nil {
+		t.Fatalf("GetPost failed: %v", err)
+	}
+	if repo.gets != 2 {
+		t.Errorf("Expected a database read after invalidation, got %d reads", repo.gets)
+	}
+}
+
+func TestPostService_GetPost_CacheErrorFallsThrough(t *testing.T) {
+	repo := &countingPostRepository{MockPostRepository: NewMockPostRepository()}
+	postService := NewPostService(repo)
+	postCache := NewMockCacheRepository()
+	postCache.err = errors.New("redis down")
+	postService.SetCache(postCache, time.Minute)
+
+	post, _ := postService.CreatePost(context.Background(), "Title", "", "", "Body", "", "", "", nil)
+	if _, err := postService.GetPost(context.Background(), post.ID); err != nil {
+		t.Fatalf("Expected read to succeed without the cache, got: %v", err)
+	}
+	if _, err := postService.GetPost(context.Background(), 99); err == nil {
+		t.Error("Expected error for missing post")
+	}
+}
+
+func TestSearchService_GetAllPostsFromIndex_ReadThroughCache(t *testing.T) {
+	mockRepo := &MockSearchIndexRepositoryForSearch{
+		getAllResults: []interface{}{
+			map[string]interface{}{"id": "1", "title": "First", "body": "Body", "created_at": float64(1700000000), "updated_at": float64(1700000000)},
+		},
+	}
+	searchService := NewSearchService(mockRepo)
+	postCache := NewMockCacheRepository()
+	searchService.SetCache(postCache, time.Minute)
+
+	posts, err := searchService.GetAllPostsFromIndex(context.Background())
+	if err != nil || len(posts) != 1 {
+		t.Fatalf("Expected 1 post, got %d (%v)", len(posts), err)
+	}
+
+	// Served from the cache even though the index has changed
+	mockRepo.getAllResults = nil
+	posts, err = searchService.GetAllPostsFromIndex(context.Background())
+	if err != nil || len(posts) != 1 || posts[0].Title != "First" {
+		t.Fatalf("Expected cached post, got %v (%v)", posts, err)
+	}
+	if !posts[0].CreatedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Expected created_at to survive the cache, got %v", posts[0].CreatedAt)
+	}
+
+	if err := NewCacheSink(postCache).Apply(context.Background(), &domain.Change{Op: domain.ChangeOpDelete, ID: "1"}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	posts, err = searchService.GetAllPostsFromIndex(context.Background())
+	if err != nil || len(posts) != 0 {
+		t.Errorf("Expected the refreshed listing to be empty, got %d posts (%v)", len(posts), err)
+	}
+}
+
+func TestSearchService_SuggestPosts_Cache(t *testing.T) {
+	mockRepo := &MockSearchIndexRepositoryForSearch{
+		searchResults: searchHits(map[string]interface{}{"id": "1", "title": "Change Data Capture"}),
+	}
+	searchService := NewSearchService(mockRepo)
+	suggestCache := NewMockCacheRepository()
+	searchService.SetSuggestCache(suggestCache, time.Second)
+
+	if _, err := searchService.SuggestPosts(context.Background(), "Change  da"); err != nil {
+		t.Fatalf("SuggestPosts failed: %v", err)
+	}
+	if _, ok := suggestCache.values[SuggestCacheKey("change da")]; !ok {
+		t.Fatalf("Expected suggestions cached under the normalized query, got %v", suggestCache.values)
+	}
+
+	// Queries differing only in case and spacing are served from the cache
+	mockRepo.searchResults = &domain.SearchResults{}
+	response, err := searchService.SuggestPosts(context.Background(), "change DA")
+	if err != nil || len(response.Posts) != 1 || response.Completions[0] != "Change Data Capture" {
+		t.Errorf("Expected cached suggestions, got %+v (%v)", response, err)
+	}
+}
+
+func TestSearchService_RelatedPosts_Cache(t *testing.T) {
+	mockRepo := &MockSearchIndexRepositoryForSearch{
+		searchResults: searchHits(map[string]interface{}{"id": "2", "title": "Streaming Changes"}),
+	}
+	searchService := NewSearchService(mockRepo)
+	postCache := NewMockCacheRepository()
+	searchService.SetCache(postCache, time.Minute)
+
+	post := &domain.Post{ID: 1, Title: "Change Data Capture"}
+	if _, err := searchService.RelatedPosts(context.Background(), post); err != nil {
+		t.Fatalf("RelatedPosts failed: %v", err)
+	}
+
+	// Served from the cache even though the index has changed
+	mockRepo.searchResults = searchHits(map[string]interface{}{"id": "3", "title": "Debezium"})
+	related, err := searchService.RelatedPosts(context.Background(), post)
+	if err != nil || len(related) != 1 || related[0].ID != 2 {
+		t.Fatalf("Expected cached post 2, got %v (%v)", related, err)
+	}
+
+	// A change to any post, such as a candidate, refreshes the related posts
+	if err := NewCacheSink(postCache).Apply(context.Background(), &domain.Change{Op: domain.ChangeOpUpsert, ID: "3"}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	related, err = searchService.RelatedPosts(context.Background(), post)
+	if err != nil || len(related) != 1 || related[0].ID != 3 {
+		t.Errorf("Expected refreshed post 3, got %v (%v)", related, err)
+	}
+}
+
+func TestCacheSink_InvalidatesAffectedKeys(t *testing.T) {
+	postCache := NewMockCacheRepository()
+	for _, key := range []string{PostCacheKey(1), PostCacheKey(2), AllPostsCacheKey, RelatedPostsVersionKey} {
+		postCache.values[key] = []byte("{}")
+	}
+
+	if err := NewCacheSink(postCache).Apply(context.Background(), &domain.Change{Op: domain.ChangeOpUpsert, ID: "1"}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if _, ok := postCache.values[PostCacheKey(1)]; ok {
+		t.Error("Expected post:1 to be invalidated")
+	}
+	if _, ok := postCache.values[AllPostsCacheKey]; ok {
+		t.Error("Expected posts:all to be invalidated")
+	}
+	if _, ok := postCache.values[RelatedPostsVersionKey]; ok {
+		t.Error("Expected the related posts version to be invalidated")
+	}
+	if _, ok := postCache.values[PostCacheKey(2)]; !ok {
+		t.Error("Expected post:2 to stay cached")
+	}
+
+	if err := NewCacheSink(postCache).Apply(context.Background(), &domain.Change{Op: domain.ChangeOpDelete, ID: "abc"}); err == nil {
+		t.Error("Expected error for a non-numeric post ID")
+	}
+}
@@ -13,43 +13,79 @@ import (
 type CDCService struct {
 	messageQueue domain.MessageQueueRepository
 	searchIndex  domain.SearchIndexRepository
+	searchSink   *SearchIndexSink
+	sinks        *SinkDispatcher
+	embedder     domain.Embedder
 }
 
-// NewCDCService creates a new CDC service instance
+// sinkDrainTimeout bounds how long StartCDC waits for async sinks on shutdown
+const sinkDrainTimeout = 30 * time.Second
+
+// NewCDCService creates a new CDC service instance. Changes are written to the
+// search index synchronously; further sinks can be added through Sinks.
 func NewCDCService(messageQueue domain.MessageQueueRepository, searchIndex domain.SearchIndexRepository) *CDCService {
+	searchSink := NewSearchIndexSink(searchIndex, "posts")
 	return &CDCService{
 		messageQueue: messageQueue,
 		searchIndex:  searchIndex,
+		searchSink:   searchSink,
+		sinks:        NewSinkDispatcher(SinkConfig{Sink: searchSink}),
 	}
 }
 
+// Sinks returns the dispatcher that fans changes out to the sinks
+func (s *CDCService) Sinks() *SinkDispatcher {
+	return s.sinks
+}
+
+// SetEmbedder makes the search index sink embed every upserted post for
+// semantic search. It must be called before StartCDC.
+func (s *CDCService) SetEmbedder(embedder domain.Embedder) {
+	s.embedder = embedder
+	s.searchSink.SetEmbedder(embedder)
+}
+
 // StartCDC starts the CDC pipeline
 func (s *CDCService) StartCDC(ctx context.Context, queueName string) error {
 	log.Printf("Starting CDC service, listening to queue: %s", queueName)
 
 	// Connect to RabbitMQ
-	if err := s.messageQueue.Connect(); err != nil {
+	if err := s.messageQueue.Connect(ctx); err != nil {
 		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
 	defer s.messageQueue.Close()
 
 	// Connect to Typesense√
-	if err := s.searchIndex.Connect(); err != nil {
+	if err := s.searchIndex.Connect(ctx); err != nil {
 		return fmt.Errorf("failed to connect to Typesense: %w", err)
 	}
 	defer s.searchIndex.Close()
 
 	// Ensure the posts collection exists
-	if err := s.ensurePostsCollection(); err != nil {
+	if err := s.ensurePostsCollection(ctx); err != nil {
 		return fmt.Errorf("failed to ensure posts collection: %w", err)
 	}
 
-	// Start consuming messages
-	return s.messageQueue.ConsumeMessages(queueName, s.handleMessage)
+	// Start the sink workers and drain them once consuming stops
+	if err := s.sinks.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start sinks: %w", err)
+	}
+	defer func() {
+		drainCtx, cancel := context.WithTimeout(context.Background(), sinkDrainTimeout)
+		defer cancel()
+		if err := s.sinks.Stop(drainCtx); err != nil {
+			log.Printf("Failed to drain sinks: %v", err)
+		}
+		s.sinks.LogStats()
+	}()
+
+	// Start consuming messages; this blocks until ctx is cancelled and the
+	// in-flight messages have been processed
+	return s.messageQueue.ConsumeMessages(ctx, queueName, s.handleMessage)
 }
 
 // handleMessage processes individual messages from RabbitMQ
-func (s *CDCService) handleMessage(message []byte) error {
+func (s *CDCService) handleMessage(ctx context.Context, message []byte) error {
 	// Parse the CDC event (try Debezium format first, fallback to original format)
 	event, err := domain.FromDebeziumJSON(message)
 	if err != nil {
@@ -72,9 +108,9 @@ func (s *CDCService) handleMessage(message []byte) error {
 	// Process the event based on its type
 	switch event.Type {
 	case domain.EventTypeInsert, domain.EventTypeUpdate, domain.EventTypeBootstrapInsert:
-		return s.handleUpsert(event)
+		return s.handleUpsert(ctx, event)
 	case domain.EventTypeDelete:
-		return s.handleDelete(event)
+		return s.handleDelete(ctx, event)
 	case domain.EventTypeBootstrapStart:
 		log.Printf("Bootstrap insert event received")
 		return nil
@@ -139,7 +175,7 @@ func (s *CDCService) convertTimestamps(data map[string]interface{}) {
 }
 
 // handleUpsert processes insert, update, and bootstrap events
-func (s *CDCService) handleUpsert(event *domain.CDCEvent) error {
+func (s *CDCService) handleUpsert(ctx context.Context, event *domain.CDCEvent) error {
 	eventType := event.Type
 	log.Printf("Processing %s event for post ID: %v", eventType, event.Data["id"])
 
@@ -153,19 +189,17 @@ func (s *CDCService) handleUpsert(event *domain.CDCEvent) error {
 		return err
 	}
 
-	// Upsert the document to Typesense
-	collectionName := "posts"
-	if err := s.searchIndex.UpsertDocument(collectionName, doc); err != nil {
-		log.Printf("Failed to upsert document to Typesense: %v", err)
-		return err
-	}
-
-	log.Printf("Successfully indexed post ID: %s", doc.ID)
-	return nil
+	// Deliver the change to every sink
+	return s.sinks.Dispatch(ctx, &domain.Change{
+		Op:       domain.ChangeOpUpsert,
+		ID:       doc.ID,
+		Document: doc,
+		Event:    event,
+	})
 }
 
 // handleDelete processes delete events
-func (s *CDCService) handleDelete(event *domain.CDCEvent) error {
+func (s *CDCService) handleDelete(ctx context.Context, event *domain.CDCEvent) error {
 	log.Printf("Processing delete event for post ID: %v", event.Data["id"])
 
 	// Get the ID from the event data
@@ -175,20 +209,16 @@ func (s *CDCService) handleDelete(event *domain.CDCEvent) error {
 		return fmt.Errorf("failed to extract ID from delete event")
 	}
 
-	// Delete the document from Typesense
-	collectionName := "posts"
-	documentID := fmt.Sprintf("%d", id)
-	if err := s.searchIndex.DeleteDocument(collectionName, documentID); err != nil {
-		log.Printf("Failed to delete document from Typesense: %v", err)
-		return err
-	}
-
-	log.Printf("Successfully removed post ID: %d from index", id)
-	return nil
+	// Deliver the change to every sink
+	return s.sinks.Dispatch(ctx, &domain.Change{
+		Op:    domain.ChangeOpDelete,
+		ID:    fmt.Sprintf("%d", id),
+		Event: event,
+	})
 }
 
 // ensurePostsCollection ensures the posts collection exists in Typesense
-func (s *CDCService) ensurePostsCollection() error {
+func (s *CDCService) ensurePostsCollection(ctx context.Context) error {
 	schema := map[string]interface{}{
 		"name": "posts",
 		"fields": []map[string]interface{}{
@@ -197,18 +227,41 @@ func (s *CDCService) ensurePostsCollection() error {
 			{"name": "image", "type": "string", "optional": true, "facet": false, "index": false},
 			{"name": "excerpt", "type": "string", "optional": true, "facet": false, "index": true},
 			{"name": "body", "type": "string", "facet": false, "index": true},
+			{"name": "author", "type": "string", "optional": true, "facet": true},
+			{"name": "category", "type": "string", "optional": true, "facet": true},
+			{"name": "language", "type": "string", "optional": true, "facet": true},
+			{"name": "tags", "type": "string[]", "optional": true, "facet": true},
+			{"name": "has_image", "type": "bool", "optional": true, "facet": true},
+			{"name": "year", "type": "string", "optional": true, "facet": true},
+			{"name": "month", "type": "string", "optional": true, "facet": true},
 			{"name": "created_at", "type": "int64", "facet": false, "index": true},
-			{"name": "updated_at", "type": "int64", "facet": false, "index": false},
+			{"name": "updated_at", "type": "int64", "facet": false, "index": true},
 		},
 		"default_sorting_field": "created_at",
 	}
 
+	// Languages in SearchLocales get text fields of their own, which Typesense
+	// tokenizes with the language as locale
+	for _, language := range domain.SearchLocales {
+		for _, field := range domain.LocalizedFields {
+			schema["fields"] = append(schema["fields"].([]map[string]interface{}), map[string]interface{}{
+				"name": domain.LocalizedField(field, language), "type": "string", "optional": true, "locale": language,
+			})
+		}
+	}
+
+	// Embeddings get a vector field sized for the embedder
+	if s.embedder != nil {
+		schema["fields"] = append(schema["fields"].([]map[string]interface{}), map[string]interface{}{
+			"name": domain.EmbeddingField, "type": "float[]", "num_dim": s.embedder.Dimensions(), "optional": true,
+		})
+	}
+
 	// Try to create the collection first
-	err := s.searchIndex.CreateCollection(schema)
+	err := s.searchIndex.CreateCollection(ctx, schema)
 	if err != nil {
-		// If collection already exists, we need to ensure it has the right schema
-		// For now, we'll just log this and continue
-		// In a production environment, you might want to handle schema updates
+		// Existing collections are migrated by the repository, so a failure
+		// here is logged and the service carries on
 		log.Printf("Collection creation failed (might already exist): %v", err)
 	}
 
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -14,11 +15,12 @@ type MockMessageQueueRepository struct {
 	connectCalled bool
 	closeCalled   bool
 	consumeCalled bool
-	handler       func([]byte) error
+	consumeCtx    context.Context
+	handler       domain.MessageHandler
 	connectError  error
 }
 
-func (m *MockMessageQueueRepository) Connect() error {
+func (m *MockMessageQueueRepository) Connect(ctx context.Context) error {
 	m.connectCalled = true
 	return m.connectError
 }
@@ -28,13 +30,14 @@ func (m *MockMessageQueueRepository) Close() error {
 	return nil
 }
 
-func (m *MockMessageQueueRepository) ConsumeMessages(queueName string, handler func([]byte) error) error {
+func (m *MockMessageQueueRepository) ConsumeMessages(ctx context.Context, queueName string, handler domain.MessageHandler) error {
 	m.consumeCalled = true
+	m.consumeCtx = ctx
 	m.handler = handler
 	return nil
 }
 
-func (m *MockMessageQueueRepository) PublishMessage(exchange, routingKey string, message []byte) error {
+func (m *MockMessageQueueRepository) PublishMessage(ctx context.Context, exchange, routingKey string, message []byte) error {
 	return nil
 }
 
@@ -46,9 +49,11 @@ type MockSearchIndexRepository struct {
 	deleteDocumentCalled   bool
 	connectError           error
 	createCollectionError  error
+	schema                 map[string]interface{}
+	upsertedDocument       interface{}
 }
 
-func (m *MockSearchIndexRepository) Connect() error {
+func (m *MockSearchIndexRepository) Connect(ctx context.Context) error {
 	m.connectCalled = true
 	return m.connectError
 }
@@ -58,29 +63,39 @@ func (m *MockSearchIndexRepository) Close() error {
 	return nil
 }
 
-func (m *MockSearchIndexRepository) CreateCollection(schema map[string]interface{}) error {
+func (m *MockSearchIndexRepository) CreateCollection(ctx context.Context, schema map[string]interface{}) error {
 	m.createCollectionCalled = true
+	m.schema = schema
 	return m.createCollectionError
 }
 
-func (m *MockSearchIndexRepository) UpsertDocument(collectionName string, document interface{}) error {
+func (m *MockSearchIndexRepository) UpsertDocument(ctx context.Context, collectionName string, document interface{}) error {
 	m.upsertDocumentCalled = true
+	m.upsertedDocument = document
 	return nil
 }
 
-func (m *MockSearchIndexRepository) DeleteDocument(collectionName string, documentID string) error {
+func (m *MockSearchIndexRepository) DeleteDocument(ctx context.Context, collectionName string, documentID string) error {
 	m.deleteDocumentCalled = true
 	return nil
 }
 
-func (m *MockSearchIndexRepository) SearchDocuments(collectionName, query string, searchParams map[string]interface{}) ([]interface{}, error) {
+func (m *MockSearchIndexRepository) SearchDocuments(ctx context.Context, collectionName, query string, searchParams map[string]interface{}) (*domain.SearchResults, error) {
 	return nil, nil
 }
 
-func (m *MockSearchIndexRepository) GetAllDocuments(collectionName string) ([]interface{}, error) {
+func (m *MockSearchIndexRepository) GetAllDocuments(ctx context.Context, collectionName string) ([]interface{}, error) {
 	return nil, nil
 }
 
+func (m *MockSearchIndexRepository) SyncSynonyms(ctx context.Context, collectionName string, synonyms []*domain.Synonym) error {
+	return nil
+}
+
+func (m *MockSearchIndexRepository) SyncCurations(ctx context.Context, collectionName string, curations []*domain.Curation) error {
+	return nil
+}
+
 func TestNewCDCService(t *testing.T) {
 	mockMQ := &MockMessageQueueRepository{}
 	mockSearch := &MockSearchIndexRepository{}
@@ -220,6 +235,30 @@ func TestCDCService_StartCDC_Success(t *testing.T) {
 	}
 }
 
+func TestCDCService_StartCDC_PropagatesContext(t *testing.T) {
+	mockMQ := &MockMessageQueueRepository{}
+	mockSearch := &MockSearchIndexRepository{}
+
+	service := NewCDCService(mockMQ, mockSearch)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := service.StartCDC(ctx, "test-queue"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if mockMQ.consumeCtx == nil {
+		t.Fatal("Expected ConsumeMessages to receive a context")
+	}
+
+	cancel()
+
+	select {
+	case <-mockMQ.consumeCtx.Done():
+	default:
+		t.Error("Expected consumer context to be cancelled with the service context")
+	}
+}
+
 func TestCDCService_StartCDC_MessageQueueConnectError(t *testing.T) {
 	mockMQ := &MockMessageQueueRepository{
 		connectError: errors.New("connection failed"),
@@ -330,7 +369,7 @@ func TestCDCService_handleMessage_ValidInsertEvent(t *testing.T) {
 	}
 
 	// Handle the message
-	err = service.handleMessage(message)
+	err = service.handleMessage(context.Background(), message)
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
@@ -367,7 +406,7 @@ func TestCDCService_handleMessage_ValidUpdateEvent(t *testing.T) {
 	}
 
 	// Handle the message
-	err = service.handleMessage(message)
+	err = service.handleMessage(context.Background(), message)
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
@@ -400,7 +439,7 @@ func TestCDCService_handleMessage_ValidDeleteEvent(t *testing.T) {
 	}
 
 	// Handle the message
-	err = service.handleMessage(message)
+	err = service.handleMessage(context.Background(), message)
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
@@ -419,7 +458,7 @@ func TestCDCService_handleMessage_InvalidJSON(t *testing.T) {
 	// Invalid JSON message
 	invalidMessage := []byte(`{"invalid": json}`)
 
-	err := service.handleMessage(invalidMessage)
+	err := service.handleMessage(context.Background(), invalidMessage)
 	if err == nil {
 		t.Error("Expected error for invalid JSON")
 	}
@@ -443,7 +482,7 @@ func TestCDCService_handleMessage_InvalidEvent(t *testing.T) {
 		t.Fatalf("Failed to convert event to JSON: %v", err)
 	}
 
-	err = service.handleMessage(message)
+	err = service.handleMessage(context.Background(), message)
 	if err == nil {
 		t.Error("Expected error for invalid event")
 	}
@@ -469,7 +508,7 @@ func TestCDCService_handleMessage_NonPostsTable(t *testing.T) {
 	}
 
 	// Should not error, just skip
-	err = service.handleMessage(message)
+	err = service.handleMessage(context.Background(), message)
 	if err != nil {
 		t.Errorf("Expected no error for non-posts table, got: %v", err)
 	}
@@ -499,7 +538,7 @@ func TestCDCService_handleMessage_UnknownEventType(t *testing.T) {
 		t.Fatalf("Failed to convert event to JSON: %v", err)
 	}
 
-	err = service.handleMessage(message)
+	err = service.handleMessage(context.Background(), message)
 	if err == nil {
 		t.Error("Expected error for unknown event type")
 	}
@@ -508,3 +547,63 @@ func TestCDCService_handleMessage_UnknownEventType(t *testing.T) {
 		t.Errorf("Expected specific error message, got: %v", err)
 	}
 }
+
+func TestCDCService_handleMessage_EmbedsPost(t *testing.T) {
+	mockSearch := &MockSearchIndexRepository{}
+	embedder := &fakeEmbedder{}
+	service := NewCDCService(&MockMessageQueueRepository{}, mockSearch)
+	service.SetEmbedder(embedder)
+
+	message, err := (&domain.CDCEvent{
+		Database: "blog",
+		Table:    "posts",
+		Type:     domain.EventTypeInsert,
+		Data:     map[string]interface{}{"id": 1, "title": "Goroutines", "excerpt": "In short", "body": "Channels and workers"},
+	}).ToJSON()
+	if err != nil {
+		t.Fatalf("Failed to convert event to JSON: %v", err)
+	}
+
+	if err := service.handleMessage(context.Background(), message); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(embedder.texts) != 1 || embedder.texts[0] != "Goroutines\nIn short\nChannels and workers" {
+		t.Errorf("Expected title, excerpt and body to be embedded, got %q", embedder.texts)
+	}
+	doc, ok := mockSearch.upsertedDocument.(*domain.SearchDocument)
+	if !ok || !reflect.DeepEqual(doc.Embedding, []float32{6, 40}) {
+		t.Errorf("Expected the embedding to be indexed, got %+v", mockSearch.upsertedDocument)
+	}
+
+	// A failing embedder fails the message so it is retried, but only the
+	// search index misses the change; no other sink sees the vector
+	history := &recordingSink{name: "history"}
+	service.Sinks().Add(SinkConfig{Sink: history})
+	embedder.err = errors.New("embedder failed")
+	if err := service.handleMessage(context.Background(), message); err == nil {
+		t.Error("Expected an error when embedding fails")
+	}
+	if history.appliedCount() != 1 || history.applied[0].Document.Embedding != nil {
+		t.Errorf("Expected the other sinks to get the change without a vector, got %+v", history.applied)
+	}
+}
+
+func TestCDCService_ensurePostsCollection_EmbeddingField(t *testing.T) {
+	mockSearch := &MockSearchIndexRepository{}
+	service := NewCDCService(&MockMessageQueueRepository{}, mockSearch)
+
+	service.ensurePostsCollection(context.Background())
+	for _, field := range mockSearch.schema["fields"].([]map[string]interface{}) {
+		if field["name"] == domain.EmbeddingField {
+			t.Fatal("Expected no embedding field without an embedder")
+		}
+	}
+
+	service.SetEmbedder(&fakeEmbedder{})
+	service.ensurePostsCollection(context.Background())
+	fields := mockSearch.schema["fields"].([]map[string]interface{})
+	last := fields[len(fields)-1]
+	if last["name"] != domain.EmbeddingField || last["type"] != "float[]" || last["num_dim"] != 2 {
+		t.Errorf("Unexpected embedding field: %v", last)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"blog-cdc-search/domain"
+)
+
+func TestPostHistoryService_GetVersion(t *testing.T) {
+	repo := &memoryPostVersionRepository{}
+	sink := NewPostHistorySink(repo)
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeInsert, Data: postRow(1, "Draft", "Body")})
+	applyHistory(t, sink, &domain.CDCEvent{Table: "posts", Type: domain.EventTypeUpdate, Data: postRow(1, "Final", "New body")})
+
+	svc := NewPostHistoryService(repo, NewPostService(NewMockPostRepository()))
+
+	diff, err := svc.GetVersion(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if diff.Previous == nil || diff.Previous.Version != 1 {
+		t.Fatalf("Expected version 1 as the previous version, got %+v", diff.Previous)
+	}
+	want := []domain.FieldChange{{Field: "title", Old: "Draft", New: "Final"}, {Field: "body", Old: "Body", New: "New body"}}
+	if len(diff.Changes) != len(want) || diff.Changes[0] != want[0] || diff.Changes[1] != want[1] {
+		t.Errorf("Expected changes %+v, got %+v", want, diff.Changes)
+	}
+
+	if _, err := svc.GetVersion(context.Background(), 1, 3); !errors.Is(err, domain.ErrPostVersionNotFound) {
+		t.Errorf("Expected ErrPostVersionNotFound, got %v", err)
+	}
+}
+
+func TestPostHistoryService_RestoreVersion(t *testing.T) {
+	posts := NewMockPostRepository()
+	postService := NewPostService(posts)
+	post, err := postService.CreatePost(context.Background(), "Final", "", "", "New body", "Grace", "News", "de", []string{"go"})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+
+	repo := &memoryPostVersionRepository{}
+	repo.CreateVersion(context.Background(), &domain.PostVersion{
+		PostID: post.ID, Version: 1, Operation: domain.PostVersionCreated, Title: "Draft", Body: "Body",
+		Author: "Ada", Category: "Deep Dives", Language: "en", Tags: []string{"cdc"},
+	})
+	svc := NewPostHistoryService(repo, postService)
+
+	restored, err := svc.RestoreVersion(context.Background(), post.ID, 1)
+	if err != nil {
+		t.Fatalf("RestoreVersion failed: %v", err)
+	}
+	if restored.Title != "Draft" || restored.Body != "Body" {
+		t.Errorf("Expected the version 1 content, got %+v", restored)
+	}
+	if restored.Author != "Ada" || restored.Category != "Deep Dives" || restored.Language != "en" || len(restored.Tags) != 1 || restored.Tags[0] != "cdc" {
+		t.Errorf("Expected the version 1 metadata, got %+v", restored)
+	}
+	if stored, _ := posts.GetByID(context.Background(), post.ID); stored.Title != "Draft" {
+		t.Errorf("Expected the restore to update the post, got %+v", stored)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// ReplayOptions control an archive replay
+type ReplayOptions struct {
+	Until      time.Time // skip events that happened after this (zero replays everything)
+	ResetIndex bool      // delete every indexed post before replaying
+}
+
+// ReplayStats summarizes a replay
+type ReplayStats struct {
+	Replayed int
+	Skipped  int
+}
+
+// Replay feeds archived events through the same path as queue messages,
+// rebuilding the search index as it was at opts.Until. Only the sinks
+// registered on the service receive the events.
+func (s *CDCService) Replay(ctx context.Context, source domain.EventSource, opts ReplayOptions) (ReplayStats, error) {
+	var stats ReplayStats
+
+	if err := s.searchIndex.Connect(ctx); err != nil {
+		return stats, fmt.Errorf("failed to connect to search index: %w", err)
+	}
+	defer s.searchIndex.Close()
+
+	if err := s.ensurePostsCollection(ctx); err != nil {
+		return stats, fmt.Errorf("failed to ensure posts collection: %w", err)
+	}
+	if opts.ResetIndex {
+		if err := s.clearPosts(ctx); err != nil {
+			return stats, err
+		}
+	}
+
+	if err := s.sinks.Start(ctx); err != nil {
+		return stats, fmt.Errorf("failed to start sinks: %w", err)
+	}
+	defer func() {
+		drainCtx, cancel := context.WithTimeout(context.Background(), sinkDrainTimeout)
+		defer cancel()
+		if err := s.sinks.Stop(drainCtx); err != nil {
+			log.Printf("Failed to drain sinks: %v", err)
+		}
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		event, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, fmt.Errorf("failed to read archived event: %w", err)
+		}
+
+		if !opts.Until.IsZero() && event.Time().After(opts.Until) {
+			stats.Skipped++
+			continue
+		}
+
+		message, err := json.Marshal(event)
+		if err != nil {
+			return stats, fmt.Errorf("failed to encode archived event: %w", err)
+		}
+		if err := s.handleMessage(ctx, message); err != nil {
+			return stats, fmt.Errorf("failed to replay event at %d: %w", event.TS, err)
+		}
+		stats.Replayed++
+	}
+}
+
+// clearPosts deletes every document from the posts collection
+func (s *CDCService) clearPosts(ctx context.Context) error {
+	documents, err := s.searchIndex.GetAllDocuments(ctx, "posts")
+	if err != nil {
+		return fmt.Errorf("failed to list indexed posts: %w", err)
+	}
+
+	for _, document := range documents {
+		fields, ok := document.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id := fmt.Sprintf("%v", fields["id"])
+		if err := s.searchIndex.DeleteDocument(ctx, "posts", id); err != nil {
+			return fmt.Errorf("failed to delete indexed post %s: %w", id, err)
+		}
+	}
+	log.Printf("Deleted %d indexed posts before replay", len(documents))
+	return nil
+}
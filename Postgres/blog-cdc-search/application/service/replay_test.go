@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"blog-cdc-search/domain"
+)
+
+// memorySearchIndex keeps indexed posts in a map
+type memorySearchIndex struct {
+	MockSearchIndexRepository
+	documents map[string]*domain.SearchDocument
+}
+
+func (m *memorySearchIndex) UpsertDocument(ctx context.Context, collectionName string, document interface{}) error {
+	doc := document.(*domain.SearchDocument)
+	m.documents[doc.ID] = doc
+	return nil
+}
+
+func (m *memorySearchIndex) DeleteDocument(ctx context.Context, collectionName string, documentID string) error {
+	delete(m.documents, documentID)
+	return nil
+}
+
+func (m *memorySearchIndex) GetAllDocuments(ctx context.Context, collectionName string) ([]interface{}, error) {
+	var documents []interface{}
+	for id := range m.documents {
+		documents = append(documents, map[string]interface{}{"id": id})
+	}
+	return documents, nil
+}
+
+func (m *memorySearchIndex) SyncSynonyms(ctx context.Context, collectionName string, synonyms []*domain.Synonym) error {
+	return nil
+}
+
+func (m *memorySearchIndex) SyncCurations(ctx context.Context, collectionName string, curations []*domain.Curation) error {
+	return nil
+}
+
+// sliceEventSource replays a fixed list of events
+type sliceEventSource []*domain.CDCEvent
+
+func (s *sliceEventSource) Next() (*domain.CDCEvent, error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+	event := (*s)[0]
+	*s = (*s)[1:]
+	return event, nil
+}
+
+func archivedPost(eventType string, id int, title string, ts int64) *domain.CDCEvent {
+	return &domain.CDCEvent{
+		Database: "blog",
+		Table:    "posts",
+		Type:     eventType,
+		Data:     map[string]interface{}{"id": float64(id), "title": title},
+		TS:       ts,
+	}
+}
+
+func TestCDCService_Replay(t *testing.T) {
+	index := &memorySearchIndex{documents: map[string]*domain.SearchDocument{
+		"99": {ID: "99", Title: "Stale"},
+	}}
+	svc := NewCDCService(&MockMessageQueueRepository{}, index)
+
+	source := sliceEventSource{
+		archivedPost(domain.EventTypeInsert, 1, "First", 1),
+		archivedPost(domain.EventTypeInsert, 2, "Second", 2),
+		archivedPost(domain.EventTypeUpdate, 1, "First, edited", 3),
+		{Database: "blog", Table: "comments", Type: domain.EventTypeInsert, Data: map[string]interface{}{"id": float64(5)}, TS: 3},
+		archivedPost(domain.EventTypeDelete, 2, "Second", 4),
+		archivedPost(domain.EventTypeInsert, 3, "Third", 5),
+	}
+
+	// Rebuild the index as it was right after the edit
+	until := (&domain.CDCEvent{TS: 3}).Time()
+	stats, err := svc.Replay(context.Background(), &source, ReplayOptions{Until: until, ResetIndex: true})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if stats.Replayed != 4 || stats.Skipped != 2 {
+		t.Errorf("Expected 4 replayed and 2 skipped, got %+v", stats)
+	}
+	if len(index.documents) != 2 {
+		t.Fatalf("Expected posts 1 and 2 in the index, got %v", index.documents)
+	}
+	if doc := index.documents["1"]; doc == nil || doc.Title != "First, edited" {
+		t.Errorf("Expected post 1 to be edited, got %+v", doc)
+	}
+	if _, ok := index.documents["99"]; ok {
+		t.Error("Expected the reset to remove posts that are not in the archive")
+	}
+}
+
+func TestCDCService_ReplayStopsOnInvalidEvent(t *testing.T) {
+	index := &memorySearchIndex{documents: map[string]*domain.SearchDocument{}}
+	svc := NewCDCService(&MockMessageQueueRepository{}, index)
+
+	source := sliceEventSource{
+		archivedPost(domain.EventTypeInsert, 1, "First", 1),
+		{Database: "blog", Table: "posts", Type: "truncate", Data: map[string]interface{}{}, TS: 2},
+		archivedPost(domain.EventTypeInsert, 2, "Second", 3),
+	}
+
+	stats, err := svc.Replay(context.Background(), &source, ReplayOptions{})
+	if err == nil {
+		t.Fatal("Expected the unknown event to stop the replay")
+	}
+	if stats.Replayed != 1 || len(index.documents) != 1 {
+		t.Errorf("Expected only the first event to be replayed, got %+v", stats)
+	}
+}
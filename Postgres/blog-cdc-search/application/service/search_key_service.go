@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// Default lifetimes of the keys handed to browsers
+const (
+	DefaultSearchKeyTTL      = time.Hour
+	DefaultSearchKeyRotation = 24 * time.Hour
+)
+
+// searchKeyDescription marks the parent keys created by the blog, so expired
+// ones can be told apart from other keys and deleted
+const searchKeyDescription = "blog: scoped search parent key"
+
+// SearchKeyConfig configures the scoped search keys
+type SearchKeyConfig struct {
+	// Filter is embedded in every scoped key as filter_by, so browsers only
+	// ever find matching posts whatever filter they send
+	Filter string
+	// TTL is how long a scoped key stays valid
+	TTL time.Duration
+	// Rotation is how often the parent key is replaced
+	Rotation time.Duration
+	// Nodes are the index URLs browsers send their searches to
+	Nodes []string
+}
+
+// ScopedSearchKey is the key and connection details a browser needs to
+// search the index directly
+type ScopedSearchKey struct {
+	Key        string    `json:"key"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Collection string    `json:"collection"`
+	Nodes      []string  `json:"nodes"`
+}
+
+// SearchKeyService hands out short-lived scoped search keys. They are
+// derived from a search-only parent key, which is replaced every Rotation;
+// the admin key never leaves the server.
+type SearchKeyService struct {
+	keys   domain.SearchKeyRepository
+	config SearchKeyConfig
+	now    func() time.Time
+
+	mu     sync.Mutex
+	parent *domain.SearchKey
+}
+
+// NewSearchKeyService creates a new search key service. Unset lifetimes get
+// the defaults, and the TTL is capped at the rotation interval.
+func NewSearchKeyService(keys domain.SearchKeyRepository, config SearchKeyConfig) *SearchKeyService {
+	if config.TTL <= 0 {
+		config.TTL = DefaultSearchKeyTTL
+	}
+	if config.Rotation <= 0 {
+		config.Rotation = DefaultSearchKeyRotation
+	}
+	if config.TTL > config.Rotation {
+		config.TTL = config.Rotation
+	}
+
+	return &SearchKeyService{
+		keys:   keys,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Key returns a new scoped key for the posts collection. A parent key is
+// created first if there is none yet or rotation is overdue, for example
+// because the index was down at startup.
+func (s *SearchKeyService) Key(ctx context.Context) (*ScopedSearchKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	expiresAt := now.Add(s.config.TTL)
+	if s.parent == nil || s.parent.ExpiresAt.Before(expiresAt) {
+		if err := s.rotate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	params := map[string]interface{}{
+		"expires_at": expiresAt.Unix(),
+		// Vectors are of no use to browsers and make every hit far larger
+		"exclude_fields": "embedding",
+	}
+	if s.config.Filter != "" {
+		params["filter_by"] = s.config.Filter
+	}
+
+	key, err := s.keys.ScopeSearchKey(s.parent, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scope search key: %w", err)
+	}
+
+	return &ScopedSearchKey{
+		Key:        key,
+		ExpiresAt:  time.Unix(expiresAt.Unix(), 0).UTC(),
+		Collection: "posts",
+		Nodes:      s.config.Nodes,
+	}, nil
+}
+
+// Rotate replaces the parent key and deletes the blog's expired ones
+func (s *SearchKeyService) Rotate(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rotate(ctx)
+}
+
+// rotate creates a parent key valid for one rotation plus the TTL, so scoped
+// keys handed out just before the next rotation do not outlive it. The
+// previous parent is left to expire on its own for the same reason.
+func (s *SearchKeyService) rotate(ctx context.Context) error {
+	now := s.now()
+	parent, err := s.keys.CreateSearchKey(ctx, searchKeyDescription, []string{"posts"}, now.Add(s.config.Rotation+s.config.TTL))
+	if err != nil {
+		return fmt.Errorf("failed to rotate search key: %w", err)
+	}
+	s.parent = parent
+
+	existing, err := s.keys.ListSearchKeys(ctx)
+	if err != nil {
+		log.Printf("Failed to list expired search keys: %v", err)
+		return nil
+	}
+	for _, key := range existing {
+		if key.Description != searchKeyDescription || key.ExpiresAt.After(now) {
+			continue
+		}
+		if err := s.keys.DeleteSearchKey(ctx, key.ID); err != nil {
+			log.Printf("Failed to delete expired search key: %v", err)
+		}
+	}
+	return nil
+}
+
+// RunRotation creates a parent key now and rotates it every Rotation until
+// ctx is done. Failures are logged and retried on the next tick; Key creates
+// the parent itself while there is none.
+func (s *SearchKeyService) RunRotation(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Rotation)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Rotate(ctx); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
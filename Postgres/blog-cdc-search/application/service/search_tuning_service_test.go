@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"blog-cdc-search/domain"
+)
+
+// MockSearchTuningRepository keeps synonyms and curations in memory
+type MockSearchTuningRepository struct {
+	synonyms  []*domain.Synonym
+	curations []*domain.Curation
+	nextID    int
+}
+
+func (m *MockSearchTuningRepository) CreateSynonym(ctx context.Context, synonym *domain.Synonym) error {
+	m.nextID++
+	synonym.ID = m.nextID
+	m.synonyms = append(m.synonyms, synonym)
+	return nil
+}
+
+func (m *MockSearchTuningRepository) ListSynonyms(ctx context.Context) ([]*domain.Synonym, error) {
+	return m.synonyms, nil
+}
+
+func (m *MockSearchTuningRepository) DeleteSynonym(ctx context.Context, id int) error {
+	for i, synonym := range m.synonyms {
+		if synonym.ID == id {
+			m.synonyms = append(m.synonyms[:i:i], m.synonyms[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrSearchTuningNotFound
+}
+
+func (m *MockSearchTuningRepository) CreateCuration(ctx context.Context, curation *domain.Curation) error {
+	m.nextID++
+	curation.ID = m.nextID
+	m.curations = append(m.curations, curation)
+	return nil
+}
+
+func (m *MockSearchTuningRepository) ListCurations(ctx context.Context) ([]*domain.Curation, error) {
+	return m.curations, nil
+}
+
+func (m *MockSearchTuningRepository) DeleteCuration(ctx context.Context, id int) error {
+	for i, curation := range m.curations {
+		if curation.ID == id {
+			m.curations = append(m.curations[:i:i], m.curations[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrSearchTuningNotFound
+}
+
+func TestSearchTuningService_SynonymsSyncToIndex(t *testing.T) {
+	repo := &MockSearchTuningRepository{}
+	index := &MockSearchIndexRepositoryForSearch{}
+	service := NewSearchTuningService(repo, index)
+	ctx := context.Background()
+
+	synonym, err := service.CreateSynonym(ctx, "", []string{"Go", "golang", "go"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(synonym.Synonyms, []string{"go", "golang"}) {
+		t.Errorf("Expected normalized words, got %v", synonym.Synonyms)
+	}
+	if len(index.syncedSynonyms) != 1 || index.syncedSynonyms[0].ID != synonym.ID {
+		t.Fatalf("Expected the synonym to be synced, got %v", index.syncedSynonyms)
+	}
+
+	if err := service.DeleteSynonym(ctx, synonym.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(index.syncedSynonyms) != 0 {
+		t.Errorf("Expected the index to be emptied, got %v", index.syncedSynonyms)
+	}
+
+	if err := service.DeleteSynonym(ctx, synonym.ID); !errors.Is(err, domain.ErrSearchTuningNotFound) {
+		t.Errorf("Expected ErrSearchTuningNotFound, got %v", err)
+	}
+}
+
+func TestSearchTuningService_InvalidDefinitions(t *testing.T) {
+	repo := &MockSearchTuningRepository{}
+	service := NewSearchTuningService(repo, &MockSearchIndexRepositoryForSearch{})
+	ctx := context.Background()
+
+	if _, err := service.CreateSynonym(ctx, "", []string{"lonely"}); err == nil {
+		t.Error("Expected error for a multi-way synonym with one word")
+	}
+	if _, err := service.CreateSynonym(ctx, "cdc", []string{"CDC"}); err == nil {
+		t.Error("Expected error for a one-way synonym without synonyms")
+	}
+	if _, err := service.CreateCuration(ctx, "  ", "", []int{1}, nil); err == nil {
+		t.Error("Expected error for an empty query")
+	}
+	if _, err := service.CreateCuration(ctx, "go", "fuzzy", []int{1}, nil); err == nil {
+		t.Error("Expected error for an unknown match")
+	}
+	if _, err := service.CreateCuration(ctx, "go", "", []int{1}, []int{1}); err == nil {
+		t.Error("Expected error for a post both pinned and hidden")
+	}
+	if len(repo.synonyms) != 0 || len(repo.curations) != 0 {
+		t.Error("Expected nothing to be stored")
+	}
+}
+
+func TestSearchTuningService_CurationSyncFailureKeepsDefinition(t *testing.T) {
+	repo := &MockSearchTuningRepository{}
+	index := &MockSearchIndexRepositoryForSearch{syncError: errors.New("index down")}
+	service := NewSearchTuningService(repo, index)
+	ctx := context.Background()
+
+	curation, err := service.CreateCuration(ctx, "  Go  Tips ", "", []int{3, 1, 3}, []int{7})
+	if err == nil || !strings.Contains(err.Error(), "saved") {
+		t.Fatalf("Expected a saved-but-not-synced error, got %v", err)
+	}
+	if curation == nil || len(repo.curations) != 1 {
+		t.Fatal("Expected the curation to be stored")
+	}
+	if curation.Query != "go tips" || curation.Match != domain.CurationMatchExact {
+		t.Errorf("Unexpected query or match: %q %q", curation.Query, curation.Match)
+	}
+	if !reflect.DeepEqual(curation.Pinned, []int{3, 1}) {
+		t.Errorf("Expected deduplicated pins, got %v", curation.Pinned)
+	}
+
+	// Once the index is back, Sync pushes everything
+	index.syncError = nil
+	if err := service.Sync(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(index.syncedCurations) != 1 || index.syncedCurations[0].ID != curation.ID {
+		t.Errorf("Expected the curation to be synced, got %v", index.syncedCurations)
+	}
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// RetryPolicy controls how often a failed delivery is retried
+type RetryPolicy struct {
+	MaxAttempts    int           // total attempts per change; 0 or 1 disables retries
+	InitialBackoff time.Duration // delay before the first retry
+	MaxBackoff     time.Duration // upper bound for the doubling delay
+}
+
+// backoff returns the delay before the given retry (1 for the first retry)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// SinkConfig describes how changes are delivered to a sink
+type SinkConfig struct {
+	Sink domain.ChangeSink
+	// Async sinks get their own buffer and worker, so they never hold up the
+	// message acknowledgement while their buffer has room. Synchronous sinks
+	// are applied before the message is acknowledged and a failure sends the
+	// message back.
+	Async      bool
+	BufferSize int // buffered changes for an async sink (default 1000)
+	Retry      RetryPolicy
+}
+
+// SinkStats reports the delivery counters of a sink
+type SinkStats struct {
+	Name        string
+	Async       bool
+	Delivered   uint64
+	Failed      uint64 // changes given up on after all attempts
+	Retried     uint64
+	Skipped     uint64 // redelivered changes the sink had already applied
+	Pending     int
+	Checkpoint  int64 // highest position of an applied change
+	LastError   string
+	LastErrorAt time.Time
+}
+
+// maxCheckpointKeys bounds the keys kept for recently applied changes; past
+// it the oldest are forgotten and would be applied again if redelivered
+const maxCheckpointKeys = 1000
+
+// sinkRunner delivers changes to one sink and tracks its stats
+type sinkRunner struct {
+	config SinkConfig
+	queue  chan *domain.Change
+	mu     sync.Mutex
+	stats  SinkStats
+	// applied holds the keys of the most recently applied changes, oldest
+	// first
+	applied []string
+}
+
+// seen reports whether the sink has already applied a change with the same
+// key. Positions are not compared: a message sent back to the queue can
+// return after later ones, and its change must still be applied. Changes
+// without a position are never skipped.
+func (r *sinkRunner) seen(change *domain.Change) bool {
+	if change.Position() == 0 {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Contains(r.applied, change.Key())
+}
+
+// advance records an applied change and moves the checkpoint up to it
+func (r *sinkRunner) advance(change *domain.Change) {
+	position := change.Position()
+	if position == 0 {
+		return
+	}
+	if position > r.stats.Checkpoint {
+		r.stats.Checkpoint = position
+	}
+	r.applied = append(r.applied, change.Key())
+	if len(r.applied) > maxCheckpointKeys {
+		r.applied = r.applied[len(r.applied)-maxCheckpointKeys:]
+	}
+}
+
+// SinkDispatcher fans each change out to the configured sinks
+type SinkDispatcher struct {
+	mu          sync.RWMutex
+	runners     []*sinkRunner
+	checkpoints domain.CheckpointStore
+	started     bool
+	stopped     bool
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// NewSinkDispatcher creates a dispatcher for the given sinks
+func NewSinkDispatcher(configs ...SinkConfig) *SinkDispatcher {
+	d := &SinkDispatcher{}
+	for _, config := range configs {
+		d.Add(config)
+	}
+	return d
+}
+
+// Add registers a sink; it must be called before Start
+func (d *SinkDispatcher) Add(config SinkConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	runner := &sinkRunner{
+		config: config,
+		stats:  SinkStats{Name: config.Sink.Name(), Async: config.Async},
+	}
+	if config.Async {
+		size := config.BufferSize
+		if size <= 0 {
+			size = 1000
+		}
+		runner.queue = make(chan *domain.Change, size)
+	}
+	d.runners = append(d.runners, runner)
+}
+
+// SetCheckpointStore persists each sink's checkpoint in store
+func (d *SinkDispatcher) SetCheckpointStore(store domain.CheckpointStore) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.checkpoints = store
+}
+
+// Start restores the checkpoints and starts the async sink workers
+func (d *SinkDispatcher) Start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.started {
+		return nil
+	}
+
+	for _, runner := range d.runners {
+		if d.checkpoints == nil {
+			continue
+		}
+		checkpoint, err := d.checkpoints.Load(ctx, runner.stats.Name)
+		if err != nil {
+			return fmt.Errorf("failed to load checkpoint for sink %s: %w", runner.stats.Name, err)
+		}
+		if checkpoint != nil {
+			runner.stats.Checkpoint = checkpoint.Position
+			runner.applied = checkpoint.Applied
+			runner.stats.Delivered = checkpoint.Delivered
+			runner.stats.Failed = checkpoint.Failed
+		}
+	}
+
+	// Workers outlive ctx so buffered changes can drain on Stop
+	workCtx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	for _, runner := range d.runners {
+		if !runner.config.Async {
+			continue
+		}
+		d.wg.Add(1)
+		go func(runner *sinkRunner) {
+			defer d.wg.Done()
+			for change := range runner.queue {
+				if err := d.deliver(workCtx, runner, change); err != nil {
+					log.Printf("Sink %s gave up on post ID %s: %v", runner.stats.Name, change.ID, err)
+				}
+			}
+		}(runner)
+	}
+	d.started = true
+	return nil
+}
+
+// Dispatch applies the change to every synchronous sink and, once they all
+// have, queues it for every async one. It returns the first synchronous sink
+// error, so the message is redelivered before any async sink sees it; async
+// sink failures are only recorded in their stats. A full async buffer is
+// waited on until ctx is done, which also fails the dispatch rather than
+// losing the change.
+func (d *SinkDispatcher) Dispatch(ctx context.Context, change *domain.Change) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.stopped {
+		return fmt.Errorf("sink dispatcher is stopped")
+	}
+
+	var firstErr error
+	for _, runner := range d.runners {
+		if runner.config.Async {
+			continue
+		}
+		if err := d.deliver(ctx, runner, change); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("sink %s: %w", runner.stats.Name, err)
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	for _, runner := range d.runners {
+		if !runner.config.Async {
+			continue
+		}
+		select {
+		case runner.queue <- change:
+		default:
+			log.Printf("Sink %s buffer is full, waiting to queue change for post ID %s", runner.stats.Name, change.ID)
+			select {
+			case runner.queue <- change:
+			case <-ctx.Done():
+				return fmt.Errorf("sink %s: buffer is full: %w", runner.stats.Name, ctx.Err())
+			}
+		}
+	}
+	return nil
+}
+
+// deliver applies a change to one sink, retrying per its policy. Changes the
+// sink has already applied are skipped.
+func (d *SinkDispatcher) deliver(ctx context.Context, runner *sinkRunner, change *domain.Change) error {
+	if runner.seen(change) {
+		runner.mu.Lock()
+		runner.stats.Skipped++
+		runner.mu.Unlock()
+		return nil
+	}
+
+	policy := runner.config.Retry
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	err := runner.config.Sink.Apply(ctx, change)
+	for retry := 1; err != nil && retry < attempts; retry++ {
+		runner.mu.Lock()
+		runner.stats.Retried++
+		runner.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return d.record(ctx, runner, change, fmt.Errorf("%w (retry abandoned: %v)", err, ctx.Err()))
+		case <-time.After(policy.backoff(retry)):
+		}
+		err = runner.config.Sink.Apply(ctx, change)
+	}
+	return d.record(ctx, runner, change, err)
+}
+
+// record updates the sink's stats and checkpoint after a delivery
+func (d *SinkDispatcher) record(ctx context.Context, runner *sinkRunner, change *domain.Change, err error) error {
+	runner.mu.Lock()
+	if err != nil {
+		runner.stats.Failed++
+		runner.stats.LastError = err.Error()
+		runner.stats.LastErrorAt = time.Now()
+	} else {
+		runner.stats.Delivered++
+		runner.advance(change)
+	}
+	checkpoint := &domain.SinkCheckpoint{
+		Sink:      runner.stats.Name,
+		Position:  runner.stats.Checkpoint,
+		Applied:   slices.Clone(runner.applied),
+		Delivered: runner.stats.Delivered,
+		Failed:    runner.stats.Failed,
+		UpdatedAt: time.Now(),
+	}
+	runner.mu.Unlock()
+
+	if d.checkpoints != nil {
+		if saveErr := d.checkpoints.Save(ctx, checkpoint); saveErr != nil {
+			log.Printf("Failed to save checkpoint for sink %s: %v", checkpoint.Sink, saveErr)
+		}
+	}
+	return err
+}
+
+// Stop waits for the async sinks to drain their buffers. When ctx expires
+// first, pending retries are abandoned and count as failed.
+func (d *SinkDispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return nil
+	}
+	d.stopped = true
+	for _, runner := range d.runners {
+		if runner.queue != nil {
+			close(runner.queue)
+		}
+	}
+	started := d.started
+	d.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return fmt.Errorf("sinks did not drain: %w", ctx.Err())
+	}
+}
+
+// Stats returns a snapshot of every sink's delivery counters
+func (d *SinkDispatcher) Stats() []SinkStats {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	stats := make([]SinkStats, 0, len(d.runners))
+	for _, runner := range d.runners {
+		runner.mu.Lock()
+		snapshot := runner.stats
+		runner.mu.Unlock()
+		if runner.queue != nil {
+			snapshot.Pending = len(runner.queue)
+		}
+		stats = append(stats, snapshot)
+	}
+	return stats
+}
+
+// LogStats writes one log line per sink
+func (d *SinkDispatcher) LogStats() {
+	for _, s := range d.Stats() {
+		log.Printf("Sink %s: delivered=%d failed=%d retried=%d skipped=%d pending=%d checkpoint=%d last_error=%q",
+			s.Name, s.Delivered, s.Failed, s.Retried, s.Skipped, s.Pending, s.Checkpoint, s.LastError)
+	}
+}
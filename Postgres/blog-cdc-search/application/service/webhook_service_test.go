@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// MockWebhookRepository keeps endpoints and deliveries in memory
+type MockWebhookRepository struct {
+	mu         sync.Mutex
+	endpoints  []*domain.WebhookEndpoint
+	deliveries []*domain.WebhookDelivery
+	// failEndpoint makes logging a delivery to that endpoint fail
+	failEndpoint int
+}
+
+func (m *MockWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	endpoint.ID = len(m.endpoints) + 1
+	stored := *endpoint
+	m.endpoints = append(m.endpoints, &stored)
+	return nil
+}
+
+func (m *MockWebhookRepository) GetEndpoint(ctx context.Context, id int) (*domain.WebhookEndpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, endpoint := range m.endpoints {
+		if endpoint.ID == id {
+			copied := *endpoint
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrWebhookNotFound
+}
+
+func (m *MockWebhookRepository) ListEndpoints(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var endpoints []*domain.WebhookEndpoint
+	for _, endpoint := range m.endpoints {
+		copied := *endpoint
+		endpoints = append(endpoints, &copied)
+	}
+	return endpoints, nil
+}
+
+func (m *MockWebhookRepository) DeleteEndpoint(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, endpoint := range m.endpoints {
+		if endpoint.ID == id {
+			m.endpoints = append(m.endpoints[:i], m.endpoints[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrWebhookNotFound
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if delivery.EndpointID == m.failEndpoint {
+		return false, errors.New("database unavailable")
+	}
+	for _, stored := range m.deliveries {
+		if delivery.ChangeKey != "" && stored.ChangeKey == delivery.ChangeKey && stored.EndpointID == delivery.EndpointID {
+			*delivery = *stored
+			return false, nil
+		}
+	}
+	delivery.ID = int64(len(m.deliveries) + 1)
+	stored := *delivery
+	m.deliveries = append(m.deliveries, &stored)
+	return true, nil
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, stored := range m.deliveries {
+		if stored.ID == delivery.ID {
+			updated := *delivery
+			m.deliveries[i] = &updated
+			return nil
+		}
+	}
+	return domain.ErrWebhookNotFound
+}
+
+func (m *MockWebhookRepository) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, delivery := range m.deliveries {
+		if delivery.ID == id {
+			copied := *delivery
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrWebhookNotFound
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, limit int) ([]*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deliveries []*domain.WebhookDelivery
+	for i := len(m.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		copied := *m.deliveries[i]
+		deliveries = append(deliveries, &copied)
+	}
+	return deliveries, nil
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deliveries []*domain.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if len(deliveries) == limit {
+			break
+		}
+		if delivery.Status != domain.WebhookDeliveryPending || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		leased := now.Add(lease)
+		delivery.NextAttemptAt = &leased
+		copied := *delivery
+		deliveries = append(deliveries, &copied)
+	}
+	return deliveries, nil
+}
+
+// webhookChange builds a change as the CDC service would for an event type
+func webhookChange(eventType, id string) *domain.Change {
+	op := domain.ChangeOpUpsert
+	if eventType == domain.EventTypeDelete {
+		op = domain.ChangeOpDelete
+	}
+	return &domain.Change{
+		Op:       op,
+		ID:       id,
+		Document: &domain.SearchDocument{ID: id, Title: "Hello"},
+		Event:    &domain.CDCEvent{Type: eventType},
+	}
+}
+
+var fastWebhookRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+// advanceWebhookClock replaces the service clock with one that moves on a
+// minute per call to tick, so every scheduled retry is due after a tick
+func advanceWebhookClock(svc *WebhookService) (tick func()) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	svc.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(time.Minute)
+	}
+}
+
+func TestWebhookService_SignsPayload(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header, body: body}
+	}))
+	defer server.Close()
+
+	repo := &MockWebhookRepository{}
+	svc := NewWebhookService(repo, server.Client(), fastWebhookRetry)
+	endpoint, err := svc.CreateEndpoint(context.Background(), server.URL, "s3cret", nil)
+	if err != nil {
+		t.Fatalf("CreateEndpoint failed: %v", err)
+	}
+
+	if err := svc.Apply(context.Background(), webhookChange(domain.EventTypeInsert, "7")); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	req := <-requests
+	timestamp := req.header.Get(WebhookTimestampHeader)
+	if got, want := req.header.Get(WebhookSignatureHeader), SignWebhook(endpoint.Secret, timestamp, req.body); got != want {
+		t.Errorf("Expected signature %s, got %s", want, got)
+	}
+	if got := req.header.Get(WebhookEventHeader); got != domain.WebhookEventPostCreated {
+		t.Errorf("Expected event header %s, got %s", domain.WebhookEventPostCreated, got)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("Invalid payload: %v", err)
+	}
+	if payload.Event != domain.WebhookEventPostCreated || payload.Post == nil || payload.Post.ID != "7" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+
+	deliveries, _ := svc.ListDeliveries(context.Background(), 0)
+	if len(deliveries) != 1 || deliveries[0].Status != domain.WebhookDeliverySucceeded || deliveries[0].PostID != 7 {
+		t.Errorf("Expected one succeeded delivery for post 7, got %+v", deliveries)
+	}
+}
+
+func TestWebhookService_EventFilter(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		events = append(events, r.Header.Get(WebhookEventHeader))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	svc := NewWebhookService(&MockWebhookRepository{}, server.Client(), fastWebhookRetry)
+	if _, err := svc.CreateEndpoint(context.Background(), server.URL, "", []string{domain.WebhookEventPostDeleted}); err != nil {
+		t.Fatalf("CreateEndpoint failed: %v", err)
+	}
+
+	for _, eventType := range []string{domain.EventTypeInsert, domain.EventTypeUpdate, domain.EventTypeDelete, domain.EventTypeBootstrapInsert} {
+		if err := svc.Apply(context.Background(), webhookChange(eventType, "1")); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+	}
+
+	if len(events) != 1 || events[0] != domain.WebhookEventPostDeleted {
+		t.Errorf("Expected only post.deleted, got %v", events)
+	}
+}
+
+func TestWebhookService_RetriesThenRecordsFailure(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := &MockWebhookRepository{}
+	svc := NewWebhookService(repo, server.Client(), fastWebhookRetry)
+	tick := advanceWebhookClock(svc)
+	svc.CreateEndpoint(context.Background(), server.URL, "s3cret", nil)
+
+	if err := svc.Apply(context.Background(), webhookChange(domain.EventTypeUpdate, "3")); err != nil {
+		t.Fatalf("Endpoint failures should not fail the sink: %v", err)
+	}
+
+	// The sink makes one attempt and leaves the retries to RetryDue
+	delivery, _ := repo.GetDelivery(context.Background(), 1)
+	if calls != 1 || delivery.Status != domain.WebhookDeliveryPending || delivery.NextAttemptAt == nil {
+		t.Fatalf("Expected one attempt and a scheduled retry, got %d calls and %+v", calls, delivery)
+	}
+	if retried, _ := svc.RetryDue(context.Background()); retried != 0 {
+		t.Errorf("Expected no retry before it is due, got %d", retried)
+	}
+
+	for i := 0; i < 3; i++ {
+		tick()
+		svc.RetryDue(context.Background())
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+	delivery, _ = repo.GetDelivery(context.Background(), 1)
+	if delivery.Status != domain.WebhookDeliveryFailed || delivery.Attempts != 3 || delivery.ResponseStatus != http.StatusServiceUnavailable {
+		t.Errorf("Expected a failed delivery after 3 attempts, got %+v", delivery)
+	}
+	if delivery.Error == "" || delivery.NextAttemptAt != nil {
+		t.Errorf("Expected the failure reason and no further retry, got %+v", delivery)
+	}
+}
+
+func TestWebhookService_RetriesUntilSuccess(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	repo := &MockWebhookRepository{}
+	svc := NewWebhookService(repo, server.Client(), fastWebhookRetry)
+	tick := advanceWebhookClock(svc)
+	svc.CreateEndpoint(context.Background(), server.URL, "s3cret", nil)
+	svc.Apply(context.Background(), webhookChange(domain.EventTypeUpdate, "3"))
+	tick()
+	svc.RetryDue(context.Background())
+
+	delivery, _ := repo.GetDelivery(context.Background(), 1)
+	if delivery.Status != domain.WebhookDeliverySucceeded || delivery.Attempts != 2 || delivery.Error != "" || delivery.NextAttemptAt != nil {
+		t.Errorf("Expected success on the second attempt, got %+v", delivery)
+	}
+}
+
+func TestWebhookService_ReappliedChangeIsSentOnce(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.URL.Path]++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	repo := &MockWebhookRepository{failEndpoint: 2}
+	svc := NewWebhookService(repo, server.Client(), fastWebhookRetry)
+	tick := advanceWebhookClock(svc)
+	svc.CreateEndpoint(context.Background(), server.URL+"/a", "s3cret", nil)
+	svc.CreateEndpoint(context.Background(), server.URL+"/b", "s3cret", nil)
+	change := webhookChange(domain.EventTypeUpdate, "5")
+
+	// Logging the second delivery fails, so nothing is sent and the sink
+	// applies the change again
+	if err := svc.Apply(context.Background(), change); err == nil {
+		t.Fatal("Expected the storage error to be returned")
+	}
+	repo.failEndpoint = 0
+	if err := svc.Apply(context.Background(), change); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	svc.Apply(context.Background(), change)
+	if len(repo.deliveries) != 2 || calls["/a"] != 0 || calls["/b"] != 1 {
+		t.Fatalf("Expected one delivery per endpoint and only the new one sent, got %d deliveries and calls %v", len(repo.deliveries), calls)
+	}
+
+	// The delivery logged by the failed pass is retried once its lease is up
+	pending, _ := repo.GetDelivery(context.Background(), 1)
+	if pending.Status != domain.WebhookDeliveryPending || pending.NextAttemptAt == nil {
+		t.Fatalf("Expected the unattempted delivery to be scheduled, got %+v", pending)
+	}
+	for i := 0; i < int(webhookRetryLease/time.Minute); i++ {
+		tick()
+	}
+	if retried, err := svc.RetryDue(context.Background()); err != nil || retried != 1 {
+		t.Fatalf("Expected the unattempted delivery to be retried, got %d, %v", retried, err)
+	}
+	if calls["/a"] != 1 || calls["/b"] != 1 {
+		t.Errorf("Expected each endpoint to get the change once, got %v", calls)
+	}
+}
+
+func TestWebhookService_RetryPostponesBacklogOfFailingEndpoint(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	repo := &MockWebhookRepository{}
+	svc := NewWebhookService(repo, server.Client(), RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Second})
+	tick := advanceWebhookClock(svc)
+	svc.CreateEndpoint(context.Background(), server.URL, "s3cret", nil)
+	svc.Apply(context.Background(), webhookChange(domain.EventTypeUpdate, "1"))
+	svc.Apply(context.Background(), webhookChange(domain.EventTypeUpdate, "2"))
+
+	tick()
+	calls = 0
+	if retried, err := svc.RetryDue(context.Background()); err != nil || retried != 2 {
+		t.Fatalf("Expected both deliveries to be claimed, got %d, %v", retried, err)
+	}
+
+	// The first retry fails, so the second is put back without a request
+	second, _ := repo.GetDelivery(context.Background(), 2)
+	if calls != 1 || second.Attempts != 1 || second.Status != domain.WebhookDeliveryPending {
+		t.Errorf("Expected one request and the second delivery postponed, got %d calls and %+v", calls, second)
+	}
+	if want := svc.now().Add(time.Second); second.NextAttemptAt == nil || !second.NextAttemptAt.Equal(want) {
+		t.Errorf("Expected the second delivery to be due at %v, got %v", want, second.NextAttemptAt)
+	}
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	fail := true
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	repo := &MockWebhookRepository{}
+	svc := NewWebhookService(repo, server.Client(), RetryPolicy{MaxAttempts: 1})
+	svc.CreateEndpoint(context.Background(), server.URL, "s3cret", nil)
+	svc.Apply(context.Background(), webhookChange(domain.EventTypeDelete, "9"))
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+
+	redelivered, err := svc.Redeliver(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Redeliver failed: %v", err)
+	}
+	if redelivered.ID != 2 || redelivered.Status != domain.WebhookDeliverySucceeded {
+		t.Errorf("Expected a new succeeded delivery, got %+v", redelivered)
+	}
+
+	original, _ := repo.GetDelivery(context.Background(), 1)
+	if original.Status != domain.WebhookDeliveryFailed || original.Payload != redelivered.Payload {
+		t.Errorf("Expected the original delivery to stay failed with the same payload, got %+v", original)
+	}
+
+	if _, err := svc.Redeliver(context.Background(), 42); err != domain.ErrWebhookNotFound {
+		t.Errorf("Expected ErrWebhookNotFound, got %v", err)
+	}
+}
+
+func TestWebhookService_ListEndpointsHidesSecrets(t *testing.T) {
+	svc := NewWebhookService(&MockWebhookRepository{}, nil, fastWebhookRetry)
+	created, err := svc.CreateEndpoint(context.Background(), "https://example.com/hook", "", nil)
+	if err != nil {
+		t.Fatalf("CreateEndpoint failed: %v", err)
+	}
+	if len(created.Secret) != 64 {
+		t.Errorf("Expected a generated 32-byte hex secret, got %q", created.Secret)
+	}
+
+	endpoints, _ := svc.ListEndpoints(context.Background())
+	if len(endpoints) != 1 || endpoints[0].Secret != "" {
+		t.Errorf("Expected secrets to be stripped, got %+v", endpoints)
+	}
+
+	for _, bad := range []struct {
+		url    string
+		events []string
+	}{
+		{"ftp://example.com", nil},
+		{"not a url", nil},
+		{"https://example.com", []string{"post.published"}},
+	} {
+		if _, err := svc.CreateEndpoint(context.Background(), bad.url, "", bad.events); err == nil {
+			t.Errorf("Expected %s %v to be rejected", bad.url, bad.events)
+		}
+	}
+}
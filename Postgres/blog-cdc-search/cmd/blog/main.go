@@ -2,26 +2,36 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"blog-cdc-search/application/service"
 	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/cache"
 	"blog-cdc-search/infrastructure/database"
+	"blog-cdc-search/infrastructure/embedding"
+	"blog-cdc-search/infrastructure/messagequeue"
+	"blog-cdc-search/infrastructure/readmodel"
 	"blog-cdc-search/infrastructure/repository"
 	"blog-cdc-search/infrastructure/searchindex"
+	"blog-cdc-search/infrastructure/tlsconfig"
 	"blog-cdc-search/infrastructure/web"
+	"blog-cdc-search/infrastructure/web/handlers"
 )
 
 // SearchServiceAdapter adapts the actual SearchService to the interface
 type SearchServiceAdapter struct {
 	service *service.SearchService
+	// readModel, when set, lists the home page posts instead of the index
+	readModel *service.PostService
 }
 
 func (a *SearchServiceAdapter) SearchPosts(ctx context.Context, params interface{}) (interface{}, error) {
@@ -31,11 +41,10 @@ func (a *SearchServiceAdapter) SearchPosts(ctx context.Context, params interface
 		// Try to convert from map[string]interface{} if that's what was passed
 		if paramMap, ok := params.(map[string]interface{}); ok {
 			searchParams = service.SearchParams{
-				Query:    paramMap["query"].(string),
-				Page:     paramMap["page"].(int),
-				PerPage:  paramMap["per_page"].(int),
-				SortBy:   paramMap["sort_by"].(string),
-				FilterBy: paramMap["filter_by"].(string),
+				Query:   paramMap["query"].(string),
+				Page:    paramMap["page"].(int),
+				PerPage: paramMap["per_page"].(int),
+				SortBy:  paramMap["sort_by"].(string),
 			}
 		} else {
 			// Return error for unsupported type
@@ -46,10 +55,21 @@ func (a *SearchServiceAdapter) SearchPosts(ctx context.Context, params interface
 	return a.service.SearchPosts(ctx, searchParams)
 }
 
+func (a *SearchServiceAdapter) SuggestPosts(ctx context.Context, query string) (interface{}, error) {
+	return a.service.SuggestPosts(ctx, query)
+}
+
 func (a *SearchServiceAdapter) GetAllPostsFromIndex(ctx context.Context) ([]*domain.Post, error) {
+	if a.readModel != nil {
+		return a.readModel.GetAllPosts(ctx)
+	}
 	return a.service.GetAllPostsFromIndex(ctx)
 }
 
+func (a *SearchServiceAdapter) RelatedPosts(ctx context.Context, post *domain.Post) ([]*domain.Post, error) {
+	return a.service.RelatedPosts(ctx, post)
+}
+
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -58,15 +78,130 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt gets an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitPairs parses comma-separated key:value pairs, skipping malformed ones
+func splitPairs(value string) map[string]string {
+	pairs := map[string]string{}
+	for _, item := range splitList(value) {
+		if key, val, ok := strings.Cut(item, ":"); ok {
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+	return pairs
+}
+
+// getEnvBool gets a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getSecret reads a secret from the file named by KEY_FILE (Docker/Kubernetes
+// secrets), falling back to the KEY environment variable
+func getSecret(key, defaultValue string) string {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read %s_FILE: %v", key, err)
+		}
+		return strings.TrimSpace(string(content))
+	}
+	return getEnv(key, defaultValue)
+}
+
+// getEnvDuration gets a duration environment variable or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloat gets a float environment variable or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// newEmbedder creates the embedder configured by EMBEDDER, or returns nil
+// when embeddings are disabled. Only the Typesense backend stores vectors.
+func newEmbedder(searchBackend string) domain.Embedder {
+	kind := getEnv("EMBEDDER", "")
+	if kind == "" {
+		return nil
+	}
+	if searchBackend != "typesense" {
+		log.Printf("Warning: Embeddings need the typesense backend, not %s; search uses keywords only", searchBackend)
+		return nil
+	}
+
+	switch kind {
+	case "hashing":
+		return embedding.NewHashingEmbedder(getEnvInt("EMBEDDER_DIMENSIONS", embedding.DefaultHashingDimensions))
+	case "wordvectors":
+		path := getEnv("EMBEDDER_VECTORS_PATH", "")
+		embedder, err := embedding.LoadWordVectors(path)
+		if err != nil {
+			log.Fatalf("Failed to load word vectors: %v", err)
+		}
+		log.Printf("Loaded %d-dimensional word vectors from %s", embedder.Dimensions(), path)
+		return embedder
+	default:
+		log.Fatalf("Unknown embedder: %s", kind)
+		return nil
+	}
+}
+
 func main() {
 	// Load configuration from environment variables
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "5432")
 	dbUser := getEnv("DB_USER", "bloguser")
-	dbPassword := getEnv("DB_PASSWORD", "blogpass")
+	dbPassword := getSecret("DB_PASSWORD", "blogpass")
 	dbName := getEnv("DB_NAME", "blog")
+	dbSSLMode := getEnv("DB_SSLMODE", "disable")
+	dbSSLRootCert := getEnv("DB_SSLROOTCERT", "")
+	dbSSLCert := getEnv("DB_SSLCERT", "")
+	dbSSLKey := getEnv("DB_SSLKEY", "")
 	port := getEnv("PORT", "8085")
 
+	// With a read model the blog serves public pages from local SQLite and
+	// never connects to the primary database
+	readModelPath := getEnv("READ_MODEL_PATH", "")
+
+	// Search backend: typesense, elasticsearch, opensearch, meilisearch or bleve
+	searchBackend := getEnv("SEARCH_BACKEND", "typesense")
+
 	// Typesense configuration
 	typesenseHost := getEnv("TYPESENSE_HOST", "localhost")
 	typesensePortStr := getEnv("TYPESENSE_PORT", "8108")
@@ -74,55 +209,296 @@ func main() {
 	if port, err := strconv.Atoi(typesensePortStr); err == nil {
 		typesensePort = port
 	}
-	typesenseAPIKey := getEnv("TYPESENSE_API_KEY", "xyz")
+	typesenseAPIKey := getSecret("TYPESENSE_API_KEY", "xyz")
+	typesenseTLS := tlsconfig.Config{
+		Enabled:            getEnvBool("TYPESENSE_TLS", false),
+		CAFile:             getEnv("TYPESENSE_CA_CERT", ""),
+		CertFile:           getEnv("TYPESENSE_CLIENT_CERT", ""),
+		KeyFile:            getEnv("TYPESENSE_CLIENT_KEY", ""),
+		InsecureSkipVerify: getEnvBool("TYPESENSE_TLS_INSECURE", false),
+	}
+	typesenseNodes := splitList(getEnv("TYPESENSE_NODES", ""))
+	typesenseNearestNode := getEnv("TYPESENSE_NEAREST_NODE", "")
+	typesenseHealthcheckInterval := getEnvDuration("TYPESENSE_HEALTHCHECK_INTERVAL", time.Minute)
+	typesenseNumRetries := getEnvInt("TYPESENSE_NUM_RETRIES", 0)
+	typesenseRetryInterval := getEnvDuration("TYPESENSE_RETRY_INTERVAL", 100*time.Millisecond)
+	typesenseConnectionTimeout := getEnvDuration("TYPESENSE_CONNECTION_TIMEOUT", 5*time.Second)
+	typesenseReadTimeout := getEnvDuration("TYPESENSE_READ_TIMEOUT", 5*time.Second)
+	typesenseWriteTimeout := getEnvDuration("TYPESENSE_WRITE_TIMEOUT", 10*time.Second)
 
 	// Setup logging
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Println("Starting Blog Application...")
 
-	// Connect to database
-	db, err := database.NewPostgreSQLConnection(dbHost, dbPort, dbUser, dbPassword, dbName)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-	defer db.Close()
+	// Connect to database, or open the read model in its place
+	var db *sql.DB
+	var readModel *readmodel.SQLiteReadModel
+	var postRepo domain.PostRepository
+	if readModelPath != "" {
+		var err error
+		readModel, err = readmodel.Open(readModelPath)
+		if err != nil {
+			log.Fatalf("Failed to open read model: %v", err)
+		}
+		defer readModel.Close()
 
-	log.Println("Connected to PostgreSQL database")
+		log.Printf("Serving posts from the read model at %s", readModelPath)
+		postRepo = readmodel.NewPostRepository(readModel)
+	} else {
+		var err error
+		db, err = database.NewPostgreSQLConnection(database.PostgreSQLConfig{
+			Host:        dbHost,
+			Port:        dbPort,
+			User:        dbUser,
+			Password:    dbPassword,
+			DBName:      dbName,
+			SSLMode:     dbSSLMode,
+			SSLRootCert: dbSSLRootCert,
+			SSLCert:     dbSSLCert,
+			SSLKey:      dbSSLKey,
+		})
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
 
-	// Initialize repositories
-	postRepo := repository.NewPostgreSQLPostRepository(db)
+		log.Println("Connected to PostgreSQL database")
+		postRepo = repository.NewPostgreSQLPostRepository(db)
+	}
 
 	// Initialize Typesense repository
 	typesenseConfig := searchindex.TypesenseConfig{
-		Host:   typesenseHost,
-		Port:   typesensePort,
-		APIKey: typesenseAPIKey,
+		Host:         typesenseHost,
+		Port:         typesensePort,
+		APIKey:       typesenseAPIKey,
+		TLS:          typesenseTLS,
+		ReadTimeout:  typesenseReadTimeout,
+		WriteTimeout: typesenseWriteTimeout,
+
+		Nodes:               typesenseNodes,
+		NearestNode:         typesenseNearestNode,
+		HealthcheckInterval: typesenseHealthcheckInterval,
+		NumRetries:          typesenseNumRetries,
+		RetryInterval:       typesenseRetryInterval,
+		ConnectionTimeout:   typesenseConnectionTimeout,
+	}
+
+	// Initialize Elasticsearch/OpenSearch repository settings
+	elasticsearchConfig := searchindex.ElasticsearchConfig{
+		URL:      getEnv("ELASTICSEARCH_URL", "http://localhost:9200"),
+		Username: getEnv("ELASTICSEARCH_USERNAME", ""),
+		Password: getSecret("ELASTICSEARCH_PASSWORD", ""),
+		APIKey:   getSecret("ELASTICSEARCH_API_KEY", ""),
+		TLS: tlsconfig.Config{
+			CAFile:             getEnv("ELASTICSEARCH_CA_CERT", ""),
+			InsecureSkipVerify: getEnvBool("ELASTICSEARCH_TLS_INSECURE", false),
+		},
+		ReadTimeout:  getEnvDuration("ELASTICSEARCH_READ_TIMEOUT", 5*time.Second),
+		WriteTimeout: getEnvDuration("ELASTICSEARCH_WRITE_TIMEOUT", 10*time.Second),
+	}
+
+	// Initialize Meilisearch repository settings
+	meilisearchConfig := searchindex.MeilisearchConfig{
+		URL:    getEnv("MEILISEARCH_URL", "http://localhost:7700"),
+		APIKey: getSecret("MEILISEARCH_API_KEY", ""),
+		TLS: tlsconfig.Config{
+			CAFile:             getEnv("MEILISEARCH_CA_CERT", ""),
+			InsecureSkipVerify: getEnvBool("MEILISEARCH_TLS_INSECURE", false),
+		},
+		ReadTimeout:  getEnvDuration("MEILISEARCH_READ_TIMEOUT", 5*time.Second),
+		WriteTimeout: getEnvDuration("MEILISEARCH_WRITE_TIMEOUT", 30*time.Second),
+	}
+
+	// Initialize embedded Bleve index settings
+	bleveConfig := searchindex.BleveConfig{
+		Path:             getEnv("BLEVE_PATH", "data/bleve"),
+		DefaultAnalyzer:  getEnv("BLEVE_ANALYZER", "standard"),
+		Analyzers:        splitPairs(getEnv("BLEVE_FIELD_ANALYZERS", "")),
+		SnapshotDir:      getEnv("BLEVE_SNAPSHOT_DIR", ""),
+		SnapshotInterval: getEnvDuration("BLEVE_SNAPSHOT_INTERVAL", 0),
+		SnapshotKeep:     getEnvInt("BLEVE_SNAPSHOT_KEEP", 7),
 	}
-	typesenseRepo := searchindex.NewTypesenseRepository(typesenseConfig)
 
-	// Connect to Typesense
-	if err := typesenseRepo.Connect(); err != nil {
-		log.Printf("Warning: Failed to connect to Typesense: %v", err)
+	// Pick the search index backend
+	var bleveRepo *searchindex.BleveRepository
+	var typesenseRepo *searchindex.TypesenseRepository
+	var searchIndex domain.SearchIndexRepository
+	switch searchBackend {
+	case "typesense":
+		typesenseRepo = searchindex.NewTypesenseRepository(typesenseConfig)
+		searchIndex = typesenseRepo
+	case "elasticsearch", "opensearch":
+		searchIndex = searchindex.NewElasticsearchRepository(elasticsearchConfig)
+	case "meilisearch":
+		searchIndex = searchindex.NewMeilisearchRepository(meilisearchConfig)
+	case "bleve":
+		bleveRepo = searchindex.NewBleveRepository(bleveConfig)
+		searchIndex = bleveRepo
+	default:
+		log.Fatalf("Unknown search backend: %s", searchBackend)
+	}
+
+	// Embed queries, and posts consumed by the embedded CDC service, for
+	// hybrid search; it must match the embedder of the CDC service
+	embedder := newEmbedder(searchBackend)
+
+	// Connect to the search index
+	if err := searchIndex.Connect(context.Background()); err != nil {
+		log.Printf("Warning: Failed to connect to %s: %v", searchBackend, err)
 		log.Println("Search functionality will not be available")
+	}
+
+	// Background work (embedded CDC, snapshots) stops with the server
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	defer bgCancel()
+	cdcDone := make(chan struct{})
+
+	// Read-through cache for post pages and the home page listing
+	var postCache domain.CacheRepository
+	if getEnvBool("CACHE_ENABLED", false) {
+		postCache = newCache()
+	}
+
+	// Outgoing webhooks and post version history live in the primary
+	// database and are handled by whichever process consumes the CDC queue
+	var webhookService *service.WebhookService
+	var postVersionRepo domain.PostVersionRepository
+	if db != nil {
+		webhookService = service.NewWebhookService(
+			repository.NewPostgreSQLWebhookRepository(db),
+			&http.Client{Timeout: getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second)},
+			service.RetryPolicy{
+				MaxAttempts:    getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+				InitialBackoff: getEnvDuration("WEBHOOK_INITIAL_BACKOFF", time.Second),
+				MaxBackoff:     getEnvDuration("WEBHOOK_MAX_BACKOFF", time.Minute),
+			},
+		)
+		postVersionRepo = repository.NewPostgreSQLPostVersionRepository(db)
+	}
+
+	// An embedded Bleve index can only be opened by one process, so the blog
+	// can consume the CDC queue itself instead of running cmd/cdc
+	if getEnvBool("CDC_ENABLED", false) {
+		cdcService := service.NewCDCService(newEmbeddedRabbitMQ(), searchIndex)
+		if embedder != nil {
+			cdcService.SetEmbedder(embedder)
+		}
+		if postCache != nil {
+			cdcService.Sinks().Add(service.SinkConfig{
+				Sink:  service.NewCacheSink(postCache),
+				Async: true,
+				Retry: service.RetryPolicy{MaxAttempts: 5, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second},
+			})
+		}
+		if readModel != nil {
+			cdcService.Sinks().Add(service.SinkConfig{
+				Sink:  readModel,
+				Retry: service.RetryPolicy{MaxAttempts: 5, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second},
+			})
+		} else {
+			cdcService.Sinks().Add(service.SinkConfig{
+				Sink:  webhookService,
+				Async: true,
+				Retry: service.RetryPolicy{MaxAttempts: 5, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second},
+			})
+			go webhookService.RunRetries(bgCtx)
+			cdcService.Sinks().Add(service.SinkConfig{
+				Sink:  service.NewPostHistorySink(postVersionRepo),
+				Retry: service.RetryPolicy{MaxAttempts: 5, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second},
+			})
+		}
+		go func() {
+			defer close(cdcDone)
+			queueName := getEnv("QUEUE_NAME", "cdc-posts")
+			log.Printf("Starting embedded CDC consumer on queue: %s", queueName)
+			if err := cdcService.StartCDC(bgCtx, queueName); err != nil {
+				log.Printf("Embedded CDC consumer stopped: %v", err)
+			}
+		}()
 	} else {
-		log.Printf("Connected to Typesense at %s:%d", typesenseHost, typesensePort)
+		close(cdcDone)
+	}
+
+	if bleveRepo != nil {
+		go bleveRepo.RunSnapshots(bgCtx)
 	}
 
 	// Initialize services
 	postService := service.NewPostService(postRepo)
-	searchService := service.NewSearchService(typesenseRepo)
+	searchService := service.NewSearchService(searchIndex)
+	if embedder != nil {
+		searchService.SetEmbedder(embedder, getEnvFloat("SEARCH_HYBRID_ALPHA", service.DefaultHybridAlpha))
+	}
+	var historyService *service.PostHistoryService
+	if postVersionRepo != nil {
+		historyService = service.NewPostHistoryService(postVersionRepo, postService)
+	}
+
+	// Synonyms and curations are kept in the database and pushed to the
+	// index on startup, so they survive the index being rebuilt
+	var tuningService *service.SearchTuningService
+	if db != nil {
+		tuningService = service.NewSearchTuningService(repository.NewPostgreSQLSearchTuningRepository(db), searchIndex)
+		if err := tuningService.Sync(context.Background()); err != nil {
+			log.Printf("Warning: Failed to sync search tuning to the index: %v", err)
+		}
+	}
+
+	// Searches and result clicks are recorded in the database; a read model
+	// has none, so its searches go unrecorded
+	var searchAnalytics handlers.SearchAnalyticsService
+	if db != nil && getEnvBool("SEARCH_ANALYTICS", true) {
+		searchAnalytics = service.NewSearchAnalyticsService(repository.NewPostgreSQLSearchAnalyticsRepository(db), getSecret("SEARCH_ANALYTICS_SALT", ""))
+	}
+	// Scoped search-only keys let browsers query Typesense directly; the
+	// admin key stays here, and the parent of the scoped keys is rotated in
+	// the background
+	var searchKeys handlers.SearchKeyService
+	if getEnvBool("SEARCH_KEYS_ENABLED", false) {
+		if typesenseRepo == nil {
+			log.Printf("Warning: Search keys need the typesense backend, not %s; GET /api/search/key is disabled", searchBackend)
+		} else {
+			if typesenseAPIKey == "xyz" {
+				log.Println("Warning: TYPESENSE_API_KEY is the default; set a secret admin key before exposing Typesense to browsers")
+			}
+			keyService := service.NewSearchKeyService(typesenseRepo, service.SearchKeyConfig{
+				Filter:   getEnv("SEARCH_KEY_FILTER", ""),
+				TTL:      getEnvDuration("SEARCH_KEY_TTL", service.DefaultSearchKeyTTL),
+				Rotation: getEnvDuration("SEARCH_KEY_ROTATION", service.DefaultSearchKeyRotation),
+				Nodes:    splitList(getEnv("SEARCH_KEY_NODES", strings.Join(typesenseRepo.NodeURLs(), ","))),
+			})
+			go keyService.RunRotation(bgCtx)
+			searchKeys = keyService
+		}
+	}
+
+	if postCache != nil {
+		cacheTTL := getEnvDuration("CACHE_TTL", 10*time.Minute)
+		postService.SetCache(postCache, cacheTTL)
+		searchService.SetCache(postCache, cacheTTL)
+	}
+
+	// Suggestions are cached in process only, briefly, as they are not invalidated
+	searchService.SetSuggestCache(cache.NewLRUCache(getEnvInt("SUGGEST_CACHE_SIZE", 1000)), getEnvDuration("SUGGEST_CACHE_TTL", 30*time.Second))
 
 	// Create search service adapter
 	searchServiceAdapter := &SearchServiceAdapter{
 		service: searchService,
 	}
+	if readModel != nil {
+		searchServiceAdapter.readModel = postService
+	}
 
 	// Initialize handlers
-	handlers := web.NewHandlers(postService, searchServiceAdapter)
+	handlers := web.NewHandlers(postService, searchServiceAdapter, webhookService, historyService, tuningService, searchAnalytics, searchKeys)
 	log.Printf("Handlers initialized: %+v", handlers)
 
-	// Setup routes
-	router := web.SetupRoutes(handlers)
+	// Setup routes; a read model only serves the public pages and read APIs
+	setupRoutes := web.SetupRoutes
+	if readModel != nil {
+		setupRoutes = web.SetupPublicRoutes
+	}
+	router := setupRoutes(handlers)
 	log.Printf("Routes setup complete")
 
 	// Create HTTP server
@@ -158,5 +534,75 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	// Let the embedded CDC consumer finish its in-flight messages
+	bgCancel()
+	select {
+	case <-cdcDone:
+	case <-ctx.Done():
+		log.Println("Embedded CDC consumer did not stop in time")
+	}
+
 	log.Println("Server exited")
 }
+
+// newEmbeddedRabbitMQ configures the queue consumer run inside the blog from
+// the same environment variables as cmd/cdc
+func newEmbeddedRabbitMQ() *messagequeue.RabbitMQRepository {
+	config := messagequeue.RabbitMQConfig{
+		Host:     getEnv("RABBITMQ_HOST", "localhost"),
+		Port:     getEnvInt("RABBITMQ_PORT", 5672),
+		Username: getEnv("RABBITMQ_USER", "admin"),
+		Password: getSecret("RABBITMQ_PASSWORD", "admin123"),
+		VHost:    getEnv("RABBITMQ_VHOST", "/"),
+		TLS: tlsconfig.Config{
+			Enabled: getEnvBool("RABBITMQ_TLS", false),
+			CAFile:  getEnv("RABBITMQ_CA_CERT", ""),
+		},
+
+		HandlerTimeout: getEnvDuration("HANDLER_TIMEOUT", 30*time.Second),
+
+		Exchange:     getEnv("RABBITMQ_EXCHANGE", "debezium"),
+		ExchangeType: getEnv("RABBITMQ_EXCHANGE_TYPE", "fanout"),
+		BindingKeys:  splitList(getEnv("RABBITMQ_BINDING_KEYS", "blog.posts")),
+		Queue: messagequeue.QueueOptions{
+			Type: getEnv("QUEUE_TYPE", ""),
+		},
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("Invalid RabbitMQ configuration: %v", err)
+	}
+	return messagequeue.NewRabbitMQRepository(config)
+}
+
+// newCache returns the Redis cache when REDIS_ADDR is set, falling back to a
+// local LRU while Redis is unreachable, or just the LRU otherwise
+func newCache() domain.CacheRepository {
+	local := cache.NewLRUCache(getEnvInt("CACHE_SIZE", 1000))
+
+	addr := getEnv("REDIS_ADDR", "")
+	if addr == "" {
+		if !getEnvBool("CDC_ENABLED", false) {
+			log.Println("Warning: in-memory cache is only invalidated by the embedded CDC consumer; entries expire after CACHE_TTL")
+		}
+		return local
+	}
+
+	redisCache, err := cache.NewRedisCache(cache.RedisConfig{
+		Addr:      addr,
+		Username:  getEnv("REDIS_USERNAME", ""),
+		Password:  getSecret("REDIS_PASSWORD", ""),
+		DB:        getEnvInt("REDIS_DB", 0),
+		KeyPrefix: getEnv("REDIS_KEY_PREFIX", "blog:"),
+		TLS: tlsconfig.Config{
+			Enabled: getEnvBool("REDIS_TLS", false),
+			CAFile:  getEnv("REDIS_CA_CERT", ""),
+		},
+	})
+	if err != nil {
+		log.Fatalf("Invalid Redis configuration: %v", err)
+	}
+	if err := redisCache.Ping(context.Background()); err != nil {
+		log.Printf("Warning: %v; using the local cache until it is reachable", err)
+	}
+	return cache.NewFallbackCache(redisCache, local, getEnvDuration("CACHE_FALLBACK_TTL", 5*time.Second))
+}
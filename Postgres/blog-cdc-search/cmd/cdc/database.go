@@ -0,0 +1,40 @@
+package main
+
+import (
+	"database/sql"
+
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/database"
+	"blog-cdc-search/infrastructure/repository"
+)
+
+// openDatabase connects to the blog database using the same DB_* environment
+// variables as the blog application
+func openDatabase() (*sql.DB, error) {
+	return database.NewPostgreSQLConnection(database.PostgreSQLConfig{
+		Host:        getEnv("DB_HOST", "localhost"),
+		Port:        getEnv("DB_PORT", "5432"),
+		User:        getEnv("DB_USER", "bloguser"),
+		Password:    getSecret("DB_PASSWORD", "blogpass"),
+		DBName:      getEnv("DB_NAME", "blog"),
+		SSLMode:     getEnv("DB_SSLMODE", "disable"),
+		SSLRootCert: getEnv("DB_SSLROOTCERT", ""),
+		SSLCert:     getEnv("DB_SSLCERT", ""),
+		SSLKey:      getEnv("DB_SSLKEY", ""),
+	})
+}
+
+// newWebhookRepository returns the webhook store backed by db
+func newWebhookRepository(db *sql.DB) domain.WebhookRepository {
+	return repository.NewPostgreSQLWebhookRepository(db)
+}
+
+// newPostVersionRepository returns the post history store backed by db
+func newPostVersionRepository(db *sql.DB) domain.PostVersionRepository {
+	return repository.NewPostgreSQLPostVersionRepository(db)
+}
+
+// newPostRepository returns the posts table backed by db
+func newPostRepository(db *sql.DB) domain.PostRepository {
+	return repository.NewPostgreSQLPostRepository(db)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log"
+
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/embedding"
+)
+
+// newEmbedder creates the configured embedder, or returns nil when
+// embeddings are disabled. Only the Typesense backend stores vectors.
+func newEmbedder(kind string, dimensions int, vectorsPath, searchBackend string) domain.Embedder {
+	if kind == "" {
+		return nil
+	}
+	if searchBackend != "typesense" {
+		log.Printf("Warning: Embeddings need the typesense backend, not %s; posts are indexed without them", searchBackend)
+		return nil
+	}
+
+	switch kind {
+	case "hashing":
+		return embedding.NewHashingEmbedder(dimensions)
+	case "wordvectors":
+		embedder, err := embedding.LoadWordVectors(vectorsPath)
+		if err != nil {
+			log.Fatalf("Failed to load word vectors: %v", err)
+		}
+		log.Printf("Loaded %d-dimensional word vectors from %s", embedder.Dimensions(), vectorsPath)
+		return embedder
+	default:
+		log.Fatalf("Unknown embedder: %s", kind)
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"blog-cdc-search/application/service"
+	"blog-cdc-search/infrastructure/sink"
+)
+
+// runReplay rebuilds the search index from an archive written by the archive
+// sink. Only the search index receives the replayed changes, so webhooks and
+// other sinks are not triggered again.
+func runReplay(cdcService *service.CDCService, dir, until string, reset bool) {
+	opts := service.ReplayOptions{ResetIndex: reset}
+	if until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			log.Fatalf("Invalid --replay-until, expected RFC 3339: %v", err)
+		}
+		opts.Until = t
+	}
+
+	reader, err := sink.OpenArchive(dir)
+	if err != nil {
+		log.Fatalf("Failed to open archive: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Replaying archive %s", dir)
+	stats, err := cdcService.Replay(ctx, reader, opts)
+	if err != nil {
+		log.Fatalf("Replay failed after %d events: %v", stats.Replayed, err)
+	}
+	log.Printf("Replay complete: %d events replayed, %d later events skipped", stats.Replayed, stats.Skipped)
+}
@@ -8,6 +8,10 @@ CREATE TABLE IF NOT EXISTS posts (
     image TEXT,
     excerpt TEXT,
     body TEXT NOT NULL,
+    author VARCHAR(255) NOT NULL DEFAULT '',
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    language VARCHAR(16) NOT NULL DEFAULT 'en',
+    tags TEXT NOT NULL DEFAULT '',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -27,6 +31,102 @@ CREATE TRIGGER update_posts_updated_at
     FOR EACH ROW 
     EXECUTE FUNCTION update_updated_at_column();
 
+-- Webhook endpoints notified about post changes
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Log of webhook deliveries, one row per event and endpoint
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id INTEGER NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event VARCHAR(64) NOT NULL,
+    post_id INTEGER NOT NULL,
+    change_key VARCHAR(64),
+    payload TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- One delivery per endpoint and change, however often the change is applied
+    UNIQUE (endpoint_id, event, post_id, change_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+-- Every version of every post, recorded from CDC events
+CREATE TABLE IF NOT EXISTS post_versions (
+    id BIGSERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    image TEXT NOT NULL DEFAULT '',
+    excerpt TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    author VARCHAR(255) NOT NULL DEFAULT '',
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    language VARCHAR(16) NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '',
+    changed_fields VARCHAR(255) NOT NULL DEFAULT '',
+    changed_at TIMESTAMP NOT NULL,
+    UNIQUE (post_id, version)
+);
+
+-- Search synonyms, synced to the search index. Root is empty for multi-way
+-- synonyms; synonyms is comma-separated.
+CREATE TABLE IF NOT EXISTS search_synonyms (
+    id SERIAL PRIMARY KEY,
+    root VARCHAR(255) NOT NULL DEFAULT '',
+    synonyms TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Search curations pinning and hiding posts for a query, synced to the
+-- search index. Pinned and hidden are comma-separated post IDs.
+CREATE TABLE IF NOT EXISTS search_curations (
+    id SERIAL PRIMARY KEY,
+    query VARCHAR(255) NOT NULL,
+    match_type VARCHAR(16) NOT NULL DEFAULT 'exact',
+    pinned TEXT NOT NULL DEFAULT '',
+    hidden TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Searches run by readers, for search analytics. Query is lowercased with
+-- its whitespace collapsed; session_id is a salted hash that changes daily.
+CREATE TABLE IF NOT EXISTS search_queries (
+    id BIGSERIAL PRIMARY KEY,
+    query VARCHAR(255) NOT NULL,
+    results INTEGER NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    page INTEGER NOT NULL DEFAULT 1,
+    session_id VARCHAR(32) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_queries_created_at ON search_queries (created_at);
+
+-- Clicks on search results; position counts from 1 across pages
+CREATE TABLE IF NOT EXISTS search_clicks (
+    id BIGSERIAL PRIMARY KEY,
+    search_id BIGINT NOT NULL,
+    post_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_clicks_search_id ON search_clicks (search_id);
+
 -- Insert some sample data
 INSERT INTO posts (title, image, excerpt, body) VALUES
 ('Welcome to My Blog', 'https://picsum.photos/536/354?random=1', 'This is my first blog post where I share my thoughts and ideas.', 'Welcome to my new blog! I am excited to start sharing my thoughts, experiences, and ideas with you. This blog will cover various topics including technology, life, and everything in between. Stay tuned for more content!'),
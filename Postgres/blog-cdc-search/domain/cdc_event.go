@@ -58,6 +58,21 @@ func (e *CDCEvent) GetID() (int, bool) {
 	return 0, false
 }
 
+// Time returns when the change happened in the source database; Debezium
+// reports ts_ms in milliseconds. It is zero when the event has no timestamp.
+func (e *CDCEvent) Time() time.Time {
+	if e.TS == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(e.TS).UTC()
+}
+
+// SourceTS converts a time to the unit of the event timestamps (milliseconds),
+// the inverse of Time
+func SourceTS(t time.Time) int64 {
+	return t.UnixMilli()
+}
+
 // ToJSON converts the event to JSON
 func (e *CDCEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
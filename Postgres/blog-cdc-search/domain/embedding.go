@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"strings"
+)
+
+// EmbeddingField is the search index field holding post embeddings
+const EmbeddingField = "embedding"
+
+// Embedder turns text into a vector whose distance to other vectors reflects
+// how close their meaning is. Implementations run locally; the same embedder
+// must be used for indexing and for queries. Embed returns nil when the text
+// has nothing the embedder can represent.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+	Dimensions() int
+}
+
+// EmbeddingText is the text of the post that is embedded
+func (d *SearchDocument) EmbeddingText() string {
+	return strings.Join([]string{d.Title, d.Excerpt, d.Body}, "\n")
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidLanguage is returned for a language that is not an ISO 639 code
+var ErrInvalidLanguage = errors.New("invalid language")
+
+// DefaultLanguage is the language of posts when none is given or detected
+const DefaultLanguage = "en"
+
+// SearchLocales are the languages whose text is indexed a second time in
+// fields of their own, such as title_fa, with the language as the Typesense
+// locale. Their scripts are written without spaces between words, or their
+// words need language-specific normalization, so the default tokenizer
+// handles them poorly. Other languages are only searched in the default
+// fields.
+var SearchLocales = []string{"ar", "fa", "he", "zh", "ja", "ko", "th", "de", "ru", "uk", "el"}
+
+// LocalizedFields are the text fields indexed per locale
+var LocalizedFields = []string{"title", "excerpt", "body"}
+
+// LocalizedField returns the name of the field holding field for a
+// language, or "" if the language has no fields of its own
+func LocalizedField(field, language string) string {
+	if !slices.Contains(SearchLocales, language) {
+		return ""
+	}
+	return field + "_" + language
+}
+
+// NormalizeLanguage reduces a language tag such as "de-AT" or "zh_Hant" to
+// its lowercase primary language code
+func NormalizeLanguage(language string) (string, error) {
+	code := strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if len(code) < 2 || len(code) > 3 || strings.IndexFunc(code, func(r rune) bool { return r < 'a' || r > 'z' }) >= 0 {
+		return "", fmt.Errorf("%w %q, expected an ISO 639 code such as en or fa", ErrInvalidLanguage, language)
+	}
+	return code, nil
+}
+
+// SetLanguage sets the language of the post. Without one the current
+// language is kept or, for a post that has none, detected from its text.
+func (p *Post) SetLanguage(language string) error {
+	if strings.TrimSpace(language) != "" {
+		code, err := NormalizeLanguage(language)
+		if err != nil {
+			return err
+		}
+		p.Language = code
+		return nil
+	}
+
+	if p.Language == "" {
+		p.Language = DetectLanguage(p.Title + "\n" + p.Excerpt + "\n" + p.Body)
+	}
+	if p.Language == "" {
+		p.Language = DefaultLanguage
+	}
+	return nil
+}
+
+// scriptLanguages maps the scripts used by a single language in SearchLocales
+// to that language
+var scriptLanguages = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Thai, "th"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+}
+
+// stopWords are frequent words that tell languages written in the Latin
+// script apart
+var stopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "with", "for", "it", "this", "are", "was", "on"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "ein", "eine", "auf", "für", "den", "von", "zu", "sich", "auch"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "un", "du", "dans", "pour", "que", "pas", "sur", "avec"},
+	"es": {"el", "los", "las", "y", "es", "del", "una", "por", "con", "para", "está", "pero", "como", "que"},
+	"it": {"il", "gli", "e", "è", "di", "che", "della", "per", "con", "una", "sono", "non", "anche", "nel"},
+	"pt": {"o", "os", "as", "e", "é", "do", "da", "não", "uma", "com", "para", "em", "que", "dos"},
+	"nl": {"de", "het", "een", "en", "van", "is", "niet", "dat", "met", "voor", "op", "zijn", "ook"},
+}
+
+// DetectLanguage guesses the language of a text from the script most of its
+// letters are written in and, for the Latin script, its most common words.
+// It returns "" when the text gives too little to go on, which is usual for
+// short Latin-script queries.
+func DetectLanguage(text string) string {
+	counts := map[*unicode.RangeTable]int{}
+	scripts := []*unicode.RangeTable{unicode.Latin, unicode.Cyrillic, unicode.Arabic, unicode.Han, unicode.Hiragana, unicode.Katakana}
+	for _, entry := range scriptLanguages {
+		scripts = append(scripts, entry.script)
+	}
+	for _, r := range text {
+		for _, script := range scripts {
+			if unicode.Is(script, r) {
+				counts[script]++
+				break
+			}
+		}
+	}
+
+	var dominant *unicode.RangeTable
+	for _, script := range scripts {
+		if counts[script] > counts[dominant] {
+			dominant = script
+		}
+	}
+	// Japanese mixes kana with Han characters, so kana count towards it
+	kana := counts[unicode.Hiragana] + counts[unicode.Katakana]
+	if kana > 0 && kana+counts[unicode.Han] > counts[dominant] {
+		return "ja"
+	}
+
+	switch dominant {
+	case nil:
+		return ""
+	case unicode.Han:
+		return "zh"
+	case unicode.Arabic:
+		// Persian adds letters to the Arabic alphabet and writes its own
+		// forms of kaf and yeh
+		if strings.ContainsAny(text, "پچژگکی") {
+			return "fa"
+		}
+		return "ar"
+	case unicode.Cyrillic:
+		if strings.ContainsAny(strings.ToLower(text), "іїєґ") {
+			return "uk"
+		}
+		return "ru"
+	case unicode.Latin:
+		return detectLatinLanguage(text)
+	}
+	for _, entry := range scriptLanguages {
+		if entry.script == dominant {
+			return entry.language
+		}
+	}
+	return ""
+}
+
+// detectLatinLanguage picks the language whose stop words occur most often,
+// if they occur at least twice and more often than those of any other
+func detectLatinLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	scores := map[string]int{}
+	for _, word := range words {
+		for language, list := range stopWords {
+			if slices.Contains(list, word) {
+				scores[language]++
+			}
+		}
+	}
+
+	best, bestScore, tied := "", 1, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = language, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The state of the art in change data capture", "en"},
+		{"Die Datenbank und der Suchindex sind nicht synchron", "de"},
+		{"Les événements de la base sont envoyés dans la file", "fr"},
+		{"برنامه‌نویسی همروند در زبان گو", "fa"},
+		{"البرمجة المتزامنة في لغة جو", "ar"},
+		{"数据库变更捕获", "zh"},
+		{"データベースの変更をキャプチャする", "ja"},
+		{"데이터베이스 변경 캡처", "ko"},
+		{"การจับการเปลี่ยนแปลงข้อมูล", "th"},
+		{"Захват изменений данных", "ru"},
+		{"Її зміни в базі даних", "uk"},
+		{"Σύλληψη αλλαγών δεδομένων", "el"},
+		{"לכידת שינויים בנתונים", "he"},
+		// Too little to go on
+		{"concurrency", ""},
+		{"1234 !!", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	for input, want := range map[string]string{"en": "en", " DE-at ": "de", "zh_Hant": "zh", "fil": "fil"} {
+		if got, err := NormalizeLanguage(input); err != nil || got != want {
+			t.Errorf("NormalizeLanguage(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "e", "english", "e1"} {
+		if _, err := NormalizeLanguage(input); !errors.Is(err, ErrInvalidLanguage) {
+			t.Errorf("NormalizeLanguage(%q) expected ErrInvalidLanguage, got %v", input, err)
+		}
+	}
+}
+
+func TestPost_SetLanguage(t *testing.T) {
+	post, _ := NewPost("Concurrency", "", "", "Goroutines")
+	if err := post.SetLanguage(""); err != nil || post.Language != DefaultLanguage {
+		t.Errorf("Expected the default language when none is detected, got %q, %v", post.Language, err)
+	}
+
+	post.Language = "fa"
+	if err := post.SetLanguage(""); err != nil || post.Language != "fa" {
+		t.Errorf("Expected the current language to be kept, got %q, %v", post.Language, err)
+	}
+	if err := post.SetLanguage("JA"); err != nil || post.Language != "ja" {
+		t.Errorf("Expected ja, got %q, %v", post.Language, err)
+	}
+}
+
+func TestSearchDocument_Localized(t *testing.T) {
+	post, _ := NewPost("همروندی", "", "خلاصه", "متن")
+	post.ID = 1
+	post.Language = "fa"
+
+	data, err := json.Marshal(NewSearchDocument(post))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, data)
+	}
+	if fields["title_fa"] != "همروندی" || fields["excerpt_fa"] != "خلاصه" || fields["body_fa"] != "متن" || fields["title"] != "همروندی" || fields["language"] != "fa" {
+		t.Errorf("Expected the text in the default and Persian fields, got %s", data)
+	}
+
+	// Languages without fields of their own are only in the default fields
+	post.Language = "en"
+	data, _ = json.Marshal(NewSearchDocument(post))
+	fields = nil
+	json.Unmarshal(data, &fields)
+	if _, ok := fields["title_en"]; ok || fields["title"] != "همروندی" {
+		t.Errorf("Expected no localized fields, got %s", data)
+	}
+
+	doc, _ := NewSearchDocumentFromMap(map[string]interface{}{"id": 2, "title": "Datenbank", "language": "de"})
+	if doc.Language != "de" || doc.Localized["title_de"] != "Datenbank" {
+		t.Errorf("Expected the German fields from the change data, got %+v", doc)
+	}
+}
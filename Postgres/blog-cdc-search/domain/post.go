@@ -2,12 +2,14 @@ package domain
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
 // Domain errors
 var (
 	ErrPostNotFound = errors.New("post not found")
+	ErrReadOnly     = errors.New("posts are read-only on this instance")
 )
 
 // Post represents a blog post entity
@@ -17,6 +19,10 @@ type Post struct {
 	Image     string    `json:"image"`
 	Excerpt   string    `json:"excerpt"`
 	Body      string    `json:"body"`
+	Author    string    `json:"author"`
+	Category  string    `json:"category"`
+	Language  string    `json:"language"`
+	Tags      []string  `json:"tags"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -68,3 +74,41 @@ func (p *Post) Validate() error {
 	}
 	return nil
 }
+
+// SetAuthor sets the name the post is credited to
+func (p *Post) SetAuthor(author string) {
+	p.Author = strings.TrimSpace(author)
+}
+
+// SetTaxonomy sets the category and tags of the post. Tags are lowercased,
+// trimmed and deduplicated so they filter and facet as whole values.
+func (p *Post) SetTaxonomy(category string, tags []string) {
+	p.Category = strings.TrimSpace(category)
+	p.Tags = NormalizeTags(tags)
+}
+
+// NormalizeTags lowercases and trims tags, dropping empty and repeated ones.
+// Commas are removed as they separate tags in storage.
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, ",", " ")))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// JoinTags encodes tags for the comma-separated tags column
+func JoinTags(tags []string) string {
+	return strings.Join(NormalizeTags(tags), ",")
+}
+
+// SplitTags decodes the comma-separated tags column
+func SplitTags(value string) []string {
+	return NormalizeTags(strings.Split(value, ","))
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -243,3 +244,24 @@ func TestPost_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestPost_SetTaxonomy(t *testing.T) {
+	post := &Post{}
+	post.SetTaxonomy("  Tutorials ", []string{"Go", " go", "", "Change Data Capture", "a,b"})
+
+	if post.Category != "Tutorials" {
+		t.Errorf("Expected trimmed category, got %q", post.Category)
+	}
+	expected := []string{"go", "change data capture", "a b"}
+	if !reflect.DeepEqual(post.Tags, expected) {
+		t.Errorf("Expected tags %v, got %v", expected, post.Tags)
+	}
+
+	// Tags survive the comma-separated column
+	if tags := SplitTags(JoinTags(post.Tags)); !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Expected tags to round trip, got %v", tags)
+	}
+	if tags := SplitTags(""); tags == nil || len(tags) != 0 {
+		t.Errorf("Expected an empty, non-nil list, got %#v", tags)
+	}
+}
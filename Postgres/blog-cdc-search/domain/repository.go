@@ -1,6 +1,18 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrServiceUnavailable is returned when a downstream service is temporarily
+// unavailable. Message consumers pause instead of retrying immediately.
+var ErrServiceUnavailable = errors.New("service temporarily unavailable")
+
+// ErrInvalidSearch is returned for a search with an unknown sort or a filter
+// that cannot be applied
+var ErrInvalidSearch = errors.New("invalid search")
 
 // PostRepository defines the interface for post data access
 type PostRepository interface {
@@ -11,21 +23,38 @@ type PostRepository interface {
 	Delete(ctx context.Context, id int) error
 }
 
+// MessageHandler processes a single message body. The context carries the
+// per-message deadline and is not cancelled when the consumer shuts down, so
+// in-flight messages can finish.
+type MessageHandler func(ctx context.Context, message []byte) error
+
 // MessageQueueRepository defines the interface for RabbitMQ operations
 type MessageQueueRepository interface {
-	Connect() error
+	Connect(ctx context.Context) error
 	Close() error
-	ConsumeMessages(queueName string, handler func([]byte) error) error
-	PublishMessage(exchange, routingKey string, message []byte) error
+	ConsumeMessages(ctx context.Context, queueName string, handler MessageHandler) error
+	PublishMessage(ctx context.Context, exchange, routingKey string, message []byte) error
 }
 
 // SearchIndexRepository defines the interface for Typesense operations
 type SearchIndexRepository interface {
-	Connect() error
+	Connect(ctx context.Context) error
 	Close() error
-	CreateCollection(schema map[string]interface{}) error
-	UpsertDocument(collectionName string, document interface{}) error
-	DeleteDocument(collectionName string, documentID string) error
-	SearchDocuments(collectionName, query string, searchParams map[string]interface{}) ([]interface{}, error)
-	GetAllDocuments(collectionName string) ([]interface{}, error)
+	CreateCollection(ctx context.Context, schema map[string]interface{}) error
+	UpsertDocument(ctx context.Context, collectionName string, document interface{}) error
+	DeleteDocument(ctx context.Context, collectionName string, documentID string) error
+	SearchDocuments(ctx context.Context, collectionName, query string, searchParams map[string]interface{}) (*SearchResults, error)
+	GetAllDocuments(ctx context.Context, collectionName string) ([]interface{}, error)
+	// SyncSynonyms and SyncCurations replace the synonyms and curations of a
+	// collection; backends without them accept and ignore the definitions
+	SyncSynonyms(ctx context.Context, collectionName string, synonyms []*Synonym) error
+	SyncCurations(ctx context.Context, collectionName string, curations []*Curation) error
+}
+
+// CacheRepository defines the interface for the read-through cache. Get
+// reports a miss with found set to false and a nil error.
+type CacheRepository interface {
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
 }
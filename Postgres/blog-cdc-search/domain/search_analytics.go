@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSearchClick is returned for a click missing its search, post or
+// position
+var ErrInvalidSearchClick = errors.New("invalid search click")
+
+// SearchEvent is one search as a reader ran it. Query is normalized so
+// searches differing only in case or spacing are counted together; Session is
+// an anonymized ID that cannot be traced back to the reader.
+type SearchEvent struct {
+	ID        int64     `json:"id"`
+	Query     string    `json:"query"`
+	Results   int       `json:"results"`
+	LatencyMS int       `json:"latency_ms"`
+	Page      int       `json:"page"`
+	Session   string    `json:"session"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SearchClick is a click on a search result. Position counts from 1 across
+// pages.
+type SearchClick struct {
+	ID        int64     `json:"id"`
+	SearchID  int64     `json:"search_id"`
+	PostID    int       `json:"post_id"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NormalizeSearchQuery lowercases a query and collapses its whitespace
+func NormalizeSearchQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// SearchQueryStats aggregates the searches for one query. Clicked counts
+// the searches with at least one result click; CTR is their share.
+type SearchQueryStats struct {
+	Query        string    `json:"query"`
+	Searches     int       `json:"searches"`
+	Sessions     int       `json:"sessions"`
+	AvgResults   float64   `json:"avg_results"`
+	AvgLatencyMS float64   `json:"avg_latency_ms"`
+	Clicked      int       `json:"clicked"`
+	CTR          float64   `json:"ctr"`
+	LastSearched time.Time `json:"last_searched"`
+}
+
+// SetCTR computes the click-through rate from the searches and clicks
+func (s *SearchQueryStats) SetCTR() {
+	if s.Searches > 0 {
+		s.CTR = float64(s.Clicked) / float64(s.Searches)
+	}
+}
+
+// SearchAnalyticsSummary sums up every search in a period
+type SearchAnalyticsSummary struct {
+	Searches       int     `json:"searches"`
+	Sessions       int     `json:"sessions"`
+	Queries        int     `json:"queries"`
+	ZeroResults    int     `json:"zero_results"`
+	Clicked        int     `json:"clicked"`
+	ZeroResultRate float64 `json:"zero_result_rate"`
+	CTR            float64 `json:"ctr"`
+}
+
+// SearchAnalyticsReport is what readers searched for since a point in time
+type SearchAnalyticsReport struct {
+	Since             time.Time              `json:"since"`
+	Summary           SearchAnalyticsSummary `json:"summary"`
+	TopQueries        []*SearchQueryStats    `json:"top_queries"`
+	ZeroResultQueries []*SearchQueryStats    `json:"zero_result_queries"`
+}
+
+// SearchAnalyticsRepository stores searches and result clicks and
+// aggregates them per query
+type SearchAnalyticsRepository interface {
+	RecordSearch(ctx context.Context, search *SearchEvent) error
+	RecordClick(ctx context.Context, click *SearchClick) error
+	Summary(ctx context.Context, since time.Time) (*SearchAnalyticsSummary, error)
+	// TopQueries returns the most searched queries, with their clicks
+	TopQueries(ctx context.Context, since time.Time, limit int) ([]*SearchQueryStats, error)
+	// ZeroResultQueries returns the queries most often searched without results
+	ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]*SearchQueryStats, error)
+}
@@ -197,3 +197,32 @@ func TestNewSearchDocumentFromMap(t *testing.T) {
 		})
 	}
 }
+
+func TestNewSearchDocumentFromMap_Taxonomy(t *testing.T) {
+	// Debezium delivers the tags column as a comma-separated string
+	doc, err := NewSearchDocumentFromMap(map[string]interface{}{
+		"id":       1,
+		"title":    "Tagged",
+		"image":    "cover.jpg",
+		"category": "Tutorials",
+		"tags":     "go,cdc",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if doc.Category != "Tutorials" || len(doc.Tags) != 2 || doc.Tags[1] != "cdc" || !doc.HasImage {
+		t.Errorf("Unexpected taxonomy: %+v", doc)
+	}
+
+	// Documents read back from the index carry a decoded JSON list
+	doc, err = NewSearchDocumentFromMap(map[string]interface{}{
+		"id":   2.0,
+		"tags": []interface{}{"Go", "go"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(doc.Tags) != 1 || doc.Tags[0] != "go" || doc.HasImage {
+		t.Errorf("Unexpected taxonomy: %+v", doc)
+	}
+}
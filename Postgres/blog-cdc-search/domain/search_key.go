@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SearchKey is a search-only API key of the index. Value is only known for
+// keys just created; listed keys carry their ID and description alone.
+type SearchKey struct {
+	ID          int64     `json:"id"`
+	Value       string    `json:"-"`
+	Description string    `json:"description"`
+	Collections []string  `json:"collections"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// SearchKeyRepository manages the search-only keys of the index and derives
+// scoped keys from them. Scoped keys are signed locally with their parent
+// key, so handing one out costs no request to the index.
+type SearchKeyRepository interface {
+	CreateSearchKey(ctx context.Context, description string, collections []string, expiresAt time.Time) (*SearchKey, error)
+	ListSearchKeys(ctx context.Context) ([]*SearchKey, error)
+	DeleteSearchKey(ctx context.Context, id int64) error
+	// ScopeSearchKey embeds search parameters, such as filter_by and
+	// expires_at, into a key derived from a search-only parent key. Clients
+	// cannot override them.
+	ScopeSearchKey(parent *SearchKey, params map[string]interface{}) (string, error)
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Curation match types: an exact query, or any query containing it
+const (
+	CurationMatchExact    = "exact"
+	CurationMatchContains = "contains"
+)
+
+// ErrSearchTuningNotFound is returned for unknown synonyms and curations
+var ErrSearchTuningNotFound = errors.New("search tuning rule not found")
+
+// Synonym makes words find each other in searches. With a root it is
+// one-way: searching for the root also finds the synonyms, not the reverse.
+// Without one every word finds all the others.
+type Synonym struct {
+	ID       int      `json:"id"`
+	Root     string   `json:"root,omitempty"`
+	Synonyms []string `json:"synonyms"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewSynonym validates and creates a synonym. Words are normalized like tags:
+// matched without regard to case and stored in lowercase, without commas.
+func NewSynonym(root string, synonyms []string) (*Synonym, error) {
+	root = strings.Join(NormalizeTags([]string{root}), "")
+	words := slices.DeleteFunc(NormalizeTags(synonyms), func(word string) bool { return word == root })
+
+	switch {
+	case root != "" && len(words) == 0:
+		return nil, errors.New("a one-way synonym needs at least one synonym for its root")
+	case root == "" && len(words) < 2:
+		return nil, errors.New("a multi-way synonym needs at least two words")
+	}
+
+	return &Synonym{
+		Root:      root,
+		Synonyms:  words,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IndexID is the synonym's ID in the search index
+func (s *Synonym) IndexID() string {
+	return fmt.Sprintf("synonym-%d", s.ID)
+}
+
+// Curation pins and hides posts in the results of a query. Pinned posts come
+// first, in order; hidden posts never appear.
+type Curation struct {
+	ID     int    `json:"id"`
+	Query  string `json:"query"`
+	Match  string `json:"match"`
+	Pinned []int  `json:"pinned"`
+	Hidden []int  `json:"hidden"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewCuration validates and creates a curation; match defaults to exact
+func NewCuration(query, match string, pinned, hidden []int) (*Curation, error) {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if query == "" {
+		return nil, errors.New("curation query cannot be empty")
+	}
+	if match == "" {
+		match = CurationMatchExact
+	}
+	if match != CurationMatchExact && match != CurationMatchContains {
+		return nil, fmt.Errorf("unknown curation match: %s", match)
+	}
+	if len(pinned) == 0 && len(hidden) == 0 {
+		return nil, errors.New("a curation needs at least one pinned or hidden post")
+	}
+	for _, id := range append(slices.Clone(pinned), hidden...) {
+		if id <= 0 {
+			return nil, fmt.Errorf("invalid post ID: %d", id)
+		}
+	}
+	for _, id := range pinned {
+		if slices.Contains(hidden, id) {
+			return nil, fmt.Errorf("post %d cannot be both pinned and hidden", id)
+		}
+	}
+
+	return &Curation{
+		Query:     query,
+		Match:     match,
+		Pinned:    uniquePostIDs(pinned),
+		Hidden:    uniquePostIDs(hidden),
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IndexID is the curation's ID in the search index
+func (c *Curation) IndexID() string {
+	return fmt.Sprintf("curation-%d", c.ID)
+}
+
+func uniquePostIDs(ids []int) []int {
+	unique := []int{}
+	for _, id := range ids {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// JoinPostIDs joins post IDs for storage
+func JoinPostIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+// SplitPostIDs splits stored post IDs, skipping malformed ones
+func SplitPostIDs(value string) []int {
+	ids := []int{}
+	for _, part := range strings.Split(value, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SearchTuningRepository stores synonyms and curations, which are the
+// source of truth the search index is synced from
+type SearchTuningRepository interface {
+	CreateSynonym(ctx context.Context, synonym *Synonym) error
+	ListSynonyms(ctx context.Context) ([]*Synonym, error)
+	DeleteSynonym(ctx context.Context, id int) error
+	CreateCuration(ctx context.Context, curation *Curation) error
+	ListCurations(ctx context.Context) ([]*Curation, error)
+	DeleteCuration(ctx context.Context, id int) error
+}
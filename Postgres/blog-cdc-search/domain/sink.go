@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Change operations delivered to sinks
+const (
+	ChangeOpUpsert = "upsert"
+	ChangeOpDelete = "delete"
+)
+
+// Change is a posts change normalized from a CDC event, delivered to every sink
+type Change struct {
+	Op       string
+	ID       string
+	Document *SearchDocument // nil for deletes
+	Event    *CDCEvent
+}
+
+// Position returns the source timestamp of the change, used for checkpoints
+func (c *Change) Position() int64 {
+	if c.Event == nil {
+		return 0
+	}
+	return c.Event.TS
+}
+
+// Key identifies the change by its content. A redelivered message has the
+// same key, while other changes with the same source timestamp differ in
+// their data.
+func (c *Change) Key() string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Op + "\x00" + c.ID + "\x00"))
+	if c.Event != nil {
+		json.NewEncoder(hash).Encode(c.Event)
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// ChangeSink is a destination that receives every change, e.g. the search
+// index, a cache invalidator, a webhook or an analytics log. Apply must be
+// safe to call again with a change it has already applied.
+type ChangeSink interface {
+	Name() string
+	Apply(ctx context.Context, change *Change) error
+}
+
+// SinkCheckpoint records how far a sink has got through the change stream.
+// Position is the latest source timestamp applied; since changes can arrive
+// out of order, Applied keeps the keys of the recently applied changes.
+type SinkCheckpoint struct {
+	Sink      string    `json:"sink"`
+	Position  int64     `json:"position"`
+	Applied   []string  `json:"applied,omitempty"`
+	Delivered uint64    `json:"delivered"`
+	Failed    uint64    `json:"failed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckpointStore persists sink checkpoints across restarts. Load returns nil
+// without an error when the sink has no checkpoint yet.
+type CheckpointStore interface {
+	Load(ctx context.Context, sink string) (*SinkCheckpoint, error)
+	Save(ctx context.Context, checkpoint *SinkCheckpoint) error
+}
+
+// EventSource yields archived CDC events in the order they were recorded.
+// Next returns io.EOF once every event has been read.
+type EventSource interface {
+	Next() (*CDCEvent, error)
+}
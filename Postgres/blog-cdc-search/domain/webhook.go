@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook event names sent to endpoints
+const (
+	WebhookEventPostCreated = "post.created"
+	WebhookEventPostUpdated = "post.updated"
+	WebhookEventPostDeleted = "post.deleted"
+)
+
+// WebhookEvents lists every event an endpoint can subscribe to
+var WebhookEvents = []string{WebhookEventPostCreated, WebhookEventPostUpdated, WebhookEventPostDeleted}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// ErrWebhookNotFound is returned for unknown endpoints and deliveries
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// WebhookEndpoint is a URL registered to receive post change events
+type WebhookEndpoint struct {
+	ID     int      `json:"id"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"` // HMAC-SHA256 key for the signature header
+	Events []string `json:"events"`           // empty subscribes to every event
+	Active bool     `json:"active"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewWebhookEndpoint validates and creates an active endpoint
+func NewWebhookEndpoint(rawURL, secret string, events []string) (*WebhookEndpoint, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("webhook URL must be an absolute http or https URL")
+	}
+	if secret == "" {
+		return nil, errors.New("webhook secret cannot be empty")
+	}
+	for _, event := range events {
+		if !isWebhookEvent(event) {
+			return nil, fmt.Errorf("unknown webhook event: %s", event)
+		}
+	}
+
+	return &WebhookEndpoint{
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    events,
+		Active:    true,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Subscribes reports whether the endpoint wants the event
+func (e *WebhookEndpoint) Subscribes(event string) bool {
+	if !e.Active {
+		return false
+	}
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range e.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// EventsString joins the event filter for storage
+func (e *WebhookEndpoint) EventsString() string {
+	return strings.Join(e.Events, ",")
+}
+
+// ParseWebhookEvents splits a stored event filter
+func ParseWebhookEvents(value string) []string {
+	var events []string
+	for _, event := range strings.Split(value, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func isWebhookEvent(event string) bool {
+	for _, known := range WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one attempt series to send an event to an endpoint. A
+// pending delivery is attempted again once NextAttemptAt has passed.
+// ChangeKey identifies the change that caused it, so a redelivered change
+// is logged and sent once per endpoint; redeliveries from the log have none.
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	EndpointID     int        `json:"endpoint_id"`
+	Event          string     `json:"event"`
+	PostID         int        `json:"post_id"`
+	ChangeKey      string     `json:"change_key,omitempty"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookRepository defines the interface for webhook endpoint and delivery storage
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *WebhookEndpoint) error
+	GetEndpoint(ctx context.Context, id int) (*WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]*WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id int) error
+	// CreateDelivery logs a new delivery and reports whether it was created.
+	// When the endpoint already has a delivery for the same change, that one
+	// is loaded into delivery instead.
+	CreateDelivery(ctx context.Context, delivery *WebhookDelivery) (bool, error)
+	UpdateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	GetDelivery(ctx context.Context, id int64) (*WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, limit int) ([]*WebhookDelivery, error)
+	// ClaimDueDeliveries returns up to limit pending deliveries whose next
+	// attempt is due at now, oldest first, and moves their next attempt to
+	// now+lease so other processes skip them while they are retried
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*WebhookDelivery, error)
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// FallbackCache serves from a local cache while the primary (Redis) fails.
+// Entries written during an outage get a short TTL because invalidations from
+// the CDC service only reach the primary.
+type FallbackCache struct {
+	primary     domain.CacheRepository
+	fallback    domain.CacheRepository
+	fallbackTTL time.Duration
+}
+
+// NewFallbackCache wraps primary with fallback; fallbackTTL caps the lifetime
+// of entries cached locally (default 5s)
+func NewFallbackCache(primary, fallback domain.CacheRepository, fallbackTTL time.Duration) *FallbackCache {
+	if fallbackTTL <= 0 {
+		fallbackTTL = 5 * time.Second
+	}
+	return &FallbackCache{
+		primary:     primary,
+		fallback:    fallback,
+		fallbackTTL: fallbackTTL,
+	}
+}
+
+// Get reads from the primary, or from the fallback if the primary fails
+func (c *FallbackCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, found, err := c.primary.Get(ctx, key)
+	if err == nil {
+		return value, found, nil
+	}
+	log.Printf("Cache primary failed, using local fallback: %v", err)
+	return c.fallback.Get(ctx, key)
+}
+
+// Set writes to the primary, or to the fallback if the primary fails
+func (c *FallbackCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := c.primary.Set(ctx, key, value, ttl)
+	if err == nil {
+		return nil
+	}
+	log.Printf("Cache primary failed, using local fallback: %v", err)
+	if ttl == 0 || ttl > c.fallbackTTL {
+		ttl = c.fallbackTTL
+	}
+	return c.fallback.Set(ctx, key, value, ttl)
+}
+
+// Delete removes the keys from both caches
+func (c *FallbackCache) Delete(ctx context.Context, keys ...string) error {
+	if err := c.fallback.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	return c.primary.Delete(ctx, keys...)
+}
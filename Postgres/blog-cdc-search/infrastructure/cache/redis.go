@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"blog-cdc-search/infrastructure/tlsconfig"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig holds the Redis connection settings
+type RedisConfig struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	DB       int
+	// KeyPrefix is prepended to every key so several deployments can share a database
+	KeyPrefix string
+	TLS       tlsconfig.Config
+
+	DialTimeout  time.Duration // default 5s
+	ReadTimeout  time.Duration // default 3s
+	WriteTimeout time.Duration // default 3s
+}
+
+// RedisCache stores cache entries in Redis, shared by every blog instance
+// and invalidated by the CDC service
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisCache creates a Redis cache; connections are opened lazily
+func NewRedisCache(config RedisConfig) (*RedisCache, error) {
+	tlsConfig, err := config.TLS.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Redis TLS config: %w", err)
+	}
+
+	options := &redis.Options{
+		Addr:         config.Addr,
+		Username:     config.Username,
+		Password:     config.Password,
+		DB:           config.DB,
+		TLSConfig:    tlsConfig,
+		DialTimeout:  config.DialTimeout,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+	}
+	if options.DialTimeout == 0 {
+		options.DialTimeout = 5 * time.Second
+	}
+	if options.ReadTimeout == 0 {
+		options.ReadTimeout = 3 * time.Second
+	}
+	if options.WriteTimeout == 0 {
+		options.WriteTimeout = 3 * time.Second
+	}
+
+	return &RedisCache{
+		client: redis.NewClient(options),
+		prefix: config.KeyPrefix,
+	}, nil
+}
+
+// Ping checks that Redis is reachable
+func (c *RedisCache) Ping(ctx context.Context) error {
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to reach Redis: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection pool
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// Get returns the cached value for key
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get cache key %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set stores a value; a ttl of 0 keeps it until it is deleted
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.client.Set(ctx, c.prefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set cache key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the given keys
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	if err := c.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("failed to delete cache keys: %w", err)
+	}
+	return nil
+}
@@ -4,17 +4,67 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 
 	_ "github.com/lib/pq"
 )
 
-// NewPostgreSQLConnection creates a new PostgreSQL database connection
-func NewPostgreSQLConnection(host, port, user, password, dbName string) (*sql.DB, error) {
-	// Create connection string
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", host, port, user, password, dbName)
+// PostgreSQLConfig holds configuration for the PostgreSQL connection
+type PostgreSQLConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	DBName   string
+
+	// SSLMode is passed to lib/pq as sslmode: disable, require, verify-ca or
+	// verify-full. Defaults to disable.
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+}
+
+// DSN builds the lib/pq connection string, quoting every value
+func (c PostgreSQLConfig) DSN() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	params := []struct{ key, value string }{
+		{"host", c.Host},
+		{"port", c.Port},
+		{"user", c.User},
+		{"password", c.Password},
+		{"dbname", c.DBName},
+		{"sslmode", sslMode},
+		{"sslrootcert", c.SSLRootCert},
+		{"sslcert", c.SSLCert},
+		{"sslkey", c.SSLKey},
+	}
 
+	var parts []string
+	for _, param := range params {
+		if param.value == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", param.key, quoteDSNValue(param.value)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue wraps a value in single quotes, escaping quotes and backslashes
+func quoteDSNValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// NewPostgreSQLConnection creates a new PostgreSQL database connection
+func NewPostgreSQLConnection(config PostgreSQLConfig) (*sql.DB, error) {
 	// Open database connection
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open("postgres", config.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
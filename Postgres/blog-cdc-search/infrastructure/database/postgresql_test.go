@@ -0,0 +1,38 @@
+package database
+
+import "testing"
+
+func TestPostgreSQLConfig_DSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   PostgreSQLConfig
+		expected string
+	}{
+		{
+			name:     "defaults to sslmode disable",
+			config:   PostgreSQLConfig{Host: "localhost", Port: "5432", User: "bloguser", Password: "blogpass", DBName: "blog"},
+			expected: "host='localhost' port='5432' user='bloguser' password='blogpass' dbname='blog' sslmode='disable'",
+		},
+		{
+			name: "includes certificates",
+			config: PostgreSQLConfig{
+				Host: "db", Port: "5432", User: "u", DBName: "blog",
+				SSLMode: "verify-full", SSLRootCert: "/certs/ca.pem", SSLCert: "/certs/client.pem", SSLKey: "/certs/client.key",
+			},
+			expected: "host='db' port='5432' user='u' dbname='blog' sslmode='verify-full' sslrootcert='/certs/ca.pem' sslcert='/certs/client.pem' sslkey='/certs/client.key'",
+		},
+		{
+			name:     "escapes special characters",
+			config:   PostgreSQLConfig{Host: "db", Password: `it's a \secret`},
+			expected: `host='db' password='it\'s a \\secret' sslmode='disable'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if dsn := tt.config.DSN(); dsn != tt.expected {
+				t.Errorf("expected DSN %q, got %q", tt.expected, dsn)
+			}
+		})
+	}
+}
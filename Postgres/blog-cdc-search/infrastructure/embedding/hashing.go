@@ -0,0 +1,90 @@
+// Package embedding provides embedders that run on the local CPU, without
+// calling an external API
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// token matches the words embedders split text into
+var token = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// tokenize splits text into lowercase words
+func tokenize(text string) []string {
+	return token.FindAllString(strings.ToLower(text), -1)
+}
+
+// normalize scales a vector to unit length, so cosine similarity is a dot
+// product. A zero vector becomes nil.
+func normalize(vector []float32) []float32 {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+	if sum == 0 {
+		return nil
+	}
+
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+// DefaultHashingDimensions is the vector size of a HashingEmbedder by default
+const DefaultHashingDimensions = 256
+
+// HashingEmbedder hashes words and their character trigrams into a fixed
+// size vector. It needs no model and is fully deterministic, so texts sharing
+// words or word stems ("concurrent", "concurrency") end up close, but words
+// that only mean the same thing do not; use a WordVectorEmbedder for that.
+type HashingEmbedder struct {
+	dimensions int
+}
+
+// NewHashingEmbedder creates a hashing embedder producing vectors of the
+// given size, or DefaultHashingDimensions if it is not positive
+func NewHashingEmbedder(dimensions int) *HashingEmbedder {
+	if dimensions <= 0 {
+		dimensions = DefaultHashingDimensions
+	}
+	return &HashingEmbedder{dimensions: dimensions}
+}
+
+// Dimensions returns the vector size
+func (e *HashingEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// Embed returns the normalized sum of the hashed features of every word
+func (e *HashingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vector := make([]float32, e.dimensions)
+	for _, word := range tokenize(text) {
+		e.add(vector, "w:"+word, 1)
+
+		// Trigrams of the word with boundary markers carry its stem
+		runes := []rune("<" + word + ">")
+		for i := 0; i+3 <= len(runes); i++ {
+			e.add(vector, "t:"+string(runes[i:i+3]), 0.5)
+		}
+	}
+	return normalize(vector), nil
+}
+
+// add adds a feature to the vector. A second bit of the hash picks the sign
+// so collisions cancel out on average instead of piling up.
+func (e *HashingEmbedder) add(vector []float32, feature string, weight float32) {
+	hash := fnv.New64a()
+	hash.Write([]byte(feature))
+	sum := hash.Sum64()
+
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vector[sum%uint64(e.dimensions)] += weight
+}
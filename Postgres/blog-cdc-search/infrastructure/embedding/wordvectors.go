@@ -0,0 +1,123 @@
+package embedding
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WordVectorEmbedder embeds text as the average of pre-trained word vectors,
+// such as GloVe or fastText, so words used in similar contexts ("concurrency",
+// "goroutines", "threads") land close together. Words missing from the
+// vectors are skipped.
+type WordVectorEmbedder struct {
+	vectors    map[string][]float32
+	dimensions int
+}
+
+// LoadWordVectors reads word vectors in the text format GloVe and fastText
+// publish: one word per line followed by its values, separated by spaces,
+// optionally after a "<count> <dimensions>" header line. Files ending in .gz
+// are decompressed.
+func LoadWordVectors(path string) (*WordVectorEmbedder, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open word vectors: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress word vectors: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	embedder, err := ReadWordVectors(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read word vectors from %s: %w", path, err)
+	}
+	return embedder, nil
+}
+
+// ReadWordVectors reads word vectors in the format LoadWordVectors accepts
+func ReadWordVectors(reader io.Reader) (*WordVectorEmbedder, error) {
+	embedder := &WordVectorEmbedder{vectors: map[string][]float32{}}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		// fastText files start with the word count and dimensions
+		if line == 1 && len(fields) == 2 {
+			if _, err := strconv.Atoi(fields[0]); err == nil {
+				continue
+			}
+		}
+
+		values := fields[1:]
+		if embedder.dimensions == 0 {
+			embedder.dimensions = len(values)
+		}
+		if len(values) != embedder.dimensions || len(values) == 0 {
+			return nil, fmt.Errorf("line %d: expected %d values, got %d", line, embedder.dimensions, len(values))
+		}
+
+		vector := make([]float32, len(values))
+		for i, value := range values {
+			parsed, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			vector[i] = float32(parsed)
+		}
+
+		// The first vector of a word wins, as files list frequent forms first
+		word := strings.ToLower(fields[0])
+		if _, ok := embedder.vectors[word]; !ok {
+			embedder.vectors[word] = vector
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(embedder.vectors) == 0 {
+		return nil, fmt.Errorf("no word vectors found")
+	}
+
+	return embedder, nil
+}
+
+// Dimensions returns the vector size
+func (e *WordVectorEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// Embed returns the normalized average of the vectors of the known words
+func (e *WordVectorEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	sum := make([]float32, e.dimensions)
+	for _, word := range tokenize(text) {
+		vector, ok := e.vectors[word]
+		if !ok {
+			continue
+		}
+		for i, value := range vector {
+			sum[i] += value
+		}
+	}
+	// Normalizing makes the average and the sum the same vector
+	return normalize(sum), nil
+}
@@ -1,16 +1,27 @@
 package messagequeue
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/tlsconfig"
 
 	"github.com/streadway/amqp"
 )
 
 // RabbitMQRepository implements the MessageQueueRepository interface
 type RabbitMQRepository struct {
+	mu      sync.Mutex
 	conn    *amqp.Connection
 	channel *amqp.Channel
+	closed  chan *amqp.Error
 	config  RabbitMQConfig
 }
 
@@ -21,28 +32,182 @@ type RabbitMQConfig struct {
 	Username string
 	Password string
 	VHost    string
+
+	// TLS switches the connection to amqps:// with the given certificates
+	TLS tlsconfig.Config
+
+	// HandlerTimeout bounds the time spent processing a single message.
+	// Zero means no deadline.
+	HandlerTimeout time.Duration
+
+	// ReconnectInitialInterval and ReconnectMaxInterval bound the jittered
+	// exponential backoff used when the broker connection is lost.
+	ReconnectInitialInterval time.Duration
+	ReconnectMaxInterval     time.Duration
+
+	// PauseInterval is how long consumption is suspended when the handler
+	// reports that a downstream service is unavailable.
+	PauseInterval time.Duration
+
+	// Exchange is the exchange the CDC connector publishes to. When empty the
+	// queue is consumed without declaring or binding an exchange.
+	Exchange string
+	// ExchangeType is the exchange kind: direct, fanout, topic or headers.
+	ExchangeType string
+	// BindingKeys are the routing keys the queue is bound with. Topic
+	// exchanges accept the * and # wildcards.
+	BindingKeys []string
+	// Queue holds the arguments used when declaring the queue.
+	Queue QueueOptions
+
+	// ConsumerTag identifies this consumer on the channel so it can be
+	// cancelled on shutdown and recognised in the management UI.
+	ConsumerTag string
+	// PrefetchCount is the number of unacknowledged messages delivered at once.
+	PrefetchCount int
+}
+
+// QueueOptions holds the optional x-arguments for the queue declaration
+type QueueOptions struct {
+	// Type is the queue type, "classic" or "quorum". Empty uses the broker default.
+	Type string
+	// MessageTTL expires messages that stay in the queue longer than this.
+	MessageTTL time.Duration
+	// MaxLength caps the number of ready messages in the queue.
+	MaxLength int
+	// DeadLetterExchange receives rejected and expired messages.
+	DeadLetterExchange string
+	// DeadLetterRoutingKey replaces the routing key of dead-lettered messages.
+	DeadLetterRoutingKey string
+}
+
+// Arguments converts the options to the table passed to QueueDeclare
+func (o QueueOptions) Arguments() amqp.Table {
+	args := amqp.Table{}
+	if o.Type != "" {
+		args["x-queue-type"] = o.Type
+	}
+	if o.MessageTTL > 0 {
+		args["x-message-ttl"] = o.MessageTTL.Milliseconds()
+	}
+	if o.MaxLength > 0 {
+		args["x-max-length"] = int64(o.MaxLength)
+	}
+	if o.DeadLetterExchange != "" {
+		args["x-dead-letter-exchange"] = o.DeadLetterExchange
+	}
+	if o.DeadLetterRoutingKey != "" {
+		args["x-dead-letter-routing-key"] = o.DeadLetterRoutingKey
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// Validate checks that the topology settings are consistent
+func (c RabbitMQConfig) Validate() error {
+	switch c.ExchangeType {
+	case "", amqp.ExchangeDirect, amqp.ExchangeFanout, amqp.ExchangeTopic, amqp.ExchangeHeaders:
+	default:
+		return fmt.Errorf("unsupported exchange type: %s", c.ExchangeType)
+	}
+
+	switch c.Queue.Type {
+	case "", "classic", "quorum":
+	default:
+		return fmt.Errorf("unsupported queue type: %s", c.Queue.Type)
+	}
+
+	if c.ExchangeType != amqp.ExchangeTopic {
+		for _, key := range c.BindingKeys {
+			if strings.ContainsAny(key, "*#") {
+				return fmt.Errorf("binding key %q uses wildcards but exchange type is %s", key, c.ExchangeType)
+			}
+		}
+	}
+
+	if c.Queue.DeadLetterRoutingKey != "" && c.Queue.DeadLetterExchange == "" {
+		return fmt.Errorf("dead letter routing key requires a dead letter exchange")
+	}
+
+	return nil
 }
 
+// Default settings used when the config leaves them unset
+const (
+	defaultReconnectInitialInterval = 500 * time.Millisecond
+	defaultReconnectMaxInterval     = 30 * time.Second
+	defaultPauseInterval            = 5 * time.Second
+	defaultConsumerTag              = "blog-cdc-search"
+	defaultPrefetchCount            = 1
+)
+
+var (
+	// errConnectionLost is returned by consume when the broker connection or channel closes
+	errConnectionLost = errors.New("connection to RabbitMQ lost")
+	// errConsumerPaused is returned by consume when a downstream service is unavailable
+	errConsumerPaused = errors.New("consumer paused")
+)
+
 // NewRabbitMQRepository creates a new RabbitMQ repository instance
 func NewRabbitMQRepository(config RabbitMQConfig) *RabbitMQRepository {
+	if config.ReconnectInitialInterval <= 0 {
+		config.ReconnectInitialInterval = defaultReconnectInitialInterval
+	}
+	if config.ReconnectMaxInterval <= 0 {
+		config.ReconnectMaxInterval = defaultReconnectMaxInterval
+	}
+	if config.PauseInterval <= 0 {
+		config.PauseInterval = defaultPauseInterval
+	}
+	if config.Exchange != "" && config.ExchangeType == "" {
+		config.ExchangeType = amqp.ExchangeFanout
+	}
+	if config.ConsumerTag == "" {
+		config.ConsumerTag = defaultConsumerTag
+	}
+	if config.PrefetchCount <= 0 {
+		config.PrefetchCount = defaultPrefetchCount
+	}
+
 	return &RabbitMQRepository{
 		config: config,
 	}
 }
 
 // Connect establishes a connection to RabbitMQ
-func (r *RabbitMQRepository) Connect() error {
-	// Build connection URL
-	url := fmt.Sprintf("amqp://%s:%s@%s:%d/%s",
-		r.config.Username,
-		r.config.Password,
-		r.config.Host,
-		r.config.Port,
-		r.config.VHost,
-	)
+func (r *RabbitMQRepository) Connect(ctx context.Context) error {
+	// Build connection URL; amqp.URI escapes credentials and the vhost
+	uri := amqp.URI{
+		Scheme:   "amqp",
+		Host:     r.config.Host,
+		Port:     r.config.Port,
+		Username: r.config.Username,
+		Password: r.config.Password,
+		Vhost:    r.config.VHost,
+	}
+
+	tlsConfig, err := r.config.TLS.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load RabbitMQ TLS config: %w", err)
+	}
+	if tlsConfig != nil {
+		uri.Scheme = "amqps"
+	}
 
-	// Establish connection
-	conn, err := amqp.Dial(url)
+	// Establish connection, honouring the context deadline if there is one
+	dialConfig := amqp.Config{
+		Heartbeat:       10 * time.Second,
+		Locale:          "en_US",
+		TLSClientConfig: tlsConfig,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		dialConfig.Dial = amqp.DefaultDial(time.Until(deadline))
+	}
+
+	conn, err := amqp.DialConfig(uri.String(), dialConfig)
 	if err != nil {
 		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
@@ -54,23 +219,30 @@ func (r *RabbitMQRepository) Connect() error {
 		return fmt.Errorf("failed to open channel: %w", err)
 	}
 
+	r.mu.Lock()
 	r.conn = conn
 	r.channel = channel
+	// Get notified when the connection drops so the consumer can reconnect
+	r.closed = conn.NotifyClose(make(chan *amqp.Error, 1))
+	r.mu.Unlock()
 
-	log.Printf("Connected to RabbitMQ at %s:%d", r.config.Host, r.config.Port)
+	log.Printf("Connected to RabbitMQ at %s://%s:%d", uri.Scheme, r.config.Host, r.config.Port)
 	return nil
 }
 
 // Close closes the RabbitMQ connection
 func (r *RabbitMQRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.channel != nil {
-		if err := r.channel.Close(); err != nil {
+		if err := r.channel.Close(); err != nil && err != amqp.ErrClosed {
 			log.Printf("Error closing channel: %v", err)
 		}
 	}
 
 	if r.conn != nil {
-		if err := r.conn.Close(); err != nil {
+		if err := r.conn.Close(); err != nil && err != amqp.ErrClosed {
 			log.Printf("Error closing connection: %v", err)
 		}
 	}
@@ -78,66 +250,91 @@ func (r *RabbitMQRepository) Close() error {
 	return nil
 }
 
-// ConsumeMessages starts consuming messages from a queue
-func (r *RabbitMQRepository) ConsumeMessages(queueName string, handler func([]byte) error) error {
-	// Declare the queue
-	queue, err := r.channel.QueueDeclare(
-		queueName, // name
-		true,      // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
-	}
-
-	// Declare the exchange (Debezium uses this)
-	err = r.channel.ExchangeDeclare(
-		"debezium", // name
-		"fanout",   // type
-		true,       // durable
-		false,      // auto-deleted
-		false,      // internal
-		false,      // no-wait
-		nil,        // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("failed to declare exchange: %w", err)
+// ConsumeMessages starts consuming messages from a queue. It blocks until ctx
+// is cancelled, then stops the consumer and finishes the messages that were
+// already delivered before returning. A lost connection or channel is
+// re-established with jittered backoff, and consumption is paused while the
+// handler reports that a downstream service is unavailable. A topology the
+// broker refuses, such as a queue declared with other arguments, is returned.
+func (r *RabbitMQRepository) ConsumeMessages(ctx context.Context, queueName string, handler domain.MessageHandler) error {
+	if err := r.config.Validate(); err != nil {
+		return fmt.Errorf("invalid RabbitMQ topology: %w", err)
 	}
 
-	// Bind the queue to the exchange with routing key for posts table
-	err = r.channel.QueueBind(
-		queueName,    // queue name
-		"blog.posts", // routing key (database.table)
-		"debezium",   // exchange
-		false,        // no-wait
-		nil,          // arguments
-	)
+	for {
+		err := r.consume(ctx, queueName, handler)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, errConsumerPaused):
+			log.Printf("Downstream service unavailable, pausing consumer for %s", r.config.PauseInterval)
+			if !sleepContext(ctx, r.config.PauseInterval) {
+				return nil
+			}
+			log.Printf("Resuming consumer on queue: %s", queueName)
+		case !reconnectable(err):
+			return fmt.Errorf("consumer on queue %s failed: %w", queueName, err)
+		default:
+			log.Printf("Consumer on queue %s stopped: %v", queueName, err)
+			if err := r.reconnect(ctx); err != nil {
+				// Only returned when ctx is cancelled while reconnecting
+				return nil
+			}
+		}
+	}
+}
+
+// reconnectable reports whether a consumer error came from the channel or
+// connection closing. The broker also closes the channel when it refuses a
+// declaration or binding, but reconnecting would only repeat the refusal.
+func reconnectable(err error) bool {
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) {
+		switch amqpErr.Code {
+		case amqp.AccessRefused, amqp.NotFound, amqp.PreconditionFailed:
+			return false
+		}
+		return true
+	}
+	return errors.Is(err, errConnectionLost)
+}
+
+// consume declares the topology and processes deliveries until ctx is
+// cancelled (nil), the connection is lost or the consumer has to pause
+func (r *RabbitMQRepository) consume(ctx context.Context, queueName string, handler domain.MessageHandler) error {
+	r.mu.Lock()
+	channel, closed := r.channel, r.closed
+	r.mu.Unlock()
+
+	if channel == nil {
+		return errConnectionLost
+	}
+
+	// Declare the queue, exchange and bindings
+	queue, err := r.declareTopology(channel, queueName)
 	if err != nil {
-		return fmt.Errorf("failed to bind queue: %w", err)
+		return err
 	}
 
 	// Set QoS for reliable message processing
-	err = r.channel.Qos(
-		1,     // prefetch count
-		0,     // prefetch size
-		false, // global
+	err = channel.Qos(
+		r.config.PrefetchCount, // prefetch count
+		0,                      // prefetch size
+		false,                  // global
 	)
 	if err != nil {
 		return fmt.Errorf("failed to set QoS: %w", err)
 	}
 
 	// Start consuming messages with manual acknowledgment
-	msgs, err := r.channel.Consume(
-		queue.Name, // queue
-		"",         // consumer
-		false,      // auto-ack (false for manual acknowledgment)
-		false,      // exclusive
-		false,      // no-local
-		false,      // no-wait
-		nil,        // args
+	msgs, err := channel.Consume(
+		queue.Name,           // queue
+		r.config.ConsumerTag, // consumer
+		false,                // auto-ack (false for manual acknowledgment)
+		false,                // exclusive
+		false,                // no-local
+		false,                // no-wait
+		nil,                  // args
 	)
 	if err != nil {
 		return fmt.Errorf("failed to start consuming: %w", err)
@@ -145,34 +342,205 @@ func (r *RabbitMQRepository) ConsumeMessages(queueName string, handler func([]by
 
 	log.Printf("Started consuming messages from queue: %s with reliable delivery (manual ACK)", queueName)
 
-	// Process messages
-	for msg := range msgs {
-		log.Printf("Received message: %s", string(msg.Body))
+	// Process messages until the context is cancelled
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopping consumer on queue: %s", queueName)
+			r.stopConsumer(ctx, channel, msgs, handler)
+			log.Printf("Consumer on queue %s drained", queueName)
+			return nil
+		case amqpErr := <-closed:
+			return fmt.Errorf("%w: %v", errConnectionLost, amqpErr)
+		case msg, ok := <-msgs:
+			if !ok {
+				return errConnectionLost
+			}
+			if err := r.handleDelivery(ctx, msg, handler); errors.Is(err, domain.ErrServiceUnavailable) {
+				// Stop prefetching while the downstream service is down; the
+				// failed message has already been requeued
+				r.stopConsumer(ctx, channel, msgs, nil)
+				return errConsumerPaused
+			}
+		}
+	}
+}
+
+// declareTopology declares the queue and, when an exchange is configured, the
+// exchange and one binding per binding key
+func (r *RabbitMQRepository) declareTopology(channel *amqp.Channel, queueName string) (amqp.Queue, error) {
+	// Declare the queue
+	queue, err := channel.QueueDeclare(
+		queueName,                  // name
+		true,                       // durable
+		false,                      // delete when unused
+		false,                      // exclusive
+		false,                      // no-wait
+		r.config.Queue.Arguments(), // arguments
+	)
+	if err != nil {
+		return queue, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	if r.config.Exchange == "" {
+		return queue, nil
+	}
+
+	// Declare the exchange the CDC connector publishes to
+	err = channel.ExchangeDeclare(
+		r.config.Exchange,     // name
+		r.config.ExchangeType, // type
+		true,                  // durable
+		false,                 // auto-deleted
+		false,                 // internal
+		false,                 // no-wait
+		nil,                   // arguments
+	)
+	if err != nil {
+		return queue, fmt.Errorf("failed to declare exchange: %w", err)
+	}
 
-		// Handle the message
-		if err := handler(msg.Body); err != nil {
-			log.Printf("Error processing message: %v", err)
-			// Reject the message and requeue it for retry
+	// Bind the queue once per routing key; fanout exchanges ignore the key
+	bindingKeys := r.config.BindingKeys
+	if len(bindingKeys) == 0 {
+		bindingKeys = []string{""}
+	}
+
+	for _, key := range bindingKeys {
+		err = channel.QueueBind(
+			queueName,         // queue name
+			key,               // routing key
+			r.config.Exchange, // exchange
+			false,             // no-wait
+			nil,               // arguments
+		)
+		if err != nil {
+			return queue, fmt.Errorf("failed to bind queue with key %q: %w", key, err)
+		}
+	}
+
+	return queue, nil
+}
+
+// stopConsumer cancels the consumer and drains deliveries that were already
+// pushed to us. With a nil handler the drained messages are requeued.
+func (r *RabbitMQRepository) stopConsumer(ctx context.Context, channel *amqp.Channel, msgs <-chan amqp.Delivery, handler domain.MessageHandler) {
+	// Stop the broker from delivering new messages; the deliveries channel
+	// is closed once the cancellation is confirmed
+	if err := channel.Cancel(r.config.ConsumerTag, false); err != nil {
+		log.Printf("Failed to cancel consumer: %v", err)
+		return
+	}
+
+	for msg := range msgs {
+		if handler == nil {
 			if err := msg.Nack(false, true); err != nil {
 				log.Printf("Failed to NACK message: %v", err)
 			}
 			continue
 		}
+		r.handleDelivery(ctx, msg, handler)
+	}
+}
+
+// reconnect re-establishes the connection with jittered exponential backoff.
+// It only gives up when ctx is cancelled.
+func (r *RabbitMQRepository) reconnect(ctx context.Context) error {
+	r.Close()
+
+	for attempt := 0; ; attempt++ {
+		delay := backoff(attempt, r.config.ReconnectInitialInterval, r.config.ReconnectMaxInterval)
+		log.Printf("Reconnecting to RabbitMQ in %s (attempt %d)", delay, attempt+1)
+		if !sleepContext(ctx, delay) {
+			return ctx.Err()
+		}
 
-		// Acknowledge the message after successful processing
-		if err := msg.Ack(false); err != nil {
-			log.Printf("Failed to ACK message: %v", err)
-		} else {
-			log.Printf("Successfully processed and acknowledged message")
+		if err := r.Connect(ctx); err != nil {
+			log.Printf("Reconnect attempt %d failed: %v", attempt+1, err)
+			continue
 		}
+		return nil
 	}
+}
+
+// backoff returns the delay before the given reconnect attempt: exponential
+// growth from initial, capped at maxDelay, with jitter in [d/2, d)
+func backoff(attempt int, initial, maxDelay time.Duration) time.Duration {
+	delay := initial
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half)
+}
+
+// sleepContext waits for d and reports whether ctx is still alive afterwards
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// handleDelivery runs the handler for a single delivery and acknowledges it.
+// It returns the handler error, if any.
+func (r *RabbitMQRepository) handleDelivery(ctx context.Context, msg amqp.Delivery, handler domain.MessageHandler) error {
+	log.Printf("Received message: %s", string(msg.Body))
+
+	// In-flight messages must not be aborted by shutdown, only by their own deadline
+	msgCtx := context.WithoutCancel(ctx)
+	if r.config.HandlerTimeout > 0 {
+		var cancel context.CancelFunc
+		msgCtx, cancel = context.WithTimeout(msgCtx, r.config.HandlerTimeout)
+		defer cancel()
+	}
+
+	// Handle the message
+	if err := handler(msgCtx, msg.Body); err != nil {
+		log.Printf("Error processing message: %v", err)
+		// Reject the message and requeue it for retry
+		if err := msg.Nack(false, true); err != nil {
+			log.Printf("Failed to NACK message: %v", err)
+		}
+		return err
+	}
+
+	// Acknowledge the message after successful processing
+	if err := msg.Ack(false); err != nil {
+		log.Printf("Failed to ACK message: %v", err)
+	} else {
+		log.Printf("Successfully processed and acknowledged message")
+	}
 	return nil
 }
 
 // PublishMessage publishes a message to an exchange
-func (r *RabbitMQRepository) PublishMessage(exchange, routingKey string, message []byte) error {
-	return r.channel.Publish(
+func (r *RabbitMQRepository) PublishMessage(ctx context.Context, exchange, routingKey string, message []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	channel := r.channel
+	r.mu.Unlock()
+
+	if channel == nil {
+		return errConnectionLost
+	}
+
+	return channel.Publish(
 		exchange,   // exchange
 		routingKey, // routing key
 		false,      // mandatory
@@ -1,7 +1,13 @@
 package messagequeue
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
 )
 
 func TestNewRabbitMQRepository(t *testing.T) {
@@ -75,3 +81,179 @@ func TestRabbitMQRepository_Close(t *testing.T) {
 		t.Errorf("Expected no error when closing without connection, got: %v", err)
 	}
 }
+
+func TestNewRabbitMQRepository_Defaults(t *testing.T) {
+	repo := NewRabbitMQRepository(RabbitMQConfig{Host: "localhost", Port: 5672})
+
+	if repo.config.ReconnectInitialInterval != defaultReconnectInitialInterval {
+		t.Errorf("Expected initial interval %v, got %v", defaultReconnectInitialInterval, repo.config.ReconnectInitialInterval)
+	}
+
+	if repo.config.ReconnectMaxInterval != defaultReconnectMaxInterval {
+		t.Errorf("Expected max interval %v, got %v", defaultReconnectMaxInterval, repo.config.ReconnectMaxInterval)
+	}
+
+	if repo.config.PauseInterval != defaultPauseInterval {
+		t.Errorf("Expected pause interval %v, got %v", defaultPauseInterval, repo.config.PauseInterval)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	initial := 100 * time.Millisecond
+	maxDelay := time.Second
+
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{attempt: 0, ceiling: 100 * time.Millisecond},
+		{attempt: 1, ceiling: 200 * time.Millisecond},
+		{attempt: 3, ceiling: 800 * time.Millisecond},
+		{attempt: 10, ceiling: time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			delay := backoff(tt.attempt, initial, maxDelay)
+			if delay < tt.ceiling/2 || delay >= tt.ceiling {
+				t.Errorf("attempt %d: expected delay in [%v, %v), got %v", tt.attempt, tt.ceiling/2, tt.ceiling, delay)
+			}
+		}
+	}
+}
+
+func TestConsumeMessages_NotConnected(t *testing.T) {
+	repo := NewRabbitMQRepository(RabbitMQConfig{
+		Host:                     "localhost",
+		Port:                     5672,
+		ReconnectInitialInterval: time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// With a cancelled context the reconnect loop must give up immediately
+	err := repo.ConsumeMessages(ctx, "test-queue", func(ctx context.Context, message []byte) error {
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected nil error after cancellation, got: %v", err)
+	}
+}
+
+func TestReconnectable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"connection lost", fmt.Errorf("%w: %v", errConnectionLost, amqp.ErrClosed), true},
+		{"channel closed", fmt.Errorf("failed to set QoS: %w", amqp.ErrClosed), true},
+		{"connection forced", &amqp.Error{Code: amqp.ConnectionForced, Reason: "broker shutdown"}, true},
+		{"queue arguments differ", fmt.Errorf("failed to declare queue: %w", &amqp.Error{Code: amqp.PreconditionFailed, Reason: "PRECONDITION_FAILED - inequivalent arg 'x-queue-type'"}), false},
+		{"missing exchange", fmt.Errorf("failed to bind queue: %w", &amqp.Error{Code: amqp.NotFound, Reason: "NOT_FOUND - no exchange 'cdc'"}), false},
+		{"access refused", &amqp.Error{Code: amqp.AccessRefused, Reason: "ACCESS_REFUSED"}, false},
+		{"other error", errors.New("unexpected"), false},
+	}
+	for _, tt := range tests {
+		if got := reconnectable(tt.err); got != tt.expected {
+			t.Errorf("%s: expected reconnectable %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestQueueOptions_Arguments(t *testing.T) {
+	if args := (QueueOptions{}).Arguments(); args != nil {
+		t.Errorf("Expected nil arguments for empty options, got: %v", args)
+	}
+
+	options := QueueOptions{
+		Type:                 "quorum",
+		MessageTTL:           time.Minute,
+		MaxLength:            1000,
+		DeadLetterExchange:   "cdc.dlx",
+		DeadLetterRoutingKey: "posts.failed",
+	}
+
+	args := options.Arguments()
+
+	expected := map[string]interface{}{
+		"x-queue-type":              "quorum",
+		"x-message-ttl":             int64(60000),
+		"x-max-length":              int64(1000),
+		"x-dead-letter-exchange":    "cdc.dlx",
+		"x-dead-letter-routing-key": "posts.failed",
+	}
+
+	for key, value := range expected {
+		if args[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, args[key])
+		}
+	}
+
+	if err := args.Validate(); err != nil {
+		t.Errorf("Expected valid AMQP table, got: %v", err)
+	}
+}
+
+func TestRabbitMQConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RabbitMQConfig
+		wantErr bool
+	}{
+		{
+			name:   "fanout exchange",
+			config: RabbitMQConfig{Exchange: "maxwell", ExchangeType: "fanout", BindingKeys: []string{"blog.posts"}},
+		},
+		{
+			name:   "topic exchange with wildcards",
+			config: RabbitMQConfig{Exchange: "cdc", ExchangeType: "topic", BindingKeys: []string{"blog.*", "shop.#"}},
+		},
+		{
+			name:    "wildcards on direct exchange",
+			config:  RabbitMQConfig{Exchange: "cdc", ExchangeType: "direct", BindingKeys: []string{"blog.*"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown exchange type",
+			config:  RabbitMQConfig{Exchange: "cdc", ExchangeType: "broadcast"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown queue type",
+			config:  RabbitMQConfig{Queue: QueueOptions{Type: "lazy"}},
+			wantErr: true,
+		},
+		{
+			name:    "dead letter routing key without exchange",
+			config:  RabbitMQConfig{Queue: QueueOptions{DeadLetterRoutingKey: "failed"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRabbitMQRepository_TopologyDefaults(t *testing.T) {
+	repo := NewRabbitMQRepository(RabbitMQConfig{Exchange: "maxwell"})
+
+	if repo.config.ExchangeType != "fanout" {
+		t.Errorf("Expected default exchange type fanout, got %s", repo.config.ExchangeType)
+	}
+
+	if repo.config.ConsumerTag != defaultConsumerTag {
+		t.Errorf("Expected default consumer tag %s, got %s", defaultConsumerTag, repo.config.ConsumerTag)
+	}
+
+	if repo.config.PrefetchCount != defaultPrefetchCount {
+		t.Errorf("Expected default prefetch %d, got %d", defaultPrefetchCount, repo.config.PrefetchCount)
+	}
+}
@@ -0,0 +1,100 @@
+package readmodel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// PostRepository implements PostRepository on top of the read model. Posts
+// only change through the change stream, so writes fail with domain.ErrReadOnly.
+type PostRepository struct {
+	db *sql.DB
+}
+
+// NewPostRepository creates a read-only post repository backed by model
+func NewPostRepository(model *SQLiteReadModel) *PostRepository {
+	return &PostRepository{db: model.db}
+}
+
+// Create is not supported by the read model
+func (r *PostRepository) Create(ctx context.Context, post *domain.Post) error {
+	return domain.ErrReadOnly
+}
+
+// GetByID retrieves a post by its ID
+func (r *PostRepository) GetByID(ctx context.Context, id int) (*domain.Post, error) {
+	query := `
+		SELECT id, title, image, excerpt, body, author, category, language, tags, created_at, updated_at
+		FROM posts WHERE id = ? AND deleted = 0
+	`
+
+	post, err := scanPost(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrPostNotFound
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	return post, nil
+}
+
+// GetAll retrieves all posts, newest first
+func (r *PostRepository) GetAll(ctx context.Context) ([]*domain.Post, error) {
+	query := `
+		SELECT id, title, image, excerpt, body, author, category, language, tags, created_at, updated_at
+		FROM posts WHERE deleted = 0 ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []*domain.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return posts, nil
+}
+
+// Update is not supported by the read model
+func (r *PostRepository) Update(ctx context.Context, post *domain.Post) error {
+	return domain.ErrReadOnly
+}
+
+// Delete is not supported by the read model
+func (r *PostRepository) Delete(ctx context.Context, id int) error {
+	return domain.ErrReadOnly
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPost(row rowScanner) (*domain.Post, error) {
+	var post domain.Post
+	var tags string
+	var createdAt, updatedAt int64
+	err := row.Scan(&post.ID, &post.Title, &post.Image, &post.Excerpt, &post.Body, &post.Author, &post.Category, &post.Language, &tags, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	post.Tags = domain.SplitTags(tags)
+	post.CreatedAt = time.Unix(createdAt, 0)
+	post.UpdatedAt = time.Unix(updatedAt, 0)
+	return &post, nil
+}
@@ -0,0 +1,295 @@
+// Package readmodel maintains a denormalized SQLite copy of the posts table,
+// fed by the change stream, so public pages can be served without the
+// primary database.
+package readmodel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-cdc-search/domain"
+
+	_ "modernc.org/sqlite"
+)
+
+// wordsPerMinute is the reading speed used for the reading time column
+const wordsPerMinute = 200
+
+const schema = `
+CREATE TABLE IF NOT EXISTS posts (
+    id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL,
+    image TEXT NOT NULL,
+    excerpt TEXT NOT NULL,
+    body TEXT NOT NULL,
+    author TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    language TEXT NOT NULL DEFAULT 'en',
+    tags TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL,
+    word_count INTEGER NOT NULL,
+    reading_minutes INTEGER NOT NULL,
+    deleted INTEGER NOT NULL DEFAULT 0,
+    source_ts INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_posts_created_at ON posts (created_at DESC);
+
+CREATE TABLE IF NOT EXISTS sync_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    position INTEGER NOT NULL,
+    snapshot_position INTEGER NOT NULL,
+    applied INTEGER NOT NULL,
+    resynced_at INTEGER,
+    updated_at INTEGER NOT NULL
+);
+
+INSERT OR IGNORE INTO sync_state (id, position, snapshot_position, applied, updated_at) VALUES (1, 0, 0, 0, 0);
+`
+
+// addedColumns are posts columns added after read models were first
+// created. Open adds them to older files; their posts are filled in by the
+// next change to each post or by a resync.
+var addedColumns = []struct{ name, definition string }{
+	{"author", "TEXT NOT NULL DEFAULT ''"},
+	{"category", "TEXT NOT NULL DEFAULT ''"},
+	{"tags", "TEXT NOT NULL DEFAULT ''"},
+	{"language", "TEXT NOT NULL DEFAULT 'en'"},
+}
+
+// Status describes how far the read model has got through the change stream
+type Status struct {
+	Position         int64      `json:"position"`          // source timestamp of the newest applied change
+	SnapshotPosition int64      `json:"snapshot_position"` // source timestamp of the last resync
+	Applied          int64      `json:"applied"`
+	Posts            int        `json:"posts"`
+	ResyncedAt       *time.Time `json:"resynced_at,omitempty"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// SQLiteReadModel is a change sink keeping a SQLite replica of the posts
+// table along with derived columns (word count and reading time). Each change
+// is applied in its own transaction together with the source position.
+type SQLiteReadModel struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// Open opens or creates the read model database at path
+func Open(path string) (*SQLiteReadModel, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create read model directory: %w", err)
+	}
+
+	// WAL lets the blog read while the CDC consumer writes
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read model: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create read model schema: %w", err)
+	}
+	if err := addMissingColumns(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteReadModel{db: db, now: time.Now}, nil
+}
+
+// addMissingColumns upgrades a read model created by an older version
+func addMissingColumns(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('posts')`)
+	if err != nil {
+		return fmt.Errorf("failed to read read model columns: %w", err)
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read read model columns: %w", err)
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read read model columns: %w", err)
+	}
+
+	for _, column := range addedColumns {
+		if existing[column.name] {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE posts ADD COLUMN ` + column.name + ` ` + column.definition); err != nil {
+			return fmt.Errorf("failed to add read model column %s: %w", column.name, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the database
+func (m *SQLiteReadModel) Close() error {
+	return m.db.Close()
+}
+
+// Name returns the sink name used in stats and checkpoints
+func (m *SQLiteReadModel) Name() string {
+	return "readmodel"
+}
+
+// Apply writes the change to the posts table. A change older than the one
+// already applied to the post, or than the last resync, is skipped so a
+// redelivered message never rolls a post back.
+func (m *SQLiteReadModel) Apply(ctx context.Context, change *domain.Change) error {
+	id, err := strconv.Atoi(change.ID)
+	if err != nil {
+		return fmt.Errorf("invalid post ID %q: %w", change.ID, err)
+	}
+	position := change.Position()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin read model transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var snapshotPosition, postPosition int64
+	if err := tx.QueryRowContext(ctx, `SELECT snapshot_position FROM sync_state WHERE id = 1`).Scan(&snapshotPosition); err != nil {
+		return fmt.Errorf("failed to read sync state: %w", err)
+	}
+	err = tx.QueryRowContext(ctx, `SELECT source_ts FROM posts WHERE id = ?`, id).Scan(&postPosition)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read post position: %w", err)
+	}
+	if position != 0 && position < max(snapshotPosition, postPosition) {
+		return nil
+	}
+	position = max(position, postPosition)
+
+	switch change.Op {
+	case domain.ChangeOpUpsert:
+		doc := change.Document
+		words := wordCount(doc.Body)
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO posts (id, title, image, excerpt, body, author, category, language, tags, created_at, updated_at, word_count, reading_minutes, deleted, source_ts)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				title = excluded.title, image = excluded.image, excerpt = excluded.excerpt, body = excluded.body,
+				author = excluded.author, category = excluded.category, language = excluded.language, tags = excluded.tags,
+				created_at = excluded.created_at, updated_at = excluded.updated_at,
+				word_count = excluded.word_count, reading_minutes = excluded.reading_minutes,
+				deleted = 0, source_ts = excluded.source_ts
+		`, id, doc.Title, doc.Image, doc.Excerpt, doc.Body, doc.Author, doc.Category, doc.Language, domain.JoinTags(doc.Tags),
+			doc.CreatedAt, doc.UpdatedAt, words, readingMinutes(words), position)
+	case domain.ChangeOpDelete:
+		// Deleted posts stay as tombstones so an older upsert cannot bring them back
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO posts (id, title, image, excerpt, body, created_at, updated_at, word_count, reading_minutes, deleted, source_ts)
+			VALUES (?, '', '', '', '', 0, 0, 0, 0, 1, ?)
+			ON CONFLICT (id) DO UPDATE SET deleted = 1, source_ts = excluded.source_ts
+		`, id, position)
+	default:
+		return fmt.Errorf("unknown change operation: %s", change.Op)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply change to read model: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sync_state SET position = MAX(position, ?), applied = applied + 1, updated_at = ? WHERE id = 1
+	`, position, m.now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to update sync state: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit read model transaction: %w", err)
+	}
+	return nil
+}
+
+// Resync replaces every post with a snapshot of the posts table taken at
+// the source position. Changes older than the snapshot are skipped afterwards.
+func (m *SQLiteReadModel) Resync(ctx context.Context, posts []*domain.Post, position int64) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin read model transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM posts`); err != nil {
+		return fmt.Errorf("failed to clear read model: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO posts (id, title, image, excerpt, body, author, category, language, tags, created_at, updated_at, word_count, reading_minutes, deleted, source_ts)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare read model insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, post := range posts {
+		words := wordCount(post.Body)
+		_, err := stmt.ExecContext(ctx, post.ID, post.Title, post.Image, post.Excerpt, post.Body,
+			post.Author, post.Category, post.Language, domain.JoinTags(post.Tags), post.CreatedAt.Unix(), post.UpdatedAt.Unix(), words, readingMinutes(words), position)
+		if err != nil {
+			return fmt.Errorf("failed to insert post %d into read model: %w", post.ID, err)
+		}
+	}
+
+	now := m.now().Unix()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sync_state SET position = ?, snapshot_position = ?, resynced_at = ?, updated_at = ? WHERE id = 1
+	`, position, position, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to update sync state: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit read model resync: %w", err)
+	}
+	return nil
+}
+
+// Status returns the source position and size of the read model
+func (m *SQLiteReadModel) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	var resyncedAt sql.NullInt64
+	var updatedAt int64
+	err := m.db.QueryRowContext(ctx, `
+		SELECT position, snapshot_position, applied, resynced_at, updated_at,
+			(SELECT COUNT(*) FROM posts WHERE deleted = 0)
+		FROM sync_state WHERE id = 1
+	`).Scan(&status.Position, &status.SnapshotPosition, &status.Applied, &resyncedAt, &updatedAt, &status.Posts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+
+	status.UpdatedAt = time.Unix(updatedAt, 0)
+	if resyncedAt.Valid {
+		t := time.Unix(resyncedAt.Int64, 0)
+		status.ResyncedAt = &t
+	}
+	return &status, nil
+}
+
+func wordCount(body string) int {
+	return len(strings.Fields(body))
+}
+
+// readingMinutes rounds up, so every post with words takes at least a minute
+func readingMinutes(words int) int {
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
@@ -28,13 +28,13 @@ func NewPostgreSQLPostRepository(db DBExecutor) *PostgreSQLPostRepository {
 // Create inserts a new post into the database
 func (r *PostgreSQLPostRepository) Create(ctx context.Context, post *domain.Post) error {
 	query := `
-		INSERT INTO posts (title, image, excerpt, body, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO posts (title, image, excerpt, body, author, category, language, tags, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id
 	`
 
 	var id int
-	err := r.db.QueryRowContext(ctx, query, post.Title, post.Image, post.Excerpt, post.Body, post.CreatedAt, post.UpdatedAt).Scan(&id)
+	err := r.db.QueryRowContext(ctx, query, post.Title, post.Image, post.Excerpt, post.Body, post.Author, post.Category, post.Language, domain.JoinTags(post.Tags), post.CreatedAt, post.UpdatedAt).Scan(&id)
 	if err != nil {
 		return fmt.Errorf("failed to create post: %w", err)
 	}
@@ -46,13 +46,14 @@ func (r *PostgreSQLPostRepository) Create(ctx context.Context, post *domain.Post
 // GetByID retrieves a post by its ID
 func (r *PostgreSQLPostRepository) GetByID(ctx context.Context, id int) (*domain.Post, error) {
 	query := `
-		SELECT id, title, image, excerpt, body, created_at, updated_at
+		SELECT id, title, image, excerpt, body, author, category, language, tags, created_at, updated_at
 		FROM posts WHERE id = $1
 	`
 
 	var post domain.Post
+	var tags string
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&post.ID, &post.Title, &post.Image, &post.Excerpt, &post.Body, &post.CreatedAt, &post.UpdatedAt,
+		&post.ID, &post.Title, &post.Image, &post.Excerpt, &post.Body, &post.Author, &post.Category, &post.Language, &tags, &post.CreatedAt, &post.UpdatedAt,
 	)
 
 	if err != nil {
@@ -62,13 +63,14 @@ func (r *PostgreSQLPostRepository) GetByID(ctx context.Context, id int) (*domain
 		return nil, fmt.Errorf("failed to get post: %w", err)
 	}
 
+	post.Tags = domain.SplitTags(tags)
 	return &post, nil
 }
 
 // GetAll retrieves all posts from the database
 func (r *PostgreSQLPostRepository) GetAll(ctx context.Context) ([]*domain.Post, error) {
 	query := `
-		SELECT id, title, image, excerpt, body, created_at, updated_at
+		SELECT id, title, image, excerpt, body, author, category, language, tags, created_at, updated_at
 		FROM posts ORDER BY created_at DESC
 	`
 
@@ -81,10 +83,12 @@ func (r *PostgreSQLPostRepository) GetAll(ctx context.Context) ([]*domain.Post,
 	var posts []*domain.Post
 	for rows.Next() {
 		var post domain.Post
-		err := rows.Scan(&post.ID, &post.Title, &post.Image, &post.Excerpt, &post.Body, &post.CreatedAt, &post.UpdatedAt)
+		var tags string
+		err := rows.Scan(&post.ID, &post.Title, &post.Image, &post.Excerpt, &post.Body, &post.Author, &post.Category, &post.Language, &tags, &post.CreatedAt, &post.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
 		}
+		post.Tags = domain.SplitTags(tags)
 		posts = append(posts, &post)
 	}
 
@@ -99,11 +103,11 @@ func (r *PostgreSQLPostRepository) GetAll(ctx context.Context) ([]*domain.Post,
 func (r *PostgreSQLPostRepository) Update(ctx context.Context, post *domain.Post) error {
 	query := `
 		UPDATE posts 
-		SET title = $1, image = $2, excerpt = $3, body = $4, updated_at = $5
-		WHERE id = $6
+		SET title = $1, image = $2, excerpt = $3, body = $4, author = $5, category = $6, language = $7, tags = $8, updated_at = $9
+		WHERE id = $10
 	`
 
-	result, err := r.db.ExecContext(ctx, query, post.Title, post.Image, post.Excerpt, post.Body, post.UpdatedAt, post.ID)
+	result, err := r.db.ExecContext(ctx, query, post.Title, post.Image, post.Excerpt, post.Body, post.Author, post.Category, post.Language, domain.JoinTags(post.Tags), post.UpdatedAt, post.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update post: %w", err)
 	}
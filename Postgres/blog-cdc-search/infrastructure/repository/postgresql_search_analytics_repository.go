@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// PostgreSQLSearchAnalyticsRepository implements SearchAnalyticsRepository using PostgreSQL
+type PostgreSQLSearchAnalyticsRepository struct {
+	db DBExecutor
+}
+
+// NewPostgreSQLSearchAnalyticsRepository creates a new PostgreSQLSearchAnalyticsRepository instance
+func NewPostgreSQLSearchAnalyticsRepository(db DBExecutor) *PostgreSQLSearchAnalyticsRepository {
+	return &PostgreSQLSearchAnalyticsRepository{db: db}
+}
+
+// RecordSearch inserts a search
+func (r *PostgreSQLSearchAnalyticsRepository) RecordSearch(ctx context.Context, search *domain.SearchEvent) error {
+	query := `
+		INSERT INTO search_queries (query, results, latency_ms, page, session_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query, search.Query, search.Results, search.LatencyMS, search.Page, search.Session, search.CreatedAt).Scan(&search.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record search: %w", err)
+	}
+	return nil
+}
+
+// RecordClick inserts a result click
+func (r *PostgreSQLSearchAnalyticsRepository) RecordClick(ctx context.Context, click *domain.SearchClick) error {
+	query := `
+		INSERT INTO search_clicks (search_id, post_id, position, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query, click.SearchID, click.PostID, click.Position, click.CreatedAt).Scan(&click.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record search click: %w", err)
+	}
+	return nil
+}
+
+// Summary counts the searches since a point in time
+func (r *PostgreSQLSearchAnalyticsRepository) Summary(ctx context.Context, since time.Time) (*domain.SearchAnalyticsSummary, error) {
+	query := `
+		SELECT COUNT(*), COUNT(DISTINCT s.session_id), COUNT(DISTINCT s.query),
+			COUNT(*) FILTER (WHERE s.results = 0), COUNT(c.search_id)
+		FROM search_queries s
+		LEFT JOIN (SELECT DISTINCT search_id FROM search_clicks) c ON c.search_id = s.id
+		WHERE s.created_at >= $1
+	`
+
+	var summary domain.SearchAnalyticsSummary
+	err := r.db.QueryRowContext(ctx, query, since).Scan(&summary.Searches, &summary.Sessions, &summary.Queries, &summary.ZeroResults, &summary.Clicked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize searches: %w", err)
+	}
+	return &summary, nil
+}
+
+// TopQueries returns the most searched queries since a point in time
+func (r *PostgreSQLSearchAnalyticsRepository) TopQueries(ctx context.Context, since time.Time, limit int) ([]*domain.SearchQueryStats, error) {
+	query := `
+		SELECT s.query, COUNT(*), COUNT(DISTINCT s.session_id), AVG(s.results), AVG(s.latency_ms),
+			COUNT(c.search_id), MAX(s.created_at)
+		FROM search_queries s
+		LEFT JOIN (SELECT DISTINCT search_id FROM search_clicks) c ON c.search_id = s.id
+		WHERE s.created_at >= $1
+		GROUP BY s.query
+		ORDER BY COUNT(*) DESC, s.query
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top searches: %w", err)
+	}
+	return scanSearchQueryStats(rows)
+}
+
+// ZeroResultQueries returns the queries most often searched without results
+// since a point in time
+func (r *PostgreSQLSearchAnalyticsRepository) ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]*domain.SearchQueryStats, error) {
+	query := `
+		SELECT query, COUNT(*), COUNT(DISTINCT session_id), 0, AVG(latency_ms), 0, MAX(created_at)
+		FROM search_queries
+		WHERE created_at >= $1 AND results = 0
+		GROUP BY query
+		ORDER BY COUNT(*) DESC, query
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query zero-result searches: %w", err)
+	}
+	return scanSearchQueryStats(rows)
+}
+
+// scanSearchQueryStats reads rows of query, searches, sessions, average
+// results, average latency, clicked searches and the last search
+func scanSearchQueryStats(rows *sql.Rows) ([]*domain.SearchQueryStats, error) {
+	defer rows.Close()
+
+	stats := []*domain.SearchQueryStats{}
+	for rows.Next() {
+		var stat domain.SearchQueryStats
+		if err := rows.Scan(&stat.Query, &stat.Searches, &stat.Sessions, &stat.AvgResults, &stat.AvgLatencyMS, &stat.Clicked, &stat.LastSearched); err != nil {
+			return nil, fmt.Errorf("failed to scan search stats: %w", err)
+		}
+		stat.SetCTR()
+		stats = append(stats, &stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return stats, nil
+}
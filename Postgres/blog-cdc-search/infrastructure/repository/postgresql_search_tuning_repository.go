@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"blog-cdc-search/domain"
+)
+
+// PostgreSQLSearchTuningRepository implements SearchTuningRepository using PostgreSQL
+type PostgreSQLSearchTuningRepository struct {
+	db DBExecutor
+}
+
+// NewPostgreSQLSearchTuningRepository creates a new PostgreSQLSearchTuningRepository instance
+func NewPostgreSQLSearchTuningRepository(db DBExecutor) *PostgreSQLSearchTuningRepository {
+	return &PostgreSQLSearchTuningRepository{db: db}
+}
+
+// CreateSynonym inserts a new synonym
+func (r *PostgreSQLSearchTuningRepository) CreateSynonym(ctx context.Context, synonym *domain.Synonym) error {
+	query := `
+		INSERT INTO search_synonyms (root, synonyms, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query, synonym.Root, strings.Join(synonym.Synonyms, ","), synonym.CreatedAt).Scan(&synonym.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create synonym: %w", err)
+	}
+	return nil
+}
+
+// ListSynonyms retrieves all synonyms
+func (r *PostgreSQLSearchTuningRepository) ListSynonyms(ctx context.Context) ([]*domain.Synonym, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, root, synonyms, created_at FROM search_synonyms ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query synonyms: %w", err)
+	}
+	defer rows.Close()
+
+	var synonyms []*domain.Synonym
+	for rows.Next() {
+		var synonym domain.Synonym
+		var words string
+		if err := rows.Scan(&synonym.ID, &synonym.Root, &words, &synonym.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan synonym: %w", err)
+		}
+		synonym.Synonyms = domain.SplitTags(words)
+		synonyms = append(synonyms, &synonym)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return synonyms, nil
+}
+
+// DeleteSynonym removes a synonym
+func (r *PostgreSQLSearchTuningRepository) DeleteSynonym(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM search_synonyms WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete synonym: %w", err)
+	}
+	return requireSearchTuningRow(result)
+}
+
+// CreateCuration inserts a new curation
+func (r *PostgreSQLSearchTuningRepository) CreateCuration(ctx context.Context, curation *domain.Curation) error {
+	query := `
+		INSERT INTO search_curations (query, match_type, pinned, hidden, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query, curation.Query, curation.Match, domain.JoinPostIDs(curation.Pinned), domain.JoinPostIDs(curation.Hidden), curation.CreatedAt).Scan(&curation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create curation: %w", err)
+	}
+	return nil
+}
+
+// ListCurations retrieves all curations
+func (r *PostgreSQLSearchTuningRepository) ListCurations(ctx context.Context) ([]*domain.Curation, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, query, match_type, pinned, hidden, created_at FROM search_curations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query curations: %w", err)
+	}
+	defer rows.Close()
+
+	var curations []*domain.Curation
+	for rows.Next() {
+		var curation domain.Curation
+		var pinned, hidden string
+		if err := rows.Scan(&curation.ID, &curation.Query, &curation.Match, &pinned, &hidden, &curation.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan curation: %w", err)
+		}
+		curation.Pinned = domain.SplitPostIDs(pinned)
+		curation.Hidden = domain.SplitPostIDs(hidden)
+		curations = append(curations, &curation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return curations, nil
+}
+
+// DeleteCuration removes a curation
+func (r *PostgreSQLSearchTuningRepository) DeleteCuration(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM search_curations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete curation: %w", err)
+	}
+	return requireSearchTuningRow(result)
+}
+
+// requireSearchTuningRow turns a delete that matched nothing into ErrSearchTuningNotFound
+func requireSearchTuningRow(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrSearchTuningNotFound
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// PostgreSQLWebhookRepository implements WebhookRepository using PostgreSQL
+type PostgreSQLWebhookRepository struct {
+	db DBExecutor
+}
+
+// NewPostgreSQLWebhookRepository creates a new PostgreSQLWebhookRepository instance
+func NewPostgreSQLWebhookRepository(db DBExecutor) *PostgreSQLWebhookRepository {
+	return &PostgreSQLWebhookRepository{db: db}
+}
+
+// CreateEndpoint inserts a new webhook endpoint
+func (r *PostgreSQLWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (url, secret, events, active, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query, endpoint.URL, endpoint.Secret, endpoint.EventsString(), endpoint.Active, endpoint.CreatedAt).Scan(&endpoint.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// GetEndpoint retrieves a webhook endpoint by its ID
+func (r *PostgreSQLWebhookRepository) GetEndpoint(ctx context.Context, id int) (*domain.WebhookEndpoint, error) {
+	query := `
+		SELECT id, url, secret, events, active, created_at
+		FROM webhook_endpoints WHERE id = $1
+	`
+
+	endpoint, err := scanWebhookEndpoint(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return endpoint, nil
+}
+
+// ListEndpoints retrieves all webhook endpoints
+func (r *PostgreSQLWebhookRepository) ListEndpoints(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	query := `
+		SELECT id, url, secret, events, active, created_at
+		FROM webhook_endpoints ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []*domain.WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return endpoints, nil
+}
+
+// DeleteEndpoint removes a webhook endpoint and its deliveries
+func (r *PostgreSQLWebhookRepository) DeleteEndpoint(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	return requireRowsAffected(result)
+}
+
+// CreateDelivery inserts a new delivery log entry, or loads the endpoint's
+// existing delivery for the same change
+func (r *PostgreSQLWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) (bool, error) {
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event, post_id, change_key, payload, status, attempts, response_status, error, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (endpoint_id, event, post_id, change_key) DO NOTHING
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		delivery.EndpointID, delivery.Event, delivery.PostID, nullableString(delivery.ChangeKey), delivery.Payload, delivery.Status,
+		delivery.Attempts, delivery.ResponseStatus, delivery.Error, delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt,
+	).Scan(&delivery.ID)
+	if err == nil {
+		return true, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	existing, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries WHERE endpoint_id = $1 AND event = $2 AND post_id = $3 AND change_key = $4
+	`, delivery.EndpointID, delivery.Event, delivery.PostID, delivery.ChangeKey))
+	if err != nil {
+		return false, fmt.Errorf("failed to get existing webhook delivery: %w", err)
+	}
+	*delivery = *existing
+	return false, nil
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt
+func (r *PostgreSQLWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, response_status = $3, error = $4, next_attempt_at = $5, updated_at = $6
+		WHERE id = $7
+	`
+
+	result, err := r.db.ExecContext(ctx, query, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.Error, delivery.NextAttemptAt, delivery.UpdatedAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return requireRowsAffected(result)
+}
+
+// GetDelivery retrieves a delivery by its ID
+func (r *PostgreSQLWebhookRepository) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries WHERE id = $1
+	`
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// ListDeliveries retrieves the most recent deliveries, newest first
+func (r *PostgreSQLWebhookRepository) ListDeliveries(ctx context.Context, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries ORDER BY id DESC LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+// ClaimDueDeliveries leases due pending deliveries in a single statement;
+// SKIP LOCKED keeps concurrent claims from waiting on each other
+func (r *PostgreSQLWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $2 AND next_attempt_at <= $3
+			ORDER BY next_attempt_at, id LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now.Add(lease), domain.WebhookDeliveryPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING gives no order; retries go out oldest first
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+// scanWebhookDeliveries reads and closes rows of deliveries
+func scanWebhookDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return deliveries, nil
+}
+
+// webhookDeliveryColumns are the columns read by scanWebhookDelivery
+const webhookDeliveryColumns = `id, endpoint_id, event, post_id, change_key, payload, status, attempts, response_status, error, next_attempt_at, created_at, updated_at`
+
+// nullableString stores an empty string as NULL
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhookEndpoint(row rowScanner) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	var events string
+	if err := row.Scan(&endpoint.ID, &endpoint.URL, &endpoint.Secret, &events, &endpoint.Active, &endpoint.CreatedAt); err != nil {
+		return nil, err
+	}
+	endpoint.Events = domain.ParseWebhookEvents(events)
+	return &endpoint, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var changeKey sql.NullString
+	err := row.Scan(
+		&delivery.ID, &delivery.EndpointID, &delivery.Event, &delivery.PostID, &changeKey, &delivery.Payload, &delivery.Status,
+		&delivery.Attempts, &delivery.ResponseStatus, &delivery.Error, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.ChangeKey = changeKey.String
+	return &delivery, nil
+}
+
+// requireRowsAffected turns an update or delete that matched nothing into ErrWebhookNotFound
+func requireRowsAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
@@ -0,0 +1,933 @@
+package searchindex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-cdc-search/domain"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/index/scorch"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// BleveRepository implements the SearchIndexRepository interface on an
+// embedded Bleve index stored on local disk, one index per collection
+type BleveRepository struct {
+	config BleveConfig
+
+	mu          sync.RWMutex
+	collections map[string]*bleveCollection
+}
+
+// BleveConfig holds the configuration for the embedded Bleve index
+type BleveConfig struct {
+	// Path is the directory holding one index directory per collection.
+	Path string
+
+	// DefaultAnalyzer analyzes text fields without an entry in Analyzers.
+	// Built in are standard, simple, keyword and en.
+	DefaultAnalyzer string
+	// Analyzers maps field names to analyzer names, e.g. {"body": "en"}.
+	Analyzers map[string]string
+
+	// OpenTimeout is how long to wait for another process holding the
+	// index to release it. Only one process can open an index at a time.
+	OpenTimeout time.Duration
+
+	// SnapshotDir receives a timestamped snapshot every SnapshotInterval
+	// while RunSnapshots runs. Zero interval disables snapshots.
+	SnapshotDir      string
+	SnapshotInterval time.Duration
+	// SnapshotKeep is the number of snapshots kept; zero keeps all of them.
+	SnapshotKeep int
+}
+
+// bleveCollection is an open index together with the schema it was built from
+type bleveCollection struct {
+	index  bleve.Index
+	schema map[string]interface{}
+}
+
+// defaultBleveOpenTimeout is used when the config leaves OpenTimeout unset
+const defaultBleveOpenTimeout = time.Second
+
+// blevePageSize is the number of documents fetched per export page
+const blevePageSize = 1000
+
+// rebuildSuffix marks the directory a collection is rebuilt into
+const rebuildSuffix = ".rebuild"
+
+// bleveSourceField is the stored field holding the original document JSON
+const bleveSourceField = "_source"
+
+// bleveSchemaKey is the internal key the collection schema is stored under
+var bleveSchemaKey = []byte("_schema")
+
+// NewBleveRepository creates a new Bleve repository instance
+func NewBleveRepository(config BleveConfig) *BleveRepository {
+	if config.DefaultAnalyzer == "" {
+		config.DefaultAnalyzer = standard.Name
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaultBleveOpenTimeout
+	}
+
+	return &BleveRepository{
+		config: config,
+	}
+}
+
+// Connect opens the indexes already present under the configured path.
+// Calling Connect on an open repository is a no-op, so the blog and an
+// embedded CDC consumer can share one repository.
+func (r *BleveRepository) Connect(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collections != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(r.config.Path, 0o755); err != nil {
+		return fmt.Errorf("failed to create Bleve directory: %w", err)
+	}
+
+	if err := r.recoverRebuilds(); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(r.config.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read Bleve directory: %w", err)
+	}
+
+	collections := map[string]*bleveCollection{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		collection, err := r.open(entry.Name())
+		if err != nil {
+			closeCollections(collections)
+			return err
+		}
+		collections[entry.Name()] = collection
+	}
+
+	r.collections = collections
+	log.Printf("Opened Bleve index at %s", r.config.Path)
+	return nil
+}
+
+// recoverRebuilds cleans up after a rebuild that was interrupted. A rebuilt
+// index is kept only if the swap had already removed the original.
+func (r *BleveRepository) recoverRebuilds() error {
+	matches, err := filepath.Glob(filepath.Join(r.config.Path, "*"+rebuildSuffix))
+	if err != nil {
+		return err
+	}
+
+	for _, tmpPath := range matches {
+		path := strings.TrimSuffix(tmpPath, rebuildSuffix)
+		if _, err := os.Stat(path); err == nil {
+			err = os.RemoveAll(tmpPath)
+		} else {
+			err = os.Rename(tmpPath, path)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to recover Bleve rebuild %s: %w", tmpPath, err)
+		}
+	}
+	return nil
+}
+
+// Close closes all open indexes
+func (r *BleveRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := closeCollections(r.collections)
+	r.collections = nil
+	return err
+}
+
+// closeCollections closes every index, returning the first error
+func closeCollections(collections map[string]*bleveCollection) error {
+	var firstErr error
+	for _, collection := range collections {
+		if err := collection.index.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// open opens an existing index and loads the schema stored with it
+func (r *BleveRepository) open(name string) (*bleveCollection, error) {
+	index, err := bleve.OpenUsing(r.indexPath(name), map[string]interface{}{
+		"bolt_timeout": r.config.OpenTimeout.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Bleve index %s: %w", name, err)
+	}
+
+	collection := &bleveCollection{index: index}
+	data, err := index.GetInternal(bleveSchemaKey)
+	if err == nil && len(data) > 0 {
+		err = json.Unmarshal(data, &collection.schema)
+	}
+	if err != nil {
+		index.Close()
+		return nil, fmt.Errorf("failed to load schema of Bleve index %s: %w", name, err)
+	}
+
+	return collection, nil
+}
+
+// create builds a new index at path from the schema
+func (r *BleveRepository) create(path string, schema map[string]interface{}) (*bleveCollection, error) {
+	indexMapping, err := r.mapping(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := bleve.NewUsing(path, indexMapping, scorch.Name, scorch.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(schema)
+	if err == nil {
+		err = index.SetInternal(bleveSchemaKey, data)
+	}
+	if err != nil {
+		index.Close()
+		os.RemoveAll(path)
+		return nil, err
+	}
+
+	// Round-trip the schema so it compares equal to one loaded from disk
+	var stored map[string]interface{}
+	json.Unmarshal(data, &stored)
+
+	return &bleveCollection{index: index, schema: stored}, nil
+}
+
+// indexPath returns the directory of a collection's index
+func (r *BleveRepository) indexPath(name string) string {
+	return filepath.Join(r.config.Path, name)
+}
+
+// collection returns an open collection or an error if it does not exist
+func (r *BleveRepository) collection(name string) (*bleveCollection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.collections == nil {
+		return nil, fmt.Errorf("bleve index is not open")
+	}
+	collection, ok := r.collections[name]
+	if !ok {
+		return nil, fmt.Errorf("collection %s not found", name)
+	}
+	return collection, nil
+}
+
+// schemaFields returns the fields of a Typesense-style schema. The schema
+// may come straight from the caller or be decoded from JSON.
+func schemaFields(schema map[string]interface{}) []map[string]interface{} {
+	switch fields := schema["fields"].(type) {
+	case []map[string]interface{}:
+		return fields
+	case []interface{}:
+		var result []map[string]interface{}
+		for _, field := range fields {
+			if f, ok := field.(map[string]interface{}); ok {
+				result = append(result, f)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// mapping translates a Typesense-style schema into a Bleve index mapping.
+// Numeric fields are always indexed so they stay sortable and filterable.
+func (r *BleveRepository) mapping(schema map[string]interface{}) (*mapping.IndexMappingImpl, error) {
+	documentMapping := bleve.NewDocumentStaticMapping()
+
+	for _, field := range schemaFields(schema) {
+		name, _ := field["name"].(string)
+		fieldType, _ := field["type"].(string)
+		index, hasIndex := field["index"].(bool)
+		facet, _ := field["facet"].(bool)
+		indexed := !hasIndex || index
+		if name == "" || name == "id" {
+			continue
+		}
+
+		var fieldMapping *mapping.FieldMapping
+		switch fieldType {
+		case "string", "string[]":
+			fieldMapping = bleve.NewTextFieldMapping()
+			fieldMapping.Analyzer = r.config.DefaultAnalyzer
+			if facet {
+				// Facets and filters match whole values
+				fieldMapping.Analyzer = keyword.Name
+			}
+			if analyzer, ok := r.config.Analyzers[name]; ok {
+				fieldMapping.Analyzer = analyzer
+			}
+			fieldMapping.Index = indexed || facet
+			// Highlighting needs term vectors and the stored text
+			fieldMapping.IncludeTermVectors = indexed
+			fieldMapping.Store = indexed
+		case "int32", "int64", "float":
+			fieldMapping = bleve.NewNumericFieldMapping()
+			fieldMapping.Store = false
+		case "bool":
+			fieldMapping = bleve.NewBooleanFieldMapping()
+			fieldMapping.Store = false
+		default:
+			return nil, fmt.Errorf("unsupported field type %q for field %s", fieldType, name)
+		}
+		fieldMapping.IncludeInAll = false
+
+		documentMapping.AddFieldMappingsAt(name, fieldMapping)
+	}
+
+	sourceMapping := bleve.NewTextFieldMapping()
+	sourceMapping.Index = false
+	sourceMapping.IncludeInAll = false
+	sourceMapping.DocValues = false
+	documentMapping.AddFieldMappingsAt(bleveSourceField, sourceMapping)
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = documentMapping
+	indexMapping.DefaultAnalyzer = r.config.DefaultAnalyzer
+	if err := indexMapping.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Bleve mapping: %w", err)
+	}
+	return indexMapping, nil
+}
+
+// CreateCollection creates an index for the schema. An existing index built
+// from a different schema is rebuilt, since Bleve mappings are immutable.
+func (r *BleveRepository) CreateCollection(ctx context.Context, schema map[string]interface{}) error {
+	name, _ := schema["name"].(string)
+	if name == "" {
+		return fmt.Errorf("failed to create collection: schema has no name")
+	}
+
+	// Normalize the schema so it can be compared with the stored one
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collections == nil {
+		return fmt.Errorf("failed to create collection: bleve index is not open")
+	}
+
+	existing, ok := r.collections[name]
+	if ok && reflect.DeepEqual(existing.schema, normalized) {
+		log.Printf("Collection %s already exists", name)
+		return nil
+	}
+
+	if !ok {
+		collection, err := r.create(r.indexPath(name), normalized)
+		if err != nil {
+			return fmt.Errorf("failed to create collection: %w", err)
+		}
+		r.collections[name] = collection
+		log.Printf("Created collection: %s", name)
+		return nil
+	}
+
+	collection, err := r.rebuild(ctx, name, existing, normalized)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild collection: %w", err)
+	}
+	r.collections[name] = collection
+	log.Printf("Rebuilt collection %s for the new schema", name)
+	return nil
+}
+
+// rebuild copies all documents of a collection into a new index built from
+// schema and swaps it in place of the old one
+func (r *BleveRepository) rebuild(ctx context.Context, name string, existing *bleveCollection, schema map[string]interface{}) (*bleveCollection, error) {
+	documents, err := exportDocuments(ctx, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	path := r.indexPath(name)
+	tmpPath := path + rebuildSuffix
+	os.RemoveAll(tmpPath)
+
+	collection, err := r.create(tmpPath, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := collection.index.NewBatch()
+	for _, document := range documents {
+		id, fields, err := bleveFields(document)
+		if err == nil {
+			err = batch.Index(id, fields)
+		}
+		if err != nil {
+			collection.index.Close()
+			os.RemoveAll(tmpPath)
+			return nil, err
+		}
+	}
+	if err := collection.index.Batch(batch); err != nil {
+		collection.index.Close()
+		os.RemoveAll(tmpPath)
+		return nil, err
+	}
+
+	// Swap the directories; the indexes must be closed while they move
+	collection.index.Close()
+	existing.index.Close()
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return nil, err
+	}
+	return r.open(name)
+}
+
+// UpsertDocument indexes a document, replacing one with the same id
+func (r *BleveRepository) UpsertDocument(ctx context.Context, collectionName string, document interface{}) error {
+	collection, err := r.collection(collectionName)
+	if err != nil {
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+
+	id, fields, err := bleveFields(document)
+	if err != nil {
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+
+	if err := collection.index.Index(id, fields); err != nil {
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+
+	return nil
+}
+
+// bleveFields turns a document into the map that is indexed. The JSON
+// encoding is kept in the stored source field so documents are returned
+// exactly as they were written, including fields the mapping leaves out.
+func bleveFields(document interface{}) (string, map[string]interface{}, error) {
+	id, err := documentID(document)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		return "", nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", nil, err
+	}
+	delete(fields, "id")
+	fields[bleveSourceField] = string(data)
+
+	return id, fields, nil
+}
+
+// DeleteDocument deletes a document. Deleting a missing document succeeds.
+func (r *BleveRepository) DeleteDocument(ctx context.Context, collectionName string, documentID string) error {
+	collection, err := r.collection(collectionName)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	if err := collection.index.Delete(documentID); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	return nil
+}
+
+// SyncSynonyms accepts and ignores the synonyms: Bleve reads them from
+// synonym sources in the index mapping, which cannot change in place
+func (r *BleveRepository) SyncSynonyms(ctx context.Context, collectionName string, synonyms []*domain.Synonym) error {
+	return nil
+}
+
+// SyncCurations accepts and ignores the curations, which Bleve does not support
+func (r *BleveRepository) SyncCurations(ctx context.Context, collectionName string, curations []*domain.Curation) error {
+	return nil
+}
+
+// SearchDocuments searches for documents in a collection. The Typesense-style
+// parameters used by the search service are translated to a Bleve request.
+func (r *BleveRepository) SearchDocuments(ctx context.Context, collectionName, queryText string, searchParams map[string]interface{}) (*domain.SearchResults, error) {
+	collection, err := r.collection(collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	fields := highlightFields
+	if queryBy, ok := searchParams["query_by"].(string); ok && queryBy != "" {
+		fields = strings.Split(queryBy, ",")
+	}
+
+	typos, _ := searchTypos(searchParams)
+	searchQuery := bleveQuery(queryText, fields, searchPrefix(searchParams), typos)
+	filters, err := bleveSearchFilters(searchFilters(searchParams))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	if filterBy, ok := searchParams["filter_by"].(string); ok && filterBy != "" {
+		parsed, err := bleveFilters(filterBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search documents: %w", err)
+		}
+		filters = append(parsed, filters...)
+	}
+	if len(filters) > 0 {
+		searchQuery = bleve.NewConjunctionQuery(append([]query.Query{searchQuery}, filters...)...)
+	}
+
+	// Add pagination parameters
+	page, perPage := searchPage(searchParams)
+
+	request := bleve.NewSearchRequestOptions(searchQuery, perPage, (page-1)*perPage, false)
+	request.Fields = []string{bleveSourceField}
+	request.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	request.Highlight.Fields = fields
+
+	sortBy, _ := searchParams["sort_by"].(string)
+	request.SortBy(bleveSort(sortBy))
+
+	facets := facetFields(searchParams)
+	for _, field := range facets {
+		request.AddFacet(field, bleve.NewFacetRequest(field, facetValueLimit))
+	}
+
+	result, err := collection.index.SearchInContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	results := &domain.SearchResults{
+		Hits:         []*domain.SearchHit{},
+		Found:        int(result.Total),
+		Page:         page,
+		PerPage:      perPage,
+		SearchTimeMS: int(result.Took.Milliseconds()),
+	}
+	if count, err := collection.index.DocCount(); err == nil {
+		results.OutOf = int(count)
+	}
+	for _, hit := range result.Hits {
+		searchHit := &domain.SearchHit{Document: bleveDocument(hit.ID, hit.Fields), Score: hit.Score}
+
+		// Only fields with a match are reported as highlights
+		highlights := map[string][]string{}
+		for field, fragments := range hit.Fragments {
+			var values []string
+			for _, fragment := range fragments {
+				if strings.Contains(fragment, "<mark>") {
+					values = append(values, fragment)
+				}
+			}
+			if len(values) > 0 {
+				highlights[field] = values
+			}
+		}
+		if len(highlights) > 0 {
+			searchHit.Highlights = highlights
+		}
+
+		results.Hits = append(results.Hits, searchHit)
+	}
+
+	for _, field := range facets {
+		if facet, ok := result.Facets[field]; ok {
+			results.Facets = append(results.Facets, bleveFacet(field, facet, schemaFieldType(collection.schema, field)))
+		}
+	}
+
+	return results, nil
+}
+
+// bleveFacet converts term facet counts. Booleans are indexed as the terms
+// T and F and reported as true and false, as in Typesense.
+func bleveFacet(field string, facet *search.FacetResult, fieldType string) domain.FacetCounts {
+	counts := domain.FacetCounts{Field: field, Counts: []domain.FacetValueCount{}}
+	for _, term := range facet.Terms.Terms() {
+		value := term.Term
+		if fieldType == "bool" {
+			value = strconv.FormatBool(value == "T")
+		}
+		counts.Counts = append(counts.Counts, domain.FacetValueCount{Value: value, Count: term.Count})
+	}
+	return counts
+}
+
+// schemaFieldType returns the type of a schema field, or "" when it is not defined
+func schemaFieldType(schema map[string]interface{}, name string) string {
+	for _, field := range schemaFields(schema) {
+		if field["name"] == name {
+			fieldType, _ := field["type"].(string)
+			return fieldType
+		}
+	}
+	return ""
+}
+
+// bleveQuery matches the query text against each field. An empty query or
+// "*" matches every document, as in Typesense. With prefix set the last word
+// matches any term it starts, and typos sets the fuzziness of whole words.
+func bleveQuery(text string, fields []string, prefix bool, typos int) query.Query {
+	text = strings.TrimSpace(text)
+	if text == "" || text == "*" {
+		return bleve.NewMatchAllQuery()
+	}
+
+	words := strings.Fields(text)
+	var queries []query.Query
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if !prefix {
+			match := bleve.NewMatchQuery(text)
+			match.SetField(field)
+			match.SetFuzziness(typos)
+			queries = append(queries, match)
+			continue
+		}
+
+		// Prefix queries are not analyzed, so the last word is lowercased
+		// to match the indexed terms
+		last := bleve.NewPrefixQuery(strings.ToLower(words[len(words)-1]))
+		last.SetField(field)
+		if len(words) == 1 {
+			queries = append(queries, last)
+			continue
+		}
+		match := bleve.NewMatchQuery(strings.Join(words[:len(words)-1], " "))
+		match.SetField(field)
+		match.SetFuzziness(typos)
+		match.SetOperator(query.MatchQueryOperatorAnd)
+		queries = append(queries, bleve.NewConjunctionQuery(match, last))
+	}
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
+// bleveSort converts a Typesense sort_by into Bleve sort fields.
+// _text_match maps to the score and the id breaks ties for stable paging.
+func bleveSort(sortBy string) []string {
+	var sort []string
+	for _, clause := range strings.Split(sortBy, ",") {
+		field, order, _ := strings.Cut(strings.TrimSpace(clause), ":")
+		if field == "" {
+			continue
+		}
+		if field == "_text_match" {
+			field = "_score"
+		}
+		if strings.EqualFold(order, "desc") {
+			field = "-" + field
+		}
+		sort = append(sort, field)
+	}
+	if len(sort) == 0 {
+		sort = append(sort, "-_score")
+	}
+	return append(sort, "_id")
+}
+
+// bleveFilters converts a Typesense filter_by made of clauses joined with &&
+// into Bleve queries. Supported forms are field:value, field:=value,
+// field:[a,b] and numeric comparisons such as field:>=10.
+func bleveFilters(filterBy string) ([]query.Query, error) {
+	var filters []query.Query
+	for _, clause := range strings.Split(filterBy, "&&") {
+		field, expr, ok := strings.Cut(strings.TrimSpace(clause), ":")
+		field, expr = strings.TrimSpace(field), strings.TrimSpace(expr)
+		if !ok || field == "" || expr == "" {
+			return nil, fmt.Errorf("unsupported filter clause: %q", clause)
+		}
+
+		var op string
+		for _, prefix := range []string{">=", "<=", ">", "<"} {
+			if strings.HasPrefix(expr, prefix) {
+				op = prefix
+				break
+			}
+		}
+
+		switch {
+		case field == "id":
+			// The id is the document ID rather than an indexed field
+			ids := strings.Split(strings.Trim(strings.TrimPrefix(expr, "="), "[]"), ",")
+			for i := range ids {
+				ids[i] = strings.Trim(strings.TrimSpace(ids[i]), "`")
+			}
+			filters = append(filters, bleve.NewDocIDQuery(ids))
+		case op != "":
+			value, err := strconv.ParseFloat(strings.TrimSpace(expr[len(op):]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("unsupported filter clause: %q", clause)
+			}
+			inclusive := len(op) == 2
+			var rangeQuery *query.NumericRangeQuery
+			if op[0] == '>' {
+				rangeQuery = bleve.NewNumericRangeInclusiveQuery(&value, nil, &inclusive, nil)
+			} else {
+				rangeQuery = bleve.NewNumericRangeInclusiveQuery(nil, &value, nil, &inclusive)
+			}
+			rangeQuery.SetField(field)
+			filters = append(filters, rangeQuery)
+		case strings.HasPrefix(expr, "[") && strings.HasSuffix(expr, "]"):
+			var values []query.Query
+			for _, value := range strings.Split(expr[1:len(expr)-1], ",") {
+				values = append(values, bleveValueQuery(field, value))
+			}
+			filters = append(filters, bleve.NewDisjunctionQuery(values...))
+		default:
+			filters = append(filters, bleveValueQuery(field, strings.TrimPrefix(expr, "=")))
+		}
+	}
+	return filters, nil
+}
+
+// bleveSearchFilters converts typed search filters into Bleve queries
+func bleveSearchFilters(filters []domain.SearchFilter) ([]query.Query, error) {
+	var queries []query.Query
+	for _, filter := range filters {
+		switch value := filter.Value.(type) {
+		case string:
+			if filter.Op != domain.FilterEqual {
+				return nil, fmt.Errorf("unsupported filter comparison for %s: %q", filter.Field, filter.Op)
+			}
+			term := bleve.NewTermQuery(value)
+			term.SetField(filter.Field)
+			queries = append(queries, term)
+		case bool:
+			if filter.Op != domain.FilterEqual {
+				return nil, fmt.Errorf("unsupported filter comparison for %s: %q", filter.Field, filter.Op)
+			}
+			boolQuery := bleve.NewBoolFieldQuery(value)
+			boolQuery.SetField(filter.Field)
+			queries = append(queries, boolQuery)
+		case int64:
+			number, inclusive := float64(value), true
+			var rangeQuery *query.NumericRangeQuery
+			switch filter.Op {
+			case domain.FilterEqual:
+				rangeQuery = bleve.NewNumericRangeInclusiveQuery(&number, &number, &inclusive, &inclusive)
+			case domain.FilterAtLeast:
+				rangeQuery = bleve.NewNumericRangeInclusiveQuery(&number, nil, &inclusive, nil)
+			case domain.FilterBelow:
+				exclusive := false
+				rangeQuery = bleve.NewNumericRangeInclusiveQuery(nil, &number, nil, &exclusive)
+			default:
+				return nil, fmt.Errorf("unsupported filter comparison for %s: %q", filter.Field, filter.Op)
+			}
+			rangeQuery.SetField(filter.Field)
+			queries = append(queries, rangeQuery)
+		default:
+			return nil, fmt.Errorf("unsupported filter value for %s: %T", filter.Field, filter.Value)
+		}
+	}
+	return queries, nil
+}
+
+// bleveValueQuery matches a single filter value, as a boolean or number when
+// it parses as one and as an exact term otherwise. Values quoted with
+// backticks are always terms.
+func bleveValueQuery(field, value string) query.Query {
+	value = strings.TrimSpace(value)
+	quoted := strings.HasPrefix(value, "`")
+	value = strings.Trim(value, "`")
+	if !quoted && (value == "true" || value == "false") {
+		boolQuery := bleve.NewBoolFieldQuery(value == "true")
+		boolQuery.SetField(field)
+		return boolQuery
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil && !quoted {
+		inclusive := true
+		rangeQuery := bleve.NewNumericRangeInclusiveQuery(&number, &number, &inclusive, &inclusive)
+		rangeQuery.SetField(field)
+		return rangeQuery
+	}
+	term := bleve.NewTermQuery(value)
+	term.SetField(field)
+	return term
+}
+
+// bleveDocument decodes a document from the stored source of a hit
+func bleveDocument(id string, stored map[string]interface{}) map[string]interface{} {
+	document := map[string]interface{}{}
+	if source, ok := stored[bleveSourceField].(string); ok {
+		json.Unmarshal([]byte(source), &document)
+	}
+	document["id"] = id
+	return document
+}
+
+// GetAllDocuments retrieves all documents from a collection page by page
+func (r *BleveRepository) GetAllDocuments(ctx context.Context, collectionName string) ([]interface{}, error) {
+	collection, err := r.collection(collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export documents: %w", err)
+	}
+
+	documents, err := exportDocuments(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export documents: %w", err)
+	}
+	return documents, nil
+}
+
+// exportDocuments reads every document of a collection in id order
+func exportDocuments(ctx context.Context, collection *bleveCollection) ([]interface{}, error) {
+	var results []interface{}
+	for from := 0; ; from += blevePageSize {
+		request := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), blevePageSize, from, false)
+		request.Fields = []string{bleveSourceField}
+		request.SortBy([]string{"_id"})
+
+		page, err := collection.index.SearchInContext(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, hit := range page.Hits {
+			results = append(results, bleveDocument(hit.ID, hit.Fields))
+		}
+
+		if len(page.Hits) < blevePageSize {
+			break
+		}
+	}
+	return results, nil
+}
+
+// Snapshot writes a consistent copy of every collection into dir, which must
+// not exist yet. Indexing continues while the copy is taken; copying the
+// snapshot back to Path while the service is stopped restores it.
+func (r *BleveRepository) Snapshot(dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("snapshot directory %s already exists", dir)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.collections == nil {
+		return fmt.Errorf("bleve index is not open")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	for name, collection := range r.collections {
+		copyable, ok := collection.index.(bleve.IndexCopyable)
+		if !ok {
+			return fmt.Errorf("collection %s does not support snapshots", name)
+		}
+		if err := copyable.CopyTo(bleve.FileSystemDirectory(filepath.Join(dir, name))); err != nil {
+			return fmt.Errorf("failed to snapshot collection %s: %w", name, err)
+		}
+	}
+
+	log.Printf("Wrote Bleve snapshot to %s", dir)
+	return nil
+}
+
+// snapshotTimeFormat names snapshot directories so they sort by age
+const snapshotTimeFormat = "20060102T150405Z"
+
+// RunSnapshots writes a snapshot into SnapshotDir every SnapshotInterval
+// until ctx is done, pruning the oldest ones beyond SnapshotKeep. Failures
+// are logged and retried on the next tick.
+func (r *BleveRepository) RunSnapshots(ctx context.Context) {
+	if r.config.SnapshotDir == "" || r.config.SnapshotInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.config.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			dir := filepath.Join(r.config.SnapshotDir, now.UTC().Format(snapshotTimeFormat))
+			if err := r.Snapshot(dir); err != nil {
+				log.Printf("Failed to write Bleve snapshot: %v", err)
+				continue
+			}
+			if err := r.pruneSnapshots(); err != nil {
+				log.Printf("Failed to prune Bleve snapshots: %v", err)
+			}
+		}
+	}
+}
+
+// pruneSnapshots removes the oldest snapshots beyond SnapshotKeep
+func (r *BleveRepository) pruneSnapshots() error {
+	if r.config.SnapshotKeep <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(r.config.SnapshotDir)
+	if err != nil {
+		return err
+	}
+
+	var snapshots []string
+	for _, entry := range entries {
+		if _, err := time.Parse(snapshotTimeFormat, entry.Name()); entry.IsDir() && err == nil {
+			snapshots = append(snapshots, entry.Name())
+		}
+	}
+	sort.Strings(snapshots)
+
+	for len(snapshots) > r.config.SnapshotKeep {
+		if err := os.RemoveAll(filepath.Join(r.config.SnapshotDir, snapshots[0])); err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package searchindex
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// blevePostsSchema mirrors the schema created by the CDC service
+func blevePostsSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"name": "posts",
+		"fields": []map[string]interface{}{
+			{"name": "id", "type": "string"},
+			{"name": "title", "type": "string", "facet": false, "index": true},
+			{"name": "image", "type": "string", "optional": true, "facet": false, "index": false},
+			{"name": "excerpt", "type": "string", "optional": true, "facet": false, "index": true},
+			{"name": "body", "type": "string", "facet": false, "index": true},
+			{"name": "created_at", "type": "int64", "facet": false, "index": true},
+			{"name": "updated_at", "type": "int64", "facet": false, "index": false},
+		},
+		"default_sorting_field": "created_at",
+	}
+}
+
+func newTestBleveRepository(t *testing.T, config BleveConfig) *BleveRepository {
+	t.Helper()
+
+	if config.Path == "" {
+		config.Path = t.TempDir()
+	}
+	repo := NewBleveRepository(config)
+	if err := repo.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	if err := repo.CreateCollection(context.Background(), blevePostsSchema()); err != nil {
+		t.Fatalf("Failed to create collection: %v", err)
+	}
+	return repo
+}
+
+func TestBleveRepository_CreateCollectionPersists(t *testing.T) {
+	path := t.TempDir()
+	ctx := context.Background()
+
+	repo := NewBleveRepository(BleveConfig{Path: path})
+	if err := repo.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := repo.CreateCollection(ctx, blevePostsSchema()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := repo.CreateCollection(ctx, blevePostsSchema()); err != nil {
+		t.Fatalf("Expected existing collection to be accepted, got %v", err)
+	}
+	if err := repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "1", Title: "Hello"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Expected no error when closing, got %v", err)
+	}
+
+	// Reopening finds the collection and its documents on disk
+	reopened := NewBleveRepository(BleveConfig{Path: path})
+	if err := reopened.Connect(ctx); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	if err := reopened.CreateCollection(ctx, blevePostsSchema()); err != nil {
+		t.Fatalf("Expected existing collection to be accepted, got %v", err)
+	}
+	documents, err := reopened.GetAllDocuments(ctx, "posts")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(documents) != 1 || documents[0].(map[string]interface{})["title"] != "Hello" {
+		t.Errorf("Expected the stored document, got %v", documents)
+	}
+}
+
+func TestBleveRepository_LockedByAnotherRepository(t *testing.T) {
+	repo := newTestBleveRepository(t, BleveConfig{})
+
+	other := NewBleveRepository(BleveConfig{Path: repo.config.Path, OpenTimeout: 50 * time.Millisecond})
+	if err := other.Connect(context.Background()); err == nil {
+		other.Close()
+		t.Fatal("Expected an error while the index is held open")
+	}
+}
+
+func TestBleveRepository_UpsertReplacesAndDeleteIgnoresMissing(t *testing.T) {
+	repo := newTestBleveRepository(t, BleveConfig{})
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "7", Title: "Old", CreatedAt: 100})
+	if err := repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "7", Title: "New", CreatedAt: 200}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	documents, _ := repo.GetAllDocuments(ctx, "posts")
+	if len(documents) != 1 {
+		t.Fatalf("Expected 1 document, got %d", len(documents))
+	}
+	document := documents[0].(map[string]interface{})
+	if document["id"] != "7" || document["title"] != "New" || document["created_at"] != float64(200) {
+		t.Errorf("Expected the replaced document, got %v", document)
+	}
+
+	if err := repo.DeleteDocument(ctx, "posts", "7"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := repo.DeleteDocument(ctx, "posts", "missing"); err != nil {
+		t.Errorf("Expected deleting a missing document to succeed, got %v", err)
+	}
+	if documents, _ := repo.GetAllDocuments(ctx, "posts"); len(documents) != 0 {
+		t.Errorf("Expected no documents, got %v", documents)
+	}
+
+	if err := repo.UpsertDocument(ctx, "missing", &domain.SearchDocument{ID: "1"}); err == nil {
+		t.Error("Expected an error for an unknown collection")
+	}
+}
+
+func TestBleveRepository_SearchDocuments(t *testing.T) {
+	repo := newTestBleveRepository(t, BleveConfig{})
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "1", Title: "Postgres replication", Body: "plain", CreatedAt: 100})
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "2", Title: "Other", Body: "about postgres", CreatedAt: 300})
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "3", Title: "Unrelated", Body: "nothing", CreatedAt: 200})
+
+	results, err := repo.SearchDocuments(ctx, "posts", "postgres", map[string]interface{}{
+		"query_by": "title,excerpt,body",
+		"sort_by":  "created_at:desc",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results.Hits) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results.Hits))
+	}
+	if results.Found != 2 || results.OutOf != 3 || results.Page != 1 || results.PerPage != 10 {
+		t.Errorf("Unexpected totals: found %d out of %d, page %d of %d", results.Found, results.OutOf, results.Page, results.PerPage)
+	}
+
+	first := results.Hits[0]
+	if first.Document["id"] != "2" {
+		t.Errorf("Expected newest match first, got %v", first.Document["id"])
+	}
+	if first.Score <= 0 {
+		t.Errorf("Expected a positive score, got %v", first.Score)
+	}
+
+	// Only the matching field is reported, in the shape SearchService expects
+	expected := map[string][]string{"body": {"about <mark>postgres</mark>"}}
+	if !reflect.DeepEqual(first.Highlights, expected) {
+		t.Errorf("Unexpected highlights: %v", first.Highlights)
+	}
+
+	// Filters and pagination apply to match-all queries too
+	results, err = repo.SearchDocuments(ctx, "posts", "*", map[string]interface{}{
+		"sort_by":   "created_at:asc",
+		"filter_by": "created_at:>=200 && id:[2,3]",
+		"page":      2,
+		"per_page":  1,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results.Hits) != 1 || results.Hits[0].Document["id"] != "2" {
+		t.Errorf("Expected the second page to hold post 2, got %v", results.Hits)
+	}
+	if results.Found != 2 {
+		t.Errorf("Expected 2 found across pages, got %d", results.Found)
+	}
+
+	if _, err := repo.SearchDocuments(ctx, "posts", "*", map[string]interface{}{"filter_by": "broken"}); err == nil {
+		t.Error("Expected an error for an unsupported filter")
+	}
+}
+
+func TestBleveRepository_FieldAnalyzers(t *testing.T) {
+	repo := newTestBleveRepository(t, BleveConfig{Analyzers: map[string]string{"body": "en"}})
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "1", Title: "running", Body: "plain"})
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "2", Title: "plain", Body: "running"})
+
+	// Only the English analyzer on body stems running to run
+	results, err := repo.SearchDocuments(ctx, "posts", "run", map[string]interface{}{"query_by": "title,body"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results.Hits) != 1 || results.Hits[0].Document["id"] != "2" {
+		t.Errorf("Expected only the stemmed body to match, got %v", results.Hits)
+	}
+}
+
+func TestBleveRepository_SchemaChangeRebuilds(t *testing.T) {
+	repo := newTestBleveRepository(t, BleveConfig{})
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", map[string]interface{}{"id": "1", "title": "Hello", "tags": []string{"go"}})
+
+	schema := blevePostsSchema()
+	schema["fields"] = append(schema["fields"].([]map[string]interface{}),
+		map[string]interface{}{"name": "tags", "type": "string[]", "facet": true})
+	if err := repo.CreateCollection(ctx, schema); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The existing document survives and the new field becomes filterable
+	results, err := repo.SearchDocuments(ctx, "posts", "*", map[string]interface{}{"filter_by": "tags:=go"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results.Hits) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results.Hits))
+	}
+	if tags := results.Hits[0].Document["tags"]; !reflect.DeepEqual(tags, []interface{}{"go"}) {
+		t.Errorf("Expected tags to stay an array, got %v", tags)
+	}
+}
+
+func TestBleveRepository_GetAllDocuments(t *testing.T) {
+	repo := newTestBleveRepository(t, BleveConfig{})
+	ctx := context.Background()
+
+	collection, _ := repo.collection("posts")
+	batch := collection.index.NewBatch()
+	for i := 0; i < blevePageSize+1; i++ {
+		batch.Index(fmt.Sprintf("%05d", i), map[string]interface{}{"title": "post"})
+	}
+	// One batch keeps the test fast
+	if err := collection.index.Batch(batch); err != nil {
+		t.Fatalf("Failed to seed documents: %v", err)
+	}
+
+	results, err := repo.GetAllDocuments(ctx, "posts")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != blevePageSize+1 {
+		t.Errorf("Expected %d documents, got %d", blevePageSize+1, len(results))
+	}
+}
+
+func TestBleveRepository_Snapshot(t *testing.T) {
+	repo := newTestBleveRepository(t, BleveConfig{})
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "1", Title: "Hello"})
+
+	dir := filepath.Join(t.TempDir(), "snapshot")
+	if err := repo.Snapshot(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := repo.Snapshot(dir); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected an existing snapshot to be refused, got %v", err)
+	}
+
+	// The snapshot is a complete index directory
+	restored := NewBleveRepository(BleveConfig{Path: dir})
+	if err := restored.Connect(ctx); err != nil {
+		t.Fatalf("Failed to open snapshot: %v", err)
+	}
+	defer restored.Close()
+
+	documents, err := restored.GetAllDocuments(ctx, "posts")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(documents) != 1 {
+		t.Errorf("Expected 1 document in the snapshot, got %d", len(documents))
+	}
+}
+
+func TestBleveSort(t *testing.T) {
+	tests := map[string][]string{
+		"":                                 {"-_score", "_id"},
+		"_text_match:desc,created_at:desc": {"-_score", "-created_at", "_id"},
+		"created_at:asc":                   {"created_at", "_id"},
+	}
+	for sortBy, expected := range tests {
+		if got := bleveSort(sortBy); !reflect.DeepEqual(got, expected) {
+			t.Errorf("bleveSort(%q) = %v, expected %v", sortBy, got, expected)
+		}
+	}
+}
+
+func TestBleveRepository_RunSnapshotsPrunes(t *testing.T) {
+	snapshotDir := t.TempDir()
+	repo := newTestBleveRepository(t, BleveConfig{
+		SnapshotDir:      snapshotDir,
+		SnapshotInterval: time.Second,
+		SnapshotKeep:     1,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+	defer cancel()
+	repo.RunSnapshots(ctx)
+
+	// Two snapshots were taken, one a second apart; only the newest is kept
+	entries, err := os.ReadDir(snapshotDir)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 snapshot, got %d", len(entries))
+	}
+}
@@ -0,0 +1,67 @@
+package searchindex
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/searchindex/searchindextest"
+)
+
+// connectForConformance connects a repository and closes it when the test ends
+func connectForConformance(t *testing.T, repo domain.SearchIndexRepository) domain.SearchIndexRepository {
+	t.Helper()
+	if err := repo.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestTypesenseRepository_Conformance(t *testing.T) {
+	searchindextest.Run(t, func(t *testing.T) domain.SearchIndexRepository {
+		server := newFakeTypesense(t)
+		return connectForConformance(t, newTestTypesenseRepository(t, server.URL, TypesenseConfig{APIKey: "test-key"}))
+	})
+}
+
+func TestBleveRepository_Conformance(t *testing.T) {
+	searchindextest.Run(t, func(t *testing.T) domain.SearchIndexRepository {
+		return connectForConformance(t, NewBleveRepository(BleveConfig{Path: t.TempDir()}))
+	})
+}
+
+// The HTTP backends run the suite against a real server when one is given,
+// e.g. ELASTICSEARCH_TEST_URL=http://localhost:9200 go test ./...
+
+func TestElasticsearchRepository_Conformance(t *testing.T) {
+	serverURL := os.Getenv("ELASTICSEARCH_TEST_URL")
+	if serverURL == "" {
+		t.Skip("ELASTICSEARCH_TEST_URL not set")
+	}
+
+	searchindextest.Run(t, func(t *testing.T) domain.SearchIndexRepository {
+		return connectForConformance(t, NewElasticsearchRepository(ElasticsearchConfig{
+			URL:      serverURL,
+			Username: os.Getenv("ELASTICSEARCH_TEST_USERNAME"),
+			Password: os.Getenv("ELASTICSEARCH_TEST_PASSWORD"),
+			// Searches must see writes immediately
+			Refresh: "wait_for",
+		}))
+	})
+}
+
+func TestMeilisearchRepository_Conformance(t *testing.T) {
+	serverURL := os.Getenv("MEILISEARCH_TEST_URL")
+	if serverURL == "" {
+		t.Skip("MEILISEARCH_TEST_URL not set")
+	}
+
+	searchindextest.Run(t, func(t *testing.T) domain.SearchIndexRepository {
+		return connectForConformance(t, NewMeilisearchRepository(MeilisearchConfig{
+			URL:    serverURL,
+			APIKey: os.Getenv("MEILISEARCH_TEST_API_KEY"),
+		}))
+	})
+}
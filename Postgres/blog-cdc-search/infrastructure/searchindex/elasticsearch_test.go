@@ -0,0 +1,574 @@
+package searchindex
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// fakeElasticsearch is an in-memory stand-in for the parts of the
+// Elasticsearch REST API used by the repository
+type fakeElasticsearch struct {
+	mu        sync.Mutex
+	indices   map[string]map[string]map[string]interface{}
+	mappings  map[string]interface{}
+	searches  []map[string]interface{}
+	scrolls   map[string][]map[string]interface{}
+	cleared   []string
+	failBulk  bool
+	pageSize  int
+	bulkLines []string
+	// aggregations is returned verbatim for searches that request facets
+	aggregations map[string]interface{}
+}
+
+func newFakeElasticsearch(t *testing.T) (*fakeElasticsearch, *httptest.Server) {
+	fake := &fakeElasticsearch{
+		indices:  map[string]map[string]map[string]interface{}{},
+		mappings: map[string]interface{}{},
+		scrolls:  map[string][]map[string]interface{}{},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	path := strings.Trim(r.URL.Path, "/")
+
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"cluster_name": "test",
+			"version":      map[string]interface{}{"number": "8.15.0"},
+		})
+
+	case path == "_bulk":
+		f.handleBulk(w, body)
+
+	case path == "_search/scroll" && r.Method == http.MethodPost:
+		var req map[string]string
+		json.Unmarshal(body, &req)
+		f.writeScrollPage(w, req["scroll_id"])
+
+	case path == "_search/scroll" && r.Method == http.MethodDelete:
+		var req struct {
+			ScrollID []string `json:"scroll_id"`
+		}
+		json.Unmarshal(body, &req)
+		f.cleared = append(f.cleared, req.ScrollID...)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"succeeded": true})
+
+	case strings.HasSuffix(path, "/_search"):
+		f.handleSearch(w, r, strings.TrimSuffix(path, "/_search"), body)
+
+	case r.Method == http.MethodHead:
+		if _, ok := f.indices[path]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == http.MethodPut:
+		if _, ok := f.indices[path]; ok {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error": map[string]string{"type": "resource_already_exists_exception", "reason": "exists"},
+			})
+			return
+		}
+		var req map[string]interface{}
+		json.Unmarshal(body, &req)
+		f.indices[path] = map[string]map[string]interface{}{}
+		f.mappings[path] = req["mappings"]
+		writeJSON(w, http.StatusOK, map[string]interface{}{"acknowledged": true})
+
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "no handler found"})
+	}
+}
+
+func (f *fakeElasticsearch) handleBulk(w http.ResponseWriter, body []byte) {
+	var items []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		f.bulkLines = append(f.bulkLines, line)
+
+		var action map[string]map[string]string
+		json.Unmarshal([]byte(line), &action)
+		for op, meta := range action {
+			index, id := meta["_index"], meta["_id"]
+			if f.indices[index] == nil {
+				f.indices[index] = map[string]map[string]interface{}{}
+			}
+
+			status := http.StatusOK
+			switch op {
+			case "index":
+				scanner.Scan()
+				f.bulkLines = append(f.bulkLines, scanner.Text())
+				var document map[string]interface{}
+				json.Unmarshal(scanner.Bytes(), &document)
+				f.indices[index][id] = document
+			case "delete":
+				if _, ok := f.indices[index][id]; !ok {
+					status = http.StatusNotFound
+				}
+				delete(f.indices[index], id)
+			}
+
+			result := map[string]interface{}{"_id": id, "status": status}
+			if f.failBulk {
+				result["status"] = http.StatusBadRequest
+				result["error"] = map[string]string{"type": "mapper_parsing_exception", "reason": "bad field"}
+			}
+			items = append(items, map[string]interface{}{op: result})
+		}
+	}
+
+	hasErrors := f.failBulk
+	for _, item := range items {
+		for _, result := range item {
+			if result.(map[string]interface{})["status"] != http.StatusOK {
+				hasErrors = true
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"errors": hasErrors, "items": items})
+}
+
+func (f *fakeElasticsearch) handleSearch(w http.ResponseWriter, r *http.Request, index string, body []byte) {
+	documents, ok := f.indices[index]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"error": map[string]string{"type": "index_not_found_exception", "reason": "no such index"},
+		})
+		return
+	}
+
+	var req map[string]interface{}
+	json.Unmarshal(body, &req)
+	f.searches = append(f.searches, req)
+
+	// Return documents in id order so tests are deterministic
+	ids := make([]string, 0, len(documents))
+	for id := range documents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var hits []map[string]interface{}
+	for _, id := range ids {
+		hit := map[string]interface{}{"_id": id, "_score": 1.5, "_source": documents[id]}
+		if _, highlight := req["highlight"]; highlight {
+			hit["highlight"] = map[string][]string{"title": {"<mark>match</mark>"}}
+		}
+		hits = append(hits, hit)
+	}
+
+	if r.URL.Query().Get("scroll") == "" {
+		response := map[string]interface{}{
+			"took": 3,
+			"hits": map[string]interface{}{"total": map[string]interface{}{"value": len(hits), "relation": "eq"}, "hits": hits},
+		}
+		if _, ok := req["aggs"]; ok && f.aggregations != nil {
+			response["aggregations"] = f.aggregations
+		}
+		writeJSON(w, http.StatusOK, response)
+		return
+	}
+
+	scrollID := fmt.Sprintf("scroll-%d", len(f.scrolls))
+	f.scrolls[scrollID] = hits
+	f.writeScrollPage(w, scrollID)
+}
+
+// writeScrollPage returns the next page of a scroll, shrinking it in place
+func (f *fakeElasticsearch) writeScrollPage(w http.ResponseWriter, scrollID string) {
+	hits := f.scrolls[scrollID]
+	size := f.pageSize
+	if size == 0 || size > len(hits) {
+		size = len(hits)
+	}
+	f.scrolls[scrollID] = hits[size:]
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"_scroll_id": scrollID,
+		"hits":       map[string]interface{}{"hits": hits[:size]},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func newTestElasticsearchRepository(t *testing.T, serverURL string) *ElasticsearchRepository {
+	t.Helper()
+
+	repo := NewElasticsearchRepository(ElasticsearchConfig{URL: serverURL + "/"})
+	if err := repo.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	return repo
+}
+
+func TestElasticsearchRepository_CreateCollection(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+	repo := newTestElasticsearchRepository(t, server.URL)
+
+	schema := map[string]interface{}{
+		"name": "posts",
+		"fields": []map[string]interface{}{
+			{"name": "id", "type": "string"},
+			{"name": "title", "type": "string", "facet": false, "index": true},
+			{"name": "image", "type": "string", "optional": true, "facet": false, "index": false},
+			{"name": "created_at", "type": "int64", "facet": false, "index": true},
+			{"name": "updated_at", "type": "int64", "facet": false, "index": false},
+		},
+		"default_sorting_field": "created_at",
+	}
+
+	if err := repo.CreateCollection(context.Background(), schema); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"id":         map[string]interface{}{"type": "keyword"},
+			"title":      map[string]interface{}{"type": "text"},
+			"image":      map[string]interface{}{"type": "keyword", "index": false},
+			"created_at": map[string]interface{}{"type": "long"},
+			"updated_at": map[string]interface{}{"type": "long", "index": false},
+		},
+	}
+	if !reflect.DeepEqual(fake.mappings["posts"], expected) {
+		t.Errorf("Unexpected mappings: %#v", fake.mappings["posts"])
+	}
+
+	// Creating it again is a no-op
+	if err := repo.CreateCollection(context.Background(), schema); err != nil {
+		t.Errorf("Expected existing index to be accepted, got %v", err)
+	}
+
+	// A schema without a name is rejected rather than panicking
+	if err := repo.CreateCollection(context.Background(), map[string]interface{}{"fields": schema["fields"]}); err == nil {
+		t.Error("Expected an error for a schema without a name")
+	}
+}
+
+func TestElasticsearchRepository_UpsertAndDelete(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+	repo := newTestElasticsearchRepository(t, server.URL)
+	ctx := context.Background()
+
+	doc := &domain.SearchDocument{ID: "42", Title: "Hello", Body: "World", CreatedAt: 1700000000}
+	if err := repo.UpsertDocument(ctx, "posts", doc); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if fake.bulkLines[0] != `{"index":{"_id":"42","_index":"posts"}}` {
+		t.Errorf("Unexpected bulk action: %s", fake.bulkLines[0])
+	}
+	if fake.indices["posts"]["42"]["title"] != "Hello" {
+		t.Errorf("Expected document to be indexed, got %v", fake.indices["posts"]["42"])
+	}
+
+	if err := repo.DeleteDocument(ctx, "posts", "42"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := fake.indices["posts"]["42"]; ok {
+		t.Error("Expected document to be deleted")
+	}
+
+	// Deleting a missing document is idempotent
+	if err := repo.DeleteDocument(ctx, "posts", "42"); err != nil {
+		t.Errorf("Expected deleting a missing document to succeed, got %v", err)
+	}
+}
+
+func TestElasticsearchRepository_BulkItemError(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+	repo := newTestElasticsearchRepository(t, server.URL)
+	fake.failBulk = true
+
+	err := repo.UpsertDocument(context.Background(), "posts", map[string]interface{}{"id": "1"})
+	var esErr *ElasticsearchError
+	if !errors.As(err, &esErr) || esErr.Type != "mapper_parsing_exception" {
+		t.Errorf("Expected bulk item error, got %v", err)
+	}
+
+	if err := repo.UpsertDocument(context.Background(), "posts", map[string]interface{}{"title": "no id"}); err == nil {
+		t.Error("Expected error for document without id")
+	}
+}
+
+func TestElasticsearchRepository_SearchDocuments(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+	repo := newTestElasticsearchRepository(t, server.URL)
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "1", Title: "match"})
+
+	results, err := repo.SearchDocuments(ctx, "posts", "match", map[string]interface{}{
+		"query_by":  "title,excerpt,body",
+		"sort_by":   "_text_match:desc,created_at:desc",
+		"filter_by": "created_at:>=100",
+		"page":      2,
+		"per_page":  5,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	request := fake.searches[0]
+	if request["from"] != float64(5) || request["size"] != float64(5) {
+		t.Errorf("Expected from=5 size=5, got from=%v size=%v", request["from"], request["size"])
+	}
+	if request["track_total_hits"] != true {
+		t.Errorf("Expected exact total hits to be requested, got %v", request["track_total_hits"])
+	}
+	query, _ := json.Marshal(request["query"])
+	if !strings.Contains(string(query), `"multi_match":{"fields":["title","excerpt","body"],"query":"match"}`) {
+		t.Errorf("Expected multi_match query, got %s", query)
+	}
+	if !strings.Contains(string(query), `"range":{"created_at":{"gte":"100"}}`) {
+		t.Errorf("Expected range filter, got %s", query)
+	}
+	sortClause, _ := json.Marshal(request["sort"])
+	if string(sortClause) != `[{"_score":{"order":"desc"}},{"created_at":{"order":"desc"}},{"id":{"order":"asc"}}]` {
+		t.Errorf("Unexpected sort: %s", sortClause)
+	}
+
+	if len(results.Hits) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results.Hits))
+	}
+	if results.Found != 1 || results.Page != 2 || results.PerPage != 5 || results.SearchTimeMS != 3 {
+		t.Errorf("Unexpected totals: found %d, page %d of %d, %dms", results.Found, results.Page, results.PerPage, results.SearchTimeMS)
+	}
+	hit := results.Hits[0]
+	if hit.Score != 1.5 {
+		t.Errorf("Expected score 1.5, got %v", hit.Score)
+	}
+	if _, ok := hit.Document["_text_match"]; ok {
+		t.Error("Expected the score to be kept out of the document")
+	}
+	if !reflect.DeepEqual(hit.Highlights, map[string][]string{"title": {"<mark>match</mark>"}}) {
+		t.Errorf("Unexpected highlights: %v", hit.Highlights)
+	}
+}
+
+func TestElasticsearchRepository_SearchDocumentsPrefix(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+	repo := newTestElasticsearchRepository(t, server.URL)
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "1", Title: "match"})
+
+	if _, err := repo.SearchDocuments(ctx, "posts", "data cap", map[string]interface{}{
+		"query_by":  "title",
+		"prefix":    "true",
+		"num_typos": "1",
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	query, _ := json.Marshal(fake.searches[0]["query"])
+	if !strings.Contains(string(query), `"multi_match":{"fields":["title"],"fuzziness":1,"query":"data cap","type":"bool_prefix"}`) {
+		t.Errorf("Expected a fuzzy bool_prefix query, got %s", query)
+	}
+}
+
+func TestElasticsearchRepository_SearchDocumentsFacets(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+	repo := newTestElasticsearchRepository(t, server.URL)
+	ctx := context.Background()
+
+	repo.UpsertDocument(ctx, "posts", &domain.SearchDocument{ID: "1", Title: "match"})
+	fake.aggregations = map[string]interface{}{
+		"tags": map[string]interface{}{"buckets": []map[string]interface{}{
+			{"key": "go", "doc_count": 2},
+			{"key": "cdc", "doc_count": 1},
+		}},
+		"has_image": map[string]interface{}{"buckets": []map[string]interface{}{
+			{"key": 1, "key_as_string": "true", "doc_count": 3},
+		}},
+	}
+
+	results, err := repo.SearchDocuments(ctx, "posts", "match", map[string]interface{}{
+		"query_by": "title",
+		"facet_by": "tags,has_image",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	aggs, _ := json.Marshal(fake.searches[0]["aggs"])
+	expected := `{"has_image":{"terms":{"field":"has_image","size":10}},"tags":{"terms":{"field":"tags","size":10}}}`
+	if string(aggs) != expected {
+		t.Errorf("Unexpected aggregations: %s", aggs)
+	}
+
+	want := []domain.FacetCounts{
+		{Field: "tags", Counts: []domain.FacetValueCount{{Value: "go", Count: 2}, {Value: "cdc", Count: 1}}},
+		{Field: "has_image", Counts: []domain.FacetValueCount{{Value: "true", Count: 3}}},
+	}
+	if !reflect.DeepEqual(results.Facets, want) {
+		t.Errorf("Unexpected facets: %+v", results.Facets)
+	}
+}
+
+func TestElasticsearchRepository_GetAllDocuments(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+	repo := newTestElasticsearchRepository(t, server.URL)
+	ctx := context.Background()
+
+	for i := 0; i < scrollPageSize+3; i++ {
+		repo.UpsertDocument(ctx, "posts", map[string]interface{}{"id": fmt.Sprintf("%04d", i)})
+	}
+	fake.pageSize = scrollPageSize
+
+	results, err := repo.GetAllDocuments(ctx, "posts")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(results) != scrollPageSize+3 {
+		t.Errorf("Expected %d documents, got %d", scrollPageSize+3, len(results))
+	}
+	if len(fake.cleared) != 1 {
+		t.Errorf("Expected scroll to be cleared once, got %v", fake.cleared)
+	}
+
+	if _, err := repo.GetAllDocuments(ctx, "missing"); err == nil {
+		t.Error("Expected error for missing index")
+	}
+}
+
+func TestElasticsearchRepository_CircuitBreakerOpens(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"cluster_name": "test"})
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := NewElasticsearchRepository(ElasticsearchConfig{URL: server.URL, BreakerFailureThreshold: 2})
+	if err := repo.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		repo.DeleteDocument(context.Background(), "posts", "1")
+	}
+
+	err := repo.DeleteDocument(context.Background(), "posts", "1")
+	if !errors.Is(err, domain.ErrServiceUnavailable) {
+		t.Errorf("Expected ErrServiceUnavailable once the breaker is open, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected open breaker to skip the request, got %d requests", requests)
+	}
+}
+
+func TestElasticsearchRepository_CallerTimeoutsKeepBreakerClosed(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"cluster_name": "test"})
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	repo := NewElasticsearchRepository(ElasticsearchConfig{URL: server.URL, WriteTimeout: time.Minute, BreakerFailureThreshold: 1})
+	if err := repo.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	// Calls the caller canceled or let expire do not open the breaker
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expiring, cancelExpiring := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelExpiring()
+	for _, ctx := range []context.Context{canceled, expiring, canceled} {
+		err := repo.DeleteDocument(ctx, "posts", "1")
+		if err == nil || errors.Is(err, domain.ErrServiceUnavailable) {
+			t.Fatalf("Expected the caller's context error, got %v", err)
+		}
+	}
+
+	// The repository's own write timeout does
+	repo.config.WriteTimeout = 10 * time.Millisecond
+	repo.DeleteDocument(context.Background(), "posts", "1")
+	err := repo.DeleteDocument(context.Background(), "posts", "1")
+	if !errors.Is(err, domain.ErrServiceUnavailable) {
+		t.Errorf("Expected ErrServiceUnavailable after a write timeout, got %v", err)
+	}
+}
+
+func TestElasticsearchSearchFilters(t *testing.T) {
+	filters, err := elasticsearchSearchFilters([]domain.SearchFilter{
+		{Field: "created_at", Op: domain.FilterAtLeast, Value: int64(1)},
+		{Field: "created_at", Op: domain.FilterBelow, Value: int64(9)},
+		{Field: "has_image", Op: domain.FilterEqual, Value: true},
+		{Field: "category", Op: domain.FilterEqual, Value: `Q&A && notes: C:\temp`},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	encoded, _ := json.Marshal(filters)
+	expected := `[{"range":{"created_at":{"gte":1}}},{"range":{"created_at":{"lt":9}}},{"term":{"has_image":true}},{"term":{"category":"Q\u0026A \u0026\u0026 notes: C:\\temp"}}]`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got %s", expected, encoded)
+	}
+
+	if _, err := elasticsearchSearchFilters([]domain.SearchFilter{{Field: "id", Op: "!=", Value: "1"}}); err == nil {
+		t.Error("Expected error for unsupported comparison")
+	}
+}
+
+func TestElasticsearchFilters(t *testing.T) {
+	filters, err := elasticsearchFilters("created_at:>1 && id:=7 && tags:[go, `cdc`]")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	encoded, _ := json.Marshal(filters)
+	expected := `[{"range":{"created_at":{"gt":"1"}}},{"term":{"id":"7"}},{"terms":{"tags":["go","cdc"]}}]`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got %s", expected, encoded)
+	}
+
+	if _, err := elasticsearchFilters("no-colon"); err == nil {
+		t.Error("Expected error for unsupported clause")
+	}
+}
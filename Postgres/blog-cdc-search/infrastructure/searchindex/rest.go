@@ -0,0 +1,107 @@
+package searchindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"blog-cdc-search/infrastructure/tlsconfig"
+
+	"github.com/sony/gobreaker"
+)
+
+// restClient sends JSON requests to the REST API of a search backend through
+// a circuit breaker
+type restClient struct {
+	baseURL string
+	client  *http.Client
+	breaker *gobreaker.CircuitBreaker
+
+	// authorize adds credentials to each request
+	authorize func(req *http.Request)
+	// parseError turns an error response into a backend specific error
+	parseError func(status int, body []byte) error
+}
+
+// ndjson is a pre-encoded newline-delimited JSON request body
+type ndjson []byte
+
+// connect creates the HTTP client, loading certificates when TLS is enabled
+func (c *restClient) connect(config tlsconfig.Config) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	c.client = &http.Client{Transport: transport}
+	return nil
+}
+
+// close releases idle connections
+func (c *restClient) close() {
+	if c.client != nil {
+		c.client.CloseIdleConnections()
+	}
+}
+
+// do sends a request and decodes the JSON response into out
+func (c *restClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var payload []byte
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case ndjson:
+		payload = b
+		contentType = "application/x-ndjson"
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		payload = encoded
+	}
+
+	return runWithBreaker(ctx, c.breaker, func() error {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Accept", "application/json")
+		if c.authorize != nil {
+			c.authorize(req)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode >= 300 {
+			return c.parseError(resp.StatusCode, data)
+		}
+
+		if out != nil && len(data) > 0 {
+			if err := json.Unmarshal(data, out); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+		}
+		return nil
+	})
+}
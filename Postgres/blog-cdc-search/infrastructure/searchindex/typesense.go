@@ -3,19 +3,28 @@ package searchindex
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/typesense/typesense-go/typesense"
-	"github.com/typesense/typesense-go/typesense/api"
+	"blog-cdc-search/domain"
+	"blog-cdc-search/infrastructure/tlsconfig"
+
+	"github.com/sony/gobreaker"
+	"github.com/typesense/typesense-go/v2/typesense"
+	"github.com/typesense/typesense-go/v2/typesense/api"
 )
 
 // TypesenseRepository implements the SearchIndexRepository interface
 type TypesenseRepository struct {
-	client *typesense.Client
-	config TypesenseConfig
+	client  *typesense.Client
+	breaker *gobreaker.CircuitBreaker
+	config  TypesenseConfig
 }
 
 // TypesenseConfig holds the configuration for Typesense connection
@@ -23,33 +32,254 @@ type TypesenseConfig struct {
 	Host   string
 	Port   int
 	APIKey string
+
+	// Nodes lists the cluster members as URLs (https://ts-1:8108) or
+	// host:port pairs. When empty, Host and Port form a single-node cluster.
+	Nodes []string
+	// NearestNode is tried first on every request, falling back to Nodes
+	// while it is unhealthy.
+	NearestNode string
+	// HealthcheckInterval is how long a failed node is skipped before it is
+	// tried again.
+	HealthcheckInterval time.Duration
+	// NumRetries is the number of attempts per request across nodes.
+	// Zero means one attempt per node.
+	NumRetries int
+	// RetryInterval is the pause before trying the next node.
+	RetryInterval time.Duration
+	// ConnectionTimeout bounds a single HTTP request to one node.
+	ConnectionTimeout time.Duration
+
+	// TLS switches the connection to https://. Certificates are only needed
+	// for private CAs or mutual TLS.
+	TLS tlsconfig.Config
+
+	// ReadTimeout bounds searches, exports and collection lookups.
+	// Zero means the caller's context is used as is.
+	ReadTimeout time.Duration
+	// WriteTimeout bounds collection creation, upserts and deletes.
+	// Zero means the caller's context is used as is.
+	WriteTimeout time.Duration
+
+	// BreakerFailureThreshold is the number of consecutive failures that
+	// opens the circuit breaker.
+	BreakerFailureThreshold uint32
+	// BreakerOpenTimeout is how long the breaker stays open before letting
+	// a probe request through.
+	BreakerOpenTimeout time.Duration
 }
 
+// Default circuit breaker settings used when the config leaves them unset
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenTimeout      = 30 * time.Second
+)
+
+// Default node failover settings, matching the typesense-go client defaults
+const (
+	defaultHealthcheckInterval = time.Minute
+	defaultRetryInterval       = 100 * time.Millisecond
+	defaultConnectionTimeout   = 5 * time.Second
+)
+
 // NewTypesenseRepository creates a new Typesense repository instance
 func NewTypesenseRepository(config TypesenseConfig) *TypesenseRepository {
+	if config.BreakerFailureThreshold == 0 {
+		config.BreakerFailureThreshold = defaultBreakerFailureThreshold
+	}
+	if config.BreakerOpenTimeout <= 0 {
+		config.BreakerOpenTimeout = defaultBreakerOpenTimeout
+	}
+	if config.HealthcheckInterval <= 0 {
+		config.HealthcheckInterval = defaultHealthcheckInterval
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaultRetryInterval
+	}
+	if config.ConnectionTimeout <= 0 {
+		config.ConnectionTimeout = defaultConnectionTimeout
+	}
+
 	return &TypesenseRepository{
-		config: config,
+		breaker: newCircuitBreaker("typesense", config.BreakerFailureThreshold, config.BreakerOpenTimeout, isAvailable),
+		config:  config,
+	}
+}
+
+// isAvailable reports whether an error still proves Typesense is reachable.
+// Client errors such as a missing document must not open the breaker.
+func isAvailable(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	var httpErr *typesense.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status < 500
+	}
+	return false
+}
+
+// typesenseFilterOps maps filter comparisons to filter_by operators
+var typesenseFilterOps = map[domain.FilterOp]string{
+	domain.FilterEqual:   ":=",
+	domain.FilterAtLeast: ":>=",
+	domain.FilterBelow:   ":<",
+}
+
+// typesenseFilterBy compiles typed search filters into a filter_by
+// expression. Strings are quoted with backticks, which cannot be escaped.
+func typesenseFilterBy(filters []domain.SearchFilter) (string, error) {
+	var clauses []string
+	for _, filter := range filters {
+		op, ok := typesenseFilterOps[filter.Op]
+		if !ok {
+			return "", fmt.Errorf("unsupported filter comparison: %q", filter.Op)
+		}
+
+		var value string
+		switch v := filter.Value.(type) {
+		case string:
+			if strings.Contains(v, "`") {
+				return "", fmt.Errorf("filter value for %s contains a backtick", filter.Field)
+			}
+			value = "`" + v + "`"
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			return "", fmt.Errorf("unsupported filter value for %s: %T", filter.Field, filter.Value)
+		}
+		clauses = append(clauses, filter.Field+op+value)
+	}
+	return strings.Join(clauses, " && "), nil
+}
+
+// execute runs fn through the circuit breaker. While the breaker is open the
+// call fails fast with domain.ErrServiceUnavailable.
+func (r *TypesenseRepository) execute(ctx context.Context, fn func() error) error {
+	return runWithBreaker(ctx, r.breaker, fn)
+}
+
+// serverURL builds the base URL of the Typesense node
+func (c TypesenseConfig) serverURL() string {
+	protocol := "http"
+	if c.TLS.Enabled {
+		protocol = "https"
+	}
+	return fmt.Sprintf("%s://%s:%d", protocol, c.Host, c.Port)
+}
+
+// nodeURL turns a host:port pair into a URL using the configured protocol
+func (c TypesenseConfig) nodeURL(node string) string {
+	if strings.Contains(node, "://") {
+		return node
+	}
+	if c.TLS.Enabled {
+		return "https://" + node
+	}
+	return "http://" + node
+}
+
+// nodeURLs returns the cluster members, falling back to Host and Port
+func (c TypesenseConfig) nodeURLs() []string {
+	if len(c.Nodes) == 0 {
+		return []string{c.serverURL()}
+	}
+
+	nodes := make([]string, 0, len(c.Nodes))
+	for _, node := range c.Nodes {
+		nodes = append(nodes, c.nodeURL(node))
+	}
+	return nodes
+}
+
+// NodeURLs returns the URLs of the cluster members
+func (r *TypesenseRepository) NodeURLs() []string {
+	return r.config.nodeURLs()
+}
+
+// clientConfig holds the node failover settings passed to typesense-go
+func (c TypesenseConfig) clientConfig() *typesense.ClientConfig {
+	config := &typesense.ClientConfig{
+		Nodes:               c.nodeURLs(),
+		NumRetries:          c.NumRetries,
+		RetryInterval:       c.RetryInterval,
+		HealthcheckInterval: c.HealthcheckInterval,
+		APIKey:              c.APIKey,
+		ConnectionTimeout:   c.ConnectionTimeout,
+	}
+	if c.NearestNode != "" {
+		config.NearestNode = c.nodeURL(c.NearestNode)
 	}
+	return config
 }
 
-// Connect establishes a connection to Typesense
-func (r *TypesenseRepository) Connect() error {
-	serverURL := fmt.Sprintf("http://%s:%d", r.config.Host, r.config.Port)
+// newClient creates the Typesense client, with a dedicated HTTP transport
+// when custom certificates are configured
+func (r *TypesenseRepository) newClient() (*typesense.Client, error) {
+	config := r.config.clientConfig()
+
+	if !r.config.TLS.HasCertificates() {
+		return typesense.NewClient(
+			typesense.WithNodes(config.Nodes),
+			typesense.WithNearestNode(config.NearestNode),
+			typesense.WithNumRetries(config.NumRetries),
+			typesense.WithRetryInterval(config.RetryInterval),
+			typesense.WithHealthcheckInterval(config.HealthcheckInterval),
+			typesense.WithConnectionTimeout(config.ConnectionTimeout),
+			typesense.WithAPIKey(config.APIKey),
+		), nil
+	}
+
+	tlsConfig, err := r.config.TLS.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Typesense TLS config: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
 
-	client := typesense.NewClient(
-		typesense.WithServer(serverURL),
-		typesense.WithAPIKey(r.config.APIKey),
+	// The request doer spreads requests over the nodes like the default client
+	apiCall := typesense.NewAPICall(&http.Client{
+		Timeout:   config.ConnectionTimeout,
+		Transport: transport,
+	}, config)
+
+	apiClient, err := api.NewClientWithResponses(config.Nodes[0],
+		api.WithAPIKey(config.APIKey),
+		api.WithHTTPClient(apiCall),
 	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Typesense client: %w", err)
+	}
+
+	return typesense.NewClient(typesense.WithAPIClient(apiClient)), nil
+}
+
+// Connect establishes a connection to Typesense. The client is kept even if
+// the health check fails, so requests start working once Typesense is up.
+func (r *TypesenseRepository) Connect(ctx context.Context) error {
+	client, err := r.newClient()
+	if err != nil {
+		return err
+	}
+	r.client = client
 
 	// Test the connection by trying to retrieve collections
-	ctx := context.Background()
-	_, err := client.Collections().Retrieve(ctx)
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
+
+	err = r.execute(ctx, func() error {
+		_, err := r.client.Collections().Retrieve(ctx)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to Typesense: %w", err)
 	}
 
-	r.client = client
-	log.Printf("Connected to Typesense at %s:%d", r.config.Host, r.config.Port)
+	log.Printf("Connected to Typesense at %s", strings.Join(r.config.nodeURLs(), ", "))
 	return nil
 }
 
@@ -60,29 +290,57 @@ func (r *TypesenseRepository) Close() error {
 }
 
 // CreateCollection creates a new collection in Typesense
-func (r *TypesenseRepository) CreateCollection(schema map[string]interface{}) error {
-	ctx := context.Background()
+func (r *TypesenseRepository) CreateCollection(ctx context.Context, schema map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
 
 	// Check if collection already exists
-	collections, err := r.client.Collections().Retrieve(ctx)
+	var collections []*api.CollectionResponse
+	err := r.execute(ctx, func() error {
+		var err error
+		collections, err = r.client.Collections().Retrieve(ctx)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to retrieve collections: %w", err)
 	}
 
 	collectionName := schema["name"].(string)
+	fields := typesenseFields(schema)
 	for _, collection := range collections {
 		if collection.Name == collectionName {
 			log.Printf("Collection %s already exists", collectionName)
-			return nil
+			return r.migrateCollection(ctx, collection, fields)
 		}
 	}
 
 	// Convert the schema map to CollectionSchema
 	collectionSchema := &api.CollectionSchema{
-		Name: collectionName,
+		Name:   collectionName,
+		Fields: fields,
+	}
+
+	// Add default sorting field
+	if defaultSortingField, ok := schema["default_sorting_field"].(string); ok {
+		collectionSchema.DefaultSortingField = &defaultSortingField
+	}
+
+	// Create the collection
+	err = r.execute(ctx, func() error {
+		_, err := r.client.Collections().Create(ctx, collectionSchema)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
 	}
 
-	// Add fields
+	log.Printf("Created collection: %s", collectionName)
+	return nil
+}
+
+// typesenseFields converts the fields of a schema map to Typesense fields
+func typesenseFields(schema map[string]interface{}) []api.Field {
+	var fieldSchemas []api.Field
 	if fields, ok := schema["fields"].([]map[string]interface{}); ok {
 		for _, field := range fields {
 			fieldSchema := api.Field{
@@ -98,31 +356,97 @@ func (r *TypesenseRepository) CreateCollection(schema map[string]interface{}) er
 			if index, ok := field["index"].(bool); ok {
 				fieldSchema.Index = &index
 			}
-			collectionSchema.Fields = append(collectionSchema.Fields, fieldSchema)
+			if numDim, ok := field["num_dim"].(int); ok {
+				fieldSchema.NumDim = &numDim
+			}
+			if locale, ok := field["locale"].(string); ok && locale != "" {
+				fieldSchema.Locale = &locale
+			}
+			fieldSchemas = append(fieldSchemas, fieldSchema)
 		}
 	}
+	return fieldSchemas
+}
 
-	// Add default sorting field
-	if defaultSortingField, ok := schema["default_sorting_field"].(string); ok {
-		collectionSchema.DefaultSortingField = &defaultSortingField
+// migrateCollection brings an existing collection in line with the wanted
+// fields: missing fields are added and fields whose definition changed are
+// dropped and added again. Typesense reindexes the affected fields itself.
+func (r *TypesenseRepository) migrateCollection(ctx context.Context, collection *api.CollectionResponse, fields []api.Field) error {
+	existing := make(map[string]api.Field, len(collection.Fields))
+	for _, field := range collection.Fields {
+		existing[field.Name] = field
 	}
 
-	// Create the collection
-	_, err = r.client.Collections().Create(ctx, collectionSchema)
+	var changes []api.Field
+	for _, field := range fields {
+		if field.Name == "id" {
+			continue
+		}
+		current, ok := existing[field.Name]
+		if ok && sameTypesenseField(current, field) {
+			continue
+		}
+		if ok {
+			drop := true
+			changes = append(changes, api.Field{Name: field.Name, Drop: &drop})
+		}
+		changes = append(changes, field)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	err := r.execute(ctx, func() error {
+		_, err := r.client.Collection(collection.Name).Update(ctx, &api.CollectionUpdateSchema{Fields: changes})
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create collection: %w", err)
+		return fmt.Errorf("failed to update collection schema: %w", err)
 	}
 
-	log.Printf("Created collection: %s", collectionName)
+	log.Printf("Updated schema of collection %s (%d field changes)", collection.Name, len(changes))
 	return nil
 }
 
+// sameTypesenseField reports whether an existing field matches the wanted
+// definition. Unset flags compare as the Typesense defaults.
+func sameTypesenseField(current, wanted api.Field) bool {
+	flag := func(value *bool, fallback bool) bool {
+		if value == nil {
+			return fallback
+		}
+		return *value
+	}
+	numDim := func(value *int) int {
+		if value == nil {
+			return 0
+		}
+		return *value
+	}
+	locale := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+	return current.Type == wanted.Type &&
+		numDim(current.NumDim) == numDim(wanted.NumDim) &&
+		locale(current.Locale) == locale(wanted.Locale) &&
+		flag(current.Facet, false) == flag(wanted.Facet, false) &&
+		flag(current.Index, true) == flag(wanted.Index, true) &&
+		flag(current.Optional, false) == flag(wanted.Optional, false)
+}
+
 // UpsertDocument upserts a document to a collection
-func (r *TypesenseRepository) UpsertDocument(collectionName string, document interface{}) error {
-	ctx := context.Background()
+func (r *TypesenseRepository) UpsertDocument(ctx context.Context, collectionName string, document interface{}) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
 
 	// Upsert the document
-	_, err := r.client.Collection(collectionName).Documents().Upsert(ctx, document)
+	err := r.execute(ctx, func() error {
+		_, err := r.client.Collection(collectionName).Documents().Upsert(ctx, document)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to upsert document: %w", err)
 	}
@@ -130,12 +454,21 @@ func (r *TypesenseRepository) UpsertDocument(collectionName string, document int
 	return nil
 }
 
-// DeleteDocument deletes a document from a collection
-func (r *TypesenseRepository) DeleteDocument(collectionName string, documentID string) error {
-	ctx := context.Background()
+// DeleteDocument deletes a document from a collection. Deleting a missing
+// document succeeds, as with the other backends.
+func (r *TypesenseRepository) DeleteDocument(ctx context.Context, collectionName string, documentID string) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
 
 	// Delete the document
-	_, err := r.client.Collection(collectionName).Document(documentID).Delete(ctx)
+	err := r.execute(ctx, func() error {
+		_, err := r.client.Collection(collectionName).Document(documentID).Delete(ctx)
+		return err
+	})
+	var httpErr *typesense.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Status == http.StatusNotFound {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
@@ -143,17 +476,242 @@ func (r *TypesenseRepository) DeleteDocument(collectionName string, documentID s
 	return nil
 }
 
+// SyncSynonyms upserts the synonyms and deletes every other synonym of the
+// collection
+func (r *TypesenseRepository) SyncSynonyms(ctx context.Context, collectionName string, synonyms []*domain.Synonym) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	var existing []*api.SearchSynonym
+	err := r.execute(ctx, func() error {
+		var err error
+		existing, err = r.client.Collection(collectionName).Synonyms().Retrieve(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list synonyms: %w", err)
+	}
+
+	wanted := map[string]bool{}
+	for _, synonym := range synonyms {
+		wanted[synonym.IndexID()] = true
+		schema := &api.SearchSynonymSchema{Synonyms: synonym.Synonyms}
+		if synonym.Root != "" {
+			schema.Root = &synonym.Root
+		}
+		err := r.execute(ctx, func() error {
+			_, err := r.client.Collection(collectionName).Synonyms().Upsert(ctx, synonym.IndexID(), schema)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upsert synonym %s: %w", synonym.IndexID(), err)
+		}
+	}
+
+	for _, synonym := range existing {
+		if synonym.Id == nil || wanted[*synonym.Id] {
+			continue
+		}
+		err := r.execute(ctx, func() error {
+			_, err := r.client.Collection(collectionName).Synonym(*synonym.Id).Delete(ctx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete synonym %s: %w", *synonym.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// SyncCurations upserts the curations as overrides and deletes every other
+// override of the collection
+func (r *TypesenseRepository) SyncCurations(ctx context.Context, collectionName string, curations []*domain.Curation) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	var existing []*api.SearchOverride
+	err := r.execute(ctx, func() error {
+		var err error
+		existing, err = r.client.Collection(collectionName).Overrides().Retrieve(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list overrides: %w", err)
+	}
+
+	wanted := map[string]bool{}
+	for _, curation := range curations {
+		wanted[curation.IndexID()] = true
+		err := r.execute(ctx, func() error {
+			_, err := r.client.Collection(collectionName).Overrides().Upsert(ctx, curation.IndexID(), typesenseOverride(curation))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upsert override %s: %w", curation.IndexID(), err)
+		}
+	}
+
+	for _, override := range existing {
+		if override.Id == nil || wanted[*override.Id] {
+			continue
+		}
+		err := r.execute(ctx, func() error {
+			_, err := r.client.Collection(collectionName).Override(*override.Id).Delete(ctx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete override %s: %w", *override.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// typesenseOverride converts a curation to an override pinning its posts
+// from position 1 and excluding the hidden ones
+func typesenseOverride(curation *domain.Curation) *api.SearchOverrideSchema {
+	includes := make([]api.SearchOverrideInclude, len(curation.Pinned))
+	for i, id := range curation.Pinned {
+		includes[i] = api.SearchOverrideInclude{Id: strconv.Itoa(id), Position: i + 1}
+	}
+	excludes := make([]api.SearchOverrideExclude, len(curation.Hidden))
+	for i, id := range curation.Hidden {
+		excludes[i] = api.SearchOverrideExclude{Id: strconv.Itoa(id)}
+	}
+
+	return &api.SearchOverrideSchema{
+		Rule: api.SearchOverrideRule{
+			Query: curation.Query,
+			Match: api.SearchOverrideRuleMatch(curation.Match),
+		},
+		Includes: &includes,
+		Excludes: &excludes,
+	}
+}
+
+// searchKeyActions are the only actions allowed to the keys handed to browsers
+var searchKeyActions = []string{"documents:search"}
+
+// CreateSearchKey creates a search-only key for the collections
+func (r *TypesenseRepository) CreateSearchKey(ctx context.Context, description string, collections []string, expiresAt time.Time) (*domain.SearchKey, error) {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	expires := expiresAt.Unix()
+	var created *api.ApiKey
+	err := r.execute(ctx, func() error {
+		var err error
+		created, err = r.client.Keys().Create(ctx, &api.ApiKeySchema{
+			Actions:     searchKeyActions,
+			Collections: collections,
+			Description: description,
+			ExpiresAt:   &expires,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search key: %w", err)
+	}
+	if created.Value == nil {
+		return nil, fmt.Errorf("failed to create search key: no key value returned")
+	}
+	return typesenseSearchKey(created), nil
+}
+
+// ListSearchKeys lists every key of the cluster, including those not
+// created by the blog
+func (r *TypesenseRepository) ListSearchKeys(ctx context.Context) ([]*domain.SearchKey, error) {
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
+
+	var keys []*api.ApiKey
+	err := r.execute(ctx, func() error {
+		var err error
+		keys, err = r.client.Keys().Retrieve(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list search keys: %w", err)
+	}
+
+	searchKeys := make([]*domain.SearchKey, 0, len(keys))
+	for _, key := range keys {
+		searchKeys = append(searchKeys, typesenseSearchKey(key))
+	}
+	return searchKeys, nil
+}
+
+// DeleteSearchKey deletes a key. Keys that are already gone are not an error.
+func (r *TypesenseRepository) DeleteSearchKey(ctx context.Context, id int64) error {
+	ctx, cancel := withTimeout(ctx, r.config.WriteTimeout)
+	defer cancel()
+
+	err := r.execute(ctx, func() error {
+		_, err := r.client.Key(id).Delete(ctx)
+		return err
+	})
+	var httpErr *typesense.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Status == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete search key %d: %w", id, err)
+	}
+	return nil
+}
+
+// ScopeSearchKey signs the parameters with the parent key, as Typesense
+// expects of scoped search keys
+func (r *TypesenseRepository) ScopeSearchKey(parent *domain.SearchKey, params map[string]interface{}) (string, error) {
+	if len(parent.Value) < 4 {
+		return "", fmt.Errorf("search key %d has no usable value", parent.ID)
+	}
+	return r.client.Keys().GenerateScopedSearchKey(parent.Value, params)
+}
+
+// typesenseSearchKey converts a Typesense API key
+func typesenseSearchKey(key *api.ApiKey) *domain.SearchKey {
+	searchKey := &domain.SearchKey{
+		Description: key.Description,
+		Collections: key.Collections,
+	}
+	if key.Id != nil {
+		searchKey.ID = *key.Id
+	}
+	if key.Value != nil {
+		searchKey.Value = *key.Value
+	}
+	if key.ExpiresAt != nil {
+		searchKey.ExpiresAt = time.Unix(*key.ExpiresAt, 0).UTC()
+	}
+	return searchKey
+}
+
 // SearchDocuments searches for documents in a collection
-func (r *TypesenseRepository) SearchDocuments(collectionName, query string, searchParams map[string]interface{}) ([]interface{}, error) {
-	ctx := context.Background()
+func (r *TypesenseRepository) SearchDocuments(ctx context.Context, collectionName, query string, searchParams map[string]interface{}) (*domain.SearchResults, error) {
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
 
-	// Prepare search parameters
+	// Prepare search parameters; embeddings are only used for ranking
+	excludeFields := domain.EmbeddingField
 	searchParameters := &api.SearchCollectionParams{
-		Q: query,
+		Q:             &query,
+		ExcludeFields: &excludeFields,
 	}
 
 	// Add additional search parameters if provided
-	if filterBy, ok := searchParams["filter_by"].(string); ok {
+	filterBy, err := typesenseFilterBy(searchFilters(searchParams))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	if raw, ok := searchParams["filter_by"].(string); ok && raw != "" {
+		if filterBy != "" {
+			raw += " && " + filterBy
+		}
+		filterBy = raw
+	}
+	if filterBy != "" {
 		searchParameters.FilterBy = &filterBy
 	}
 
@@ -162,54 +720,213 @@ func (r *TypesenseRepository) SearchDocuments(collectionName, query string, sear
 	}
 
 	if queryBy, ok := searchParams["query_by"].(string); ok {
-		searchParameters.QueryBy = queryBy
+		searchParameters.QueryBy = &queryBy
 	}
 
-	// Add pagination parameters
-	if page, ok := searchParams["page"].(int); ok {
-		searchParameters.Page = &page
+	if facetBy, ok := searchParams["facet_by"].(string); ok && facetBy != "" {
+		searchParameters.FacetBy = &facetBy
 	}
 
-	if perPage, ok := searchParams["per_page"].(int); ok {
-		searchParameters.PerPage = &perPage
+	if prefix, ok := searchParams["prefix"].(string); ok {
+		searchParameters.Prefix = &prefix
 	}
 
-	// Perform the search
-	searchResult, err := r.client.Collection(collectionName).Documents().Search(ctx, searchParameters)
+	if numTypos, ok := searchParams["num_typos"].(string); ok {
+		searchParameters.NumTypos = &numTypos
+	}
+
+	// Add pagination parameters
+	page, perPage := searchPage(searchParams)
+	searchParameters.Page = &page
+	searchParameters.PerPage = &perPage
+
+	// A query vector makes the search hybrid, or a nearest neighbour search
+	// for the query "*". It is too long for a query string, so the search is
+	// sent in a multi-search request body.
+	var searchResult *api.SearchResult
+	if vector, ok := searchParams["vector"].([]float32); ok && len(vector) > 0 {
+		alpha, _ := searchParams["alpha"].(float64)
+		searchResult, err = r.hybridSearch(ctx, collectionName, searchParameters, typesenseVectorQuery(vector, alpha, query != "*"))
+	} else {
+		err = r.execute(ctx, func() error {
+			var err error
+			searchResult, err = r.client.Collection(collectionName).Documents().Search(ctx, searchParameters)
+			return err
+		})
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to search documents: %w", err)
 	}
 
-	// Convert results to interface slice
-	var results []interface{}
+	results := &domain.SearchResults{
+		Hits:    []*domain.SearchHit{},
+		Page:    page,
+		PerPage: perPage,
+	}
+	if searchResult.Found != nil {
+		results.Found = *searchResult.Found
+	}
+	if searchResult.OutOf != nil {
+		results.OutOf = *searchResult.OutOf
+	}
+	if searchResult.SearchTimeMs != nil {
+		results.SearchTimeMS = *searchResult.SearchTimeMs
+	}
 	if searchResult.Hits != nil {
 		for _, hit := range *searchResult.Hits {
-			// Return the raw document with additional metadata
-			document := *hit.Document
-			document["_text_match"] = hit.TextMatch
-
-			results = append(results, document)
+			if hit.Document == nil {
+				continue
+			}
+			result := &domain.SearchHit{Document: *hit.Document}
+			if hit.TextMatch != nil {
+				result.Score = float64(*hit.TextMatch)
+			}
+			if highlights := typesenseHighlights(hit.Highlights); len(highlights) > 0 {
+				result.Highlights = highlights
+			}
+			results.Hits = append(results.Hits, result)
 		}
 	}
+	if searchResult.FacetCounts != nil {
+		results.Facets = typesenseFacets(*searchResult.FacetCounts)
+	}
 
 	return results, nil
 }
 
-// GetAllDocuments retrieves all documents from a collection
-func (r *TypesenseRepository) GetAllDocuments(collectionName string) ([]interface{}, error) {
-	ctx := context.Background()
+// typesenseVectorQuery builds the vector_query of a search. In a hybrid
+// search alpha is the weight of the vector ranking.
+func typesenseVectorQuery(vector []float32, alpha float64, hybrid bool) string {
+	values := make([]string, len(vector))
+	for i, value := range vector {
+		values[i] = strconv.FormatFloat(float64(value), 'f', -1, 32)
+	}
+	if !hybrid {
+		return fmt.Sprintf("%s:([%s])", domain.EmbeddingField, strings.Join(values, ","))
+	}
+	return fmt.Sprintf("%s:([%s], alpha: %s)", domain.EmbeddingField, strings.Join(values, ","), strconv.FormatFloat(alpha, 'f', -1, 64))
+}
 
-	// Export all documents from the collection
-	exportReader, err := r.client.Collection(collectionName).Documents().Export(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to export documents: %w", err)
+// hybridSearch runs a search with a vector query through the multi-search
+// endpoint. Errors of the search itself come back inside the response body.
+func (r *TypesenseRepository) hybridSearch(ctx context.Context, collectionName string, params *api.SearchCollectionParams, vectorQuery string) (*api.SearchResult, error) {
+	searches := api.MultiSearchSearchesParameter{
+		Searches: []api.MultiSearchCollectionParameters{{
+			Collection:    collectionName,
+			Q:             params.Q,
+			QueryBy:       params.QueryBy,
+			FilterBy:      params.FilterBy,
+			SortBy:        params.SortBy,
+			FacetBy:       params.FacetBy,
+			Prefix:        params.Prefix,
+			NumTypos:      params.NumTypos,
+			Page:          params.Page,
+			PerPage:       params.PerPage,
+			ExcludeFields: params.ExcludeFields,
+			VectorQuery:   &vectorQuery,
+		}},
 	}
-	defer exportReader.Close()
 
-	// Read the exported data
-	exportData, err := io.ReadAll(exportReader)
+	var searchResult *api.SearchResult
+	err := r.execute(ctx, func() error {
+		response, err := r.client.MultiSearch.PerformWithContentType(ctx, &api.MultiSearchParams{}, searches, "application/json")
+		if err != nil {
+			return err
+		}
+		if response.StatusCode() != http.StatusOK {
+			return &typesense.HTTPError{Status: response.StatusCode(), Body: response.Body}
+		}
+
+		var multi struct {
+			Results []struct {
+				api.SearchResult
+				Code  int    `json:"code"`
+				Error string `json:"error"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(response.Body, &multi); err != nil {
+			return fmt.Errorf("failed to decode multi-search response: %w", err)
+		}
+		if len(multi.Results) != 1 {
+			return fmt.Errorf("expected 1 multi-search result, got %d", len(multi.Results))
+		}
+		if result := multi.Results[0]; result.Error != "" {
+			return &typesense.HTTPError{Status: result.Code, Body: []byte(result.Error)}
+		}
+		searchResult = &multi.Results[0].SearchResult
+		return nil
+	})
+	return searchResult, err
+}
+
+// typesenseHighlights converts hit highlights into the field to snippets map
+// the search service expects
+func typesenseHighlights(hitHighlights *[]api.SearchHighlight) map[string][]string {
+	highlights := map[string][]string{}
+	if hitHighlights == nil {
+		return highlights
+	}
+
+	for _, highlight := range *hitHighlights {
+		if highlight.Field == nil {
+			continue
+		}
+
+		var snippets []string
+		if highlight.Snippet != nil {
+			snippets = append(snippets, *highlight.Snippet)
+		}
+		if highlight.Snippets != nil {
+			snippets = append(snippets, *highlight.Snippets...)
+		}
+		if len(snippets) > 0 {
+			highlights[*highlight.Field] = snippets
+		}
+	}
+	return highlights
+}
+
+// typesenseFacets converts facet counts into the domain shape
+func typesenseFacets(facetCounts []api.FacetCounts) []domain.FacetCounts {
+	facets := make([]domain.FacetCounts, 0, len(facetCounts))
+	for _, facet := range facetCounts {
+		if facet.FieldName == nil {
+			continue
+		}
+
+		counts := domain.FacetCounts{Field: *facet.FieldName, Counts: []domain.FacetValueCount{}}
+		if facet.Counts != nil {
+			for _, count := range *facet.Counts {
+				if count.Value == nil || count.Count == nil {
+					continue
+				}
+				counts.Counts = append(counts.Counts, domain.FacetValueCount{Value: *count.Value, Count: *count.Count})
+			}
+		}
+		facets = append(facets, counts)
+	}
+	return facets
+}
+
+// GetAllDocuments retrieves all documents from a collection
+func (r *TypesenseRepository) GetAllDocuments(ctx context.Context, collectionName string) ([]interface{}, error) {
+	ctx, cancel := withTimeout(ctx, r.config.ReadTimeout)
+	defer cancel()
+
+	// Export all documents from the collection and read the exported data
+	var exportData []byte
+	err := r.execute(ctx, func() error {
+		exportReader, err := r.client.Collection(collectionName).Documents().Export(ctx)
+		if err != nil {
+			return err
+		}
+		defer exportReader.Close()
+
+		exportData, err = io.ReadAll(exportReader)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read exported documents: %w", err)
+		return nil, fmt.Errorf("failed to export documents: %w", err)
 	}
 
 	// Parse the JSONL format (each line is a JSON document)
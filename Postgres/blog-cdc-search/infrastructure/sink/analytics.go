@@ -0,0 +1,108 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// AnalyticsRecord is one line of the analytics log
+type AnalyticsRecord struct {
+	Time          time.Time `json:"time"`
+	Position      int64     `json:"position"`
+	Database      string    `json:"database"`
+	Table         string    `json:"table"`
+	EventType     string    `json:"event_type"`
+	Op            string    `json:"op"`
+	ID            string    `json:"id"`
+	ChangedFields []string  `json:"changed_fields,omitempty"`
+}
+
+// AnalyticsLogSink appends a JSON line per change to a file or stdout
+type AnalyticsLogSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	now    func() time.Time
+}
+
+// NewAnalyticsLogSink opens path for appending; "-" writes to stdout
+func NewAnalyticsLogSink(path string) (*AnalyticsLogSink, error) {
+	if path == "-" {
+		return &AnalyticsLogSink{w: os.Stdout, now: time.Now}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create analytics log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analytics log: %w", err)
+	}
+	return &AnalyticsLogSink{w: file, closer: file, now: time.Now}, nil
+}
+
+// Name returns the sink name used in stats and checkpoints
+func (s *AnalyticsLogSink) Name() string {
+	return "analytics"
+}
+
+// Apply writes the change as one JSON line
+func (s *AnalyticsLogSink) Apply(ctx context.Context, change *domain.Change) error {
+	record := AnalyticsRecord{
+		Time:     s.now().UTC(),
+		Position: change.Position(),
+		Op:       change.Op,
+		ID:       change.ID,
+	}
+	if event := change.Event; event != nil {
+		record.Database = event.Database
+		record.Table = event.Table
+		record.EventType = event.Type
+		if event.Type == domain.EventTypeUpdate {
+			record.ChangedFields = changedFields(event.Old, event.Data)
+		}
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode analytics record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write analytics record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file
+func (s *AnalyticsLogSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// changedFields lists the fields whose value differs between old and current.
+// Maxwell only puts changed fields in old, Debezium sends the full row.
+func changedFields(old, current map[string]interface{}) []string {
+	var fields []string
+	for field, before := range old {
+		if after, ok := current[field]; !ok || !reflect.DeepEqual(before, after) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
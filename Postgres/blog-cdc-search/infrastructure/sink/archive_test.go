@@ -0,0 +1,186 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+// tsAt converts a time to the source timestamp unit of this module's events
+func tsAt(t time.Time) int64 {
+	unit := (&domain.CDCEvent{TS: 1}).Time().Sub(time.Unix(0, 0))
+	return int64(t.Sub(time.Unix(0, 0)) / unit)
+}
+
+func archiveEvent(id int, title string, at time.Time) *domain.CDCEvent {
+	return &domain.CDCEvent{
+		Database: "blog",
+		Table:    "posts",
+		Type:     domain.EventTypeUpdate,
+		Data:     map[string]interface{}{"id": float64(id), "title": title},
+		Old:      map[string]interface{}{"title": "Before"},
+		TS:       tsAt(at),
+	}
+}
+
+func readAll(t *testing.T, dir string) []*domain.CDCEvent {
+	t.Helper()
+	reader, err := OpenArchive(dir)
+	if err != nil {
+		t.Fatalf("OpenArchive failed: %v", err)
+	}
+	var events []*domain.CDCEvent
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		events = append(events, event)
+	}
+}
+
+func TestArchiveSink_RotatesAndPartitions(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewArchiveSink(ArchiveConfig{Dir: dir, Compress: true, MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	now := time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	day1 := time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 2, 1, 0, 30, 0, 0, time.UTC)
+	events := []*domain.CDCEvent{
+		archiveEvent(1, "One", day1),
+		archiveEvent(2, "Two", day1),
+		archiveEvent(3, "Three", day2), // new date partition
+		archiveEvent(4, "Four", day2),  // segment too old by then
+	}
+	for i, event := range events {
+		if i == 3 {
+			now = now.Add(2 * time.Hour)
+		}
+		if err := s.Apply(context.Background(), &domain.Change{Event: event}); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	manifest, err := readArchiveManifest(dir)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if len(manifest.Segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %d", len(manifest.Segments))
+	}
+	wantEvents := []int{2, 1, 1}
+	for i, segment := range manifest.Segments {
+		if segment.ClosedAt == nil || segment.Events != wantEvents[i] {
+			t.Errorf("Segment %d: expected %d events and sealed, got %+v", i, wantEvents[i], segment)
+		}
+		if !strings.HasPrefix(segment.Path, filepath.Join("table=posts", "date="+segment.Date)) || !strings.HasSuffix(segment.Path, ".jsonl.gz") {
+			t.Errorf("Unexpected segment path %s", segment.Path)
+		}
+	}
+	if manifest.Segments[0].Date != "2025-01-31" || manifest.Segments[1].Date != "2025-02-01" {
+		t.Errorf("Expected date partitions from the event times, got %s and %s", manifest.Segments[0].Date, manifest.Segments[1].Date)
+	}
+
+	if got := readAll(t, dir); !reflect.DeepEqual(got, events) {
+		t.Errorf("Expected the archived events back in order, got %+v", got)
+	}
+}
+
+func TestArchiveSink_Parquet(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewArchiveSink(ArchiveConfig{Dir: dir, Format: ArchiveFormatParquet, MaxBytes: 1})
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []*domain.CDCEvent{archiveEvent(1, "One", at), archiveEvent(2, "Two", at)}
+	events[1].Old = nil
+	for _, event := range events {
+		if err := s.Apply(context.Background(), &domain.Change{Event: event}); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	manifest, _ := readArchiveManifest(dir)
+	if len(manifest.Segments) != 2 {
+		t.Fatalf("Expected a segment per event with MaxBytes 1, got %d", len(manifest.Segments))
+	}
+	for _, segment := range manifest.Segments {
+		if segment.Format != ArchiveFormatParquet || !strings.HasSuffix(segment.Path, ".parquet") {
+			t.Errorf("Expected a parquet segment, got %+v", segment)
+		}
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*.jsonl*")); len(matches) != 0 {
+		t.Errorf("Expected converted JSONL segments to be removed, got %v", matches)
+	}
+
+	if got := readAll(t, dir); !reflect.DeepEqual(got, events) {
+		t.Errorf("Expected the archived events back, got %+v", got)
+	}
+}
+
+func TestArchiveSink_RecoversOpenSegment(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewArchiveSink(ArchiveConfig{Dir: dir, Compress: true})
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []*domain.CDCEvent{archiveEvent(1, "One", at), archiveEvent(2, "Two", at)}
+	for _, event := range events {
+		if err := s.Apply(context.Background(), &domain.Change{Event: event}); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+	}
+
+	// The process dies mid-write: the gzip stream is never finished
+	writer := s.open["posts"]
+	writer.file.Write([]byte{0x1f, 0x8b, 0x00})
+	writer.file.Close()
+
+	// Events flushed before the crash can already be read
+	if got := readAll(t, dir); !reflect.DeepEqual(got, events) {
+		t.Errorf("Expected the flushed events from the open segment, got %+v", got)
+	}
+
+	s, err = NewArchiveSink(ArchiveConfig{Dir: dir, Compress: true})
+	if err != nil {
+		t.Fatalf("Failed to reopen archive: %v", err)
+	}
+	defer s.Close()
+
+	manifest, _ := readArchiveManifest(dir)
+	segment := manifest.Segments[0]
+	if segment.ClosedAt == nil || segment.Events != 2 {
+		t.Errorf("Expected the segment to be sealed with 2 events, got %+v", segment)
+	}
+	if got := readAll(t, dir); !reflect.DeepEqual(got, events) {
+		t.Errorf("Expected both events after recovery, got %+v", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, segment.Path+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("Expected no leftover temporary file, got %v", err)
+	}
+}
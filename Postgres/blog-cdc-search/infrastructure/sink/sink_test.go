@@ -0,0 +1,117 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"blog-cdc-search/domain"
+)
+
+func TestAnalyticsLogSink_Apply(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := &AnalyticsLogSink{w: &buf, now: func() time.Time { return now }}
+
+	change := &domain.Change{
+		Op: domain.ChangeOpUpsert,
+		ID: "1",
+		Event: &domain.CDCEvent{
+			Database: "blog",
+			Table:    "posts",
+			Type:     domain.EventTypeUpdate,
+			Data:     map[string]interface{}{"id": 1.0, "title": "New", "body": "Same"},
+			Old:      map[string]interface{}{"id": 1.0, "title": "Old", "body": "Same"},
+			TS:       42,
+		},
+	}
+	if err := s.Apply(context.Background(), change); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := s.Apply(context.Background(), &domain.Change{Op: domain.ChangeOpDelete, ID: "2"}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+
+	var record AnalyticsRecord
+	if err := json.Unmarshal(lines[0], &record); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+	expected := AnalyticsRecord{
+		Time:          now,
+		Position:      42,
+		Database:      "blog",
+		Table:         "posts",
+		EventType:     domain.EventTypeUpdate,
+		Op:            domain.ChangeOpUpsert,
+		ID:            "1",
+		ChangedFields: []string{"title"},
+	}
+	if !reflect.DeepEqual(record, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, record)
+	}
+}
+
+func TestNewAnalyticsLogSink_AppendsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "analytics.jsonl")
+
+	for i := 0; i < 2; i++ {
+		s, err := NewAnalyticsLogSink(path)
+		if err != nil {
+			t.Fatalf("Failed to open sink: %v", err)
+		}
+		if err := s.Apply(context.Background(), &domain.Change{Op: domain.ChangeOpDelete, ID: "1"}); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	if lines := bytes.Count(content, []byte("\n")); lines != 2 {
+		t.Errorf("Expected 2 appended lines, got %d", lines)
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	store, err := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	checkpoint, err := store.Load(ctx, "search")
+	if err != nil || checkpoint != nil {
+		t.Fatalf("Expected no checkpoint, got %+v, %v", checkpoint, err)
+	}
+
+	saved := &domain.SinkCheckpoint{Sink: "search", Position: 10, Delivered: 3, Failed: 1, UpdatedAt: time.Unix(100, 0).UTC()}
+	if err := store.Save(ctx, saved); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved.Position = 20
+	if err := store.Save(ctx, saved); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	checkpoint, err = store.Load(ctx, "search")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(checkpoint, saved) {
+		t.Errorf("Expected %+v, got %+v", saved, checkpoint)
+	}
+}
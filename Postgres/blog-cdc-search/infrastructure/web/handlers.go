@@ -13,16 +13,28 @@ type Handlers struct {
 	Web       *handlers.WebHandlers
 	Dashboard *handlers.DashboardHandlers
 	Search    *handlers.SearchHandlers
+	Webhooks  *handlers.WebhookHandlers
+	History   *handlers.HistoryHandlers
+	Tuning    *handlers.SearchTuningHandlers
+	Analytics *handlers.SearchAnalyticsHandlers
+	Keys      *handlers.SearchKeyHandlers
 }
 
 // NewHandlers creates a new Handlers instance
-func NewHandlers(postService *service.PostService, searchService handlers.SearchService) *Handlers {
+func NewHandlers(postService *service.PostService, searchService handlers.SearchService, webhookService handlers.WebhookService, historyService handlers.HistoryService, tuningService handlers.SearchTuningService, analyticsService handlers.SearchAnalyticsService, keyService handlers.SearchKeyService) *Handlers {
 	base := handlers.NewBaseHandler(postService, searchService)
+	search := handlers.NewSearchHandlers(base)
+	search.Analytics = analyticsService
 	return &Handlers{
 		API:       handlers.NewAPIHandlers(base),
 		Web:       handlers.NewWebHandlers(base),
 		Dashboard: handlers.NewDashboardHandlers(base),
-		Search:    handlers.NewSearchHandlers(base),
+		Search:    search,
+		Webhooks:  handlers.NewWebhookHandlers(webhookService),
+		History:   handlers.NewHistoryHandlers(historyService),
+		Tuning:    handlers.NewSearchTuningHandlers(tuningService),
+		Analytics: handlers.NewSearchAnalyticsHandlers(analyticsService),
+		Keys:      handlers.NewSearchKeyHandlers(keyService),
 	}
 }
 
@@ -76,3 +88,104 @@ func (h *Handlers) SearchPosts(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) SearchPostsGet(w http.ResponseWriter, r *http.Request) {
 	h.Search.SearchPostsGet(w, r)
 }
+
+func (h *Handlers) SuggestPosts(w http.ResponseWriter, r *http.Request) {
+	h.Search.SuggestPosts(w, r)
+}
+
+func (h *Handlers) RelatedPosts(w http.ResponseWriter, r *http.Request) {
+	h.Search.RelatedPosts(w, r)
+}
+
+// Webhook methods
+func (h *Handlers) ServeWebhooks(w http.ResponseWriter, r *http.Request) {
+	h.Webhooks.ServeWebhooks(w, r)
+}
+
+func (h *Handlers) ListWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	h.Webhooks.ListEndpoints(w, r)
+}
+
+func (h *Handlers) CreateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	h.Webhooks.CreateEndpoint(w, r)
+}
+
+func (h *Handlers) DeleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	h.Webhooks.DeleteEndpoint(w, r)
+}
+
+func (h *Handlers) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	h.Webhooks.ListDeliveries(w, r)
+}
+
+func (h *Handlers) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	h.Webhooks.Redeliver(w, r)
+}
+
+// History methods
+func (h *Handlers) ServePostHistory(w http.ResponseWriter, r *http.Request) {
+	h.History.ServeHistory(w, r)
+}
+
+func (h *Handlers) ListPostVersions(w http.ResponseWriter, r *http.Request) {
+	h.History.ListVersions(w, r)
+}
+
+func (h *Handlers) GetPostVersion(w http.ResponseWriter, r *http.Request) {
+	h.History.GetVersion(w, r)
+}
+
+func (h *Handlers) RestorePostVersion(w http.ResponseWriter, r *http.Request) {
+	h.History.RestoreVersion(w, r)
+}
+
+// Search tuning methods
+func (h *Handlers) ServeSearchTuning(w http.ResponseWriter, r *http.Request) {
+	h.Tuning.ServeSearchTuning(w, r)
+}
+
+func (h *Handlers) ListSearchSynonyms(w http.ResponseWriter, r *http.Request) {
+	h.Tuning.ListSynonyms(w, r)
+}
+
+func (h *Handlers) CreateSearchSynonym(w http.ResponseWriter, r *http.Request) {
+	h.Tuning.CreateSynonym(w, r)
+}
+
+func (h *Handlers) DeleteSearchSynonym(w http.ResponseWriter, r *http.Request) {
+	h.Tuning.DeleteSynonym(w, r)
+}
+
+func (h *Handlers) ListSearchCurations(w http.ResponseWriter, r *http.Request) {
+	h.Tuning.ListCurations(w, r)
+}
+
+func (h *Handlers) CreateSearchCuration(w http.ResponseWriter, r *http.Request) {
+	h.Tuning.CreateCuration(w, r)
+}
+
+func (h *Handlers) DeleteSearchCuration(w http.ResponseWriter, r *http.Request) {
+	h.Tuning.DeleteCuration(w, r)
+}
+
+func (h *Handlers) SyncSearchTuning(w http.ResponseWriter, r *http.Request) {
+	h.Tuning.Sync(w, r)
+}
+
+// Search analytics methods
+func (h *Handlers) RecordSearchClick(w http.ResponseWriter, r *http.Request) {
+	h.Analytics.RecordClick(w, r)
+}
+
+func (h *Handlers) ServeSearchAnalytics(w http.ResponseWriter, r *http.Request) {
+	h.Analytics.ServeSearchAnalytics(w, r)
+}
+
+func (h *Handlers) GetSearchAnalytics(w http.ResponseWriter, r *http.Request) {
+	h.Analytics.GetReport(w, r)
+}
+
+// Search key methods
+func (h *Handlers) GetSearchKey(w http.ResponseWriter, r *http.Request) {
+	h.Keys.GetSearchKey(w, r)
+}
@@ -24,10 +24,14 @@ func (h *APIHandlers) CreatePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Title   string `json:"title"`
-		Image   string `json:"image"`
-		Excerpt string `json:"excerpt"`
-		Body    string `json:"body"`
+		Title    string   `json:"title"`
+		Image    string   `json:"image"`
+		Excerpt  string   `json:"excerpt"`
+		Body     string   `json:"body"`
+		Author   string   `json:"author"`
+		Category string   `json:"category"`
+		Language string   `json:"language"`
+		Tags     []string `json:"tags"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -35,7 +39,7 @@ func (h *APIHandlers) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := h.PostService.CreatePost(r.Context(), req.Title, req.Image, req.Excerpt, req.Body)
+	post, err := h.PostService.CreatePost(r.Context(), req.Title, req.Image, req.Excerpt, req.Body, req.Author, req.Category, req.Language, req.Tags)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -112,10 +116,14 @@ func (h *APIHandlers) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Title   string `json:"title"`
-		Image   string `json:"image"`
-		Excerpt string `json:"excerpt"`
-		Body    string `json:"body"`
+		Title    string   `json:"title"`
+		Image    string   `json:"image"`
+		Excerpt  string   `json:"excerpt"`
+		Body     string   `json:"body"`
+		Author   string   `json:"author"`
+		Category string   `json:"category"`
+		Language string   `json:"language"`
+		Tags     []string `json:"tags"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -123,7 +131,7 @@ func (h *APIHandlers) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := h.PostService.UpdatePost(r.Context(), id, req.Title, req.Image, req.Excerpt, req.Body)
+	post, err := h.PostService.UpdatePost(r.Context(), id, req.Title, req.Image, req.Excerpt, req.Body, req.Author, req.Category, req.Language, req.Tags)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
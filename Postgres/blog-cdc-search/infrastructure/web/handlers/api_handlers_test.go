@@ -25,7 +25,7 @@ func NewMockPostService() *MockPostService {
 	}
 }
 
-func (m *MockPostService) CreatePost(ctx context.Context, title, image, excerpt, body string) (*domain.Post, error) {
+func (m *MockPostService) CreatePost(ctx context.Context, title, image, excerpt, body, author, category, language string, tags []string) (*domain.Post, error) {
 	post := &domain.Post{
 		ID:        m.nextID,
 		Title:     title,
@@ -35,6 +35,11 @@ func (m *MockPostService) CreatePost(ctx context.Context, title, image, excerpt,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	post.SetAuthor(author)
+	post.SetTaxonomy(category, tags)
+	if err := post.SetLanguage(language); err != nil {
+		return nil, err
+	}
 	m.posts[post.ID] = post
 	m.nextID++
 	return post, nil
@@ -56,7 +61,7 @@ func (m *MockPostService) GetAllPosts(ctx context.Context) ([]*domain.Post, erro
 	return posts, nil
 }
 
-func (m *MockPostService) UpdatePost(ctx context.Context, id int, title, image, excerpt, body string) (*domain.Post, error) {
+func (m *MockPostService) UpdatePost(ctx context.Context, id int, title, image, excerpt, body, author, category, language string, tags []string) (*domain.Post, error) {
 	post, exists := m.posts[id]
 	if !exists {
 		return nil, domain.ErrPostNotFound
@@ -66,6 +71,11 @@ func (m *MockPostService) UpdatePost(ctx context.Context, id int, title, image,
 	post.Image = image
 	post.Excerpt = excerpt
 	post.Body = body
+	post.SetAuthor(author)
+	post.SetTaxonomy(category, tags)
+	if err := post.SetLanguage(language); err != nil {
+		return nil, err
+	}
 	post.UpdatedAt = time.Now()
 
 	return post, nil
@@ -147,7 +157,7 @@ func TestAPIHandlers_GetPost(t *testing.T) {
 	handler := NewAPIHandlers(base)
 
 	// Create a test post
-	post, _ := mockService.CreatePost(context.Background(), "Test Title", "image.jpg", "excerpt", "body")
+	post, _ := mockService.CreatePost(context.Background(), "Test Title", "image.jpg", "excerpt", "body", "", "", "", nil)
 
 	tests := []struct {
 		name           string
@@ -213,8 +223,8 @@ func TestAPIHandlers_GetAllPosts(t *testing.T) {
 	handler := NewAPIHandlers(base)
 
 	// Create test posts
-	mockService.CreatePost(context.Background(), "Post 1", "image1.jpg", "excerpt1", "body1")
-	mockService.CreatePost(context.Background(), "Post 2", "image2.jpg", "excerpt2", "body2")
+	mockService.CreatePost(context.Background(), "Post 1", "image1.jpg", "excerpt1", "body1", "", "", "", nil)
+	mockService.CreatePost(context.Background(), "Post 2", "image2.jpg", "excerpt2", "body2", "", "", "", nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/posts", nil)
 	recorder := httptest.NewRecorder()
@@ -242,7 +252,7 @@ func TestAPIHandlers_UpdatePost(t *testing.T) {
 	handler := NewAPIHandlers(base)
 
 	// Create a test post
-	mockService.CreatePost(context.Background(), "Original Title", "image.jpg", "excerpt", "body")
+	mockService.CreatePost(context.Background(), "Original Title", "image.jpg", "excerpt", "body", "", "", "", nil)
 
 	tests := []struct {
 		name           string
@@ -311,7 +321,7 @@ func TestAPIHandlers_DeletePost(t *testing.T) {
 	handler := NewAPIHandlers(base)
 
 	// Create a test post
-	mockService.CreatePost(context.Background(), "Test Title", "image.jpg", "excerpt", "body")
+	mockService.CreatePost(context.Background(), "Test Title", "image.jpg", "excerpt", "body", "", "", "", nil)
 
 	tests := []struct {
 		name           string
@@ -350,3 +360,25 @@ func TestAPIHandlers_DeletePost(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIHandlers_CreatePost_Metadata(t *testing.T) {
+	mockService := NewMockPostService()
+	handler := NewAPIHandlers(&BaseHandler{PostService: mockService})
+
+	requestBody := `{"title":"Title","body":"Body","author":" Ada ","category":"Tutorials","tags":["Go","go","CDC"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/posts", bytes.NewBufferString(requestBody))
+	recorder := httptest.NewRecorder()
+	handler.CreatePost(recorder, req)
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, recorder.Code)
+	}
+
+	var post domain.Post
+	if err := json.NewDecoder(recorder.Body).Decode(&post); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if post.Author != "Ada" || post.Category != "Tutorials" || len(post.Tags) != 2 || post.Tags[1] != "cdc" {
+		t.Errorf("expected author, category and normalized tags, got %+v", post)
+	}
+}
@@ -7,17 +7,19 @@ import (
 
 // PostService interface for mocking in tests
 type PostService interface {
-	CreatePost(ctx context.Context, title, image, excerpt, body string) (*domain.Post, error)
+	CreatePost(ctx context.Context, title, image, excerpt, body, author, category, language string, tags []string) (*domain.Post, error)
 	GetPost(ctx context.Context, id int) (*domain.Post, error)
 	GetAllPosts(ctx context.Context) ([]*domain.Post, error)
-	UpdatePost(ctx context.Context, id int, title, image, excerpt, body string) (*domain.Post, error)
+	UpdatePost(ctx context.Context, id int, title, image, excerpt, body, author, category, language string, tags []string) (*domain.Post, error)
 	DeletePost(ctx context.Context, id int) error
 }
 
 // SearchService interface for mocking in tests
 type SearchService interface {
 	SearchPosts(ctx context.Context, params interface{}) (interface{}, error)
+	SuggestPosts(ctx context.Context, query string) (interface{}, error)
 	GetAllPostsFromIndex(ctx context.Context) ([]*domain.Post, error)
+	RelatedPosts(ctx context.Context, post *domain.Post) ([]*domain.Post, error)
 }
 
 // BaseHandler contains common dependencies
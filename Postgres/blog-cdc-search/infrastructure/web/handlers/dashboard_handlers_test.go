@@ -14,8 +14,8 @@ func TestDashboardHandlers_ServeDashboard(t *testing.T) {
 	handler := NewDashboardHandlers(base)
 
 	// Create test posts
-	mockService.CreatePost(context.Background(), "Admin Post 1", "image1.jpg", "excerpt1", "body1")
-	mockService.CreatePost(context.Background(), "Admin Post 2", "image2.jpg", "excerpt2", "body2")
+	mockService.CreatePost(context.Background(), "Admin Post 1", "image1.jpg", "excerpt1", "body1", "", "", "", nil)
+	mockService.CreatePost(context.Background(), "Admin Post 2", "image2.jpg", "excerpt2", "body2", "", "", "", nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
 	recorder := httptest.NewRecorder()
@@ -143,7 +143,7 @@ func TestDashboardHandlers_ServeEditForm(t *testing.T) {
 	handler := NewDashboardHandlers(base)
 
 	// Create a test post
-	post, _ := mockService.CreatePost(context.Background(), "Edit Test Post", "edit_image.jpg", "Edit excerpt", "Edit body content")
+	post, _ := mockService.CreatePost(context.Background(), "Edit Test Post", "edit_image.jpg", "Edit excerpt", "Edit body content", "", "", "", nil)
 
 	tests := []struct {
 		name           string
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"blog-cdc-search/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// HistoryService interface for mocking in tests
+type HistoryService interface {
+	ListVersions(ctx context.Context, postID int) ([]*domain.PostVersion, error)
+	GetVersion(ctx context.Context, postID, version int) (*domain.PostVersionDiff, error)
+	RestoreVersion(ctx context.Context, postID, version int) (*domain.Post, error)
+}
+
+// HistoryHandlers handles the post version history and restores
+type HistoryHandlers struct {
+	HistoryService HistoryService
+}
+
+// NewHistoryHandlers creates a new history handlers instance
+func NewHistoryHandlers(historyService HistoryService) *HistoryHandlers {
+	return &HistoryHandlers{HistoryService: historyService}
+}
+
+// ServeHistory serves the dashboard page with a post's versions and the diff
+// of the selected version, the newest one by default
+func (h *HistoryHandlers) ServeHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.HistoryService.ListVersions(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	version := 0
+	if versionParam := r.URL.Query().Get("version"); versionParam != "" {
+		if version, err = strconv.Atoi(versionParam); err != nil {
+			http.Error(w, "Invalid version", http.StatusBadRequest)
+			return
+		}
+	} else if len(versions) > 0 {
+		version = versions[0].Version
+	}
+
+	var selected *domain.PostVersionDiff
+	if version > 0 {
+		selected, err = h.HistoryService.GetVersion(r.Context(), id, version)
+		if err != nil {
+			writeHistoryError(w, err)
+			return
+		}
+	}
+
+	html := generatePostHistoryHTML(id, versions, selected)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(html))
+}
+
+// ListVersions handles GET /api/posts/{id}/history
+func (h *HistoryHandlers) ListVersions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.HistoryService.ListVersions(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if versions == nil {
+		versions = []*domain.PostVersion{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// GetVersion handles GET /api/posts/{id}/history/{version}
+func (h *HistoryHandlers) GetVersion(w http.ResponseWriter, r *http.Request) {
+	id, version, ok := postVersionVars(w, r)
+	if !ok {
+		return
+	}
+
+	diff, err := h.HistoryService.GetVersion(r.Context(), id, version)
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// RestoreVersion handles POST /api/posts/{id}/history/{version}/restore
+func (h *HistoryHandlers) RestoreVersion(w http.ResponseWriter, r *http.Request) {
+	id, version, ok := postVersionVars(w, r)
+	if !ok {
+		return
+	}
+
+	post, err := h.HistoryService.RestoreVersion(r.Context(), id, version)
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+func postVersionVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return id, version, true
+}
+
+func writeHistoryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrPostVersionNotFound) || errors.Is(err, domain.ErrPostNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
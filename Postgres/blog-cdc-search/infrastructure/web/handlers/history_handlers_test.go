@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"blog-cdc-search/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// mockHistoryService serves fixed versions and records restores
+type mockHistoryService struct {
+	versions []*domain.PostVersion // oldest first
+	restored []int
+}
+
+func (m *mockHistoryService) ListVersions(ctx context.Context, postID int) ([]*domain.PostVersion, error) {
+	var versions []*domain.PostVersion
+	for i := len(m.versions) - 1; i >= 0; i-- {
+		versions = append(versions, m.versions[i])
+	}
+	return versions, nil
+}
+
+func (m *mockHistoryService) GetVersion(ctx context.Context, postID, version int) (*domain.PostVersionDiff, error) {
+	if version < 1 || version > len(m.versions) {
+		return nil, domain.ErrPostVersionNotFound
+	}
+	current := m.versions[version-1]
+	var previous *domain.PostVersion
+	if version > 1 {
+		previous = m.versions[version-2]
+	}
+	return &domain.PostVersionDiff{Version: current, Previous: previous, Changes: domain.DiffPostVersions(previous, current)}, nil
+}
+
+func (m *mockHistoryService) RestoreVersion(ctx context.Context, postID, version int) (*domain.Post, error) {
+	if version < 1 || version > len(m.versions) {
+		return nil, domain.ErrPostVersionNotFound
+	}
+	m.restored = append(m.restored, version)
+	restored := m.versions[version-1]
+	return &domain.Post{ID: postID, Title: restored.Title, Body: restored.Body}, nil
+}
+
+func newMockHistoryService() *mockHistoryService {
+	return &mockHistoryService{versions: []*domain.PostVersion{
+		{PostID: 1, Version: 1, Operation: domain.PostVersionCreated, Title: "Draft", Body: "Body", ChangedFields: []string{"title", "body"}},
+		{PostID: 1, Version: 2, Operation: domain.PostVersionUpdated, Title: "<b>Final</b>", Body: "Body", ChangedFields: []string{"title"}},
+	}}
+}
+
+func TestHistoryHandlers_ServeHistory(t *testing.T) {
+	handler := NewHistoryHandlers(newMockHistoryService())
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHistory(recorder, httptest.NewRequest(http.MethodGet, "/dashboard/history?id=1", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	body := recorder.Body.String()
+	for _, want := range []string{"Version 2", `<div class="old">Draft</div>`, `<div class="new">&lt;b&gt;Final&lt;/b&gt;</div>`, "restoreVersion(1, 2)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("response should contain %q", want)
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHistory(recorder, httptest.NewRequest(http.MethodGet, "/dashboard/history?id=1&version=5", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown version, got %d", http.StatusNotFound, recorder.Code)
+	}
+}
+
+func TestHistoryHandlers_GetVersion(t *testing.T) {
+	handler := NewHistoryHandlers(newMockHistoryService())
+
+	tests := []struct {
+		version string
+		status  int
+	}{
+		{"2", http.StatusOK},
+		{"3", http.StatusNotFound},
+		{"abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/posts/1/history/"+tt.version, nil), map[string]string{"id": "1", "version": tt.version})
+		handler.GetVersion(recorder, req)
+		if recorder.Code != tt.status {
+			t.Errorf("getting version %s: expected status %d, got %d", tt.version, tt.status, recorder.Code)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/posts/1/history/2", nil), map[string]string{"id": "1", "version": "2"})
+	handler.GetVersion(recorder, req)
+
+	var diff domain.PostVersionDiff
+	if err := json.NewDecoder(recorder.Body).Decode(&diff); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Field != "title" || diff.Changes[0].Old != "Draft" {
+		t.Errorf("expected a title change from Draft, got %+v", diff.Changes)
+	}
+}
+
+func TestHistoryHandlers_ListVersions(t *testing.T) {
+	handler := NewHistoryHandlers(&mockHistoryService{})
+
+	recorder := httptest.NewRecorder()
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/posts/1/history", nil), map[string]string{"id": "1"})
+	handler.ListVersions(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if strings.TrimSpace(recorder.Body.String()) != "[]" {
+		t.Errorf("expected an empty JSON array, got %s", recorder.Body.String())
+	}
+}
+
+func TestHistoryHandlers_RestoreVersion(t *testing.T) {
+	service := newMockHistoryService()
+	handler := NewHistoryHandlers(service)
+
+	recorder := httptest.NewRecorder()
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/posts/1/history/1/restore", nil), map[string]string{"id": "1", "version": "1"})
+	handler.RestoreVersion(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if len(service.restored) != 1 || service.restored[0] != 1 {
+		t.Errorf("expected version 1 to be restored, got %v", service.restored)
+	}
+
+	var post domain.Post
+	if err := json.NewDecoder(recorder.Body).Decode(&post); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if post.Title != "Draft" {
+		t.Errorf("expected the restored post, got %+v", post)
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"blog-cdc-search/application/service"
+	"blog-cdc-search/domain"
+)
+
+// SearchAnalyticsService interface for mocking in tests
+type SearchAnalyticsService interface {
+	SessionID(clientAddr, userAgent string) string
+	RecordSearch(ctx context.Context, query string, results, page int, latency time.Duration, session string) (int64, error)
+	RecordClick(ctx context.Context, searchID int64, postID, position int) error
+	Report(ctx context.Context, days int) (*domain.SearchAnalyticsReport, error)
+}
+
+// SearchAnalyticsHandlers handles result clicks and the analytics reports
+type SearchAnalyticsHandlers struct {
+	AnalyticsService SearchAnalyticsService
+}
+
+// NewSearchAnalyticsHandlers creates a new search analytics handlers instance
+func NewSearchAnalyticsHandlers(analyticsService SearchAnalyticsService) *SearchAnalyticsHandlers {
+	return &SearchAnalyticsHandlers{AnalyticsService: analyticsService}
+}
+
+// RecordClick handles POST /api/search/click. Without analytics the click is
+// accepted and dropped, so the home page works the same either way.
+func (h *SearchAnalyticsHandlers) RecordClick(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SearchID int64 `json:"search_id"`
+		PostID   int   `json:"post_id"`
+		Position int   `json:"position"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if h.AnalyticsService != nil {
+		err := h.AnalyticsService.RecordClick(r.Context(), req.SearchID, req.PostID, req.Position)
+		if errors.Is(err, domain.ErrInvalidSearchClick) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeSearchAnalytics serves the dashboard page with the top and
+// zero-result queries of the last ?days
+func (h *SearchAnalyticsHandlers) ServeSearchAnalytics(w http.ResponseWriter, r *http.Request) {
+	report, days, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	html := generateSearchAnalyticsHTML(report, days)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(html))
+}
+
+// GetReport handles GET /api/admin/search/analytics
+func (h *SearchAnalyticsHandlers) GetReport(w http.ResponseWriter, r *http.Request) {
+	report, _, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// report builds the report for the ?days of a request, writing the error
+// response if it cannot
+func (h *SearchAnalyticsHandlers) report(w http.ResponseWriter, r *http.Request) (*domain.SearchAnalyticsReport, int, bool) {
+	if h.AnalyticsService == nil {
+		http.Error(w, "Search analytics are disabled", http.StatusNotFound)
+		return nil, 0, false
+	}
+
+	days := service.DefaultSearchAnalyticsDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid days, expected a positive number", http.StatusBadRequest)
+			return nil, 0, false
+		}
+		days = parsed
+	}
+
+	report, err := h.AnalyticsService.Report(r.Context(), days)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, 0, false
+	}
+	return report, days, true
+}
+
+// recordSearch records a search for analytics and sets its ID on the
+// response, so clicks on its results can refer to it. Failures are logged
+// rather than failing the search.
+func (h *SearchHandlers) recordSearch(r *http.Request, results interface{}, latency time.Duration) {
+	response, ok := results.(*service.SearchResponse)
+	if h.Analytics == nil || !ok || response == nil {
+		return
+	}
+
+	session := h.Analytics.SessionID(clientAddress(r), r.UserAgent())
+	id, err := h.Analytics.RecordSearch(r.Context(), response.Query, response.Total, response.Page, latency, session)
+	if err != nil {
+		log.Printf("Failed to record search: %v", err)
+		return
+	}
+	response.SearchID = id
+}
+
+// clientAddress returns the IP address of the client of a request
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-cdc-search/application/service"
+	"blog-cdc-search/domain"
+)
+
+// mockSearchAnalyticsService records searches and clicks in memory
+type mockSearchAnalyticsService struct {
+	searches []string
+	sessions []string
+	clicks   []domain.SearchClick
+	days     int
+	report   *domain.SearchAnalyticsReport
+	err      error
+}
+
+func (m *mockSearchAnalyticsService) SessionID(clientAddr, userAgent string) string {
+	return clientAddr + "|" + userAgent
+}
+
+func (m *mockSearchAnalyticsService) RecordSearch(ctx context.Context, query string, results, page int, latency time.Duration, session string) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.searches = append(m.searches, fmt.Sprintf("%s:%d:%d", query, results, page))
+	m.sessions = append(m.sessions, session)
+	return int64(len(m.searches)), nil
+}
+
+func (m *mockSearchAnalyticsService) RecordClick(ctx context.Context, searchID int64, postID, position int) error {
+	if searchID <= 0 || postID <= 0 || position <= 0 {
+		return domain.ErrInvalidSearchClick
+	}
+	m.clicks = append(m.clicks, domain.SearchClick{SearchID: searchID, PostID: postID, Position: position})
+	return m.err
+}
+
+func (m *mockSearchAnalyticsService) Report(ctx context.Context, days int) (*domain.SearchAnalyticsReport, error) {
+	m.days = days
+	return m.report, m.err
+}
+
+func TestSearchPosts_RecordsSearch(t *testing.T) {
+	mockSearchService := &MockSearchService{
+		searchResults: &service.SearchResponse{Results: []*service.SearchResult{}, Total: 3, Page: 2, PerPage: 10, Query: "go"},
+	}
+	analytics := &mockSearchAnalyticsService{}
+	handlers := NewSearchHandlers(&BaseHandler{SearchService: mockSearchService})
+	handlers.Analytics = analytics
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search?q=go&page=2", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("User-Agent", "Firefox")
+	w := httptest.NewRecorder()
+	handlers.SearchPostsGet(w, req)
+
+	if len(analytics.searches) != 1 || analytics.searches[0] != "go:3:2" {
+		t.Fatalf("Expected the search to be recorded, got %v", analytics.searches)
+	}
+	if analytics.sessions[0] != "203.0.113.7|Firefox" {
+		t.Errorf("Expected the session from the client address and user agent, got %q", analytics.sessions[0])
+	}
+
+	var response service.SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.SearchID != 1 {
+		t.Errorf("Expected search ID 1 in the response, got %d", response.SearchID)
+	}
+
+	// A failure to record does not fail the search
+	analytics.err = errors.New("database down")
+	mockSearchService.searchResults.SearchID = 0
+	w = httptest.NewRecorder()
+	handlers.SearchPosts(w, httptest.NewRequest(http.MethodPost, "/api/search", strings.NewReader(`{"query":"go"}`)))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	response = service.SearchResponse{}
+	json.NewDecoder(w.Body).Decode(&response)
+	if response.SearchID != 0 {
+		t.Errorf("Expected no search ID, got %d", response.SearchID)
+	}
+}
+
+func TestSearchAnalyticsHandlers_RecordClick(t *testing.T) {
+	analytics := &mockSearchAnalyticsService{}
+	handlers := NewSearchAnalyticsHandlers(analytics)
+
+	w := httptest.NewRecorder()
+	handlers.RecordClick(w, httptest.NewRequest(http.MethodPost, "/api/search/click", strings.NewReader(`{"search_id":4,"post_id":2,"position":11}`)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	if len(analytics.clicks) != 1 || analytics.clicks[0] != (domain.SearchClick{SearchID: 4, PostID: 2, Position: 11}) {
+		t.Errorf("Unexpected clicks: %+v", analytics.clicks)
+	}
+
+	for _, body := range []string{`{"search_id":4}`, `not json`} {
+		w = httptest.NewRecorder()
+		handlers.RecordClick(w, httptest.NewRequest(http.MethodPost, "/api/search/click", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, w.Code)
+		}
+	}
+
+	// Without analytics clicks are accepted and dropped
+	w = httptest.NewRecorder()
+	NewSearchAnalyticsHandlers(nil).RecordClick(w, httptest.NewRequest(http.MethodPost, "/api/search/click", strings.NewReader(`{"search_id":4,"post_id":2,"position":1}`)))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204 without analytics, got %d", w.Code)
+	}
+}
+
+func TestSearchAnalyticsHandlers_Report(t *testing.T) {
+	analytics := &mockSearchAnalyticsService{
+		report: &domain.SearchAnalyticsReport{
+			Summary:           domain.SearchAnalyticsSummary{Searches: 10, CTR: 0.4},
+			TopQueries:        []*domain.SearchQueryStats{{Query: "<go>", Searches: 6, CTR: 0.5}},
+			ZeroResultQueries: []*domain.SearchQueryStats{{Query: "rust", Searches: 2}},
+		},
+	}
+	handlers := NewSearchAnalyticsHandlers(analytics)
+
+	w := httptest.NewRecorder()
+	handlers.ServeSearchAnalytics(w, httptest.NewRequest(http.MethodGet, "/dashboard/search/analytics?days=7", nil))
+	if w.Code != http.StatusOK || analytics.days != 7 {
+		t.Fatalf("Expected status 200 for 7 days, got %d for %d", w.Code, analytics.days)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"&lt;go&gt;", "50.0%", "rust", "40.0%"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the page to contain %q", want)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	handlers.GetReport(w, httptest.NewRequest(http.MethodGet, "/api/admin/search/analytics", nil))
+	var report domain.SearchAnalyticsReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if analytics.days != service.DefaultSearchAnalyticsDays || len(report.TopQueries) != 1 {
+		t.Errorf("Expected the default period, got %d days and %+v", analytics.days, report)
+	}
+
+	w = httptest.NewRecorder()
+	handlers.GetReport(w, httptest.NewRequest(http.MethodGet, "/api/admin/search/analytics?days=week", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid days, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	NewSearchAnalyticsHandlers(nil).GetReport(w, httptest.NewRequest(http.MethodGet, "/api/admin/search/analytics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without analytics, got %d", w.Code)
+	}
+}
@@ -4,31 +4,59 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"blog-cdc-search/application/service"
 	"blog-cdc-search/domain"
+
+	"github.com/gorilla/mux"
 )
 
 // MockSearchService is a mock implementation for testing
 type MockSearchService struct {
 	searchResults *service.SearchResponse
 	searchError   error
+	params        interface{}
+	suggestions   *service.SuggestResponse
+	suggestQuery  string
+	related       []*domain.Post
+	relatedPost   *domain.Post
 }
 
 func (m *MockSearchService) SearchPosts(ctx context.Context, params interface{}) (interface{}, error) {
+	m.params = params
 	if m.searchError != nil {
 		return nil, m.searchError
 	}
 	return m.searchResults, nil
 }
 
+func (m *MockSearchService) SuggestPosts(ctx context.Context, query string) (interface{}, error) {
+	m.suggestQuery = query
+	if m.searchError != nil {
+		return nil, m.searchError
+	}
+	return m.suggestions, nil
+}
+
 func (m *MockSearchService) GetAllPostsFromIndex(ctx context.Context) ([]*domain.Post, error) {
 	return nil, nil
 }
 
+func (m *MockSearchService) RelatedPosts(ctx context.Context, post *domain.Post) ([]*domain.Post, error) {
+	m.relatedPost = post
+	if m.searchError != nil {
+		return nil, m.searchError
+	}
+	return m.related, nil
+}
+
 func TestNewSearchHandlers(t *testing.T) {
 	mockSearchService := &MockSearchService{}
 	base := &BaseHandler{
@@ -78,11 +106,15 @@ func TestSearchPosts_PostMethod(t *testing.T) {
 
 	// Create request
 	reqBody := map[string]interface{}{
-		"query":     "test",
-		"page":      1,
-		"per_page":  10,
-		"sort_by":   "created_at:desc",
-		"filter_by": "",
+		"query":    "test",
+		"page":     1,
+		"per_page": 10,
+		"sort_by":  "newest",
+		"filters": map[string]interface{}{
+			"created_after": "2024-01-01",
+			"has_image":     true,
+			"tags":          []string{"go"},
+		},
 	}
 
 	reqBodyBytes, _ := json.Marshal(reqBody)
@@ -100,6 +132,12 @@ func TestSearchPosts_PostMethod(t *testing.T) {
 		t.Errorf("Expected status OK, got: %d", w.Code)
 	}
 
+	params := mockSearchService.params.(service.SearchParams)
+	if params.SortBy != "newest" || params.Filters.CreatedAfter == nil || !params.Filters.CreatedAfter.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+		params.Filters.HasImage == nil || !*params.Filters.HasImage || len(params.Filters.Tags) != 1 {
+		t.Errorf("Expected the sort and filters to be passed on, got: %+v", params)
+	}
+
 	// Parse response
 	var response service.SearchResponse
 	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
@@ -337,6 +375,65 @@ func TestSearchPostsGet_DefaultPagination(t *testing.T) {
 	}
 }
 
+func TestSearchPostsGet_PageLinks(t *testing.T) {
+	mockSearchService := &MockSearchService{
+		searchResults: &service.SearchResponse{
+			Results:    []*service.SearchResult{},
+			Total:      25,
+			Page:       2,
+			PerPage:    10,
+			TotalPages: 3,
+			Query:      "go cdc",
+			SortBy:     "oldest",
+			Filters:    &service.SearchFilters{Tags: []string{"go", "cdc"}, Category: "News"},
+		},
+	}
+	handlers := NewSearchHandlers(&BaseHandler{SearchService: mockSearchService})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search?q=go+cdc&page=2&sort=oldest&tag=go&tag=cdc&category=News", nil)
+	w := httptest.NewRecorder()
+	handlers.SearchPostsGet(w, req)
+
+	if params := mockSearchService.params.(service.SearchParams); params.SortBy != "oldest" || len(params.Filters.Tags) != 2 || params.Filters.Category != "News" || params.Page != 2 {
+		t.Errorf("Expected the sort, filters and page to be passed on, got: %+v", params)
+	}
+
+	var response service.SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	links := map[string]string{
+		"self": "/api/search?category=News&page=2&per_page=10&q=go+cdc&sort=oldest&tag=go&tag=cdc",
+		"next": "/api/search?category=News&page=3&per_page=10&q=go+cdc&sort=oldest&tag=go&tag=cdc",
+		"prev": "/api/search?category=News&page=1&per_page=10&q=go+cdc&sort=oldest&tag=go&tag=cdc",
+	}
+	for name, got := range map[string]string{"self": response.Links.Self, "next": response.Links.Next, "prev": response.Links.Prev} {
+		if got != links[name] {
+			t.Errorf("Expected %s link %q, got: %q", name, links[name], got)
+		}
+	}
+
+	// The last page has no next link and the first no previous one
+	mockSearchService.searchResults.Page = 3
+	w = httptest.NewRecorder()
+	handlers.SearchPostsGet(w, httptest.NewRequest(http.MethodGet, "/api/search?q=go+cdc&page=3", nil))
+	response = service.SearchResponse{}
+	json.NewDecoder(w.Body).Decode(&response)
+	if response.Links.Next != "" || response.Links.Prev == "" {
+		t.Errorf("Expected only a prev link on the last page, got: %+v", response.Links)
+	}
+
+	mockSearchService.searchResults.Page = 1
+	w = httptest.NewRecorder()
+	handlers.SearchPostsGet(w, httptest.NewRequest(http.MethodGet, "/api/search?q=go+cdc", nil))
+	response = service.SearchResponse{}
+	json.NewDecoder(w.Body).Decode(&response)
+	if response.Links.Prev != "" || response.Links.Next == "" {
+		t.Errorf("Expected only a next link on the first page, got: %+v", response.Links)
+	}
+}
+
 func TestSearchPostsGet_ServiceError(t *testing.T) {
 	mockSearchService := &MockSearchService{
 		searchError: domain.ErrPostNotFound,
@@ -360,3 +457,183 @@ func TestSearchPostsGet_ServiceError(t *testing.T) {
 		t.Errorf("Expected status Internal Server Error, got: %d", w.Code)
 	}
 }
+
+func TestSearchPostsGet_Filters(t *testing.T) {
+	mockSearchService := &MockSearchService{searchResults: &service.SearchResponse{}}
+	handlers := NewSearchHandlers(&BaseHandler{SearchService: mockSearchService})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search?q=go&created_after=2024-01-01T12:00:00Z&created_before=2024-02-01&has_image=false&author=Ada&year=2024&month=2024-05&language=fa", nil)
+	w := httptest.NewRecorder()
+	handlers.SearchPostsGet(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status OK, got: %d", w.Code)
+	}
+	filters := mockSearchService.params.(service.SearchParams).Filters
+	if filters.CreatedAfter == nil || !filters.CreatedAfter.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected created_after to be parsed, got: %v", filters.CreatedAfter)
+	}
+	if filters.CreatedBefore == nil || !filters.CreatedBefore.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected created_before to be parsed, got: %v", filters.CreatedBefore)
+	}
+	if filters.HasImage == nil || *filters.HasImage {
+		t.Errorf("Expected has_image false, got: %v", filters.HasImage)
+	}
+	if filters.Author != "Ada" || filters.Year != 2024 || filters.Month != "2024-05" || filters.Language != "fa" {
+		t.Errorf("Expected author, year, month and language to be passed on, got: %+v", filters)
+	}
+}
+
+func TestSearchPostsGet_FacetLinks(t *testing.T) {
+	mockSearchService := &MockSearchService{
+		searchResults: &service.SearchResponse{
+			Results:    []*service.SearchResult{},
+			Total:      12,
+			Page:       1,
+			PerPage:    10,
+			TotalPages: 2,
+			Query:      "go",
+			Filters:    &service.SearchFilters{Author: "Ada Lovelace", Year: 2024, Month: "2024-05"},
+		},
+	}
+	handlers := NewSearchHandlers(&BaseHandler{SearchService: mockSearchService})
+
+	w := httptest.NewRecorder()
+	handlers.SearchPostsGet(w, httptest.NewRequest(http.MethodGet, "/api/search?q=go&author=Ada+Lovelace&year=2024&month=2024-05", nil))
+
+	var response service.SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expected := "/api/search?author=Ada+Lovelace&month=2024-05&page=2&per_page=10&q=go&year=2024"
+	if response.Links.Next != expected {
+		t.Errorf("Expected next link %q, got: %q", expected, response.Links.Next)
+	}
+}
+
+func TestSuggestPosts(t *testing.T) {
+	mockSearchService := &MockSearchService{
+		suggestions: &service.SuggestResponse{
+			Query:       "chan",
+			Completions: []string{"Change Data Capture"},
+			Posts:       []*service.SuggestedPost{{ID: 1, Title: "Change Data Capture"}},
+		},
+	}
+	handlers := NewSearchHandlers(&BaseHandler{SearchService: mockSearchService})
+
+	w := httptest.NewRecorder()
+	handlers.SuggestPosts(w, httptest.NewRequest(http.MethodGet, "/api/search/suggest?q=Chan", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status OK, got: %d", w.Code)
+	}
+	if mockSearchService.suggestQuery != "Chan" {
+		t.Errorf("Expected the query to be passed on, got: %q", mockSearchService.suggestQuery)
+	}
+	if cacheControl := w.Header().Get("Cache-Control"); cacheControl != "public, max-age=30" {
+		t.Errorf("Expected a cacheable response, got Cache-Control %q", cacheControl)
+	}
+
+	var response service.SuggestResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Completions) != 1 || len(response.Posts) != 1 || response.Posts[0].ID != 1 {
+		t.Errorf("Unexpected suggestions: %+v", response)
+	}
+}
+
+func TestSuggestPosts_ServiceError(t *testing.T) {
+	handlers := NewSearchHandlers(&BaseHandler{SearchService: &MockSearchService{searchError: errors.New("index down")}})
+
+	w := httptest.NewRecorder()
+	handlers.SuggestPosts(w, httptest.NewRequest(http.MethodGet, "/api/search/suggest?q=go", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status Internal Server Error, got: %d", w.Code)
+	}
+	if w.Header().Get("Cache-Control") != "" {
+		t.Error("Expected errors not to be cacheable")
+	}
+}
+
+func TestSearchPosts_InvalidSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		err    error
+	}{
+		{"bad date", http.MethodGet, "/api/search?q=go&created_after=yesterday", "", nil},
+		{"bad has_image", http.MethodGet, "/api/search?q=go&has_image=maybe", "", nil},
+		{"bad year", http.MethodGet, "/api/search?q=go&year=last", "", nil},
+		{"bad month", http.MethodPost, "/api/search", `{"query":"go","filters":{"month":"May"}}`, fmt.Errorf("%w: month", domain.ErrInvalidSearch)},
+		{"bad body date", http.MethodPost, "/api/search", `{"query":"go","filters":{"created_before":"01/02/2024"}}`, nil},
+		{"unknown sort", http.MethodGet, "/api/search?q=go&sort=title", "", fmt.Errorf("%w: unknown sort", domain.ErrInvalidSearch)},
+		{"rejected filter", http.MethodPost, "/api/search", `{"query":"go","filters":{"category":"a` + "`" + `"}}`, fmt.Errorf("%w: backtick", domain.ErrInvalidSearch)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers := NewSearchHandlers(&BaseHandler{SearchService: &MockSearchService{searchError: tt.err}})
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			if tt.method == http.MethodGet {
+				handlers.SearchPostsGet(w, req)
+			} else {
+				handlers.SearchPosts(w, req)
+			}
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status Bad Request, got: %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestSearchHandlers_RelatedPosts(t *testing.T) {
+	mockPostService := NewMockPostService()
+	mockPostService.CreatePost(context.Background(), "Change Data Capture", "", "Streaming changes", "Body", "", "", "", nil)
+	mockSearchService := &MockSearchService{related: []*domain.Post{{ID: 2, Title: "Debezium"}}}
+	handler := NewSearchHandlers(&BaseHandler{PostService: mockPostService, SearchService: mockSearchService})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/posts/{id}/related", handler.RelatedPosts).Methods("GET")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/posts/1/related", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	var related []*domain.Post
+	if err := json.NewDecoder(recorder.Body).Decode(&related); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(related) != 1 || related[0].ID != 2 {
+		t.Errorf("expected post 2, got %v", related)
+	}
+	if mockSearchService.relatedPost == nil || mockSearchService.relatedPost.Title != "Change Data Capture" {
+		t.Errorf("expected related posts of post 1, got %v", mockSearchService.relatedPost)
+	}
+
+	tests := []struct {
+		path           string
+		searchError    error
+		expectedStatus int
+	}{
+		{"/api/posts/999/related", nil, http.StatusNotFound},
+		{"/api/posts/abc/related", nil, http.StatusBadRequest},
+		{"/api/posts/1/related", errors.New("index down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		mockSearchService.searchError = tt.searchError
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if recorder.Code != tt.expectedStatus {
+			t.Errorf("GET %s: expected status %d, got %d", tt.path, tt.expectedStatus, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"blog-cdc-search/application/service"
+	"blog-cdc-search/domain"
+)
+
+// SearchKeyService interface for mocking in tests
+type SearchKeyService interface {
+	Key(ctx context.Context) (*service.ScopedSearchKey, error)
+}
+
+// SearchKeyHandlers hands out scoped keys for searching the index directly
+type SearchKeyHandlers struct {
+	KeyService SearchKeyService
+}
+
+// NewSearchKeyHandlers creates a new search key handlers instance
+func NewSearchKeyHandlers(keyService SearchKeyService) *SearchKeyHandlers {
+	return &SearchKeyHandlers{KeyService: keyService}
+}
+
+// GetSearchKey handles GET /api/search/key. Every response carries a new
+// key, so it must not be cached.
+func (h *SearchKeyHandlers) GetSearchKey(w http.ResponseWriter, r *http.Request) {
+	if h.KeyService == nil {
+		http.Error(w, "Search keys are disabled", http.StatusNotFound)
+		return
+	}
+
+	key, err := h.KeyService.Key(r.Context())
+	if errors.Is(err, domain.ErrServiceUnavailable) {
+		log.Printf("Failed to hand out search key: %v", err)
+		http.Error(w, "Search is temporarily unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(key)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"blog-cdc-search/application/service"
+	"blog-cdc-search/domain"
+)
+
+// mockSearchKeyService hands out numbered keys
+type mockSearchKeyService struct {
+	issued int
+	err    error
+}
+
+func (m *mockSearchKeyService) Key(ctx context.Context) (*service.ScopedSearchKey, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.issued++
+	return &service.ScopedSearchKey{Key: fmt.Sprintf("key-%d", m.issued), Collection: "posts", Nodes: []string{"https://search.example.com"}}, nil
+}
+
+func TestSearchKeyHandlers_GetSearchKey(t *testing.T) {
+	keys := &mockSearchKeyService{}
+	handlers := NewSearchKeyHandlers(keys)
+
+	w := httptest.NewRecorder()
+	handlers.GetSearchKey(w, httptest.NewRequest(http.MethodGet, "/api/search/key", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if cacheControl := w.Header().Get("Cache-Control"); cacheControl != "no-store" {
+		t.Errorf("Expected the key not to be cached, got Cache-Control %q", cacheControl)
+	}
+	var key service.ScopedSearchKey
+	if err := json.NewDecoder(w.Body).Decode(&key); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if key.Key != "key-1" || key.Collection != "posts" || len(key.Nodes) != 1 {
+		t.Errorf("Unexpected key: %+v", key)
+	}
+
+	keys.err = fmt.Errorf("failed to rotate search key: %w", domain.ErrServiceUnavailable)
+	w = httptest.NewRecorder()
+	handlers.GetSearchKey(w, httptest.NewRequest(http.MethodGet, "/api/search/key", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 while the index is down, got %d", w.Code)
+	}
+
+	keys.err = errors.New("bad parent key")
+	w = httptest.NewRecorder()
+	handlers.GetSearchKey(w, httptest.NewRequest(http.MethodGet, "/api/search/key", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	NewSearchKeyHandlers(nil).GetSearchKey(w, httptest.NewRequest(http.MethodGet, "/api/search/key", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without search keys, got %d", w.Code)
+	}
+}
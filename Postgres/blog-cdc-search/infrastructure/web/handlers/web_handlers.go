@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"strconv"
 
@@ -63,7 +64,13 @@ func (h *WebHandlers) ServePostDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	html := generatePostDetailHTML(post)
+	// The post is still shown when the search index cannot be reached
+	related, err := h.SearchService.RelatedPosts(r.Context(), post)
+	if err != nil {
+		log.Printf("Failed to find related posts for post %d: %v", id, err)
+	}
+
+	html := generatePostDetailHTML(post, related)
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Write([]byte(html))
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -14,7 +15,9 @@ import (
 
 // MockSearchServiceForWeb implements SearchService interface for testing
 type MockSearchServiceForWeb struct {
-	posts []*domain.Post
+	posts        []*domain.Post
+	related      []*domain.Post
+	relatedError error
 }
 
 func NewMockSearchServiceForWeb() *MockSearchServiceForWeb {
@@ -27,10 +30,18 @@ func (m *MockSearchServiceForWeb) SearchPosts(ctx context.Context, params interf
 	return nil, nil
 }
 
+func (m *MockSearchServiceForWeb) SuggestPosts(ctx context.Context, query string) (interface{}, error) {
+	return nil, nil
+}
+
 func (m *MockSearchServiceForWeb) GetAllPostsFromIndex(ctx context.Context) ([]*domain.Post, error) {
 	return m.posts, nil
 }
 
+func (m *MockSearchServiceForWeb) RelatedPosts(ctx context.Context, post *domain.Post) ([]*domain.Post, error) {
+	return m.related, m.relatedError
+}
+
 func (m *MockSearchServiceForWeb) AddPost(post *domain.Post) {
 	m.posts = append(m.posts, post)
 }
@@ -46,8 +57,8 @@ func TestWebHandlers_ServeHomePage(t *testing.T) {
 	handler := NewWebHandlers(base)
 
 	// Create test posts
-	post1, _ := mockPostService.CreatePost(context.Background(), "Post 1", "image1.jpg", "excerpt1", "body1")
-	post2, _ := mockPostService.CreatePost(context.Background(), "Post 2", "image2.jpg", "excerpt2", "body2")
+	post1, _ := mockPostService.CreatePost(context.Background(), "Post 1", "image1.jpg", "excerpt1", "body1", "", "", "", nil)
+	post2, _ := mockPostService.CreatePost(context.Background(), "Post 2", "image2.jpg", "excerpt2", "body2", "", "", "", nil)
 
 	// Add posts to search service
 	mockSearchService.AddPost(post1)
@@ -85,6 +96,14 @@ func TestWebHandlers_ServeHomePage(t *testing.T) {
 	if !strings.Contains(body, "Admin Dashboard") {
 		t.Error("response should contain admin dashboard link")
 	}
+
+	if !strings.Contains(body, `id="facets"`) || !strings.Contains(body, "filters: currentFilters") {
+		t.Error("response should render search facets and send the selected filters")
+	}
+
+	if !strings.Contains(body, "/api/search/suggest?q=") {
+		t.Error("response should fetch suggestions as the user types")
+	}
 }
 
 func TestWebHandlers_ServeHomePage_EmptyPosts(t *testing.T) {
@@ -114,11 +133,11 @@ func TestWebHandlers_ServeHomePage_EmptyPosts(t *testing.T) {
 
 func TestWebHandlers_ServePostDetail(t *testing.T) {
 	mockService := NewMockPostService()
-	base := &BaseHandler{PostService: mockService}
+	base := &BaseHandler{PostService: mockService, SearchService: NewMockSearchServiceForWeb()}
 	handler := NewWebHandlers(base)
 
 	// Create a test post
-	mockService.CreatePost(context.Background(), "Test Post", "image.jpg", "Test excerpt", "Test body content")
+	mockService.CreatePost(context.Background(), "Test Post", "image.jpg", "Test excerpt", "Test body content", "", "", "", nil)
 
 	tests := []struct {
 		name           string
@@ -185,6 +204,39 @@ func TestWebHandlers_ServePostDetail(t *testing.T) {
 	}
 }
 
+func TestWebHandlers_ServePostDetail_RelatedPosts(t *testing.T) {
+	mockService := NewMockPostService()
+	mockSearchService := NewMockSearchServiceForWeb()
+	handler := NewWebHandlers(&BaseHandler{PostService: mockService, SearchService: mockSearchService})
+
+	mockService.CreatePost(context.Background(), "Change Data Capture", "", "Streaming changes", "Body", "", "", "", nil)
+	mockSearchService.related = []*domain.Post{{ID: 2, Title: "Debezium <Connectors>", Excerpt: "Setting up"}}
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/post/1", nil), map[string]string{"id": "1"})
+	recorder := httptest.NewRecorder()
+	handler.ServePostDetail(recorder, req)
+
+	body := recorder.Body.String()
+	for _, want := range []string{"Related posts", `href="/post/2"`, "Debezium &lt;Connectors&gt;", "Setting up"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected page to contain %q", want)
+		}
+	}
+
+	// The post is served without the block when related posts fail
+	mockSearchService.related = nil
+	mockSearchService.relatedError = errors.New("index down")
+	recorder = httptest.NewRecorder()
+	handler.ServePostDetail(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if strings.Contains(recorder.Body.String(), "Related posts") {
+		t.Error("expected no related posts block")
+	}
+}
+
 func TestWebHandlers_MethodNotAllowed(t *testing.T) {
 	mockService := NewMockPostService()
 	base := &BaseHandler{PostService: mockService}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"blog-cdc-search/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhookService interface for mocking in tests
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, url, secret string, events []string) (*domain.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]*domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id int) error
+	ListDeliveries(ctx context.Context, limit int) ([]*domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, id int64) (*domain.WebhookDelivery, error)
+}
+
+// WebhookHandlers handles webhook endpoint management and the delivery log
+type WebhookHandlers struct {
+	WebhookService WebhookService
+}
+
+// NewWebhookHandlers creates a new webhook handlers instance
+func NewWebhookHandlers(webhookService WebhookService) *WebhookHandlers {
+	return &WebhookHandlers{WebhookService: webhookService}
+}
+
+// ServeWebhooks serves the dashboard page listing endpoints and deliveries
+func (h *WebhookHandlers) ServeWebhooks(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.WebhookService.ListEndpoints(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	deliveries, err := h.WebhookService.ListDeliveries(r.Context(), 50)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	html := generateWebhooksHTML(endpoints, deliveries)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(html))
+}
+
+// ListEndpoints handles GET /api/webhooks
+func (h *WebhookHandlers) ListEndpoints(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.WebhookService.ListEndpoints(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if endpoints == nil {
+		endpoints = []*domain.WebhookEndpoint{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(endpoints)
+}
+
+// CreateEndpoint handles POST /api/webhooks. The response is the only place
+// the secret is returned.
+func (h *WebhookHandlers) CreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL    string   `json:"url"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	endpoint, err := h.WebhookService.CreateEndpoint(r.Context(), req.URL, req.Secret, req.Events)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(endpoint)
+}
+
+// DeleteEndpoint handles DELETE /api/webhooks/{id}
+func (h *WebhookHandlers) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.WebhookService.DeleteEndpoint(r.Context(), id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /api/webhooks/deliveries?limit={n}
+func (h *WebhookHandlers) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	deliveries, err := h.WebhookService.ListDeliveries(r.Context(), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []*domain.WebhookDelivery{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// Redeliver handles POST /api/webhooks/deliveries/{id}/redeliver
+func (h *WebhookHandlers) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid delivery ID", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := h.WebhookService.Redeliver(r.Context(), id)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delivery)
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
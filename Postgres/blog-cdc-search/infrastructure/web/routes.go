@@ -8,6 +8,52 @@ import (
 
 // SetupRoutes configures all the routes for the application
 func SetupRoutes(handlers *Handlers) *mux.Router {
+	router := SetupPublicRoutes(handlers)
+
+	// Admin dashboard routes
+	router.HandleFunc("/dashboard", handlers.ServeDashboard).Methods("GET")
+	router.HandleFunc("/dashboard/create", handlers.ServeCreateForm).Methods("GET")
+	router.HandleFunc("/dashboard/edit", handlers.ServeEditForm).Methods("GET")
+	router.HandleFunc("/dashboard/webhooks", handlers.ServeWebhooks).Methods("GET")
+	router.HandleFunc("/dashboard/history", handlers.ServePostHistory).Methods("GET")
+	router.HandleFunc("/dashboard/search", handlers.ServeSearchTuning).Methods("GET")
+	router.HandleFunc("/dashboard/search/analytics", handlers.ServeSearchAnalytics).Methods("GET")
+
+	// API routes
+	router.HandleFunc("/api/posts", handlers.CreatePost).Methods("POST")
+	router.HandleFunc("/api/posts", handlers.UpdatePost).Methods("PUT")
+	router.HandleFunc("/api/posts", handlers.DeletePost).Methods("DELETE")
+
+	// Post history API routes
+	router.HandleFunc("/api/posts/{id:[0-9]+}/history", handlers.ListPostVersions).Methods("GET")
+	router.HandleFunc("/api/posts/{id:[0-9]+}/history/{version:[0-9]+}", handlers.GetPostVersion).Methods("GET")
+	router.HandleFunc("/api/posts/{id:[0-9]+}/history/{version:[0-9]+}/restore", handlers.RestorePostVersion).Methods("POST")
+
+	// Webhook API routes
+	router.HandleFunc("/api/webhooks", handlers.ListWebhookEndpoints).Methods("GET")
+	router.HandleFunc("/api/webhooks", handlers.CreateWebhookEndpoint).Methods("POST")
+	router.HandleFunc("/api/webhooks/{id:[0-9]+}", handlers.DeleteWebhookEndpoint).Methods("DELETE")
+	router.HandleFunc("/api/webhooks/deliveries", handlers.ListWebhookDeliveries).Methods("GET")
+	router.HandleFunc("/api/webhooks/deliveries/{id:[0-9]+}/redeliver", handlers.RedeliverWebhook).Methods("POST")
+
+	// Search tuning API routes
+	router.HandleFunc("/api/admin/search/synonyms", handlers.ListSearchSynonyms).Methods("GET")
+	router.HandleFunc("/api/admin/search/synonyms", handlers.CreateSearchSynonym).Methods("POST")
+	router.HandleFunc("/api/admin/search/synonyms/{id:[0-9]+}", handlers.DeleteSearchSynonym).Methods("DELETE")
+	router.HandleFunc("/api/admin/search/curations", handlers.ListSearchCurations).Methods("GET")
+	router.HandleFunc("/api/admin/search/curations", handlers.CreateSearchCuration).Methods("POST")
+	router.HandleFunc("/api/admin/search/curations/{id:[0-9]+}", handlers.DeleteSearchCuration).Methods("DELETE")
+	router.HandleFunc("/api/admin/search/sync", handlers.SyncSearchTuning).Methods("POST")
+
+	// Search analytics API routes
+	router.HandleFunc("/api/admin/search/analytics", handlers.GetSearchAnalytics).Methods("GET")
+
+	return router
+}
+
+// SetupPublicRoutes configures the public pages and read-only APIs, which
+// are all a blog serving from the read model offers
+func SetupPublicRoutes(handlers *Handlers) *mux.Router {
 	router := mux.NewRouter()
 
 	// Debug route
@@ -19,21 +65,17 @@ func SetupRoutes(handlers *Handlers) *mux.Router {
 	router.HandleFunc("/", handlers.ServeHomePage).Methods("GET")
 	router.HandleFunc("/post/{id:[0-9]+}", handlers.ServePostDetail).Methods("GET")
 
-	// Admin dashboard routes
-	router.HandleFunc("/dashboard", handlers.ServeDashboard).Methods("GET")
-	router.HandleFunc("/dashboard/create", handlers.ServeCreateForm).Methods("GET")
-	router.HandleFunc("/dashboard/edit", handlers.ServeEditForm).Methods("GET")
-
-	// API routes
-	router.HandleFunc("/api/posts", handlers.CreatePost).Methods("POST")
+	// Read-only API routes
 	router.HandleFunc("/api/posts", handlers.GetAllPosts).Methods("GET")
 	router.HandleFunc("/api/posts", handlers.GetPost).Methods("GET")
-	router.HandleFunc("/api/posts", handlers.UpdatePost).Methods("PUT")
-	router.HandleFunc("/api/posts", handlers.DeletePost).Methods("DELETE")
+	router.HandleFunc("/api/posts/{id:[0-9]+}/related", handlers.RelatedPosts).Methods("GET")
 
 	// Search API routes
 	router.HandleFunc("/api/search", handlers.SearchPosts).Methods("POST")
 	router.HandleFunc("/api/search", handlers.SearchPostsGet).Methods("GET")
+	router.HandleFunc("/api/search/suggest", handlers.SuggestPosts).Methods("GET")
+	router.HandleFunc("/api/search/click", handlers.RecordSearchClick).Methods("POST")
+	router.HandleFunc("/api/search/key", handlers.GetSearchKey).Methods("GET")
 
 	return router
 }